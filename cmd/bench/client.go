@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// client calls a running Mentis instance over its HTTP API
+type client struct {
+	baseURL   string
+	apiKey    string
+	namespace string
+	http      *http.Client
+}
+
+func (c *client) publish(ctx context.Context, artifacts []domain.Artifact) (*domain.PublishResponse, error) {
+	var response domain.PublishResponse
+	err := c.post(ctx, "/v1/cache/publish", domain.PublishRequest{Objects: artifacts}, &response)
+	return &response, err
+}
+
+func (c *client) lookup(ctx context.Context, options domain.LookupOptions) (*domain.LookupResponse, error) {
+	var response domain.LookupResponse
+	err := c.post(ctx, "/v1/cache/lookup", domain.LookupRequest{Options: options}, &response)
+	return &response, err
+}
+
+func (c *client) createSession(ctx context.Context, goal string) (*domain.WorkflowSession, error) {
+	var session domain.WorkflowSession
+	err := c.post(ctx, "/v1/workflow/sessions", domain.CreateSessionRequest{Goal: goal}, &session)
+	return &session, err
+}
+
+func (c *client) executeStep(ctx context.Context, req domain.WorkflowStepRequest) (*domain.WorkflowStepResponse, error) {
+	var response domain.WorkflowStepResponse
+	err := c.post(ctx, "/v1/workflow/steps", req, &response)
+	return &response, err
+}
+
+// post sends body as JSON and decodes a successful response into out
+func (c *client) post(ctx context.Context, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.baseURL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Mentis-Namespace", c.namespace)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", http.MethodPost, path, resp.Status, bytes.TrimSpace(message))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,261 @@
+// Command bench drives load against a running Mentis instance. It publishes
+// synthetic artifacts, then runs a mixed lookup and workflow step workload and
+// reports latency percentiles, throughput and cache hit rates per operation
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type options struct {
+	url         string
+	apiKey      string
+	namespace   string
+	artifacts   int
+	batchSize   int
+	duration    time.Duration
+	concurrency int
+	lookupRatio float64
+	missRatio   float64
+	stepInputs  int
+	topK        int
+	timeout     time.Duration
+	seed        int64
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.url, "url", "http://localhost:8080", "base URL of the Mentis instance")
+	flag.StringVar(&opts.apiKey, "api-key", os.Getenv("MENTIS_API_KEY"), "API key sent as X-API-Key (default $MENTIS_API_KEY)")
+	flag.StringVar(&opts.namespace, "namespace", "", "namespace to run in; empty uses the key's namespace")
+	flag.IntVar(&opts.artifacts, "artifacts", 1000, "synthetic artifacts to publish before the workload; 0 skips publishing")
+	flag.IntVar(&opts.batchSize, "batch", 50, "artifacts per publish request")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "how long to run the mixed workload")
+	flag.IntVar(&opts.concurrency, "concurrency", 16, "concurrent requests")
+	flag.Float64Var(&opts.lookupRatio, "lookup-ratio", 0.7, "share of workload requests that are lookups; the rest are workflow steps")
+	flag.Float64Var(&opts.missRatio, "miss-ratio", 0.2, "share of lookups for text that was never published")
+	flag.IntVar(&opts.stepInputs, "step-inputs", 200, "distinct step inputs; steps repeat them, so fewer inputs mean more cache hits")
+	flag.IntVar(&opts.topK, "top-k", 5, "results per lookup")
+	flag.DurationVar(&opts.timeout, "timeout", 30*time.Second, "per-request timeout")
+	flag.Int64Var(&opts.seed, "seed", time.Now().UnixNano(), "random seed for generated content and the request mix")
+	flag.Parse()
+
+	if opts.concurrency <= 0 || opts.batchSize <= 0 || opts.stepInputs <= 0 || opts.artifacts < 0 {
+		log.Fatal("concurrency, batch and step-inputs must be positive, and artifacts not negative")
+	}
+	if opts.lookupRatio < 0 || opts.lookupRatio > 1 || opts.missRatio < 0 || opts.missRatio > 1 {
+		log.Fatal("lookup-ratio and miss-ratio must be between 0 and 1")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	b := &bench{
+		opts: opts,
+		client: &client{
+			baseURL:   opts.url,
+			apiKey:    opts.apiKey,
+			namespace: opts.namespace,
+			http: &http.Client{
+				Timeout: opts.timeout,
+				Transport: &http.Transport{
+					Proxy:               http.ProxyFromEnvironment,
+					MaxIdleConns:        opts.concurrency,
+					MaxIdleConnsPerHost: opts.concurrency,
+					IdleConnTimeout:     90 * time.Second,
+				},
+			},
+		},
+		runID: fmt.Sprintf("%x", rand.New(rand.NewSource(opts.seed)).Int63()),
+	}
+	if err := b.run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// bench holds the state of one run. Generated content carries the run ID so
+// repeated runs against the same instance publish new artifacts
+type bench struct {
+	opts   options
+	client *client
+	runID  string
+
+	// queries are phrases taken from published artifacts, so lookups for them should hit
+	queries []string
+}
+
+func (b *bench) run(ctx context.Context) error {
+	fmt.Printf("Benchmarking %s (run %s, seed %d)\n", b.opts.url, b.runID, b.opts.seed)
+
+	if b.opts.artifacts > 0 {
+		if err := b.publish(ctx); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil || b.opts.duration <= 0 {
+		return nil
+	}
+
+	session, err := b.client.createSession(ctx, "mentis benchmark run "+b.runID)
+	if err != nil {
+		return fmt.Errorf("failed to create benchmark session: %w", err)
+	}
+	b.mixed(ctx, session.ID)
+	return nil
+}
+
+// publish seeds the instance with synthetic artifacts, one batch per request
+func (b *bench) publish(ctx context.Context) error {
+	rng := rand.New(rand.NewSource(b.opts.seed))
+	var batches [][]domain.Artifact
+	for start := 0; start < b.opts.artifacts; start += b.opts.batchSize {
+		var batch []domain.Artifact
+		for i := start; i < min(start+b.opts.batchSize, b.opts.artifacts); i++ {
+			text := sentence(rng, 40)
+			b.queries = append(b.queries, firstWords(text, 6))
+			batch = append(batch, domain.Artifact{
+				Type:    domain.DERIVED,
+				Content: []byte(fmt.Sprintf("%s\n\nbench %s document %d", text, b.runID, i)),
+				Metadata: map[string]interface{}{
+					"source_url": fmt.Sprintf("bench://%s/%d", b.runID, i),
+					"bench_run":  b.runID,
+				},
+				Tags: []string{"bench"},
+			})
+		}
+		batches = append(batches, batch)
+	}
+
+	rec := newRecorder("publish", false)
+	work := make(chan []domain.Artifact)
+	var wg sync.WaitGroup
+	var failed sync.Once
+	var firstErr error
+	started := time.Now()
+	for i := 0; i < b.opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range work {
+				begin := time.Now()
+				_, err := b.client.publish(ctx, batch)
+				rec.record(time.Since(begin), false, err)
+				if err != nil && ctx.Err() == nil {
+					failed.Do(func() { firstErr = err })
+				}
+			}
+		}()
+	}
+	for _, batch := range batches {
+		if ctx.Err() != nil {
+			break
+		}
+		work <- batch
+	}
+	close(work)
+	wg.Wait()
+
+	printReport(os.Stdout, fmt.Sprintf("Publish: %d artifacts in batches of %d", b.opts.artifacts, b.opts.batchSize), time.Since(started), rec)
+	if rec.succeeded() == 0 && firstErr != nil {
+		return fmt.Errorf("every publish failed, first error: %w", firstErr)
+	}
+	if firstErr != nil {
+		fmt.Printf("Some publishes failed, first error: %v\n", firstErr)
+	}
+	return nil
+}
+
+// mixed runs lookups and workflow steps concurrently for the configured duration
+func (b *bench) mixed(ctx context.Context, sessionID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(ctx, b.opts.duration)
+	defer cancel()
+
+	lookups := newRecorder("lookup", true)
+	steps := newRecorder("step", true)
+
+	var wg sync.WaitGroup
+	started := time.Now()
+	for i := 0; i < b.opts.concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(b.opts.seed + int64(worker) + 1))
+			for ctx.Err() == nil {
+				if rng.Float64() < b.opts.lookupRatio {
+					b.lookup(ctx, rng, lookups)
+				} else {
+					b.step(ctx, rng, sessionID, steps)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	printReport(os.Stdout, fmt.Sprintf("Mixed workload: %d workers, %.0f%% lookups", b.opts.concurrency, b.opts.lookupRatio*100), time.Since(started), lookups, steps)
+}
+
+func (b *bench) lookup(ctx context.Context, rng *rand.Rand, rec *recorder) {
+	query := sentence(rng, 6)
+	if len(b.queries) > 0 && rng.Float64() >= b.opts.missRatio {
+		query = b.queries[rng.Intn(len(b.queries))]
+	}
+
+	begin := time.Now()
+	response, err := b.client.lookup(ctx, domain.LookupOptions{Query: query, TopK: b.opts.topK})
+	// Requests cut off by the end of the run are not counted
+	if ctx.Err() != nil {
+		return
+	}
+	rec.record(time.Since(begin), err == nil && len(response.Results) > 0, err)
+}
+
+func (b *bench) step(ctx context.Context, rng *rand.Rand, sessionID uuid.UUID, rec *recorder) {
+	req := domain.WorkflowStepRequest{
+		SessionID: sessionID,
+		StepType:  "bench",
+		Input:     map[string]interface{}{"run": b.runID, "item": rng.Intn(b.opts.stepInputs)},
+	}
+
+	begin := time.Now()
+	response, err := b.client.executeStep(ctx, req)
+	if ctx.Err() != nil {
+		return
+	}
+	rec.record(time.Since(begin), err == nil && response.Cached, err)
+}
+
+// words is the vocabulary synthetic content is drawn from
+var words = strings.Fields(`
+	agent answer batch cache chunk cluster context cursor dataset document
+	embedding endpoint eviction feature graph index inference input lease
+	lookup memory metric model namespace network output pipeline policy prompt
+	provider query ranking reasoning refresh request result retrieval schema
+	search session signal source step storage summary task template token
+	trace vector version window workflow worker`)
+
+func sentence(rng *rand.Rand, n int) string {
+	picked := make([]string, n)
+	for i := range picked {
+		picked[i] = words[rng.Intn(len(words))]
+	}
+	return strings.Join(picked, " ")
+}
+
+func firstWords(text string, n int) string {
+	fields := strings.Fields(text)
+	return strings.Join(fields[:min(n, len(fields))], " ")
+}
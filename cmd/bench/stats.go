@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// recorder collects the latency and outcome of every request of one operation
+type recorder struct {
+	name string
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+	hits      int
+	// tracksHits is set for operations whose responses can be cache hits
+	tracksHits bool
+}
+
+func newRecorder(name string, tracksHits bool) *recorder {
+	return &recorder{name: name, tracksHits: tracksHits}
+}
+
+func (r *recorder) record(latency time.Duration, hit bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		r.errors++
+		return
+	}
+	r.latencies = append(r.latencies, latency)
+	if hit {
+		r.hits++
+	}
+}
+
+func (r *recorder) succeeded() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.latencies)
+}
+
+// summary is a recorder's results over a run
+type summary struct {
+	name       string
+	count      int
+	errors     int
+	throughput float64
+	hitRate    float64
+	tracksHits bool
+	p50        time.Duration
+	p90        time.Duration
+	p99        time.Duration
+	max        time.Duration
+}
+
+func (r *recorder) summarize(elapsed time.Duration) summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latencies := slices.Clone(r.latencies)
+	slices.Sort(latencies)
+
+	s := summary{
+		name:       r.name,
+		count:      len(latencies),
+		errors:     r.errors,
+		tracksHits: r.tracksHits,
+		p50:        percentile(latencies, 0.50),
+		p90:        percentile(latencies, 0.90),
+		p99:        percentile(latencies, 0.99),
+	}
+	if len(latencies) > 0 {
+		s.max = latencies[len(latencies)-1]
+		s.hitRate = float64(r.hits) / float64(len(latencies))
+	}
+	if elapsed > 0 {
+		s.throughput = float64(len(latencies)) / elapsed.Seconds()
+	}
+	return s
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+func printReport(w io.Writer, title string, elapsed time.Duration, recorders ...*recorder) {
+	fmt.Fprintf(w, "\n%s (%s)\n", title, elapsed.Round(time.Millisecond))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "operation\trequests\terrors\treq/s\tp50\tp90\tp99\tmax\thit rate\t")
+	for _, r := range recorders {
+		s := r.summarize(elapsed)
+		hitRate := "-"
+		if s.tracksHits && s.count > 0 {
+			hitRate = fmt.Sprintf("%.1f%%", s.hitRate*100)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t%s\t\n",
+			s.name, s.count, s.errors, s.throughput,
+			formatLatency(s.p50), formatLatency(s.p90), formatLatency(s.p99), formatLatency(s.max), hitRate)
+	}
+	tw.Flush()
+}
+
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -2,24 +2,33 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
+	"github.com/anunay/mentis/internal/alerting"
 	"github.com/anunay/mentis/internal/api/handlers"
 	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/broker"
 	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/egress"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
 	"github.com/anunay/mentis/internal/core/services"
 	"github.com/anunay/mentis/internal/core/services/embedding"
+	"github.com/anunay/mentis/internal/core/services/expansion"
+	"github.com/anunay/mentis/internal/core/services/processors"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/anunay/mentis/internal/queue"
+	"github.com/anunay/mentis/internal/storage/blob"
 	"github.com/anunay/mentis/internal/storage/postgres"
 	"github.com/anunay/mentis/internal/storage/vector"
+	"github.com/anunay/mentis/internal/telemetry"
 	"github.com/gin-gonic/gin"
-	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
@@ -30,14 +39,21 @@ func main() {
 	}
 
 	// Setup logging
-	config.SetupLogging(cfg.Log.Level)
+	if err := logging.Setup(cfg.Log); err != nil {
+		log.Fatal("Failed to set up logging:", err)
+	}
+
+	shutdownTracing, err := telemetry.Setup(context.Background(), cfg.Tracing, cfg.SlowLog)
+	if err != nil {
+		logrus.Fatal("Failed to set up tracing:", err)
+	}
 
 	// Connect to PostgreSQL
-	db, err := sql.Open("postgres", cfg.Database.URL)
+	db, closeDB, err := postgres.Open(context.Background(), cfg.Database)
 	if err != nil {
 		logrus.Fatal("Failed to connect to database:", err)
 	}
-	defer db.Close()
+	defer closeDB()
 
 	// Test database connection
 	if err := db.Ping(); err != nil {
@@ -52,9 +68,55 @@ func main() {
 	}
 	logrus.Infof("Connected to vector database via provider: %s", cfg.Vector.Provider)
 
+	queryExpander, err := expansion.NewQueryExpander(cfg.Expansion)
+	if err != nil {
+		logrus.Fatal("Failed to create query expander:", err)
+	}
+
+	blobStore, err := blob.NewBlobStore(&cfg.Blob)
+	if err != nil {
+		logrus.Fatal("Failed to create blob store:", err)
+	}
+	if blobStore != nil {
+		logrus.Infof("Offloading artifact content above %d bytes via provider: %s", cfg.Blob.Threshold, cfg.Blob.Provider)
+	}
+
 	// Initialize repositories
-	artifactRepo := postgres.NewArtifactRepository(db)
+	artifactRepo := services.NewCachedArtifactRepository(postgres.NewArtifactRepository(db), cfg.ArtifactCache)
 	workflowRepo := postgres.NewWorkflowRepository(db)
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
+	quotaRepo := postgres.NewQuotaRepository(db)
+	var jobRepo ports.JobRepository = postgres.NewJobRepository(db)
+	schemaRepo := postgres.NewStepSchemaRepository(db)
+	templateRepo := postgres.NewTemplateRepository(db)
+	scheduleRepo := postgres.NewScheduleRepository(db)
+	eventRepo := postgres.NewSessionEventRepository(db)
+	negativeRepo := postgres.NewNegativeCacheRepository(db)
+	cacheStatsRepo := postgres.NewCacheStatsRepository(db)
+	refreshHookRepo := postgres.NewRefreshHookRepository(db)
+	invalidationPolicyRepo := postgres.NewInvalidationPolicyRepository(db)
+	healthRepo := postgres.NewHealthRepository(db)
+	adminStatsRepo := postgres.NewAdminStatsRepository(db)
+	usageRepo := postgres.NewUsageRepository(db)
+	outboxRepo := postgres.NewVectorOutboxRepository(db)
+	webhookSubscriptionRepo := postgres.NewWebhookSubscriptionRepository(db)
+	freshnessSourceRepo := postgres.NewFreshnessSourceRepository(db)
+
+	// Webhook deliveries and, on Redis, step jobs go through a durable queue when configured
+	taskQueue, err := queue.NewTaskQueue(&cfg.Queue, db)
+	if err != nil {
+		logrus.Fatal("Failed to create task queue:", err)
+	}
+	if taskQueue != nil {
+		logrus.Infof("Dispatching webhook deliveries via queue backend: %s", cfg.Queue.Backend)
+	}
+	if queue.Backend(cfg.Queue.Backend) == queue.BackendRedis {
+		jobRepo = services.NewQueuedJobRepository(jobRepo, taskQueue)
+	}
+
+	// Vectors stored directly are taken off the outbox; the relay retries the rest
+	relayVectorRepo := vectorRepo
+	vectorRepo = services.NewOutboxVectorRepository(vectorRepo, outboxRepo)
 
 	// Initialize services
 	hashService := services.NewHashService()
@@ -64,18 +126,203 @@ func main() {
 	}
 	logrus.Infof("Using embedding provider: %s", cfg.Embedding.Provider)
 	
-	cacheService := services.NewCacheService(artifactRepo, vectorRepo, hashService)
+	transactor := postgres.NewTransactor(db)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, hashService)
+	quotaService := services.NewQuotaService(quotaRepo, apiKeyRepo, transactor, cfg.Quota)
+	piiScanner := services.NewPIIScanner(cfg.PII)
+	schemaService := services.NewStepSchemaService(schemaRepo)
+	artifactTTLs := services.NewArtifactTTLs(cfg.Expiry)
+	negativeCache := services.NewNegativeCache(negativeRepo, cfg.Negative)
+	lookupCache := services.NewLookupCache(cfg.LookupCache)
+	cacheStats := services.NewCacheStatsService(cacheStatsRepo)
+	accessTracker := services.NewAccessTracker(artifactRepo, cfg.Access)
+	egressGuard := egress.NewGuard(cfg.Egress)
+	if cfg.Egress.AllowPrivateNetworks {
+		logrus.Warn("EGRESS_ALLOW_PRIVATE_NETWORKS set; webhooks and scrapes may reach internal addresses")
+	}
+	webhookDispatcher := services.NewWebhookDispatcher(webhookSubscriptionRepo, taskQueue, egressGuard, cfg.Webhook, cfg.Queue)
+	webhookDispatcher.Start()
+	if cfg.Webhook.Secret == "" {
+		logrus.Warn("WEBHOOK_SECRET not set; session callbacks are sent unsigned")
+	}
+
+	// Events go to webhooks and, when configured, are streamed to a broker and
+	// raise failure alerts
+	notifiers := services.Notifiers{webhookDispatcher}
+	eventBroker, err := broker.NewEventBroker(&cfg.Events)
+	if err != nil {
+		logrus.Fatal("Failed to create event broker:", err)
+	}
+	var eventStreamer *services.EventStreamer
+	if eventBroker != nil {
+		eventStreamer = services.NewEventStreamer(eventBroker, cfg.Events)
+		eventStreamer.Start(context.Background())
+		notifiers = append(notifiers, eventStreamer)
+		logrus.Infof("Streaming events via broker: %s", cfg.Events.Broker)
+	}
+	alertSenders, err := alerting.NewAlertSenders(&cfg.Alerts)
+	if err != nil {
+		logrus.Fatal("Failed to create alert senders:", err)
+	}
+	var alerter *services.Alerter
+	if len(alertSenders) > 0 {
+		alerter = services.NewAlerter(alertSenders, cfg.Alerts)
+		alerter.Start(context.Background())
+		notifiers = append(notifiers, alerter)
+	}
+	var notifier ports.WebhookNotifier = notifiers
+	cacheService := services.NewCacheService(artifactRepo, vectorRepo, embeddingService, hashService, piiScanner, quotaService, transactor, eventRepo, notifier, artifactTTLs, negativeCache, cacheStats, accessTracker, blobStore, cfg.Blob.Threshold, cfg.Chunking, cfg.Embedding.BatchSize, queryExpander, lookupCache, cfg.Lookup)
+	importer := services.NewImporter(cacheService, embeddingService.GetDimensions())
+	executions := services.NewExecutionManager()
+	stepProcessors := map[string]ports.StepProcessor{}
+	if cfg.Scrape.Enabled {
+		stepProcessors["scrape"] = processors.NewScrapeProcessor(cfg.Scrape, egressGuard)
+	}
+	retryPolicies, err := services.NewRetryPolicies(cfg.Retry)
+	if err != nil {
+		logrus.Fatal("Invalid step retry policies:", err)
+	}
 	workflowService := services.NewWorkflowService(
 		workflowRepo,
+		templateRepo,
+		eventRepo,
+		jobRepo,
 		artifactRepo,
 		vectorRepo,
+		transactor,
 		embeddingService,
 		hashService,
+		quotaService,
+		schemaService,
+		piiScanner,
+		retryPolicies,
+		services.NewCacheScopes(cfg.StepCache),
+		artifactTTLs,
+		negativeCache,
+		cacheStats,
+		accessTracker,
+		lookupCache,
+		executions,
+		notifier,
+		stepProcessors,
+		egressGuard,
+		cfg.Workflow,
 	)
 
+	scheduleService := services.NewScheduleService(scheduleRepo, workflowService)
+	invalidationPolicyService := services.NewInvalidationPolicyService(invalidationPolicyRepo)
+	webhookSubscriptionService := services.NewWebhookSubscriptionService(webhookSubscriptionRepo, egressGuard)
+	freshnessSourceService := services.NewFreshnessSourceService(freshnessSourceRepo, cfg.Freshness)
+	refreshService := services.NewRefreshService(refreshHookRepo, artifactRepo, cacheService, workflowService, egressGuard, cfg.Refresh, cfg.Webhook)
+	healthService := services.NewHealthService(healthRepo, vectorRepo, embeddingService, cfg.Health)
+	adminStatsService := services.NewAdminStatsService(adminStatsRepo, vectorRepo)
+	statusService := services.NewStatusService(healthService, embeddingService, cfg.Status)
+
+	var urlSigner ports.URLSigner
+	if cfg.SignedURL.Enabled {
+		signer, err := services.NewURLSigner(cfg.SignedURL.Secret)
+		if err != nil {
+			logrus.Fatal("Failed to create URL signer:", err)
+		}
+		urlSigner = signer
+	}
+
+	// Background workers stop claiming jobs and schedules as soon as shutdown begins
+	workCtx, stopWork := context.WithCancel(context.Background())
+
+	// Elect one replica to run each job below that must not run twice at once
+	leaderElector := services.NewLeaderElector(postgres.NewLeaseRepository(db), cfg.Leader)
+	leaderElector.Start(workCtx)
+
+	// Start flushing buffered artifact hits
+	accessTracker.Start(workCtx)
+
+	// Start background step workers
+	stepWorkers := services.NewStepWorkerPool(jobRepo, workflowService, cfg.Worker)
+	stepWorkers.Start(workCtx)
+
+	// Start the artifact expiry sweeper
+	artifactSweeper := services.NewArtifactSweeper(artifactRepo, invalidationPolicyRepo, vectorRepo, blobStore, lookupCache, leaderElector, cfg.Expiry)
+	artifactSweeper.Start(workCtx)
+
+	// Start the storage budget evictor
+	artifactEvictor := services.NewArtifactEvictor(artifactRepo, vectorRepo, blobStore, lookupCache, leaderElector, cfg.Eviction)
+	if cfg.Eviction.Enabled() {
+		artifactEvictor.Start(workCtx)
+	}
+
+	// Start the workflow scheduler
+	scheduler := services.NewScheduler(scheduleRepo, scheduleService, leaderElector, cfg.Scheduler)
+	if cfg.Scheduler.Enabled {
+		scheduler.Start(workCtx)
+	}
+
+	// Start the stale artifact refresher
+	artifactRefresher := services.NewArtifactRefresher(artifactRepo, refreshHookRepo, refreshService, leaderElector, cfg.Refresh)
+	if cfg.Refresh.Enabled {
+		artifactRefresher.Start(workCtx)
+	}
+
+	// Start the artifact/vector reconciler
+	reconciler := services.NewReconciler(artifactRepo, vectorRepo, cacheService, leaderElector, cfg.Reconcile)
+	if cfg.Reconcile.Enabled {
+		reconciler.Start(workCtx)
+	}
+
+	// Start the vector outbox relay
+	outboxRelay := services.NewOutboxRelay(outboxRepo, artifactRepo, relayVectorRepo, lookupCache, cfg.Outbox)
+	outboxRelay.Start(workCtx)
+
+	// Start the daily usage aggregator
+	usageService := services.NewUsageService(usageRepo, leaderElector, cfg.Usage)
+	if cfg.Usage.Enabled {
+		usageService.Start(workCtx)
+	}
+
+	// Start consuming source change events
+	eventSource, err := broker.NewEventSource(&cfg.SourceEvents)
+	if err != nil {
+		logrus.Fatal("Failed to create source event consumer:", err)
+	}
+	sourceChangeConsumer := services.NewSourceChangeConsumer(cacheService, eventSource, cfg.SourceEvents)
+	if eventSource != nil {
+		sourceChangeConsumer.Start(workCtx)
+	}
+
+	// Start the sitemap and feed freshness watcher
+	freshnessWatcher := services.NewFreshnessWatcher(freshnessSourceRepo, artifactRepo, cacheService, leaderElector, egressGuard, cfg.Freshness)
+	if cfg.Freshness.Enabled {
+		freshnessWatcher.Start(workCtx)
+	}
+
+	// Start alerting on dependency outages
+	var dependencyMonitor *services.DependencyMonitor
+	if alerter != nil {
+		dependencyMonitor = services.NewDependencyMonitor(alerter, statusService, leaderElector, cfg.Alerts)
+		dependencyMonitor.Start(workCtx)
+	}
+
 	// Initialize handlers
-	cacheHandler := handlers.NewCacheHandler(cacheService)
+	cacheHandler := handlers.NewCacheHandler(cacheService, urlSigner, cfg.SignedURL, cfg.Publish)
 	workflowHandler := handlers.NewWorkflowHandler(workflowService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
+	schemaHandler := handlers.NewStepSchemaHandler(schemaService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	evictionHandler := handlers.NewEvictionHandler(artifactEvictor)
+	reconcileHandler := handlers.NewReconcileHandler(reconciler)
+	cacheStatsHandler := handlers.NewCacheStatsHandler(cacheStats)
+	refreshHandler := handlers.NewRefreshHandler(refreshService)
+	invalidationPolicyHandler := handlers.NewInvalidationPolicyHandler(invalidationPolicyService)
+	webhookHandler := handlers.NewWebhookHandler(webhookSubscriptionService)
+	freshnessSourceHandler := handlers.NewFreshnessSourceHandler(freshnessSourceService)
+	importHandler := handlers.NewImportHandler(importer, cfg.Publish)
+	toolHandler := handlers.NewToolHandler()
+	healthHandler := handlers.NewHealthHandler(healthService)
+	adminStatsHandler := handlers.NewAdminStatsHandler(adminStatsService)
+	usageHandler := handlers.NewUsageHandler(usageService)
+	statusHandler := handlers.NewStatusHandler(statusService)
+	embeddingHandler := handlers.NewEmbeddingHandler(embeddingService)
 
 	// Setup Gin router
 	if cfg.Log.Level != "debug" {
@@ -83,29 +330,86 @@ func main() {
 	}
 
 	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logrus.Fatal("Invalid trusted proxies:", err)
+	}
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
 	router.Use(middleware.ErrorHandlingMiddleware())
 	router.Use(middleware.LoggingMiddleware())
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.BodyLimitMiddleware(cfg.Server.MaxBodyBytes, map[string]int64{
+		"/v1/cache/publish": cfg.Publish.MaxBodyBytes,
+		"/v1/cache/import":  cfg.Publish.MaxBodyBytes,
+		"/v1/cache/load":    cfg.Publish.LoadMaxBodyBytes,
+	}))
+	if cfg.Log.Bodies.Enabled {
+		logrus.Warn("Request and response body logging is enabled")
+		router.Use(middleware.BodyLoggingMiddleware(cfg.Log.Bodies))
+	}
+
+	// IP filters run before authentication
+	ipFilter, err := middleware.NewIPFilter(cfg.Server.IPFilter.Allow, cfg.Server.IPFilter.Deny)
+	if err != nil {
+		logrus.Fatal("Invalid IP filter:", err)
+	}
+	if !ipFilter.Empty() {
+		router.Use(middleware.IPFilterMiddleware(ipFilter))
+	}
+	restrictedFilter, err := middleware.NewIPFilter(cfg.Server.IPFilter.RestrictedAllow, nil)
+	if err != nil {
+		logrus.Fatal("Invalid restricted IP allowlist:", err)
+	}
+	if !restrictedFilter.Empty() {
+		router.Use(middleware.PathIPFilterMiddleware(cfg.Server.IPFilter.RestrictedPaths, restrictedFilter))
+	}
+
+	// Liveness and readiness probes
+	healthHandler.RegisterRoutes(router)
 
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "healthy",
-			"timestamp": time.Now().UTC(),
-			"service":   "mentis",
-		})
-	})
+	// The dashboard's assets are public; its API calls authenticate like any other
+	if cfg.Server.AdminUI {
+		handlers.NewUIHandler().RegisterRoutes(router)
+	}
+
+	// Signed source change webhooks authenticate without an API key
+	if cfg.SourceEvents.WebhookSecret != "" {
+		handlers.NewSourceChangeHandler(sourceChangeConsumer).RegisterRoutes(router)
+	}
 
 	// API routes
 	v1 := router.Group("/v1")
+	if urlSigner != nil {
+		v1.Use(middleware.SignedURLMiddleware(urlSigner))
+	}
+	v1.Use(middleware.AuthMiddleware(cfg.Auth, apiKeyService))
 	{
 		cacheHandler.RegisterRoutes(v1)
 		workflowHandler.RegisterRoutes(v1)
+		apiKeyHandler.RegisterRoutes(v1)
+		quotaHandler.RegisterRoutes(v1)
+		schemaHandler.RegisterRoutes(v1)
+		scheduleHandler.RegisterRoutes(v1)
+		evictionHandler.RegisterRoutes(v1)
+		reconcileHandler.RegisterRoutes(v1)
+		cacheStatsHandler.RegisterRoutes(v1)
+		refreshHandler.RegisterRoutes(v1)
+		invalidationPolicyHandler.RegisterRoutes(v1)
+		webhookHandler.RegisterRoutes(v1)
+		freshnessSourceHandler.RegisterRoutes(v1)
+		importHandler.RegisterRoutes(v1)
+		toolHandler.RegisterRoutes(v1)
+		adminStatsHandler.RegisterRoutes(v1)
+		usageHandler.RegisterRoutes(v1)
+		statusHandler.RegisterRoutes(v1)
+		embeddingHandler.RegisterRoutes(v1)
+		if cfg.Server.DebugEndpoints {
+			handlers.NewDebugHandler().RegisterRoutes(v1)
+		}
 
 		// Quick lookup endpoints
-		v1.GET("/lookup", cacheHandler.QuickLookup)
-		v1.GET("/workflow/lookup", workflowHandler.QuickStepLookup)
+		v1.GET("/lookup", middleware.RequireOperation(domain.OpLookup), cacheHandler.QuickLookup)
+		v1.GET("/workflow/lookup", middleware.RequireOperation(domain.OpWorkflowRead), workflowHandler.QuickStepLookup)
 	}
 
 	// Create HTTP server
@@ -114,10 +418,26 @@ func main() {
 		Handler: router,
 	}
 
+	if cfg.Server.TLS.Enabled() {
+		tlsConfig, err := newTLSConfig(cfg.Server.TLS)
+		if err != nil {
+			logrus.Fatal("Failed to configure TLS:", err)
+		}
+		server.TLSConfig = tlsConfig
+	}
+
 	// Start server in a goroutine
 	go func() {
-		logrus.Infof("Starting server on port %s", cfg.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			logrus.WithField("client_auth", server.TLSConfig.ClientAuth.String()).
+				Infof("Starting TLS server on port %s", cfg.Server.Port)
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			logrus.Infof("Starting server on port %s", cfg.Server.Port)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logrus.Fatal("Failed to start server:", err)
 		}
 	}()
@@ -128,12 +448,48 @@ func main() {
 	<-quit
 	logrus.Info("Shutting down server...")
 
-	// Give outstanding requests 30 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Take no new work; outstanding requests and step executions share the shutdown timeout
+	stopWork()
+	executions.StopAccepting()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		logrus.Fatal("Server forced to shutdown:", err)
+		logrus.WithError(err).Warn("Server forced to shutdown")
+	}
+	if interrupted := executions.Drain(ctx); interrupted > 0 {
+		logrus.WithField("count", interrupted).Warn("Interrupted in-flight step executions; they will resume from the queue")
+	}
+
+	scheduler.Stop()
+	artifactRefresher.Stop()
+	artifactSweeper.Stop()
+	artifactEvictor.Stop()
+	reconciler.Stop()
+	outboxRelay.Stop()
+	sourceChangeConsumer.Stop()
+	freshnessWatcher.Stop()
+	if dependencyMonitor != nil {
+		dependencyMonitor.Stop()
+	}
+	usageService.Stop()
+	leaderElector.Stop()
+	stepWorkers.Stop()
+	webhookDispatcher.Stop()
+	if taskQueue != nil {
+		taskQueue.Close()
+	}
+	if eventStreamer != nil {
+		eventStreamer.Stop()
+	}
+	if alerter != nil {
+		alerter.Stop()
+	}
+	accessTracker.Stop()
+
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.WithError(err).Warn("Failed to flush traces")
 	}
 
 	logrus.Info("Server exited")
@@ -0,0 +1,143 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// implicitTLSPort is the submission port spoken over TLS from the start;
+// other ports upgrade with STARTTLS when the server offers it
+const implicitTLSPort = 465
+
+// Sender emails alerts through an SMTP server
+type Sender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	to       []string
+	timeout  time.Duration
+}
+
+func NewSender(cfg config.AlertsConfig) (*Sender, error) {
+	if cfg.EmailFrom == "" || len(cfg.EmailTo) == 0 {
+		return nil, fmt.Errorf("ALERT_EMAIL_FROM and ALERT_EMAIL_TO are required with ALERT_SMTP_HOST")
+	}
+	return &Sender{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.EmailFrom,
+		to:       cfg.EmailTo,
+		timeout:  cfg.Timeout,
+	}, nil
+}
+
+func (s *Sender) Name() string {
+	return "email"
+}
+
+func (s *Sender) Send(ctx context.Context, alert *domain.Alert) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	if s.port != implicitTLSPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+				return fmt.Errorf("failed to start TLS: %w", err)
+			}
+		}
+	}
+	// PlainAuth refuses to send credentials over an unencrypted connection
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.from); err != nil {
+		return fmt.Errorf("sender rejected: %w", err)
+	}
+	for _, recipient := range s.to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", recipient, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := w.Write(s.message(alert)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+func (s *Sender) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	dialer := &net.Dialer{Timeout: s.timeout}
+	if s.port == implicitTLSPort {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.host}}
+		return tlsDialer.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// message renders the alert as a plain-text email
+func (s *Sender) message(alert *domain.Alert) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[Mentis] "+alert.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", alert.CreatedAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+
+	b.WriteString(crlf(alert.Message))
+	b.WriteString("\r\n")
+	if len(alert.Fields) > 0 {
+		b.WriteString("\r\n")
+	}
+	for _, field := range alert.Fields {
+		fmt.Fprintf(&b, "%s: %s\r\n", field.Name, crlf(field.Value))
+	}
+	return b.Bytes()
+}
+
+// crlf normalizes line endings to the CRLF SMTP requires
+func crlf(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+}
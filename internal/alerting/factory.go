@@ -0,0 +1,25 @@
+package alerting
+
+import (
+	"github.com/anunay/mentis/internal/alerting/email"
+	"github.com/anunay/mentis/internal/alerting/slack"
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/ports"
+)
+
+// NewAlertSenders creates a sender for each configured alert target; none
+// are returned when alerting is not configured
+func NewAlertSenders(cfg *config.AlertsConfig) ([]ports.AlertSender, error) {
+	var senders []ports.AlertSender
+	if cfg.SlackWebhookURL != "" {
+		senders = append(senders, slack.NewSender(*cfg))
+	}
+	if cfg.SMTPHost != "" {
+		sender, err := email.NewSender(*cfg)
+		if err != nil {
+			return nil, err
+		}
+		senders = append(senders, sender)
+	}
+	return senders, nil
+}
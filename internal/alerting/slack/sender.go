@@ -0,0 +1,75 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// Sender posts alerts to a Slack incoming webhook as mrkdwn messages
+type Sender struct {
+	webhookURL string
+	client     *http.Client
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+func NewSender(cfg config.AlertsConfig) *Sender {
+	return &Sender{
+		webhookURL: cfg.SlackWebhookURL,
+		client:     &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (s *Sender) Name() string {
+	return "slack"
+}
+
+func (s *Sender) Send(ctx context.Context, alert *domain.Alert) error {
+	body, err := json.Marshal(message{Text: format(alert)})
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Slack webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// format renders the alert as a bold title, the message, then one bullet per field
+func format(alert *domain.Alert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n%s", escape(alert.Title), escape(alert.Message))
+	for _, field := range alert.Fields {
+		fmt.Fprintf(&b, "\n• *%s:* %s", escape(field.Name), escape(field.Value))
+	}
+	return b.String()
+}
+
+// escape encodes the characters Slack reserves for markup
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminStatsHandler struct {
+	statsService ports.AdminStatsService
+}
+
+func NewAdminStatsHandler(statsService ports.AdminStatsService) *AdminStatsHandler {
+	return &AdminStatsHandler{
+		statsService: statsService,
+	}
+}
+
+func (h *AdminStatsHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/admin/stats", middleware.RequireOperation(domain.OpAdmin), h.GetStats)
+}
+
+// GetStats reports artifact, session, step, storage, and vector totals across
+// every namespace, or for the one named by the namespace query parameter
+func (h *AdminStatsHandler) GetStats(c *gin.Context) {
+	stats, err := h.statsService.Stats(c.Request.Context(), c.Query("namespace"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
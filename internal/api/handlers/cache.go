@@ -1,101 +1,771 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/config"
 	"github.com/anunay/mentis/internal/core/domain"
 	"github.com/anunay/mentis/internal/core/ports"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// ndjsonContentType marks a streamed publish body: one artifact object per line
+const ndjsonContentType = "application/x-ndjson"
+
 type CacheHandler struct {
 	cacheService ports.CacheService
+	urlSigner    ports.URLSigner // nil when signed URLs are disabled
+	signedURLCfg config.SignedURLConfig
+	publishCfg   config.PublishConfig
 }
 
-func NewCacheHandler(cacheService ports.CacheService) *CacheHandler {
+func NewCacheHandler(cacheService ports.CacheService, urlSigner ports.URLSigner, signedURLCfg config.SignedURLConfig, publishCfg config.PublishConfig) *CacheHandler {
 	return &CacheHandler{
 		cacheService: cacheService,
+		urlSigner:    urlSigner,
+		signedURLCfg: signedURLCfg,
+		publishCfg:   publishCfg,
 	}
 }
 
 func (h *CacheHandler) RegisterRoutes(r *gin.RouterGroup) {
 	cache := r.Group("/cache")
 	{
-		cache.POST("/publish", h.Publish)
-		cache.POST("/lookup", h.Lookup)
-		cache.GET("/artifacts/:id", h.GetArtifact)
-		cache.DELETE("/artifacts/:id", h.DeleteArtifact)
-		cache.POST("/invalidate", h.Invalidate)
+		cache.POST("/publish", middleware.RequireOperation(domain.OpPublish), h.Publish)
+		cache.POST("/load", middleware.RequireOperation(domain.OpPublish), h.Load)
+		cache.POST("/lookup", middleware.RequireOperation(domain.OpLookup), h.Lookup)
+		cache.GET("/artifacts", middleware.RequireOperation(domain.OpRead), h.ListArtifacts)
+		cache.GET("/artifacts/:id", middleware.RequireOperation(domain.OpRead), h.GetArtifact)
+		cache.GET("/artifacts/:id/content", middleware.RequireOperation(domain.OpRead), h.GetArtifactContent)
+		cache.GET("/artifacts/:id/versions", middleware.RequireOperation(domain.OpRead), h.ListArtifactVersions)
+		cache.GET("/artifacts/:id/versions/:version", middleware.RequireOperation(domain.OpRead), h.GetArtifactVersion)
+		cache.GET("/top-artifacts", middleware.RequireOperation(domain.OpRead), h.ListTopArtifacts)
+		cache.GET("/artifacts/:id/lineage", middleware.RequireOperation(domain.OpRead), h.GetArtifactLineage)
+		if h.urlSigner != nil {
+			cache.POST("/artifacts/:id/signed-url", middleware.RequireOperation(domain.OpRead), h.CreateSignedURL)
+		}
+		cache.DELETE("/artifacts/:id", middleware.RequireOperation(domain.OpDelete), h.DeleteArtifact)
+		cache.POST("/artifacts/:id/restore", middleware.RequireOperation(domain.OpDelete), h.RestoreArtifact)
+		cache.PUT("/artifacts/:id/pin", middleware.RequireOperation(domain.OpPublish), h.PinArtifact)
+		cache.DELETE("/artifacts/:id/pin", middleware.RequireOperation(domain.OpPublish), h.UnpinArtifact)
+		cache.POST("/invalidate", middleware.RequireOperation(domain.OpInvalidate), h.Invalidate)
+	}
+
+	// Retriever endpoint for RAG frameworks
+	r.POST("/retrieve", middleware.RequireOperation(domain.OpLookup), h.Retrieve)
+
+	llmCache := r.Group("/llm-cache")
+	{
+		llmCache.POST("/store", middleware.RequireOperation(domain.OpPublish), h.StoreLLMAnswer)
+		llmCache.POST("/check", middleware.RequireOperation(domain.OpLookup), h.CheckLLMCache)
 	}
 }
 
 func (h *CacheHandler) Publish(c *gin.Context) {
+	if c.ContentType() == ndjsonContentType {
+		h.publishStream(c)
+		return
+	}
+
 	var req domain.PublishRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
+	for _, object := range req.Objects {
+		if !allowsArtifactType(c, object.Type) {
+			respondError(c, &domain.Error{
+				Code:    domain.CodeForbidden,
+				Message: "API key does not permit artifact type " + string(object.Type),
+			})
+			return
+		}
+	}
+
 	response, err := h.cacheService.Publish(c.Request.Context(), req.Objects)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// publishStream publishes an NDJSON body of artifacts. Objects the key may not
+// publish fail on their own
+func (h *CacheHandler) publishStream(c *gin.Context) {
+	allow := func(artifact domain.Artifact) error {
+		if !allowsArtifactType(c, artifact.Type) {
+			return &domain.Error{
+				Code:    domain.CodeForbidden,
+				Message: "API key does not permit artifact type " + string(artifact.Type),
+			}
+		}
+		return nil
+	}
+	streamPublish(c, h.publishCfg.StreamBatchSize, allow, h.cacheService.Publish)
+}
+
+// streamPublish publishes an NDJSON body a batch at a time, so only one batch
+// of objects is held in memory however large the body is. Objects allow rejects
+// fail on their own. A malformed or oversized object ends the stream; the
+// objects before it are still published, and the error reports its index
+func streamPublish[T any](c *gin.Context, batchSize int, allow func(T) error, publish func(context.Context, []T) (*domain.PublishResponse, error)) {
+	ctx := c.Request.Context()
+	response := &domain.PublishResponse{
+		Published: []uuid.UUID{},
+		Skipped:   []uuid.UUID{},
+		Replaced:  []uuid.UUID{},
+		Results:   []domain.PublishResult{},
+	}
+
+	var firstErr error
+	fail := func(index int, err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+		_, envelope := errorResponse(ctx, err)
+		response.Results = append(response.Results, domain.PublishResult{
+			Index:  index,
+			Status: domain.PublishFailed,
+			Error:  &domain.PublishError{Code: envelope.Code, Message: envelope.Message, Details: envelope.Details},
+		})
+	}
+
+	var batch []T
+	var indexes []int
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		published, err := publish(ctx, batch)
+		if err != nil {
+			for _, index := range indexes {
+				fail(index, err)
+			}
+		} else {
+			for _, result := range published.Results {
+				result.Index = indexes[result.Index]
+				response.Results = append(response.Results, result)
+			}
+			response.Published = append(response.Published, published.Published...)
+			response.Skipped = append(response.Skipped, published.Skipped...)
+			response.Replaced = append(response.Replaced, published.Replaced...)
+		}
+		batch, indexes = nil, nil
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	for index := 0; ; index++ {
+		var object T
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			flush()
+			respondError(c, bodyError(err).WithDetail("index", index))
+			return
+		}
+
+		if err := allow(object); err != nil {
+			fail(index, err)
+			continue
+		}
+		batch = append(batch, object)
+		indexes = append(indexes, index)
+		if len(batch) >= batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	// With nothing published or skipped, the request as a whole failed
+	if firstErr != nil && len(response.Published) == 0 && len(response.Skipped) == 0 {
+		respondError(c, firstErr)
 		return
 	}
 
+	sort.Slice(response.Results, func(i, j int) bool { return response.Results[i].Index < response.Results[j].Index })
 	c.JSON(http.StatusOK, response)
 }
 
+// Load publishes an NDJSON body of any size, answering with an NDJSON stream
+// of events while it reads. A batch is published before the next is read, so
+// a client sending faster than artifacts are stored is held back by the
+// connection, and a progress event acknowledges each batch. Only failed
+// results and warnings are reported individually, so memory stays bounded by
+// a batch however many objects are loaded
+func (h *CacheHandler) Load(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Progress is written while the body is still being read
+	if err := http.NewResponseController(c.Writer).EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		respondError(c, err)
+		return
+	}
+	c.Header("Content-Type", ndjsonContentType)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	progress := domain.PublishEvent{}
+	emit := func(event domain.PublishEvent) bool {
+		if err := encoder.Encode(event); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+	report := func(result domain.PublishResult) bool {
+		if result.Status == domain.PublishFailed {
+			progress.Failed++
+		}
+		event := progress
+		event.Type = domain.PublishEventResult
+		event.Result = &result
+		return emit(event)
+	}
+
+	var batch []domain.Artifact
+	var indexes []int
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		published, err := h.cacheService.Publish(ctx, batch)
+		if err != nil {
+			_, envelope := errorResponse(ctx, err)
+			for _, index := range indexes {
+				if !report(domain.PublishResult{
+					Index:  index,
+					Status: domain.PublishFailed,
+					Error:  &domain.PublishError{Code: envelope.Code, Message: envelope.Message, Details: envelope.Details},
+				}) {
+					return false
+				}
+			}
+		} else {
+			progress.Published += len(published.Published)
+			progress.Skipped += len(published.Skipped)
+			progress.Replaced += len(published.Replaced)
+			for _, result := range published.Results {
+				if result.Status != domain.PublishFailed && result.Warning == "" {
+					continue
+				}
+				result.Index = indexes[result.Index]
+				if !report(result) {
+					return false
+				}
+			}
+		}
+		batch, indexes = batch[:0], indexes[:0]
+
+		event := progress
+		event.Type = domain.PublishEventProgress
+		return emit(event)
+	}
+
+	// end publishes the objects read before a body error, then reports it
+	end := func(index int, err *domain.Error) {
+		if !flush() {
+			return
+		}
+		_, envelope := errorResponse(ctx, err.WithDetail("index", index))
+		event := progress
+		event.Type = domain.PublishEventError
+		event.Error = &domain.PublishError{Code: envelope.Code, Message: envelope.Message, Details: envelope.Details}
+		emit(event)
+	}
+
+	maxObject := int(h.publishCfg.MaxBodyBytes)
+	if maxObject <= 0 {
+		maxObject = math.MaxInt32
+	}
+	scanner := bufio.NewScanner(c.Request.Body)
+	// The initial buffer must not exceed the cap, or the scanner grows past it
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxObject)), maxObject)
+
+	index := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var artifact domain.Artifact
+		if err := json.Unmarshal(line, &artifact); err != nil {
+			end(index, bodyError(err))
+			return
+		}
+		progress.Acknowledged++
+
+		if !allowsArtifactType(c, artifact.Type) {
+			if !report(domain.PublishResult{
+				Index:  index,
+				Status: domain.PublishFailed,
+				Error: &domain.PublishError{
+					Code:    domain.CodeForbidden,
+					Message: "API key does not permit artifact type " + string(artifact.Type),
+				},
+			}) {
+				return
+			}
+		} else {
+			batch = append(batch, artifact)
+			indexes = append(indexes, index)
+			if len(batch) >= h.publishCfg.StreamBatchSize && !flush() {
+				return
+			}
+		}
+		index++
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = &http.MaxBytesError{Limit: int64(maxObject)}
+		}
+		end(index, bodyError(err))
+		return
+	}
+
+	if flush() {
+		progress.Type = domain.PublishEventDone
+		emit(progress)
+	}
+}
+
 func (h *CacheHandler) Lookup(c *gin.Context) {
 	var req domain.LookupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
+		return
+	}
+
+	if !scopeLookupType(c, &req.Options) {
+		return
+	}
+	if !scopeLookupNamespaces(c, &req.Options) {
 		return
 	}
 
 	response, err := h.cacheService.Lookup(c.Request.Context(), req.Options)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// Retrieve answers the retriever contract of RAG frameworks: a query, k and
+// metadata filters in, documents with text, metadata and score out
+func (h *CacheHandler) Retrieve(c *gin.Context) {
+	var req domain.RetrieveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	options := req.LookupOptions()
+	if !scopeLookupType(c, &options) {
+		return
+	}
+	if !scopeLookupNamespaces(c, &options) {
+		return
+	}
+
+	response, err := h.cacheService.Lookup(c.Request.Context(), options)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	documents := make([]domain.RetrievedDocument, 0, len(response.Results))
+	for _, result := range response.Results {
+		documents = append(documents, domain.NewRetrievedDocument(result))
+	}
+	c.JSON(http.StatusOK, domain.RetrieveResponse{Documents: documents})
+}
+
 func (h *CacheHandler) GetArtifact(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid artifact ID"})
+		respondValidationError(c, "invalid artifact ID")
 		return
 	}
 
 	artifact, err := h.cacheService.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	if artifact == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "artifact not found"})
+	// Artifacts outside the key's permitted types are indistinguishable from missing ones
+	if artifact == nil || !allowsArtifactType(c, artifact.Type) {
+		respondError(c, domain.NewNotFoundError("artifact", id))
 		return
 	}
 
 	c.JSON(http.StatusOK, artifact)
 }
 
+// GetArtifactContent serves the raw artifact content; it also accepts signed URLs
+func (h *CacheHandler) GetArtifactContent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid artifact ID")
+		return
+	}
+
+	// Content is streamed rather than loaded with the artifact
+	artifact, err := h.cacheService.GetMetadata(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if artifact == nil || !allowsArtifactType(c, artifact.Type) {
+		respondError(c, domain.NewNotFoundError("artifact", id))
+		return
+	}
+
+	content, err := h.cacheService.OpenContent(c.Request.Context(), artifact)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer content.Close()
+
+	reader := bufio.NewReaderSize(content, sniffLen)
+	contentType, _ := artifact.Metadata["content_type"].(string)
+	if contentType == "" {
+		if artifact.ContentRef != "" {
+			contentType = "application/octet-stream"
+		} else {
+			// Peek returns what it could read; a read error surfaces again while streaming
+			head, _ := reader.Peek(sniffLen)
+			contentType = http.DetectContentType(head)
+		}
+	}
+
+	c.Header("ETag", `"`+artifact.ContentHash+`"`)
+	c.DataFromReader(http.StatusOK, artifact.ContentSize, contentType, reader, nil)
+}
+
+// sniffLen is how much content http.DetectContentType considers
+const sniffLen = 512
+
+// CreateSignedURL issues a short-lived URL for fetching an artifact's content without an API key
+func (h *CacheHandler) CreateSignedURL(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid artifact ID")
+		return
+	}
+
+	var req struct {
+		TTLSeconds int `json:"ttl_seconds"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+
+	ttl := h.signedURLCfg.DefaultTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > h.signedURLCfg.MaxTTL {
+		respondValidationError(c, "ttl_seconds exceeds the maximum of "+strconv.Itoa(int(h.signedURLCfg.MaxTTL.Seconds())))
+		return
+	}
+
+	artifact, err := h.cacheService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if artifact == nil || !allowsArtifactType(c, artifact.Type) {
+		respondError(c, domain.NewNotFoundError("artifact", id))
+		return
+	}
+
+	path := "/v1/cache/artifacts/" + id.String() + "/content"
+	expires := time.Now().Add(ttl)
+	query := h.urlSigner.Sign(path, artifact.Namespace, expires)
+
+	// The host comes from configuration, not the request, so a client can't
+	// have a URL issued for a host it chooses
+	c.JSON(http.StatusOK, gin.H{
+		"url":        h.signedURLCfg.PublicBaseURL + path + "?" + query,
+		"expires_at": expires.UTC(),
+	})
+}
+
+func (h *CacheHandler) ListArtifacts(c *gin.Context) {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	list := h.cacheService.List
+	if c.Query("deleted") == "true" {
+		list = h.cacheService.ListDeleted
+	}
+
+	response, err := list(c.Request.Context(), limit, c.Query("cursor"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Drop artifacts the key may not see; pages can come back shorter than limit
+	visible := response.Artifacts[:0]
+	for _, artifact := range response.Artifacts {
+		if allowsArtifactType(c, artifact.Type) {
+			visible = append(visible, artifact)
+		}
+	}
+	response.Artifacts = visible
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListArtifactVersions lists the archived versions of an artifact, newest first
+func (h *CacheHandler) ListArtifactVersions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid artifact ID")
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	response, err := h.cacheService.ListVersions(c.Request.Context(), id, limit, c.Query("cursor"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	visible := response.Versions[:0]
+	for _, version := range response.Versions {
+		if allowsArtifactType(c, version.Type) {
+			visible = append(visible, version)
+		}
+	}
+	response.Versions = visible
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CacheHandler) GetArtifactVersion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid artifact ID")
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		respondValidationError(c, "invalid artifact version")
+		return
+	}
+
+	artifactVersion, err := h.cacheService.GetVersion(c.Request.Context(), id, version)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowsArtifactType(c, artifactVersion.Type) {
+		respondError(c, domain.NewNotFoundError("artifact version", c.Param("id")+"@"+c.Param("version")))
+		return
+	}
+
+	c.JSON(http.StatusOK, artifactVersion)
+}
+
+// ListTopArtifacts reports the namespace's most reused artifacts by hit count
+func (h *CacheHandler) ListTopArtifacts(c *gin.Context) {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	usages, err := h.cacheService.MostAccessed(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	visible := usages[:0]
+	for _, usage := range usages {
+		if allowsArtifactType(c, usage.Type) {
+			visible = append(visible, usage)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"artifacts": visible})
+}
+
+// GetArtifactLineage returns the graph of sources an artifact derives from and
+// artifacts derived from it
+func (h *CacheHandler) GetArtifactLineage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid artifact ID")
+		return
+	}
+
+	depth := 0
+	if depthStr := c.Query("depth"); depthStr != "" {
+		if d, err := strconv.Atoi(depthStr); err == nil {
+			depth = d
+		}
+	}
+
+	lineage, err := h.cacheService.Lineage(c.Request.Context(), id, domain.LineageDirection(c.Query("direction")), depth)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Nodes outside the key's permitted types are left out along with their edges
+	hidden := make(map[uuid.UUID]bool)
+	visible := lineage.Nodes[:0]
+	for _, node := range lineage.Nodes {
+		if !allowsArtifactType(c, node.Type) {
+			hidden[node.ID] = true
+			continue
+		}
+		visible = append(visible, node)
+	}
+	if hidden[id] {
+		respondError(c, domain.NewNotFoundError("artifact", id))
+		return
+	}
+	lineage.Nodes = visible
+
+	edges := lineage.Edges[:0]
+	for _, edge := range lineage.Edges {
+		if !hidden[edge.From] && !hidden[edge.To] {
+			edges = append(edges, edge)
+		}
+	}
+	lineage.Edges = edges
+
+	c.JSON(http.StatusOK, lineage)
+}
+
+// PinArtifact exempts an artifact from storage eviction
+func (h *CacheHandler) PinArtifact(c *gin.Context) {
+	h.setPinned(c, true)
+}
+
+func (h *CacheHandler) UnpinArtifact(c *gin.Context) {
+	h.setPinned(c, false)
+}
+
+func (h *CacheHandler) setPinned(c *gin.Context, pinned bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid artifact ID")
+		return
+	}
+
+	if principal := domain.PrincipalFromContext(c.Request.Context()); principal != nil && len(principal.ArtifactTypes) > 0 {
+		artifact, err := h.cacheService.GetByID(c.Request.Context(), id)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if artifact == nil || !allowsArtifactType(c, artifact.Type) {
+			respondError(c, domain.NewNotFoundError("artifact", id))
+			return
+		}
+	}
+
+	if err := h.cacheService.SetPinned(c.Request.Context(), id, pinned); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "pinned": pinned})
+}
+
+// RestoreArtifact undeletes an artifact that has not been purged yet
+func (h *CacheHandler) RestoreArtifact(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid artifact ID")
+		return
+	}
+
+	if principal := domain.PrincipalFromContext(c.Request.Context()); principal != nil && len(principal.ArtifactTypes) > 0 {
+		artifact, err := h.cacheService.GetDeleted(c.Request.Context(), id)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if artifact == nil || !allowsArtifactType(c, artifact.Type) {
+			respondError(c, domain.NewNotFoundError("deleted artifact", id))
+			return
+		}
+	}
+
+	if err := h.cacheService.Restore(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "restored": true})
+}
+
 func (h *CacheHandler) DeleteArtifact(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid artifact ID"})
+		respondValidationError(c, "invalid artifact ID")
 		return
 	}
 
+	if principal := domain.PrincipalFromContext(c.Request.Context()); principal != nil && len(principal.ArtifactTypes) > 0 {
+		artifact, err := h.cacheService.GetByID(c.Request.Context(), id)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if artifact == nil || !allowsArtifactType(c, artifact.Type) {
+			respondError(c, domain.NewNotFoundError("artifact", id))
+			return
+		}
+	}
+
 	err = h.cacheService.Delete(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -103,43 +773,42 @@ func (h *CacheHandler) DeleteArtifact(c *gin.Context) {
 }
 
 func (h *CacheHandler) Invalidate(c *gin.Context) {
-	var req struct {
-		SourceURL string `json:"source_url" binding:"required"`
-	}
-
+	var req domain.InvalidateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	err := h.cacheService.Invalidate(c.Request.Context(), req.SourceURL)
+	response, err := h.cacheService.Invalidate(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "artifacts invalidated"})
+	c.JSON(http.StatusOK, response)
 }
 
 // Quick lookup endpoint for GET requests
 func (h *CacheHandler) QuickLookup(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter 'q' is required"})
+		respondValidationError(c, "query parameter 'q' is required")
 		return
 	}
 
-	topK := 10
+	// Zero leaves the server default in place
+	var topK int
 	if topKStr := c.Query("top_k"); topKStr != "" {
 		if k, err := strconv.Atoi(topKStr); err == nil {
 			topK = k
 		}
 	}
 
-	minScore := float32(0.85)
+	var minScore *float32
 	if minScoreStr := c.Query("min_score"); minScoreStr != "" {
 		if score, err := strconv.ParseFloat(minScoreStr, 32); err == nil {
-			minScore = float32(score)
+			minScore = new(float32)
+			*minScore = float32(score)
 		}
 	}
 
@@ -150,15 +819,41 @@ func (h *CacheHandler) QuickLookup(c *gin.Context) {
 		IncludeContent:  c.Query("include_content") == "true",
 		IncludeEmbedding: c.Query("include_embedding") == "true",
 		IncludeStale:    c.Query("include_stale") == "true",
+		Cursor:          c.Query("cursor"),
+		Mode:            domain.LookupMode(c.Query("mode")),
+		GroupBy:         c.Query("group_by"),
+		Queries:         c.QueryArray("queries"),
+		Expand:          c.Query("expand") == "true",
+		Explain:         c.Query("explain") == "true",
 	}
 
 	if artifactType := c.Query("type"); artifactType != "" {
 		options.ArtifactType = domain.ArtifactType(artifactType)
 	}
 
+	if tags := c.Query("tags"); tags != "" {
+		options.Tags = strings.Split(tags, ",")
+	}
+	if namespaces := c.Query("namespaces"); namespaces != "" {
+		options.Namespaces = strings.Split(namespaces, ",")
+	}
+	if filter := c.Query("filter"); filter != "" {
+		if err := json.Unmarshal([]byte(filter), &options.Filter); err != nil {
+			respondValidationError(c, "filter must be a JSON list of conditions")
+			return
+		}
+	}
+
+	if !scopeLookupType(c, &options) {
+		return
+	}
+	if !scopeLookupNamespaces(c, &options) {
+		return
+	}
+
 	response, err := h.cacheService.Lookup(c.Request.Context(), options)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type CacheStatsHandler struct {
+	statsService ports.CacheStatsService
+}
+
+func NewCacheStatsHandler(statsService ports.CacheStatsService) *CacheStatsHandler {
+	return &CacheStatsHandler{
+		statsService: statsService,
+	}
+}
+
+func (h *CacheStatsHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/cache/stats", middleware.RequireOperation(domain.OpRead), h.GetStats)
+	r.GET("/admin/cache/stats", middleware.RequireOperation(domain.OpAdmin), h.GetAllStats)
+}
+
+// GetStats reports lookup and step cache hit rates for the caller's namespace
+func (h *CacheStatsHandler) GetStats(c *gin.Context) {
+	h.respond(c, h.statsService.Stats)
+}
+
+// GetAllStats reports hit rates across every namespace
+func (h *CacheStatsHandler) GetAllStats(c *gin.Context) {
+	h.respond(c, h.statsService.AllStats)
+}
+
+func (h *CacheStatsHandler) respond(c *gin.Context, stats func(ctx context.Context, days int) (*domain.CacheStatsResponse, error)) {
+	days := 0
+	if daysStr := c.Query("days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil {
+			respondValidationError(c, "days must be an integer")
+			return
+		}
+		days = d
+	}
+
+	response, err := stats(c.Request.Context(), days)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
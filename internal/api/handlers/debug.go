@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"expvar"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+var startTime = time.Now()
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() interface{} {
+		return runtime.NumGoroutine()
+	}))
+	expvar.Publish("uptime_seconds", expvar.Func(func() interface{} {
+		return int64(time.Since(startTime).Seconds())
+	}))
+}
+
+// DebugHandler serves pprof profiles and expvar runtime stats to admins. The
+// server's router does not serve http.DefaultServeMux, so importing pprof and
+// expvar exposes nothing beyond these routes
+type DebugHandler struct{}
+
+func NewDebugHandler() *DebugHandler {
+	return &DebugHandler{}
+}
+
+func (h *DebugHandler) RegisterRoutes(r *gin.RouterGroup) {
+	debug := r.Group("/admin/debug", middleware.RequireOperation(domain.OpAdmin))
+	{
+		debug.GET("/vars", gin.WrapH(expvar.Handler()))
+		debug.GET("/pprof/", gin.WrapF(pprof.Index))
+		debug.GET("/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/pprof/profile", gin.WrapF(pprof.Profile))
+		debug.GET("/pprof/symbol", gin.WrapF(pprof.Symbol))
+		debug.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/pprof/trace", gin.WrapF(pprof.Trace))
+		debug.GET("/pprof/:profile", h.Profile)
+	}
+}
+
+// Profile serves a named runtime profile such as heap, goroutine, or allocs.
+// pprof.Index only resolves names under /debug/pprof/, so they are routed here
+func (h *DebugHandler) Profile(c *gin.Context) {
+	pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type EmbeddingHandler struct {
+	monitor ports.EmbeddingMonitor
+}
+
+func NewEmbeddingHandler(monitor ports.EmbeddingMonitor) *EmbeddingHandler {
+	return &EmbeddingHandler{
+		monitor: monitor,
+	}
+}
+
+func (h *EmbeddingHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/admin/embedding", middleware.RequireOperation(domain.OpAdmin), h.GetStats)
+}
+
+// GetStats reports request counts, error rate, latency, and circuit state per provider
+func (h *EmbeddingHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.monitor.ProviderStats()})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse is the JSON envelope returned for every failed request
+type ErrorResponse struct {
+	Code    domain.ErrorCode       `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// statusForCode maps domain error codes to HTTP status codes
+func statusForCode(code domain.ErrorCode) int {
+	switch code {
+	case domain.CodeUnauthorized:
+		return http.StatusUnauthorized
+	case domain.CodeForbidden:
+		return http.StatusForbidden
+	case domain.CodeNotFound:
+		return http.StatusNotFound
+	case domain.CodeConflict:
+		return http.StatusConflict
+	case domain.CodeQuotaExceeded:
+		return http.StatusTooManyRequests
+	case domain.CodeValidation:
+		return http.StatusBadRequest
+	case domain.CodePayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case domain.CodeUpstreamUnavailable, domain.CodeShuttingDown:
+		return http.StatusServiceUnavailable
+	case domain.CodeTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondError writes err as an error envelope, hiding internal error text from clients
+func respondError(c *gin.Context, err error) {
+	c.JSON(errorResponse(c.Request.Context(), err))
+}
+
+// errorResponse maps err to its HTTP status and envelope, logging server-side failures
+func errorResponse(ctx context.Context, err error) (int, ErrorResponse) {
+	domainErr := domain.AsError(err)
+	if domainErr == nil {
+		logging.FromContext(ctx).WithError(err).Error("Unhandled error")
+		return http.StatusInternalServerError, ErrorResponse{
+			Code:    domain.CodeInternal,
+			Message: "internal server error",
+		}
+	}
+
+	status := statusForCode(domainErr.Code)
+	if status >= http.StatusInternalServerError {
+		logging.FromContext(ctx).WithError(err).Error("Request failed")
+	}
+
+	return status, ErrorResponse{
+		Code:    domainErr.Code,
+		Message: domainErr.Message,
+		Details: domainErr.Details,
+	}
+}
+
+// respondValidationError writes a 400 envelope for malformed request input
+func respondValidationError(c *gin.Context, message string) {
+	respondError(c, domain.NewValidationError(message))
+}
+
+// respondBindError writes the envelope for a request body that failed to
+// decode: 413 when it exceeded the body limit, 400 otherwise
+func respondBindError(c *gin.Context, err error) {
+	respondError(c, bodyError(err))
+}
+
+// bodyError maps a failure reading or decoding a request body to a typed error
+func bodyError(err error) *domain.Error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &domain.Error{
+			Code:    domain.CodePayloadTooLarge,
+			Message: "request body too large",
+			Details: map[string]interface{}{"max_bytes": tooLarge.Limit},
+		}
+	}
+	return domain.NewValidationError(err.Error())
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type EvictionHandler struct {
+	evictor ports.ArtifactEvictor
+}
+
+func NewEvictionHandler(evictor ports.ArtifactEvictor) *EvictionHandler {
+	return &EvictionHandler{
+		evictor: evictor,
+	}
+}
+
+func (h *EvictionHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/admin/eviction", middleware.RequireOperation(domain.OpAdmin), h.GetStats)
+}
+
+// GetStats reports the storage budget, current usage, and eviction counts
+func (h *EvictionHandler) GetStats(c *gin.Context) {
+	stats, err := h.evictor.Stats(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
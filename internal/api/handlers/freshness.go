@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FreshnessSourceHandler struct {
+	sourceService ports.FreshnessSourceService
+}
+
+func NewFreshnessSourceHandler(sourceService ports.FreshnessSourceService) *FreshnessSourceHandler {
+	return &FreshnessSourceHandler{
+		sourceService: sourceService,
+	}
+}
+
+func (h *FreshnessSourceHandler) RegisterRoutes(r *gin.RouterGroup) {
+	sources := r.Group("/cache/freshness-sources")
+	{
+		read := middleware.RequireOperation(domain.OpRead)
+		write := middleware.RequireOperation(domain.OpInvalidate)
+
+		sources.POST("", write, h.CreateSource)
+		sources.GET("", read, h.ListSources)
+		sources.GET("/:id", read, h.GetSource)
+		sources.PUT("/:id", write, h.UpdateSource)
+		sources.DELETE("/:id", write, h.DeleteSource)
+	}
+}
+
+func (h *FreshnessSourceHandler) CreateSource(c *gin.Context) {
+	var req domain.FreshnessSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	source, err := h.sourceService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, source)
+}
+
+func (h *FreshnessSourceHandler) ListSources(c *gin.Context) {
+	sources, err := h.sourceService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sources": sources})
+}
+
+func (h *FreshnessSourceHandler) GetSource(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid freshness source ID")
+		return
+	}
+
+	source, err := h.sourceService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, source)
+}
+
+func (h *FreshnessSourceHandler) UpdateSource(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid freshness source ID")
+		return
+	}
+
+	var req domain.FreshnessSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	source, err := h.sourceService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, source)
+}
+
+func (h *FreshnessSourceHandler) DeleteSource(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid freshness source ID")
+		return
+	}
+
+	if err := h.sourceService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "freshness source deleted"})
+}
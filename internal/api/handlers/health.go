@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	healthService ports.HealthService
+}
+
+func NewHealthHandler(healthService ports.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// RegisterRoutes mounts the probes outside /v1 so they need no credentials
+func (h *HealthHandler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/health", h.Live)
+	r.GET("/health/live", h.Live)
+	r.GET("/health/ready", h.Ready)
+}
+
+// Live reports that the process is serving requests; it touches no dependencies
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "healthy",
+		"timestamp": time.Now().UTC(),
+		"service":   "mentis",
+	})
+}
+
+// Ready checks Postgres, the vector store and optionally the embedding provider,
+// answering 503 when any of them is unavailable
+func (h *HealthHandler) Ready(c *gin.Context) {
+	response := h.healthService.Ready(c.Request.Context())
+	status := http.StatusOK
+	if !response.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, response)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type ImportHandler struct {
+	importService ports.ImportService
+	publishCfg    config.PublishConfig
+}
+
+func NewImportHandler(importService ports.ImportService, publishCfg config.PublishConfig) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+		publishCfg:    publishCfg,
+	}
+}
+
+func (h *ImportHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/cache/import", middleware.RequireOperation(domain.OpPublish), h.Import)
+}
+
+// Import publishes an NDJSON export of LangChain documents, LlamaIndex nodes
+// or generic records, a batch at a time as streamed publishes are
+func (h *ImportHandler) Import(c *gin.Context) {
+	options := domain.ImportOptions{
+		Type:    domain.ArtifactType(c.DefaultQuery("type", string(domain.RAW))),
+		Source:  c.Query("source"),
+		Tags:    c.QueryArray("tags"),
+		Reembed: c.Query("reembed") == "true",
+	}
+	if !allowsArtifactType(c, options.Type) {
+		respondError(c, &domain.Error{
+			Code:    domain.CodeForbidden,
+			Message: "API key does not permit artifact type " + string(options.Type),
+		})
+		return
+	}
+
+	allow := func(domain.ImportRecord) error { return nil }
+	publish := func(ctx context.Context, records []domain.ImportRecord) (*domain.PublishResponse, error) {
+		return h.importService.Import(ctx, records, options)
+	}
+	streamPublish(c, h.publishCfg.StreamBatchSize, allow, publish)
+}
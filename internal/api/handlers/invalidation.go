@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InvalidationPolicyHandler struct {
+	policyService ports.InvalidationPolicyService
+}
+
+func NewInvalidationPolicyHandler(policyService ports.InvalidationPolicyService) *InvalidationPolicyHandler {
+	return &InvalidationPolicyHandler{
+		policyService: policyService,
+	}
+}
+
+func (h *InvalidationPolicyHandler) RegisterRoutes(r *gin.RouterGroup) {
+	policies := r.Group("/cache/invalidation-policies")
+	{
+		read := middleware.RequireOperation(domain.OpRead)
+		write := middleware.RequireOperation(domain.OpInvalidate)
+
+		policies.POST("", write, h.CreatePolicy)
+		policies.GET("", read, h.ListPolicies)
+		policies.GET("/:id", read, h.GetPolicy)
+		policies.PUT("/:id", write, h.UpdatePolicy)
+		policies.DELETE("/:id", write, h.DeletePolicy)
+	}
+}
+
+func (h *InvalidationPolicyHandler) CreatePolicy(c *gin.Context) {
+	var req domain.InvalidationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	policy, err := h.policyService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+func (h *InvalidationPolicyHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.policyService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"policies": policies})
+}
+
+func (h *InvalidationPolicyHandler) GetPolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid invalidation policy ID")
+		return
+	}
+
+	policy, err := h.policyService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *InvalidationPolicyHandler) UpdatePolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid invalidation policy ID")
+		return
+	}
+
+	var req domain.InvalidationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	policy, err := h.policyService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *InvalidationPolicyHandler) DeletePolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid invalidation policy ID")
+		return
+	}
+
+	if err := h.policyService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invalidation policy deleted"})
+}
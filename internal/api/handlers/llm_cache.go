@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *CacheHandler) StoreLLMAnswer(c *gin.Context) {
+	var req domain.LLMCacheStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if !allowsArtifactType(c, domain.ANSWER) {
+		respondError(c, &domain.Error{
+			Code:    domain.CodeForbidden,
+			Message: "API key does not permit artifact type " + string(domain.ANSWER),
+		})
+		return
+	}
+
+	response, err := h.cacheService.StoreLLMAnswer(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CacheHandler) CheckLLMCache(c *gin.Context) {
+	var req domain.LLMCacheCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if !allowsArtifactType(c, domain.ANSWER) {
+		respondError(c, &domain.Error{
+			Code:    domain.CodeForbidden,
+			Message: "API key does not permit artifact type " + string(domain.ANSWER),
+		})
+		return
+	}
+
+	response, err := h.cacheService.CheckLLMCache(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type QuotaHandler struct {
+	quotaService ports.QuotaService
+}
+
+func NewQuotaHandler(quotaService ports.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+func (h *QuotaHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/quota", h.GetQuota)
+
+	admin := r.Group("/admin/quotas", middleware.RequireOperation(domain.OpAdmin))
+	{
+		admin.PUT("/:namespace", h.SetQuota)
+		admin.DELETE("/:namespace", h.ResetQuota)
+	}
+
+	keys := r.Group("/admin/api-keys/:id/quota", middleware.RequireOperation(domain.OpAdmin))
+	{
+		keys.PUT("", h.SetKeyQuota)
+		keys.DELETE("", h.ResetKeyQuota)
+	}
+}
+
+// GetQuota reports limits, usage, and remaining headroom for the caller's namespace
+func (h *QuotaHandler) GetQuota(c *gin.Context) {
+	status, err := h.quotaService.Status(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *QuotaHandler) SetQuota(c *gin.Context) {
+	var limits domain.QuotaLimits
+	if err := c.ShouldBindJSON(&limits); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := h.quotaService.SetLimits(c.Request.Context(), c.Param("namespace"), &limits); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"namespace": c.Param("namespace"), "limits": limits})
+}
+
+func (h *QuotaHandler) ResetQuota(c *gin.Context) {
+	if err := h.quotaService.ResetLimits(c.Request.Context(), c.Param("namespace")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "quota reset to defaults"})
+}
+
+// SetKeyQuota caps one API key's usage across all of its namespaces
+func (h *QuotaHandler) SetKeyQuota(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid API key ID")
+		return
+	}
+
+	var limits domain.QuotaLimits
+	if err := c.ShouldBindJSON(&limits); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := h.quotaService.SetKeyLimits(c.Request.Context(), keyID, &limits); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"key_id": keyID, "limits": limits})
+}
+
+func (h *QuotaHandler) ResetKeyQuota(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid API key ID")
+		return
+	}
+
+	if err := h.quotaService.ResetKeyLimits(c.Request.Context(), keyID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key quota removed"})
+}
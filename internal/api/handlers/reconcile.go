@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type ReconcileHandler struct {
+	reconciler ports.Reconciler
+}
+
+func NewReconcileHandler(reconciler ports.Reconciler) *ReconcileHandler {
+	return &ReconcileHandler{
+		reconciler: reconciler,
+	}
+}
+
+func (h *ReconcileHandler) RegisterRoutes(r *gin.RouterGroup) {
+	admin := r.Group("/admin/reconcile", middleware.RequireOperation(domain.OpAdmin))
+	{
+		admin.GET("", h.GetReport)
+		admin.POST("", h.Run)
+	}
+}
+
+// GetReport returns the most recent reconciliation report
+func (h *ReconcileHandler) GetReport(c *gin.Context) {
+	report := h.reconciler.LastReport()
+	if report == nil {
+		respondError(c, domain.NewNotFoundError("reconciliation report", "latest"))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Run performs a reconciliation pass and returns its report
+func (h *ReconcileHandler) Run(c *gin.Context) {
+	c.JSON(http.StatusOK, h.reconciler.Run(c.Request.Context()))
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RefreshHandler struct {
+	refreshService ports.RefreshService
+}
+
+func NewRefreshHandler(refreshService ports.RefreshService) *RefreshHandler {
+	return &RefreshHandler{
+		refreshService: refreshService,
+	}
+}
+
+func (h *RefreshHandler) RegisterRoutes(r *gin.RouterGroup) {
+	// Hooks make the server call out to arbitrary URLs, so managing them is an admin operation
+	hooks := r.Group("/cache/refresh-hooks")
+	{
+		read := middleware.RequireOperation(domain.OpRead)
+		admin := middleware.RequireOperation(domain.OpAdmin)
+
+		hooks.POST("", admin, h.CreateHook)
+		hooks.GET("", read, h.ListHooks)
+		hooks.GET("/:id", read, h.GetHook)
+		hooks.PUT("/:id", admin, h.UpdateHook)
+		hooks.DELETE("/:id", admin, h.DeleteHook)
+	}
+
+	r.POST("/cache/artifacts/:id/refresh", middleware.RequireOperation(domain.OpPublish), h.RefreshArtifact)
+}
+
+func (h *RefreshHandler) CreateHook(c *gin.Context) {
+	var req domain.RefreshHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	hook, err := h.refreshService.CreateHook(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+func (h *RefreshHandler) ListHooks(c *gin.Context) {
+	hooks, err := h.refreshService.ListHooks(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hooks": hooks})
+}
+
+func (h *RefreshHandler) GetHook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid refresh hook ID")
+		return
+	}
+
+	hook, err := h.refreshService.GetHook(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *RefreshHandler) UpdateHook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid refresh hook ID")
+		return
+	}
+
+	var req domain.RefreshHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	hook, err := h.refreshService.UpdateHook(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *RefreshHandler) DeleteHook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid refresh hook ID")
+		return
+	}
+
+	if err := h.refreshService.DeleteHook(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "refresh hook deleted"})
+}
+
+// RefreshArtifact runs the matching refresh hook for an artifact immediately
+func (h *RefreshHandler) RefreshArtifact(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid artifact ID")
+		return
+	}
+
+	result, err := h.refreshService.Refresh(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ScheduleHandler struct {
+	scheduleService ports.ScheduleService
+}
+
+func NewScheduleHandler(scheduleService ports.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+func (h *ScheduleHandler) RegisterRoutes(r *gin.RouterGroup) {
+	schedules := r.Group("/workflow/schedules")
+	{
+		read := middleware.RequireOperation(domain.OpWorkflowRead)
+		write := middleware.RequireOperation(domain.OpWorkflowWrite)
+
+		schedules.POST("", write, h.CreateSchedule)
+		schedules.GET("", read, h.ListSchedules)
+		schedules.GET("/:id", read, h.GetSchedule)
+		schedules.PUT("/:id", write, h.UpdateSchedule)
+		schedules.DELETE("/:id", write, h.DeleteSchedule)
+		schedules.GET("/:id/runs", read, h.ListRuns)
+		schedules.POST("/:id/run", write, h.TriggerSchedule)
+	}
+}
+
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req domain.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	schedule, err := h.scheduleService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.scheduleService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid schedule ID")
+		return
+	}
+
+	schedule, err := h.scheduleService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid schedule ID")
+		return
+	}
+
+	var req domain.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	schedule, err := h.scheduleService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid schedule ID")
+		return
+	}
+
+	if err := h.scheduleService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "schedule deleted"})
+}
+
+// ListRuns returns a schedule's most recent runs, newest first
+func (h *ScheduleHandler) ListRuns(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid schedule ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	runs, err := h.scheduleService.ListRuns(c.Request.Context(), id, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// TriggerSchedule runs a schedule immediately, outside its cron cadence
+func (h *ScheduleHandler) TriggerSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid schedule ID")
+		return
+	}
+
+	schedule, err := h.scheduleService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	run, err := h.scheduleService.Run(c.Request.Context(), schedule)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type StepSchemaHandler struct {
+	schemaService ports.StepSchemaService
+}
+
+func NewStepSchemaHandler(schemaService ports.StepSchemaService) *StepSchemaHandler {
+	return &StepSchemaHandler{
+		schemaService: schemaService,
+	}
+}
+
+func (h *StepSchemaHandler) RegisterRoutes(r *gin.RouterGroup) {
+	schemas := r.Group("/workflow/step-types")
+	{
+		read := middleware.RequireOperation(domain.OpWorkflowRead)
+		write := middleware.RequireOperation(domain.OpWorkflowWrite)
+
+		schemas.GET("", read, h.ListSchemas)
+		schemas.GET("/:type/schema", read, h.GetSchema)
+		schemas.PUT("/:type/schema", write, h.PutSchema)
+		schemas.DELETE("/:type/schema", write, h.DeleteSchema)
+	}
+}
+
+func (h *StepSchemaHandler) ListSchemas(c *gin.Context) {
+	schemas, err := h.schemaService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schemas": schemas})
+}
+
+func (h *StepSchemaHandler) GetSchema(c *gin.Context) {
+	schema, err := h.schemaService.Get(c.Request.Context(), c.Param("type"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// PutSchema registers or replaces the input/output schemas of a step type
+func (h *StepSchemaHandler) PutSchema(c *gin.Context) {
+	var req struct {
+		InputSchema  map[string]interface{} `json:"input_schema"`
+		OutputSchema map[string]interface{} `json:"output_schema"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	schema, err := h.schemaService.Register(c.Request.Context(), &domain.StepSchema{
+		StepType:     c.Param("type"),
+		InputSchema:  req.InputSchema,
+		OutputSchema: req.OutputSchema,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+func (h *StepSchemaHandler) DeleteSchema(c *gin.Context) {
+	if err := h.schemaService.Delete(c.Request.Context(), c.Param("type")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "step schema deleted"})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// allowsArtifactType reports whether the caller's key may access artifacts of type t
+func allowsArtifactType(c *gin.Context, t domain.ArtifactType) bool {
+	principal := domain.PrincipalFromContext(c.Request.Context())
+	return principal == nil || principal.AllowsArtifactType(t)
+}
+
+// scopeLookupNamespaces rejects lookups into namespaces the caller's key may not access.
+// It writes an error response and returns false when a namespace is not permitted.
+func scopeLookupNamespaces(c *gin.Context, options *domain.LookupOptions) bool {
+	principal := domain.PrincipalFromContext(c.Request.Context())
+	if principal == nil {
+		return true
+	}
+
+	for _, namespace := range options.Namespaces {
+		if !principal.AllowsNamespace(namespace) {
+			respondError(c, &domain.Error{
+				Code:    domain.CodeForbidden,
+				Message: "API key is not valid for namespace " + namespace,
+			})
+			return false
+		}
+	}
+
+	return true
+}
+
+// scopeLookupType restricts a lookup to the caller's permitted artifact types.
+// It writes an error response and returns false when the lookup can't be scoped.
+func scopeLookupType(c *gin.Context, options *domain.LookupOptions) bool {
+	principal := domain.PrincipalFromContext(c.Request.Context())
+	if principal == nil || len(principal.ArtifactTypes) == 0 {
+		return true
+	}
+
+	if options.ArtifactType == "" {
+		if len(principal.ArtifactTypes) > 1 {
+			respondValidationError(c, "artifact_type is required for keys restricted to multiple artifact types")
+			return false
+		}
+		options.ArtifactType = principal.ArtifactTypes[0]
+	}
+
+	if !principal.AllowsArtifactType(options.ArtifactType) {
+		respondError(c, &domain.Error{
+			Code:    domain.CodeForbidden,
+			Message: "API key does not permit artifact type " + string(options.ArtifactType),
+		})
+		return false
+	}
+
+	return true
+}
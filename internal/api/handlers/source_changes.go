@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type SourceChangeHandler struct {
+	sourceChanges ports.SourceChangeService
+}
+
+func NewSourceChangeHandler(sourceChanges ports.SourceChangeService) *SourceChangeHandler {
+	return &SourceChangeHandler{
+		sourceChanges: sourceChanges,
+	}
+}
+
+// RegisterRoutes adds the webhook receiver. CMSs and crawlers can sign
+// requests but rarely send API keys, so it is registered outside API key
+// authentication and admits signed requests instead
+func (h *SourceChangeHandler) RegisterRoutes(r gin.IRoutes) {
+	r.POST("/v1/events/source-changes", h.Receive)
+}
+
+// Receive invalidates the artifacts fetched from the source a signed event reports changed
+func (h *SourceChangeHandler) Receive(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	err = h.sourceChanges.VerifySignature(c.GetHeader("X-Mentis-Timestamp"), c.GetHeader("X-Mentis-Signature"), body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var event domain.SourceChangeEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if event.SourceURL == "" {
+		respondValidationError(c, "source_url is required")
+		return
+	}
+
+	response, err := h.sourceChanges.Handle(c.Request.Context(), &event)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type StatusHandler struct {
+	statusService ports.StatusService
+}
+
+func NewStatusHandler(statusService ports.StatusService) *StatusHandler {
+	return &StatusHandler{
+		statusService: statusService,
+	}
+}
+
+func (h *StatusHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/admin/status", middleware.RequireOperation(domain.OpAdmin), h.GetStatus)
+}
+
+// GetStatus reports each dependency and background job with its state, last
+// error, and error count over the last minutes (the configured window by default)
+func (h *StatusHandler) GetStatus(c *gin.Context) {
+	var window time.Duration
+	if minutesParam := c.Query("minutes"); minutesParam != "" {
+		minutes, err := strconv.Atoi(minutesParam)
+		if err != nil {
+			respondValidationError(c, "minutes must be an integer")
+			return
+		}
+		window = time.Duration(minutes) * time.Minute
+	}
+
+	report, err := h.statusService.Status(c.Request.Context(), window)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// toolDefinition is a tool together with the operation its endpoint requires
+type toolDefinition struct {
+	operation domain.Operation
+	endpoint  domain.ToolEndpoint
+	function  domain.ToolFunction
+}
+
+var artifactTypes = []interface{}{domain.RAW, domain.DERIVED, domain.REASONING, domain.ANSWER}
+
+// toolDefinitions describe the request bodies of their endpoints, so a tool
+// call's arguments can be sent as they are
+var toolDefinitions = []toolDefinition{
+	{
+		operation: domain.OpLookup,
+		endpoint:  domain.ToolEndpoint{Method: http.MethodPost, Path: "/v1/cache/lookup"},
+		function: domain.ToolFunction{
+			Name: "lookup",
+			Description: "Search the Mentis cache for previously stored content semantically similar to a query. " +
+				"Use it before fetching or computing something that may already be known.",
+			Parameters: objectSchema(map[string]interface{}{
+				"options": objectSchema(map[string]interface{}{
+					"query":           stringSchema("Natural-language description of the content wanted"),
+					"top_k":           map[string]interface{}{"type": "integer", "minimum": 1, "description": "Maximum number of results"},
+					"min_score":       map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1, "description": "Minimum similarity score; omit for the server default"},
+					"artifact_type":   enumSchema("Only return artifacts of this type", artifactTypes),
+					"include_content": map[string]interface{}{"type": "boolean", "description": "Return each artifact's content; set true to read the results"},
+					"tags":            arraySchema("Only return artifacts carrying any of these tags", stringSchema("")),
+					"mode":            enumSchema("vector similarity, or hybrid keyword and vector search", []interface{}{domain.LookupVector, domain.LookupHybrid}),
+					"filter": arraySchema("Conditions on artifact metadata that results must all satisfy", objectSchema(map[string]interface{}{
+						"field": stringSchema("Metadata field, such as source_url"),
+						"op": enumSchema("Comparison", []interface{}{
+							domain.FilterEq, domain.FilterNe, domain.FilterGt, domain.FilterLt,
+							domain.FilterIn, domain.FilterExists, domain.FilterPrefix,
+						}),
+						"value": map[string]interface{}{"description": "Value compared against; a list for in, omitted for exists"},
+					}, "field", "op")),
+				}, "query"),
+			}, "options"),
+		},
+	},
+	{
+		operation: domain.OpPublish,
+		endpoint:  domain.ToolEndpoint{Method: http.MethodPost, Path: "/v1/cache/publish"},
+		function: domain.ToolFunction{
+			Name:        "publish",
+			Description: "Store content in the Mentis cache so later lookups by this or other agents can reuse it.",
+			Parameters: objectSchema(map[string]interface{}{
+				"objects": arraySchema("Artifacts to store", objectSchema(map[string]interface{}{
+					"type":     enumSchema("RAW for fetched source content, DERIVED for processed content, REASONING or ANSWER for model output", artifactTypes),
+					"content":  map[string]interface{}{"type": "string", "contentEncoding": "base64", "description": "The content, base64-encoded"},
+					"metadata": map[string]interface{}{"type": "object", "description": "Metadata such as source_url, the page the content came from"},
+					"tags":     arraySchema("Labels lookups can filter by", stringSchema("")),
+					"upsert_key": stringSchema("Metadata field identifying the artifact, such as source_url; " +
+						"publishing again with the same value stores a new version instead of another artifact"),
+				}, "type", "content")),
+			}, "objects"),
+		},
+	},
+	{
+		operation: domain.OpWorkflowWrite,
+		endpoint:  domain.ToolEndpoint{Method: http.MethodPost, Path: "/v1/workflow/steps"},
+		function: domain.ToolFunction{
+			Name: "execute_step",
+			Description: "Run a step of a Mentis workflow session, such as scrape with a URL as input. " +
+				"A step already run with the same type and input returns its cached result.",
+			Parameters: objectSchema(map[string]interface{}{
+				"session_id": map[string]interface{}{"type": "string", "format": "uuid", "description": "Workflow session the step belongs to"},
+				"step_type":  stringSchema("Kind of step, such as scrape, process, reason or answer"),
+				"input":      map[string]interface{}{"description": "The step's input; for scrape, the URL to fetch"},
+				"metadata":   map[string]interface{}{"type": "object", "description": "Metadata stored with the step"},
+				"timeout":    stringSchema("Bound on each attempt, as a duration such as 30s"),
+				"force":      map[string]interface{}{"type": "boolean", "description": "Run fresh even if a cached result exists, and replace it"},
+			}, "session_id", "step_type", "input"),
+		},
+	},
+}
+
+type ToolHandler struct{}
+
+func NewToolHandler() *ToolHandler {
+	return &ToolHandler{}
+}
+
+func (h *ToolHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/tools/manifest", h.GetManifest)
+}
+
+// GetManifest lists the tools the caller's API key may call, so function-calling
+// agents can discover Mentis at runtime
+func (h *ToolHandler) GetManifest(c *gin.Context) {
+	principal := domain.PrincipalFromContext(c.Request.Context())
+
+	manifest := domain.ToolManifest{
+		Tools:     []domain.Tool{},
+		Endpoints: make(map[string]domain.ToolEndpoint),
+	}
+	for _, definition := range toolDefinitions {
+		if principal != nil && !principal.Allows(definition.operation) {
+			continue
+		}
+		manifest.Tools = append(manifest.Tools, domain.Tool{Type: "function", Function: definition.function})
+		manifest.Endpoints[definition.function.Name] = definition.endpoint
+	}
+
+	c.JSON(http.StatusOK, manifest)
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func arraySchema(description string, items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "description": description, "items": items}
+}
+
+func stringSchema(description string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "string"}
+	if description != "" {
+		schema["description"] = description
+	}
+	return schema
+}
+
+func enumSchema(description string, values []interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description, "enum": values}
+}
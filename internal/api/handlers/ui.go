@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed ui
+var uiAssets embed.FS
+
+// UIHandler serves the embedded admin dashboard. The assets themselves are
+// public: the dashboard calls the API with a key the operator enters, so it
+// shows only what that key may read
+type UIHandler struct {
+	files http.FileSystem
+}
+
+func NewUIHandler() *UIHandler {
+	assets, _ := fs.Sub(uiAssets, "ui")
+	return &UIHandler{files: http.FS(assets)}
+}
+
+func (h *UIHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/ui", func(c *gin.Context) { c.Redirect(http.StatusMovedPermanently, "/ui/") })
+	r.Group("/ui", uiHeaders).StaticFS("/", h.files)
+}
+
+// uiHeaders keep the dashboard to its own scripts and out of frames
+func uiHeaders(c *gin.Context) {
+	c.Header("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Next()
+}
@@ -0,0 +1,290 @@
+// Mentis admin dashboard. Everything shown comes from the /v1 API, called with
+// the key entered in the header; the key is kept for the browser session only.
+// Server data is only ever inserted as text, never as markup.
+"use strict";
+
+const keyStorage = "mentis.apiKey";
+const view = document.getElementById("view");
+const errorBox = document.getElementById("error");
+
+function apiKey() {
+  return sessionStorage.getItem(keyStorage) || "";
+}
+
+async function api(method, path, body) {
+  const headers = {};
+  if (apiKey()) headers["Authorization"] = "Bearer " + apiKey();
+  if (body) headers["Content-Type"] = "application/json";
+  const response = await fetch("/v1" + path, { method, headers, body: body ? JSON.stringify(body) : undefined });
+  const data = await response.json().catch(() => null);
+  if (!response.ok) {
+    throw new Error(data && data.message ? data.message : response.status + " " + response.statusText);
+  }
+  return data;
+}
+
+// el builds an element; strings among children become text nodes
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    if (name === "onclick" || name === "onsubmit") node[name] = value;
+    else if (value !== undefined && value !== null && value !== false) node.setAttribute(name, value);
+  }
+  for (const child of children.flat()) {
+    if (child === undefined || child === null) continue;
+    node.append(child instanceof Node ? child : String(child));
+  }
+  return node;
+}
+
+function link(text, hash) {
+  return el("a", { href: "#" + hash }, text);
+}
+
+function badge(text) {
+  return el("span", { class: "badge " + text }, text);
+}
+
+function time(value) {
+  return value ? new Date(value).toLocaleString() : "";
+}
+
+function table(headings, rows) {
+  return el("table", {},
+    el("tr", {}, headings.map((heading) => el("th", {}, heading))),
+    rows.length ? rows : el("tr", {}, el("td", { colspan: headings.length }, "Nothing to show")));
+}
+
+function showError(err) {
+  errorBox.textContent = err ? err.message : "";
+  errorBox.hidden = !err;
+}
+
+function render(...nodes) {
+  view.replaceChildren(...nodes);
+}
+
+// decodeContent turns base64 artifact content into text for display
+function decodeContent(content) {
+  if (!content) return "";
+  const bytes = Uint8Array.from(atob(content), (c) => c.charCodeAt(0));
+  return new TextDecoder().decode(bytes);
+}
+
+async function overview() {
+  const cards = el("div", { class: "cards" });
+  const sections = [el("h2", {}, "Cache"), cards];
+
+  const stats = await api("GET", "/cache/stats");
+  cards.append(
+    el("div", { class: "card" }, el("b", {}, (stats.lookups.hit_rate * 100).toFixed(1) + "%"), "lookup hit rate"),
+    el("div", { class: "card" }, el("b", {}, stats.lookups.hits), "lookup hits"),
+    el("div", { class: "card" }, el("b", {}, (stats.steps.hit_rate * 100).toFixed(1) + "%"), "step hit rate"),
+    el("div", { class: "card" }, el("b", {}, stats.steps.hits), "steps served from cache"));
+
+  // Admin views need an admin key; other keys just see the cache stats
+  try {
+    const contents = await api("GET", "/admin/stats");
+    sections.push(el("h2", {}, "Artifacts"), table(["Type", "Fresh", "Stale", "Deleted", "Bytes"],
+      contents.artifacts.map((row) => el("tr", {}, el("td", {}, row.type), el("td", {}, row.fresh),
+        el("td", {}, row.stale), el("td", {}, row.deleted), el("td", {}, row.bytes)))));
+    const status = await api("GET", "/admin/status");
+    sections.push(el("h2", {}, "Components ", badge(status.status)), table(["Component", "State", "Errors", "Last error"],
+      status.components.map((component) => el("tr", {}, el("td", {}, component.name), el("td", {}, badge(component.state)),
+        el("td", {}, component.errors), el("td", { class: "mono" }, component.last_error || "")))));
+  } catch (err) {
+    sections.push(el("p", {}, "Component status and storage need a key with the admin operation."));
+  }
+  render(...sections);
+}
+
+async function artifacts(cursor) {
+  const page = await api("GET", "/cache/artifacts?limit=50" + (cursor ? "&cursor=" + encodeURIComponent(cursor) : ""));
+  const rows = page.artifacts.map((artifact) => el("tr", {},
+    el("td", { class: "mono" }, link(artifact.id, "artifacts/" + artifact.id)),
+    el("td", {}, artifact.type),
+    el("td", {}, artifact.stale ? badge("stale") : badge("active")),
+    el("td", { class: "mono" }, (artifact.metadata && artifact.metadata.source_url) || ""),
+    el("td", {}, (artifact.tags || []).join(", ")),
+    el("td", {}, time(artifact.updated_at))));
+  const more = page.next_cursor ? el("button", { onclick: () => artifacts(page.next_cursor) }, "Next page") : null;
+  render(el("h2", {}, "Artifacts"), table(["ID", "Type", "State", "Source", "Tags", "Updated"], rows), more);
+}
+
+async function artifact(id) {
+  const [item, lineage] = await Promise.all([
+    api("GET", "/cache/artifacts/" + id),
+    api("GET", "/cache/artifacts/" + id + "/lineage?direction=both&depth=3"),
+  ]);
+  const stale = el("button", {
+    onclick: async () => {
+      const url = item.metadata && item.metadata.source_url;
+      if (!url || !confirm("Mark every artifact fetched from " + url + " stale?")) return;
+      await invalidate({ source_url: url });
+      route();
+    },
+    disabled: !(item.metadata && item.metadata.source_url),
+  }, "Invalidate its source URL");
+
+  render(
+    el("h2", {}, "Artifact ", el("span", { class: "mono" }, item.id), " ", item.stale ? badge("stale") : badge("active")),
+    table(["Type", "Version", "Size", "Created", "Updated", "Hits"], [el("tr", {},
+      el("td", {}, item.type), el("td", {}, item.version), el("td", {}, item.content_size),
+      el("td", {}, time(item.created_at)), el("td", {}, time(item.updated_at)), el("td", {}, item.hit_count))]),
+    stale,
+    el("h2", {}, "Dependency graph"), lineageGraph(lineage),
+    el("h2", {}, "Metadata"), el("pre", {}, JSON.stringify(item.metadata, null, 2)),
+    el("h2", {}, "Content"), el("pre", {}, item.content ? decodeContent(item.content).slice(0, 20000) : item.content_ref ? "(offloaded to object storage)" : ""));
+}
+
+// lineageGraph lays nodes out in columns by depth, upstream on the left
+function lineageGraph(lineage) {
+  const svgNS = "http://www.w3.org/2000/svg";
+  const width = 250, height = 28, gapX = 60, gapY = 12;
+  const columns = new Map();
+  for (const node of lineage.nodes) {
+    if (!columns.has(node.depth)) columns.set(node.depth, []);
+    columns.get(node.depth).push(node);
+  }
+  const depths = [...columns.keys()].sort((a, b) => a - b);
+  const positions = new Map();
+  depths.forEach((depth, column) => {
+    columns.get(depth).forEach((node, row) => {
+      positions.set(node.id, { x: 10 + column * (width + gapX), y: 10 + row * (height + gapY) });
+    });
+  });
+
+  const tallest = Math.max(1, ...[...columns.values()].map((nodes) => nodes.length));
+  const svg = document.createElementNS(svgNS, "svg");
+  svg.setAttribute("width", 20 + depths.length * (width + gapX));
+  svg.setAttribute("height", 20 + tallest * (height + gapY));
+
+  for (const edge of lineage.edges) {
+    const from = positions.get(edge.from), to = positions.get(edge.to);
+    if (!from || !to) continue;
+    const line = document.createElementNS(svgNS, "line");
+    line.setAttribute("x1", from.x + width);
+    line.setAttribute("y1", from.y + height / 2);
+    line.setAttribute("x2", to.x);
+    line.setAttribute("y2", to.y + height / 2);
+    svg.append(line);
+  }
+  for (const node of lineage.nodes) {
+    const position = positions.get(node.id);
+    const rect = document.createElementNS(svgNS, "rect");
+    rect.setAttribute("x", position.x);
+    rect.setAttribute("y", position.y);
+    rect.setAttribute("width", width);
+    rect.setAttribute("height", height);
+    rect.setAttribute("class", node.id === lineage.root ? "root" : node.stale ? "stale" : "");
+    const label = document.createElementNS(svgNS, "text");
+    label.setAttribute("x", position.x + 6);
+    label.setAttribute("y", position.y + 18);
+    label.textContent = node.type + " " + node.id.slice(0, 8) + " " + node.status;
+    label.onclick = () => { location.hash = "artifacts/" + node.id; };
+    svg.append(rect, label);
+  }
+
+  const note = lineage.truncated ? el("p", {}, "The graph was truncated.") : null;
+  return el("div", { class: "graph" }, svg, note);
+}
+
+async function sessions(cursor) {
+  const page = await api("GET", "/workflow/sessions?limit=50" + (cursor ? "&cursor=" + encodeURIComponent(cursor) : ""));
+  const rows = page.sessions.map((session) => el("tr", {},
+    el("td", { class: "mono" }, link(session.id, "sessions/" + session.id)),
+    el("td", {}, session.goal),
+    el("td", {}, badge(session.status)),
+    el("td", {}, (session.steps || []).length),
+    el("td", {}, time(session.created_at))));
+  const more = page.next_cursor ? el("button", { onclick: () => sessions(page.next_cursor) }, "Next page") : null;
+  render(el("h2", {}, "Sessions"), table(["ID", "Goal", "Status", "Steps", "Created"], rows), more);
+}
+
+async function session(id) {
+  const [item, events] = await Promise.all([
+    api("GET", "/workflow/sessions/" + id),
+    api("GET", "/workflow/sessions/" + id + "/events").catch(() => null),
+  ]);
+  const steps = (item.steps || []).map((step) => el("tr", {},
+    el("td", { class: "mono" }, step.id),
+    el("td", {}, step.step_type),
+    el("td", {}, badge(step.status)),
+    el("td", { class: "mono" }, step.artifact_id && !/^0{8}-/.test(step.artifact_id) ? link(step.artifact_id, "artifacts/" + step.artifact_id) : ""),
+    el("td", {}, time(step.created_at)),
+    el("td", {}, time(step.completed_at))));
+
+  const sections = [
+    el("h2", {}, "Session ", el("span", { class: "mono" }, item.id), " ", badge(item.status)),
+    el("p", {}, item.goal),
+    el("h2", {}, "Steps"), table(["ID", "Type", "Status", "Artifact", "Created", "Completed"], steps),
+  ];
+  if (events && events.events) {
+    sections.push(el("h2", {}, "Timeline"), table(["Time", "Event", "Step"], events.events.map((event) => el("tr", {},
+      el("td", {}, time(event.created_at)), el("td", {}, event.type), el("td", { class: "mono" }, event.step_id || "")))));
+  }
+  sections.push(el("h2", {}, "Context"), el("pre", {}, JSON.stringify(item.context, null, 2)));
+  render(...sections);
+}
+
+async function invalidate(request) {
+  const result = await api("POST", "/cache/invalidate", request);
+  alert(result.message + " (" + result.invalidated + " invalidated)");
+}
+
+function invalidateForm() {
+  const form = el("form", { class: "stacked" },
+    el("label", {}, "Source URL"), el("input", { name: "source_url", placeholder: "https://example.com/page" }),
+    el("p", {}, "or"),
+    el("label", {}, "Artifact type"), el("select", { name: "artifact_type" },
+      ["", "RAW", "DERIVED", "REASONING", "ANSWER"].map((type) => el("option", { value: type }, type || "any"))),
+    el("label", {}, "Older than"), el("input", { name: "older_than", placeholder: "168h" }),
+    el("p", {}, el("button", { type: "submit" }, "Invalidate")));
+  form.onsubmit = async (event) => {
+    event.preventDefault();
+    const data = new FormData(form);
+    const request = {};
+    for (const [name, value] of data.entries()) if (value) request[name] = value;
+    try {
+      showError(null);
+      await invalidate(request);
+    } catch (err) {
+      showError(err);
+    }
+  };
+  render(el("h2", {}, "Invalidate artifacts"),
+    el("p", {}, "Marks matching artifacts stale and removes their vectors, so lookups stop returning them."), form);
+}
+
+async function route() {
+  const [page, id] = location.hash.slice(1).split("/");
+  for (const a of document.querySelectorAll("nav a")) {
+    a.classList.toggle("active", a.getAttribute("href") === "#" + (page || "overview"));
+  }
+  showError(null);
+  try {
+    switch (page) {
+      case "artifacts": await (id ? artifact(id) : artifacts()); break;
+      case "sessions": await (id ? session(id) : sessions()); break;
+      case "invalidate": invalidateForm(); break;
+      default: await overview();
+    }
+  } catch (err) {
+    render();
+    showError(err);
+  }
+}
+
+document.getElementById("key-form").onsubmit = (event) => {
+  event.preventDefault();
+  const input = document.getElementById("key");
+  sessionStorage.setItem(keyStorage, input.value);
+  input.value = "";
+  route();
+};
+document.getElementById("forget-key").onclick = () => {
+  sessionStorage.removeItem(keyStorage);
+  route();
+};
+window.addEventListener("hashchange", route);
+route();
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type UsageHandler struct {
+	usageService ports.UsageService
+}
+
+func NewUsageHandler(usageService ports.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+func (h *UsageHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/admin/usage", middleware.RequireOperation(domain.OpAdmin), h.GetUsage)
+}
+
+// GetUsage reports daily usage between the from and to dates (YYYY-MM-DD,
+// inclusive), for every namespace or the one named by the namespace parameter
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	var from, to time.Time
+	for _, param := range []struct {
+		name string
+		date *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondValidationError(c, param.name+" must be a date in YYYY-MM-DD format")
+			return
+		}
+		*param.date = date
+	}
+
+	report, err := h.usageService.Report(c.Request.Context(), from, to, c.Query("namespace"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anunay/mentis/internal/api/middleware"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	subscriptionService ports.WebhookSubscriptionService
+}
+
+func NewWebhookHandler(subscriptionService ports.WebhookSubscriptionService) *WebhookHandler {
+	return &WebhookHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+func (h *WebhookHandler) RegisterRoutes(r *gin.RouterGroup) {
+	// Subscriptions make the server call out to arbitrary URLs, so managing them is an admin operation
+	webhooks := r.Group("/webhooks")
+	{
+		read := middleware.RequireOperation(domain.OpRead)
+		admin := middleware.RequireOperation(domain.OpAdmin)
+
+		webhooks.POST("", admin, h.CreateSubscription)
+		webhooks.GET("", read, h.ListSubscriptions)
+		webhooks.GET("/:id", read, h.GetSubscription)
+		webhooks.PUT("/:id", admin, h.UpdateSubscription)
+		webhooks.DELETE("/:id", admin, h.DeleteSubscription)
+		webhooks.GET("/:id/deliveries", read, h.ListDeliveries)
+	}
+}
+
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req domain.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	subscription, err := h.subscriptionService.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.subscriptionService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subscriptions})
+}
+
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid webhook subscription ID")
+		return
+	}
+
+	subscription, err := h.subscriptionService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid webhook subscription ID")
+		return
+	}
+
+	var req domain.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	subscription, err := h.subscriptionService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid webhook subscription ID")
+		return
+	}
+
+	if err := h.subscriptionService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook subscription deleted"})
+}
+
+// ListDeliveries returns a subscription's most recent delivery attempts, newest first
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid webhook subscription ID")
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	deliveries, err := h.subscriptionService.ListDeliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/anunay/mentis/internal/api/middleware"
 	"github.com/anunay/mentis/internal/core/domain"
 	"github.com/anunay/mentis/internal/core/ports"
 	"github.com/gin-gonic/gin"
@@ -23,29 +26,44 @@ func NewWorkflowHandler(workflowService ports.WorkflowService) *WorkflowHandler
 func (h *WorkflowHandler) RegisterRoutes(r *gin.RouterGroup) {
 	workflow := r.Group("/workflow")
 	{
-		workflow.POST("/sessions", h.CreateSession)
-		workflow.GET("/sessions/:id", h.GetSession)
-		workflow.POST("/sessions/:id/complete", h.CompleteSession)
-		workflow.POST("/sessions/:id/fail", h.FailSession)
-		workflow.POST("/steps", h.ExecuteStep)
-		workflow.POST("/steps/lookup", h.LookupStep)
+		read := middleware.RequireOperation(domain.OpWorkflowRead)
+		write := middleware.RequireOperation(domain.OpWorkflowWrite)
+
+		workflow.POST("/sessions", write, h.CreateSession)
+		workflow.GET("/sessions", read, h.ListSessions)
+		workflow.GET("/sessions/search", read, h.SearchSessions)
+		workflow.GET("/savings", read, h.GetSavings)
+		workflow.GET("/sessions/:id", read, h.GetSession)
+		workflow.GET("/sessions/:id/events", read, h.ListSessionEvents)
+		workflow.GET("/sessions/:id/progress", read, h.GetSessionProgress)
+		workflow.POST("/sessions/:id/fork", write, h.ForkSession)
+		workflow.POST("/sessions/:id/complete", write, h.CompleteSession)
+		workflow.POST("/sessions/:id/fail", write, h.FailSession)
+		workflow.POST("/steps", write, h.ExecuteStep)
+		workflow.POST("/steps/stream", write, h.StreamStep)
+		workflow.POST("/steps/batch", write, h.ExecuteSteps)
+		workflow.POST("/steps/claim", write, h.ClaimSteps)
+		workflow.POST("/steps/:id/complete", write, h.CompleteStep)
+		workflow.POST("/steps/:id/heartbeat", write, h.RenewStepLease)
+		workflow.GET("/steps/:id", read, h.GetStep)
+		workflow.POST("/steps/lookup", read, h.LookupStep)
+		workflow.POST("/templates", write, h.PublishTemplate)
+		workflow.GET("/templates", read, h.ListTemplates)
+		workflow.GET("/templates/:name", read, h.GetTemplate)
+		workflow.GET("/templates/:name/versions", read, h.ListTemplateVersions)
 	}
 }
 
 func (h *WorkflowHandler) CreateSession(c *gin.Context) {
-	var req struct {
-		Goal    string                 `json:"goal" binding:"required"`
-		Context map[string]interface{} `json:"context"`
-	}
-
+	var req domain.CreateSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	session, err := h.workflowService.CreateSession(c.Request.Context(), req.Goal, req.Context)
+	session, err := h.workflowService.CreateSession(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -56,30 +74,168 @@ func (h *WorkflowHandler) GetSession(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		respondValidationError(c, "invalid session ID")
 		return
 	}
 
 	session, err := h.workflowService.GetSession(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, session)
 }
 
+func (h *WorkflowHandler) ListSessions(c *gin.Context) {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	response, err := h.workflowService.ListSessions(c.Request.Context(), limit, c.Query("cursor"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchSessions finds past sessions with goals similar to ?q=
+func (h *WorkflowHandler) SearchSessions(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		respondValidationError(c, "q parameter is required")
+		return
+	}
+
+	topK := 10
+	if topKStr := c.Query("top_k"); topKStr != "" {
+		if k, err := strconv.Atoi(topKStr); err == nil {
+			topK = k
+		}
+	}
+
+	var minScore float32
+	if scoreStr := c.Query("min_score"); scoreStr != "" {
+		score, err := strconv.ParseFloat(scoreStr, 32)
+		if err != nil {
+			respondValidationError(c, "invalid min_score")
+			return
+		}
+		minScore = float32(score)
+	}
+
+	response, err := h.workflowService.SearchSessions(c.Request.Context(), query, topK, minScore)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSavings totals steps served from cache and what they saved over recent sessions
+func (h *WorkflowHandler) GetSavings(c *gin.Context) {
+	days := 0
+	if daysStr := c.Query("days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil {
+			respondValidationError(c, "days must be an integer")
+			return
+		}
+		days = d
+	}
+
+	report, err := h.workflowService.GetSavings(c.Request.Context(), days)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetSessionProgress returns step counts, completion and a remaining-time estimate
+func (h *WorkflowHandler) GetSessionProgress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid session ID")
+		return
+	}
+
+	progress, err := h.workflowService.GetSessionProgress(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// ListSessionEvents returns the session's event timeline, oldest first
+func (h *WorkflowHandler) ListSessionEvents(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid session ID")
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = l
+		}
+	}
+
+	response, err := h.workflowService.ListSessionEvents(c.Request.Context(), id, limit, c.Query("cursor"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ForkSession creates a session sharing the parent's completed steps
+func (h *WorkflowHandler) ForkSession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid session ID")
+		return
+	}
+
+	var req domain.ForkSessionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+
+	session, err := h.workflowService.ForkSession(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
 func (h *WorkflowHandler) CompleteSession(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		respondValidationError(c, "invalid session ID")
 		return
 	}
 
 	err = h.workflowService.CompleteSession(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -90,7 +246,7 @@ func (h *WorkflowHandler) FailSession(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		respondValidationError(c, "invalid session ID")
 		return
 	}
 
@@ -99,13 +255,13 @@ func (h *WorkflowHandler) FailSession(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	err = h.workflowService.FailSession(c.Request.Context(), id, req.Reason)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -115,13 +271,187 @@ func (h *WorkflowHandler) FailSession(c *gin.Context) {
 func (h *WorkflowHandler) ExecuteStep(c *gin.Context) {
 	var req domain.WorkflowStepRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	response, err := h.workflowService.ExecuteStep(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	if response.Queued {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// streamBufferSize is how many chunks may queue while the client catches up
+const streamBufferSize = 64
+
+// StreamStep executes a step inline, streaming its partial output as server-sent
+// "chunk" events followed by a final "result" or "error" event
+func (h *WorkflowHandler) StreamStep(c *gin.Context) {
+	var req domain.WorkflowStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if req.Async || req.Executor != "" {
+		respondValidationError(c, "streamed steps run inline; async and executor are not supported")
+		return
+	}
+
+	reqCtx := c.Request.Context()
+	chunks := make(chan domain.StepChunk, streamBufferSize)
+	ctx := domain.WithChunkSink(reqCtx, func(chunk domain.StepChunk) {
+		select {
+		case chunks <- chunk:
+		case <-reqCtx.Done():
+		}
+	})
+
+	var response *domain.WorkflowStepResponse
+	var execErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		response, execErr = h.workflowService.ExecuteStep(ctx, &req)
+	}()
+
+	// Wait for the first output so failures before it keep their HTTP status
+	var first *domain.StepChunk
+	select {
+	case chunk := <-chunks:
+		first = &chunk
+	case <-done:
+		if execErr != nil && len(chunks) == 0 {
+			respondError(c, execErr)
+			return
+		}
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		if first != nil {
+			c.SSEvent("chunk", *first)
+			first = nil
+			return true
+		}
+
+		select {
+		case chunk := <-chunks:
+			c.SSEvent("chunk", chunk)
+			return true
+		case <-done:
+		}
+
+		// All chunks were queued before the step returned
+		for len(chunks) > 0 {
+			c.SSEvent("chunk", <-chunks)
+		}
+		if execErr != nil {
+			_, body := errorResponse(c.Request.Context(), execErr)
+			c.SSEvent("error", body)
+		} else {
+			c.SSEvent("result", response)
+		}
+		return false
+	})
+}
+
+// ExecuteSteps runs a batch of steps, reporting each step's result or error separately
+func (h *WorkflowHandler) ExecuteSteps(c *gin.Context) {
+	var req domain.BatchStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := h.workflowService.ExecuteSteps(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ClaimSteps leases queued external steps to the calling executor
+func (h *WorkflowHandler) ClaimSteps(c *gin.Context) {
+	var req domain.ClaimStepsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := h.workflowService.ClaimSteps(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CompleteStep records the artifact or error an external executor produced for a claimed step
+func (h *WorkflowHandler) CompleteStep(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid step ID")
+		return
+	}
+
+	var req domain.CompleteStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := h.workflowService.CompleteStep(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RenewStepLease extends an external executor's lease on a claimed step
+func (h *WorkflowHandler) RenewStepLease(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid step ID")
+		return
+	}
+
+	var req domain.RenewStepLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := h.workflowService.RenewStepLease(c.Request.Context(), id, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "lease renewed"})
+}
+
+// GetStep returns a step and its artifact, letting clients poll asynchronous executions
+func (h *WorkflowHandler) GetStep(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondValidationError(c, "invalid step ID")
+		return
+	}
+
+	response, err := h.workflowService.GetStep(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -131,7 +461,7 @@ func (h *WorkflowHandler) ExecuteStep(c *gin.Context) {
 func (h *WorkflowHandler) LookupStep(c *gin.Context) {
 	var req domain.WorkflowLookupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -141,7 +471,7 @@ func (h *WorkflowHandler) LookupStep(c *gin.Context) {
 
 	response, err := h.workflowService.LookupStep(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -152,25 +482,30 @@ func (h *WorkflowHandler) LookupStep(c *gin.Context) {
 func (h *WorkflowHandler) QuickStepLookup(c *gin.Context) {
 	sessionIDStr := c.Query("session_id")
 	if sessionIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id parameter is required"})
+		respondValidationError(c, "session_id parameter is required")
 		return
 	}
 
 	sessionID, err := uuid.Parse(sessionIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+		respondValidationError(c, "invalid session ID")
 		return
 	}
 
 	stepType := c.Query("step_type")
-	if stepType == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "step_type parameter is required"})
+	var stepTypes []string
+	if types := c.Query("step_types"); types != "" {
+		stepTypes = strings.Split(types, ",")
+	}
+	allStepTypes := c.Query("all_step_types") == "true"
+	if stepType == "" && len(stepTypes) == 0 && !allStepTypes {
+		respondValidationError(c, "step_type, step_types, or all_step_types parameter is required")
 		return
 	}
 
 	input := c.Query("input")
 	if input == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "input parameter is required"})
+		respondValidationError(c, "input parameter is required")
 		return
 	}
 
@@ -181,18 +516,87 @@ func (h *WorkflowHandler) QuickStepLookup(c *gin.Context) {
 		}
 	}
 
+	var minScore *float32
+	if minScoreStr := c.Query("min_score"); minScoreStr != "" {
+		if score, err := strconv.ParseFloat(minScoreStr, 32); err == nil {
+			minScore = new(float32)
+			*minScore = float32(score)
+		}
+	}
+
 	req := domain.WorkflowLookupRequest{
-		SessionID: sessionID,
-		StepType:  stepType,
-		Input:     input,
-		TopK:      topK,
+		SessionID:    sessionID,
+		StepType:     stepType,
+		Input:        input,
+		TopK:         topK,
+		StepTypes:    stepTypes,
+		AllStepTypes: allStepTypes,
+		MinScore:     minScore,
 	}
 
 	response, err := h.workflowService.LookupStep(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+// PublishTemplate stores a new version of a workflow template
+func (h *WorkflowHandler) PublishTemplate(c *gin.Context) {
+	var req domain.PublishTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	response, err := h.workflowService.PublishTemplate(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *WorkflowHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.workflowService.ListTemplates(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// GetTemplate returns the latest version of a template, or the one given by ?version=
+func (h *WorkflowHandler) GetTemplate(c *gin.Context) {
+	version := 0
+	if v := c.Query("version"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			respondValidationError(c, "invalid version")
+			return
+		}
+		version = parsed
+	}
+
+	template, err := h.workflowService.GetTemplate(c.Request.Context(), c.Param("name"), version)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *WorkflowHandler) ListTemplateVersions(c *gin.Context) {
+	templates, err := h.workflowService.ListTemplateVersions(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": templates})
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const namespaceHeader = "X-Mentis-Namespace"
+
+// AuthMiddleware resolves the calling principal and stores it on the request context.
+// Static keys from config are unrestricted within their namespace; keys created
+// through the API carry their own namespace, operation, and artifact type scopes.
+// With authentication disabled the namespace header is trusted as-is.
+func AuthMiddleware(cfg config.AuthConfig, apiKeyService ports.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Already authenticated, e.g. by a signed URL
+		if domain.PrincipalFromContext(c.Request.Context()) != nil {
+			c.Next()
+			return
+		}
+
+		requested := c.GetHeader(namespaceHeader)
+
+		if !cfg.Required && len(cfg.APIKeys) == 0 {
+			setPrincipal(c, &domain.Principal{Namespace: requested})
+			return
+		}
+
+		key := extractAPIKey(c)
+		if key == "" {
+			abortWithError(c, http.StatusUnauthorized, domain.CodeUnauthorized, "missing API key")
+			return
+		}
+
+		var principal *domain.Principal
+		if namespace, ok := lookupAPIKey(cfg.APIKeys, key); ok {
+			principal = &domain.Principal{
+				KeyID:      keyID(key),
+				Namespaces: []string{namespace},
+			}
+		} else {
+			apiKey, err := apiKeyService.Authenticate(c.Request.Context(), key)
+			if err != nil {
+				logrus.WithError(err).Error("Failed to authenticate API key")
+				abortWithError(c, http.StatusServiceUnavailable, domain.CodeUpstreamUnavailable, "authentication unavailable")
+				return
+			}
+			if apiKey == nil {
+				abortWithError(c, http.StatusUnauthorized, domain.CodeUnauthorized, "invalid API key")
+				return
+			}
+			principal = &domain.Principal{
+				KeyID:         apiKey.ID.String(),
+				Namespaces:    apiKey.Namespaces,
+				ArtifactTypes: apiKey.ArtifactTypes,
+				Operations:    apiKey.Operations,
+			}
+		}
+
+		// Default to the key's first namespace unless the caller picked one
+		if requested == "" && len(principal.Namespaces) > 0 && principal.Namespaces[0] != domain.AllNamespaces {
+			requested = principal.Namespaces[0]
+		}
+		if requested == "" {
+			requested = domain.DefaultNamespace
+		}
+		if !principal.AllowsNamespace(requested) {
+			abortWithError(c, http.StatusForbidden, domain.CodeForbidden, "API key is not valid for namespace "+requested)
+			return
+		}
+		principal.Namespace = requested
+
+		setPrincipal(c, principal)
+	}
+}
+
+// SignedURLMiddleware admits requests carrying a valid URL signature, granting
+// read access to exactly the signed path. Unsigned requests pass through to auth.
+func SignedURLMiddleware(signer ports.URLSigner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if query.Get("signature") == "" {
+			c.Next()
+			return
+		}
+
+		if c.Request.Method != http.MethodGet {
+			abortWithError(c, http.StatusForbidden, domain.CodeForbidden, "signed URLs only permit GET")
+			return
+		}
+
+		namespace, err := signer.Verify(c.Request.URL.Path, query)
+		if err != nil {
+			abortWithError(c, http.StatusForbidden, domain.CodeForbidden, err.Error())
+			return
+		}
+
+		setPrincipal(c, &domain.Principal{
+			KeyID:      "signed-url",
+			Namespace:  namespace,
+			Namespaces: []string{namespace},
+			Operations: []domain.Operation{domain.OpRead},
+		})
+	}
+}
+
+// RequireOperation rejects requests whose principal was not granted op
+func RequireOperation(op domain.Operation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := domain.PrincipalFromContext(c.Request.Context())
+		if principal == nil || !principal.Allows(op) {
+			abortWithError(c, http.StatusForbidden, domain.CodeForbidden, "API key does not permit operation "+string(op))
+			return
+		}
+		c.Next()
+	}
+}
+
+func setPrincipal(c *gin.Context, principal *domain.Principal) {
+	if principal.Namespace == "" {
+		principal.Namespace = domain.DefaultNamespace
+	}
+
+	c.Set("principal", principal)
+	c.Request = c.Request.WithContext(domain.WithPrincipal(c.Request.Context(), principal))
+	c.Next()
+}
+
+// extractAPIKey reads the key from a bearer token or the X-API-Key header
+func extractAPIKey(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return c.GetHeader("X-API-Key")
+}
+
+// lookupAPIKey compares keys in constant time to avoid leaking key prefixes
+func lookupAPIKey(keys map[string]string, key string) (string, bool) {
+	for candidate, namespace := range keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			return namespace, true
+		}
+	}
+	return "", false
+}
+
+// keyID returns a non-reversible identifier for a key, safe to log
+func keyID(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+func abortWithError(c *gin.Context, status int, code domain.ErrorCode, message string) {
+	c.AbortWithStatusJSON(status, gin.H{"code": code, "message": message})
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitMiddleware caps request bodies at maxBytes, or at the limit in
+// routeLimits for the matched route path. Reads past the cap fail with an
+// *http.MaxBytesError, which handlers answer with 413. A limit of zero
+// leaves bodies uncapped
+func BodyLimitMiddleware(maxBytes int64, routeLimits map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := maxBytes
+		if routeLimit, ok := routeLimits[c.FullPath()]; ok {
+			limit = routeLimit
+		}
+		if limit > 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			if c.Request.ContentLength > limit {
+				abortWithError(c, http.StatusRequestEntityTooLarge, domain.CodePayloadTooLarge, "request body too large")
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
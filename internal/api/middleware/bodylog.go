@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// defaultRedactFields are JSON fields that always carry credentials, such as
+// the plaintext key in an API key creation response
+var defaultRedactFields = []string{
+	"key", "api_key", "authorization", "password", "secret", "token", "access_token", "refresh_token",
+}
+
+// redactHeaders are request headers whose values are never logged
+var redactHeaders = []string{"Authorization", "X-API-Key", "Cookie", "Proxy-Authorization"}
+
+// BodyLoggingMiddleware logs a sample of request and response bodies. Bodies are
+// capped at cfg.MaxBytes; credential headers and configured JSON fields are
+// masked at any depth before logging. Bodies cut off by the cap are no longer
+// valid JSON, so they are replaced with a marker
+func BodyLoggingMiddleware(cfg config.BodyLogConfig) gin.HandlerFunc {
+	fields := make(map[string]struct{}, len(defaultRedactFields)+len(cfg.RedactFields))
+	for _, field := range append(defaultRedactFields, cfg.RedactFields...) {
+		fields[strings.ToLower(field)] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate {
+			c.Next()
+			return
+		}
+
+		var request []byte
+		requestTruncated := false
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			head, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(cfg.MaxBytes)+1))
+			if err != nil {
+				logrus.WithError(err).Debug("Failed to read request body for logging")
+			}
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(head), c.Request.Body), c.Request.Body}
+			request, requestTruncated = capBody(head, cfg.MaxBytes)
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, limit: cfg.MaxBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		response, responseTruncated := capBody(writer.body.Bytes(), cfg.MaxBytes)
+		if writer.overflow {
+			responseTruncated = true
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"request_id":      c.GetString("request_id"),
+			"method":          c.Request.Method,
+			"path":            c.Request.URL.Path,
+			"status":          writer.Status(),
+			"request_headers": redactedHeaders(c.Request.Header),
+			"request_body":    redactBody(request, requestTruncated, fields),
+			"response_body":   redactBody(response, responseTruncated, fields),
+		}).Info("HTTP Body")
+	}
+}
+
+// bodyCaptureWriter keeps the first limit bytes of the response while passing
+// everything through, so streamed responses still flush as they are written
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyCaptureWriter) capture(b []byte) {
+	if room := w.limit - w.body.Len(); room < len(b) {
+		w.overflow = true
+		if room > 0 {
+			w.body.Write(b[:room])
+		}
+		return
+	}
+	w.body.Write(b)
+}
+
+func capBody(body []byte, limit int) ([]byte, bool) {
+	if len(body) > limit {
+		return body[:limit], true
+	}
+	return body, false
+}
+
+func redactedHeaders(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		out[name] = strings.Join(values, ", ")
+	}
+	for _, name := range redactHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(name)]; ok {
+			out[http.CanonicalHeaderKey(name)] = redacted
+		}
+	}
+	return out
+}
+
+// redactBody masks the configured fields in a JSON body. Anything that cannot be
+// parsed is dropped rather than logged, since secrets in it cannot be found
+func redactBody(body []byte, truncated bool, fields map[string]struct{}) any {
+	if len(body) == 0 {
+		return nil
+	}
+	if truncated {
+		return "[truncated]"
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "[non-JSON body omitted]"
+	}
+	return redactValue(value, fields)
+}
+
+func redactValue(value any, fields map[string]struct{}) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if _, ok := fields[strings.ToLower(key)]; ok {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(item, fields)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item, fields)
+		}
+	}
+	return value
+}
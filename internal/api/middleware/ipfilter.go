@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// IPFilter matches client addresses against CIDR allow and deny lists.
+// Deny entries win; an empty allow list admits every address not denied.
+type IPFilter struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+func NewIPFilter(allow, deny []string) (*IPFilter, error) {
+	allowNets, err := parseCIDRs(allow)
+	if err != nil {
+		return nil, err
+	}
+	denyNets, err := parseCIDRs(deny)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IPFilter{allow: allowNets, deny: denyNets}, nil
+}
+
+// Empty reports whether the filter has no rules and admits everything
+func (f *IPFilter) Empty() bool {
+	return len(f.allow) == 0 && len(f.deny) == 0
+}
+
+func (f *IPFilter) Allowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range f.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, n := range f.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPFilterMiddleware rejects requests from addresses the filter does not admit
+func IPFilterMiddleware(filter *IPFilter) gin.HandlerFunc {
+	return PathIPFilterMiddleware(nil, filter)
+}
+
+// PathIPFilterMiddleware applies the filter only to requests under one of the
+// given path prefixes; with no prefixes it applies to every request
+func PathIPFilterMiddleware(prefixes []string, filter *IPFilter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(prefixes) > 0 && !hasAnyPrefix(c.Request.URL.Path, prefixes) {
+			c.Next()
+			return
+		}
+
+		if !filter.Allowed(net.ParseIP(c.ClientIP())) {
+			logrus.WithFields(logrus.Fields{
+				"client_ip": c.ClientIP(),
+				"path":      c.Request.URL.Path,
+			}).Warn("Request blocked by IP filter")
+			abortWithError(c, http.StatusForbidden, domain.CodeForbidden, "client address not permitted")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCIDRs accepts CIDR blocks or bare addresses, which match a single host
+func parseCIDRs(entries []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %s", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %w", entry, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"time"
 
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -26,7 +28,7 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-API-Key, X-Mentis-Namespace")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -43,7 +45,7 @@ func ErrorHandlingMiddleware() gin.HandlerFunc {
 		defer func() {
 			if err := recover(); err != nil {
 				logrus.WithField("error", err).Error("Panic recovered")
-				c.JSON(500, gin.H{"error": "Internal server error"})
+				c.JSON(500, gin.H{"code": domain.CodeInternal, "message": "internal server error"})
 				c.Abort()
 			}
 		}()
@@ -58,6 +60,9 @@ func RequestIDMiddleware() gin.HandlerFunc {
 			requestID = generateRequestID()
 		}
 		c.Set("request_id", requestID)
+		ctx := domain.WithRequestID(c.Request.Context(), requestID)
+		ctx = logging.WithLogger(ctx, logrus.WithField("request_id", requestID))
+		c.Request = c.Request.WithContext(ctx)
 		c.Writer.Header().Set("X-Request-ID", requestID)
 		c.Next()
 	}
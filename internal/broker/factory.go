@@ -0,0 +1,47 @@
+package broker
+
+import (
+	"fmt"
+
+	"github.com/anunay/mentis/internal/broker/kafka"
+	"github.com/anunay/mentis/internal/broker/nats"
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/ports"
+)
+
+// Provider represents the message broker events are streamed to
+type Provider string
+
+const (
+	ProviderNone  Provider = ""
+	ProviderNATS  Provider = "nats"
+	ProviderKafka Provider = "kafka"
+)
+
+// NewEventBroker creates a broker for the configured provider, or nil when event streaming is disabled
+func NewEventBroker(cfg *config.EventsConfig) (ports.EventBroker, error) {
+	switch Provider(cfg.Broker) {
+	case ProviderNone:
+		return nil, nil
+	case ProviderNATS:
+		return nats.NewPublisher(*cfg)
+	case ProviderKafka:
+		return kafka.NewPublisher(*cfg)
+	default:
+		return nil, fmt.Errorf("unsupported event broker: %s", cfg.Broker)
+	}
+}
+
+// NewEventSource creates a consumer for the configured provider, or nil when no broker is consumed
+func NewEventSource(cfg *config.SourceEventsConfig) (ports.EventSource, error) {
+	switch Provider(cfg.Broker) {
+	case ProviderNone:
+		return nil, nil
+	case ProviderNATS:
+		return nats.NewSubscriber(*cfg)
+	case ProviderKafka:
+		return kafka.NewConsumer(*cfg)
+	default:
+		return nil, fmt.Errorf("unsupported event broker: %s", cfg.Broker)
+	}
+}
@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// pollTimeout is how long the proxy waits for records before answering a poll
+	pollTimeout = 5 * time.Second
+	// cleanupTimeout bounds removing the consumer instance once consuming stops
+	cleanupTimeout = 5 * time.Second
+)
+
+// Consumer consumes records through a REST proxy speaking the Confluent v2
+// API. Replicas join one consumer group, so each record is handled by one of
+// them; offsets are committed automatically as records are fetched
+type Consumer struct {
+	baseURL  *url.URL
+	group    string
+	user     string
+	password string
+	client   *http.Client
+}
+
+type consumerInstance struct {
+	InstanceID string `json:"instance_id"`
+	BaseURI    string `json:"base_uri"`
+}
+
+type consumedRecord struct {
+	Topic string          `json:"topic"`
+	Value json.RawMessage `json:"value"`
+}
+
+func NewConsumer(cfg config.SourceEventsConfig) (*Consumer, error) {
+	parsed, err := url.Parse(cfg.URL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid Kafka REST proxy URL: %s", cfg.URL)
+	}
+
+	password, _ := parsed.User.Password()
+	user := parsed.User.Username()
+	parsed.User = nil
+
+	return &Consumer{
+		baseURL:  parsed,
+		group:    cfg.Group,
+		user:     user,
+		password: password,
+		// Polls are held open by the proxy for up to pollTimeout
+		client: &http.Client{Timeout: cfg.Timeout + pollTimeout},
+	}, nil
+}
+
+// Consume subscribes a new consumer instance to topic and calls handle with
+// each record value until ctx ends, which returns nil, or the proxy fails
+func (c *Consumer) Consume(ctx context.Context, topic string, handle func(payload []byte)) error {
+	var instance consumerInstance
+	err := c.do(ctx, http.MethodPost, c.baseURL.JoinPath("consumers", c.group).String(), map[string]string{
+		"format":             "json",
+		"auto.offset.reset":  "latest",
+		"auto.commit.enable": "true",
+	}, &instance)
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka consumer: %w", err)
+	}
+	defer c.remove(instance)
+
+	if err := c.do(ctx, http.MethodPost, instance.BaseURI+"/subscription", map[string][]string{"topics": {topic}}, nil); err != nil {
+		return fmt.Errorf("failed to subscribe to Kafka topic %s: %w", topic, err)
+	}
+
+	recordsURL := fmt.Sprintf("%s/records?timeout=%d", instance.BaseURI, pollTimeout.Milliseconds())
+	for ctx.Err() == nil {
+		var records []consumedRecord
+		if err := c.do(ctx, http.MethodGet, recordsURL, nil, &records); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to poll Kafka records: %w", err)
+		}
+		for _, record := range records {
+			handle(record.Value)
+		}
+	}
+	return nil
+}
+
+// remove deletes the consumer instance, so the group rebalances right away
+// rather than once the proxy times it out
+func (c *Consumer) remove(instance consumerInstance) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	if err := c.do(ctx, http.MethodDelete, instance.BaseURI, nil, nil); err != nil {
+		logrus.WithError(err).WithField("instance_id", instance.InstanceID).Warn("Failed to remove Kafka consumer instance")
+	}
+}
+
+// do sends a v2 API request with an optional JSON body and decodes a JSON
+// response into out, when given
+func (c *Consumer) do(ctx context.Context, method, endpoint string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/vnd.kafka.v2+json")
+	}
+	req.Header.Set("Accept", "application/vnd.kafka.json.v2+json, application/vnd.kafka.v2+json")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Kafka REST proxy returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/anunay/mentis/internal/config"
+)
+
+// contentType is the v2 embedded JSON format of the Confluent REST proxy API
+const contentType = "application/vnd.kafka.json.v2+json"
+
+// Publisher produces records to Kafka through a REST proxy speaking the
+// Confluent v2 API, so no Kafka client or broker connections are needed
+type Publisher struct {
+	baseURL  *url.URL
+	user     string
+	password string
+	client   *http.Client
+}
+
+type produceRequest struct {
+	Records []record `json:"records"`
+}
+
+type record struct {
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+type produceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func NewPublisher(cfg config.EventsConfig) (*Publisher, error) {
+	parsed, err := url.Parse(cfg.URL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid Kafka REST proxy URL: %s", cfg.URL)
+	}
+
+	password, _ := parsed.User.Password()
+	user := parsed.User.Username()
+	parsed.User = nil
+
+	return &Publisher{
+		baseURL:  parsed,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Publish produces payload, which must be JSON, as the value of one record
+func (p *Publisher) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	body, err := json.Marshal(produceRequest{Records: []record{{Key: key, Value: payload}}})
+	if err != nil {
+		return fmt.Errorf("failed to encode Kafka record: %w", err)
+	}
+
+	endpoint := p.baseURL.JoinPath("topics", topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if p.user != "" {
+		req.SetBasicAuth(p.user, p.password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Kafka REST proxy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Kafka REST proxy returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	// The proxy answers 200 even when a record failed; failures are per offset
+	var produced produceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("failed to decode Kafka REST proxy response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("Kafka rejected the record: %s", offset.Error)
+		}
+	}
+	return nil
+}
+
+// Close releases idle connections to the proxy
+func (p *Publisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
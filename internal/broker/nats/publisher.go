@@ -0,0 +1,106 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Publisher publishes messages over the NATS client protocol. It connects on
+// the first publish and reconnects on the next one after the connection drops;
+// publishes are fire-and-forget, as in core NATS
+type Publisher struct {
+	server server
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewPublisher(cfg config.EventsConfig) (*Publisher, error) {
+	server, err := parseServer(cfg.URL, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{server: server}, nil
+}
+
+func (p *Publisher) Publish(ctx context.Context, subject, _ string, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		conn, reader, err := p.server.connect(ctx)
+		if err != nil {
+			return err
+		}
+		p.conn = conn
+		go p.read(conn, reader)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(p.server.timeout)
+	}
+	message := fmt.Sprintf("PUB %s %d\r\n%s\r\n", subject, len(payload), payload)
+	if err := write(p.conn, []byte(message), deadline); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+// read answers server PINGs, which keep the connection alive, and drops the
+// connection when the server reports an error or closes it
+func (p *Publisher) read(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			p.drop(conn)
+			return
+		}
+
+		switch line = strings.TrimSpace(line); {
+		case line == "PING":
+			p.mu.Lock()
+			err = write(conn, []byte("PONG\r\n"), time.Now().Add(p.server.timeout))
+			p.mu.Unlock()
+			if err != nil {
+				p.drop(conn)
+				return
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			logrus.WithField("error", line).Warn("NATS server reported an error")
+		}
+	}
+}
+
+// drop forgets conn, if still current, so the next publish reconnects
+func (p *Publisher) drop(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conn.Close()
+	if p.conn == conn {
+		p.conn = nil
+	}
+}
@@ -0,0 +1,108 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// server is a NATS server to connect to, as given by a nats:// or tls:// URL
+// with optional credentials
+type server struct {
+	address  string
+	useTLS   bool
+	user     string
+	password string
+	// timeout bounds connecting and writes not bounded by a context deadline
+	timeout time.Duration
+}
+
+// connectOptions is the CONNECT message sent after the server's INFO
+type connectOptions struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+}
+
+func parseServer(rawURL string, timeout time.Duration) (server, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "nats" && parsed.Scheme != "tls") {
+		return server{}, fmt.Errorf("invalid NATS URL: %s", rawURL)
+	}
+
+	address := parsed.Host
+	if parsed.Port() == "" {
+		address = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+	password, _ := parsed.User.Password()
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return server{
+		address:  address,
+		useTLS:   parsed.Scheme == "tls",
+		user:     parsed.User.Username(),
+		password: password,
+		timeout:  timeout,
+	}, nil
+}
+
+// connect dials the server, reads its INFO, and sends CONNECT, confirming the
+// server accepted it with a PING round trip. The reader holds what the server
+// sends next
+func (s server) connect(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	dialer := &net.Dialer{Timeout: s.timeout}
+	var conn net.Conn
+	var err error
+	if s.useTLS {
+		host, _, _ := net.SplitHostPort(s.address)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", s.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.address)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	conn.SetDeadline(time.Now().Add(s.timeout))
+	reader := bufio.NewReader(conn)
+	if line, err := reader.ReadString('\n'); err != nil || !strings.HasPrefix(line, "INFO") {
+		conn.Close()
+		return nil, nil, fmt.Errorf("NATS server did not send INFO: %v", err)
+	}
+
+	options, _ := json.Marshal(connectOptions{Name: "mentis", Lang: "go", User: s.user, Pass: s.password})
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", options); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to send NATS CONNECT: %w", err)
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "PONG" {
+		conn.Close()
+		return nil, nil, fmt.Errorf("NATS server rejected the connection: %s", strings.TrimSpace(line))
+	}
+	conn.SetDeadline(time.Time{})
+
+	return conn, reader, nil
+}
+
+// write sends data, giving up at deadline
+func write(conn net.Conn, data []byte, deadline time.Time) error {
+	conn.SetWriteDeadline(deadline)
+	defer conn.SetWriteDeadline(time.Time{})
+
+	_, err := conn.Write(data)
+	return err
+}
@@ -0,0 +1,77 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+)
+
+// Subscriber consumes messages over the NATS client protocol. Replicas
+// subscribe in one queue group, so each message is handled by one of them
+type Subscriber struct {
+	server server
+	group  string
+}
+
+func NewSubscriber(cfg config.SourceEventsConfig) (*Subscriber, error) {
+	server, err := parseServer(cfg.URL, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Subscriber{server: server, group: cfg.Group}, nil
+}
+
+// Consume subscribes to subject and calls handle with each message payload
+// until ctx ends, which returns nil, or the connection fails
+func (s *Subscriber) Consume(ctx context.Context, subject string, handle func(payload []byte)) error {
+	conn, reader, err := s.server.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Closing the connection unblocks the read below
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	subscribe := fmt.Sprintf("SUB %s %s 1\r\n", subject, s.group)
+	if err := write(conn, []byte(subscribe), time.Now().Add(s.server.timeout)); err != nil {
+		return fmt.Errorf("failed to subscribe to NATS subject %s: %w", subject, err)
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("NATS connection lost: %w", err)
+		}
+
+		switch line = strings.TrimSpace(line); {
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <size>
+			fields := strings.Fields(line)
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || size < 0 {
+				return fmt.Errorf("malformed NATS message header: %s", line)
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return fmt.Errorf("NATS connection lost: %w", err)
+			}
+			handle(payload[:size])
+		case line == "PING":
+			if err := write(conn, []byte("PONG\r\n"), time.Now().Add(s.server.timeout)); err != nil {
+				return fmt.Errorf("NATS connection lost: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS server reported an error: %s", line)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// ArtifactTypeStats counts artifacts of one type by staleness
+type ArtifactTypeStats struct {
+	Type  ArtifactType `json:"type"`
+	Fresh int64        `json:"fresh"`
+	Stale int64        `json:"stale"`
+	// Deleted artifacts are soft-deleted and still restorable
+	Deleted int64 `json:"deleted"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// StepTypeStats counts the steps of one type by status
+type StepTypeStats struct {
+	StepType string               `json:"step_type"`
+	Total    int64                `json:"total"`
+	ByStatus map[StepStatus]int64 `json:"by_status"`
+}
+
+// StorageStats splits artifact content bytes between Postgres and the blob store
+type StorageStats struct {
+	TotalBytes  int64 `json:"total_bytes"`
+	InlineBytes int64 `json:"inline_bytes"`
+	BlobBytes   int64 `json:"blob_bytes"`
+}
+
+// AdminStats summarizes cache and workflow contents for dashboards
+type AdminStats struct {
+	// Namespace is empty when the stats cover every namespace
+	Namespace     string                  `json:"namespace,omitempty"`
+	Artifacts     []ArtifactTypeStats     `json:"artifacts"`
+	Sessions      map[SessionStatus]int64 `json:"sessions"`
+	StepsByStatus map[StepStatus]int64    `json:"steps_by_status"`
+	StepsByType   []StepTypeStats         `json:"steps_by_type"`
+	Storage       StorageStats            `json:"storage"`
+	VectorPoints  int64                   `json:"vector_points"`
+	GeneratedAt   time.Time               `json:"generated_at"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+type AlertKind string
+
+const (
+	AlertSessionFailed       AlertKind = "session_failed"
+	AlertStepFailures        AlertKind = "step_failures"
+	AlertDependencyDown      AlertKind = "dependency_down"
+	AlertDependencyRecovered AlertKind = "dependency_recovered"
+)
+
+// Alert tells operators about a failure, or the end of one, through the
+// configured Slack webhook and email recipients
+type Alert struct {
+	Kind    AlertKind
+	Title   string
+	Message string
+	// Fields are listed in order below the message
+	Fields    []AlertField
+	CreatedAt time.Time
+}
+
+type AlertField struct {
+	Name  string
+	Value string
+}
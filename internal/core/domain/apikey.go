@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operation is a capability an API key can be granted
+type Operation string
+
+const (
+	OpLookup        Operation = "lookup"
+	OpRead          Operation = "read"
+	OpPublish       Operation = "publish"
+	OpDelete        Operation = "delete"
+	OpInvalidate    Operation = "invalidate"
+	OpWorkflowRead  Operation = "workflow:read"
+	OpWorkflowWrite Operation = "workflow:write"
+	OpAdmin         Operation = "admin"
+)
+
+// AllNamespaces grants a key access to every namespace
+const AllNamespaces = "*"
+
+var validOperations = map[Operation]bool{
+	OpLookup:        true,
+	OpRead:          true,
+	OpPublish:       true,
+	OpDelete:        true,
+	OpInvalidate:    true,
+	OpWorkflowRead:  true,
+	OpWorkflowWrite: true,
+	OpAdmin:         true,
+}
+
+// IsValidOperation reports whether op is a known operation
+func IsValidOperation(op Operation) bool {
+	return validOperations[op]
+}
+
+type APIKey struct {
+	ID            uuid.UUID      `json:"id"`
+	Name          string         `json:"name"`
+	Prefix        string         `json:"prefix"`
+	KeyHash       string         `json:"-"`
+	Namespaces    []string       `json:"namespaces"`
+	ArtifactTypes []ArtifactType `json:"artifact_types"`
+	Operations    []Operation    `json:"operations"`
+	CreatedAt     time.Time      `json:"created_at"`
+	ExpiresAt     *time.Time     `json:"expires_at,omitempty"`
+	RevokedAt     *time.Time     `json:"revoked_at,omitempty"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string         `json:"name" binding:"required"`
+	Namespaces    []string       `json:"namespaces" binding:"required"`
+	ArtifactTypes []ArtifactType `json:"artifact_types"`
+	Operations    []Operation    `json:"operations" binding:"required"`
+	ExpiresAt     *time.Time     `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse carries the plaintext key, which is only ever returned once
+type CreateAPIKeyResponse struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"api_key"`
+}
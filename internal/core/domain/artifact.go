@@ -17,30 +17,165 @@ const (
 
 type Artifact struct {
 	ID           uuid.UUID              `json:"id"`
+	Namespace    string                 `json:"namespace"`
 	Type         ArtifactType           `json:"type"`
 	ContentHash  string                 `json:"content_hash"`
 	Content      []byte                 `json:"content"`
+	// ContentRef is the object storage key of content offloaded from Postgres; Content is then empty
+	ContentRef  string `json:"content_ref,omitempty"`
+	ContentSize int64  `json:"content_size"`
 	Embedding    []float32              `json:"embedding,omitempty"`
 	Dependencies []uuid.UUID            `json:"dependencies"`
 	Metadata     map[string]interface{} `json:"metadata"`
+	Tags         []string               `json:"tags"`
 	CreatedAt    time.Time              `json:"created_at"`
 	UpdatedAt    time.Time              `json:"updated_at"`
 	Stale        bool                   `json:"stale"`
+	// Version counts republications of the artifact under the same identity
+	Version int `json:"version"`
+	// ExpiresAt is when the artifact is marked stale and its vector removed
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Pinned artifacts are exempt from storage eviction
+	Pinned         bool       `json:"pinned"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+	// DeletedAt is set while the artifact is deleted but still restorable
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Indexed records that the artifact should have a vector, so a missing one is repaired
+	Indexed bool `json:"indexed"`
+	// HitCount counts the artifact being returned from lookups, reads, and step cache hits
+	HitCount int64 `json:"hit_count"`
+	// UpsertKey names a metadata field identifying the artifact; publishing replaces
+	// the content of the artifact with the same value as a new version
+	UpsertKey string `json:"upsert_key,omitempty"`
+	// Chunk asks Publish to split the content into separately embedded chunk artifacts
+	Chunk bool `json:"chunk,omitempty"`
+}
+
+// ArtifactUsage summarizes how often an artifact has been reused
+type ArtifactUsage struct {
+	ID             uuid.UUID    `json:"id"`
+	Type           ArtifactType `json:"type"`
+	SourceURL      string       `json:"source_url,omitempty"`
+	HitCount       int64        `json:"hit_count"`
+	LastAccessedAt time.Time    `json:"last_accessed_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// ArtifactVersion is an archived revision of an artifact replaced by a republish
+type ArtifactVersion struct {
+	ArtifactID   uuid.UUID              `json:"artifact_id"`
+	Version      int                    `json:"version"`
+	Namespace    string                 `json:"namespace"`
+	Type         ArtifactType           `json:"type"`
+	ContentHash  string                 `json:"content_hash"`
+	Content      []byte                 `json:"content,omitempty"`
+	ContentRef   string                 `json:"content_ref,omitempty"`
+	ContentSize  int64                  `json:"content_size"`
+	Metadata     map[string]interface{} `json:"metadata"`
+	PublishedAt  time.Time              `json:"published_at"`
+	SupersededAt time.Time              `json:"superseded_at"`
+}
+
+type ListArtifactVersionsResponse struct {
+	Versions   []*ArtifactVersion `json:"versions"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
 type LookupResult struct {
 	Artifact *Artifact `json:"artifact"`
-	Score    float32   `json:"score"`
+	// Score is the vector similarity, or the fused rank score in hybrid mode
+	Score float32 `json:"score"`
+	// VectorScore and TextScore are the scores hybrid lookups fused, when the artifact had one
+	VectorScore float32 `json:"vector_score,omitempty"`
+	TextScore   float32 `json:"text_score,omitempty"`
+	// Chunks lists the artifact's chunks that matched, best first
+	Chunks []ChunkMatch `json:"chunks,omitempty"`
+	// GroupCount counts the matched artifacts sharing this result's group_by value
+	GroupCount int `json:"group_count,omitempty"`
+	// Explanation breaks down the ranking when the lookup asked for it
+	Explanation *ScoreExplanation `json:"explanation,omitempty"`
+	// ContentOmitted is set when the artifact's content was left out to keep
+	// the response under its size limit
+	ContentOmitted bool `json:"content_omitted,omitempty"`
+}
+
+// ScoreExplanation lists the signals behind a lookup result's rank. Ranks are
+// 1-based positions among all matches of that kind, before paging
+type ScoreExplanation struct {
+	Mode        LookupMode `json:"mode"`
+	VectorScore float32    `json:"vector_score,omitempty"`
+	VectorRank  int        `json:"vector_rank,omitempty"`
+	TextScore   float32    `json:"text_score,omitempty"`
+	TextRank    int        `json:"text_rank,omitempty"`
+	// FusedScore is the reciprocal rank fusion of the vector and text ranks
+	FusedScore float32 `json:"fused_score,omitempty"`
+	// MatchedQuery is the phrasing whose embedding scored best
+	MatchedQuery   string            `json:"matched_query"`
+	MatchedChunks  int               `json:"matched_chunks,omitempty"`
+	MatchedTags    []string          `json:"matched_tags,omitempty"`
+	MatchedFilters []FilterCondition `json:"matched_filters,omitempty"`
 }
 
 type LookupOptions struct {
 	Query           string       `json:"query"`
 	TopK            int          `json:"top_k"`
-	MinScore        float32      `json:"min_score"`
+	// MinScore falls back to the server default when unset; 0 disables the threshold
+	MinScore        *float32     `json:"min_score,omitempty"`
 	ArtifactType    ArtifactType `json:"artifact_type,omitempty"`
 	IncludeStale    bool         `json:"include_stale"`
 	IncludeContent  bool         `json:"include_content"`
 	IncludeEmbedding bool        `json:"include_embedding"`
+	Cursor          string       `json:"cursor,omitempty"`
+	// Tags matches artifacts carrying any of the tags
+	Tags []string `json:"tags,omitempty"`
+	// Namespaces searches these namespaces instead of the caller's own
+	Namespaces []string `json:"namespaces,omitempty"`
+	// SkipNegativeCache searches even if the same lookup recently found nothing
+	SkipNegativeCache bool `json:"skip_negative_cache,omitempty"`
+	// Mode selects vector similarity alone (the default) or hybrid full-text and vector search
+	Mode LookupMode `json:"mode,omitempty"`
+	// Filter restricts results to artifacts whose metadata satisfies every condition
+	Filter Filter `json:"filter,omitempty"`
+	// GroupBy names a metadata field, such as source_url; only the best result
+	// for each of its values is returned
+	GroupBy string `json:"group_by,omitempty"`
+	// Queries are further phrasings searched alongside Query, keeping each
+	// artifact's best score
+	Queries []string `json:"queries,omitempty"`
+	// Expand also searches paraphrases of Query generated by the configured model
+	Expand bool `json:"expand,omitempty"`
+	// Explain adds a breakdown of each result's score
+	Explain bool `json:"explain,omitempty"`
+}
+
+// DefaultMinScore is the similarity step lookups require when min_score is unset
+const DefaultMinScore float32 = 0.85
+
+// MaxLookupQueries caps the phrasings one lookup searches, including Query
+const MaxLookupQueries = 8
+
+type LookupMode string
+
+const (
+	LookupVector LookupMode = "vector"
+	// LookupHybrid fuses full-text keyword matches with vector similarity
+	LookupHybrid LookupMode = "hybrid"
+)
+
+// TextMatch is an artifact whose content matched a full-text query
+type TextMatch struct {
+	ID        uuid.UUID
+	Namespace string
+	Rank      float32
+}
+
+// TextSearchFilter restricts a full-text search like a lookup's vector filter
+type TextSearchFilter struct {
+	Namespaces   []string
+	ArtifactType ArtifactType
+	Tags         []string
+	IncludeStale bool
+	Conditions   Filter
 }
 
 type PublishRequest struct {
@@ -50,6 +185,65 @@ type PublishRequest struct {
 type PublishResponse struct {
 	Published []uuid.UUID `json:"published"`
 	Skipped   []uuid.UUID `json:"skipped"`
+	// Replaced lists published artifacts that replaced an existing artifact's content
+	Replaced []uuid.UUID `json:"replaced"`
+	// Results reports the outcome of each object, in request order
+	Results []PublishResult `json:"results"`
+}
+
+type PublishStatus string
+
+const (
+	PublishPublished PublishStatus = "published"
+	// PublishSkipped objects matched content that is already cached
+	PublishSkipped PublishStatus = "skipped"
+	PublishFailed  PublishStatus = "failed"
+)
+
+// PublishResult is the outcome of publishing one object
+type PublishResult struct {
+	Index int `json:"index"`
+	// ID is the artifact the object was published or skipped as
+	ID     *uuid.UUID    `json:"id,omitempty"`
+	Status PublishStatus `json:"status"`
+	Error  *PublishError `json:"error,omitempty"`
+	// Warning notes a problem that did not stop the object being published
+	Warning string `json:"warning,omitempty"`
+}
+
+// PublishError is why an object failed to publish, in the shape of an error response
+type PublishError struct {
+	Code    ErrorCode              `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// PublishEventType is the kind of a line in a streaming load's response
+type PublishEventType string
+
+const (
+	// PublishEventResult reports an object that failed or was published with a warning
+	PublishEventResult PublishEventType = "result"
+	// PublishEventProgress acknowledges every object read so far
+	PublishEventProgress PublishEventType = "progress"
+	// PublishEventDone ends a load that read the whole body
+	PublishEventDone PublishEventType = "done"
+	// PublishEventError ends a load early, such as at a malformed line
+	PublishEventError PublishEventType = "error"
+)
+
+// PublishEvent is a line of a streaming load's response. Counts are running
+// totals; Acknowledged objects were all published, skipped or failed, so an
+// interrupted load may resume after that many
+type PublishEvent struct {
+	Type         PublishEventType `json:"type"`
+	Acknowledged int              `json:"acknowledged"`
+	Published    int              `json:"published"`
+	Skipped      int              `json:"skipped"`
+	Replaced     int              `json:"replaced"`
+	Failed       int              `json:"failed"`
+	Result       *PublishResult   `json:"result,omitempty"`
+	Error        *PublishError    `json:"error,omitempty"`
 }
 
 type LookupRequest struct {
@@ -57,5 +251,16 @@ type LookupRequest struct {
 }
 
 type LookupResponse struct {
-	Results []LookupResult `json:"results"`
+	Results    []LookupResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	// KnownMiss is set when the results come from a recent identical lookup that found nothing
+	KnownMiss *NegativeEntry `json:"known_miss,omitempty"`
+	// Degraded is set when the vector store was unavailable and results come
+	// from full-text search alone
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+type ListArtifactsResponse struct {
+	Artifacts  []*Artifact `json:"artifacts"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// CacheStatKind separates artifact lookups from workflow step reuse
+type CacheStatKind string
+
+const (
+	CacheStatLookup CacheStatKind = "lookup"
+	CacheStatStep   CacheStatKind = "step"
+)
+
+// CacheStatCount is a raw hit and miss count for one namespace, kind and step type
+type CacheStatCount struct {
+	Namespace string
+	Kind      CacheStatKind
+	StepType  string
+	Hits      int64
+	Misses    int64
+}
+
+// CacheHitRate summarizes hits and misses; for steps a miss is an execution
+type CacheHitRate struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+func (r *CacheHitRate) Add(hits, misses int64) {
+	r.Hits += hits
+	r.Misses += misses
+	if total := r.Hits + r.Misses; total > 0 {
+		r.HitRate = float64(r.Hits) / float64(total)
+	}
+}
+
+type CacheStats struct {
+	Lookups   CacheHitRate             `json:"lookups"`
+	Steps     CacheHitRate             `json:"steps"`
+	StepTypes map[string]*CacheHitRate `json:"step_types"`
+}
+
+type CacheStatsResponse struct {
+	Since time.Time `json:"since"`
+	CacheStats
+	// Namespaces breaks the totals down per namespace for admins
+	Namespaces map[string]*CacheStats `json:"namespaces,omitempty"`
+}
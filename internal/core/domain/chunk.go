@@ -0,0 +1,21 @@
+package domain
+
+import "github.com/google/uuid"
+
+// Metadata keys recorded on chunk artifacts
+const (
+	ChunkOfKey    = "chunk_of"
+	ChunkIndexKey = "chunk_index"
+	ChunkStartKey = "chunk_start"
+	ChunkEndKey   = "chunk_end"
+)
+
+// ChunkMatch is a chunk of a looked-up artifact that matched the query;
+// Start and End are byte offsets into the parent's content
+type ChunkMatch struct {
+	ArtifactID uuid.UUID `json:"artifact_id"`
+	Index      int       `json:"index"`
+	Start      int       `json:"start"`
+	End        int       `json:"end"`
+	Score      float32   `json:"score"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// CircuitState is the state of a circuit breaker guarding an upstream service
+type CircuitState string
+
+const (
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen fails calls without trying the upstream
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe call through to test recovery
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// EmbeddingProviderStats reports an embedding provider's health since startup
+type EmbeddingProviderStats struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Requests int64  `json:"requests"`
+	Failures int64  `json:"failures"`
+	// Rejected calls failed fast while the circuit was open
+	Rejected        int64        `json:"rejected"`
+	ErrorRate       float64      `json:"error_rate"`
+	AvgLatencyMS    float64      `json:"avg_latency_ms"`
+	MaxLatencyMS    float64      `json:"max_latency_ms"`
+	LastError       string       `json:"last_error,omitempty"`
+	LastErrorAt     *time.Time   `json:"last_error_at,omitempty"`
+	Circuit         CircuitState `json:"circuit"`
+	CircuitOpenedAt *time.Time   `json:"circuit_opened_at,omitempty"`
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrorCode is a stable, machine-readable error identifier returned to API clients
+type ErrorCode string
+
+const (
+	CodeUnauthorized        ErrorCode = "unauthorized"
+	CodeForbidden           ErrorCode = "forbidden"
+	CodeNotFound            ErrorCode = "not_found"
+	CodeConflict            ErrorCode = "conflict"
+	CodeQuotaExceeded       ErrorCode = "quota_exceeded"
+	CodeValidation          ErrorCode = "validation_failed"
+	CodePayloadTooLarge     ErrorCode = "payload_too_large"
+	CodeUpstreamUnavailable ErrorCode = "upstream_unavailable"
+	CodeTimeout             ErrorCode = "timeout"
+	CodeShuttingDown        ErrorCode = "shutting_down"
+	CodeInternal            ErrorCode = "internal"
+)
+
+// Error is a typed domain error carrying a code, a client-safe message and optional details
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details map[string]interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Sentinels for matching typed errors by kind with errors.Is, e.g.
+// errors.Is(err, ErrNotFound) holds for any not-found Error in err's chain
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// Is matches the sentinel for the error's code
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == CodeNotFound
+	case ErrConflict:
+		return e.Code == CodeConflict
+	case ErrValidation:
+		return e.Code == CodeValidation
+	}
+	return false
+}
+
+// WithDetail returns the error with an additional detail field set
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+func NewNotFoundError(resource string, id interface{}) *Error {
+	return &Error{
+		Code:    CodeNotFound,
+		Message: fmt.Sprintf("%s not found", resource),
+		Details: map[string]interface{}{"resource": resource, "id": fmt.Sprint(id)},
+	}
+}
+
+func NewConflictError(message string, err error) *Error {
+	return &Error{Code: CodeConflict, Message: message, Err: err}
+}
+
+func NewValidationError(message string) *Error {
+	return &Error{Code: CodeValidation, Message: message}
+}
+
+func NewUpstreamError(service string, err error) *Error {
+	return &Error{
+		Code:    CodeUpstreamUnavailable,
+		Message: fmt.Sprintf("%s unavailable", service),
+		Details: map[string]interface{}{"service": service},
+		Err:     err,
+	}
+}
+
+func NewTimeoutError(operation string, timeout time.Duration) *Error {
+	return &Error{
+		Code:    CodeTimeout,
+		Message: fmt.Sprintf("%s timed out", operation),
+		Details: map[string]interface{}{"timeout": timeout.String()},
+	}
+}
+
+// ErrShuttingDown rejects new step executions while the server drains
+var ErrShuttingDown = &Error{Code: CodeShuttingDown, Message: "server is shutting down"}
+
+// ErrStepInterrupted marks an execution cancelled by shutdown before it finished
+var ErrStepInterrupted = &Error{Code: CodeShuttingDown, Message: "step execution interrupted by shutdown"}
+
+// AsError returns the first typed error in err's chain. An error wrapping one of
+// the sentinels becomes a typed error with err's text as its message; anything
+// else returns nil
+func AsError(err error) *Error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+	for sentinel, code := range sentinelCodes {
+		if errors.Is(err, sentinel) {
+			return &Error{Code: code, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+var sentinelCodes = map[error]ErrorCode{
+	ErrNotFound:   CodeNotFound,
+	ErrConflict:   CodeConflict,
+	ErrValidation: CodeValidation,
+}
+
+// ErrorCodeOf returns the code of the first typed error in err's chain, or CodeInternal
+func ErrorCodeOf(err error) ErrorCode {
+	if domainErr := AsError(err); domainErr != nil {
+		return domainErr.Code
+	}
+	return CodeInternal
+}
+
+// IsNotFound reports whether err is a not-found domain error
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionEventType string
+
+const (
+	SessionEventCreated           SessionEventType = "session.created"
+	SessionEventForked            SessionEventType = "session.forked"
+	SessionEventCompleted         SessionEventType = "session.completed"
+	SessionEventFailed            SessionEventType = "session.failed"
+	SessionEventStepQueued        SessionEventType = "step.queued"
+	SessionEventStepStarted       SessionEventType = "step.started"
+	SessionEventStepCacheHit      SessionEventType = "step.cache_hit"
+	SessionEventStepCompleted     SessionEventType = "step.completed"
+	SessionEventStepRetrying      SessionEventType = "step.retrying"
+	SessionEventStepFailed        SessionEventType = "step.failed"
+	SessionEventStepInterrupted   SessionEventType = "step.interrupted"
+	SessionEventStepSuperseded    SessionEventType = "step.superseded"
+	SessionEventArtifactPublished SessionEventType = "artifact.published"
+	SessionEventInvalidated       SessionEventType = "cache.invalidated"
+)
+
+// SessionEvent is one entry in a session's timeline; Sequence orders events
+// across the whole store
+type SessionEvent struct {
+	ID        uuid.UUID              `json:"id"`
+	Sequence  int64                  `json:"sequence"`
+	Namespace string                 `json:"namespace"`
+	SessionID uuid.UUID              `json:"session_id"`
+	Type      SessionEventType       `json:"type"`
+	StepID    *uuid.UUID             `json:"step_id,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type ListSessionEventsResponse struct {
+	Events     []*SessionEvent `json:"events"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EvictionStats reports the storage budget, current usage, and what the
+// evictor has removed since the process started
+type EvictionStats struct {
+	MaxArtifacts     int64      `json:"max_artifacts"`
+	MaxBytes         int64      `json:"max_bytes"`
+	Artifacts        int64      `json:"artifacts"`
+	Bytes            int64      `json:"bytes"`
+	Runs             int64      `json:"runs"`
+	EvictedArtifacts int64      `json:"evicted_artifacts"`
+	EvictedBytes     int64      `json:"evicted_bytes"`
+	LastRunAt        *time.Time `json:"last_run_at,omitempty"`
+}
+
+// RemovedArtifact identifies a permanently removed artifact and the blobs left to delete
+type RemovedArtifact struct {
+	ID    uuid.UUID
+	Bytes int64
+	// BlobKeys holds offloaded content of the artifact and its archived versions
+	BlobKeys []string
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// FilterOp is a comparison in a metadata filter condition
+type FilterOp string
+
+const (
+	FilterEq FilterOp = "eq"
+	// FilterNe also matches artifacts without the field
+	FilterNe     FilterOp = "ne"
+	FilterGt     FilterOp = "gt"
+	FilterLt     FilterOp = "lt"
+	FilterIn     FilterOp = "in"
+	FilterExists FilterOp = "exists"
+	FilterPrefix FilterOp = "prefix"
+)
+
+// FilterCondition compares one metadata field. Nested fields are addressed
+// with dots, e.g. "source.host"
+type FilterCondition struct {
+	Field string      `json:"field"`
+	Op    FilterOp    `json:"op"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Filter matches artifacts satisfying all of its conditions
+type Filter []FilterCondition
+
+func Eq(field string, value interface{}) FilterCondition {
+	return FilterCondition{Field: field, Op: FilterEq, Value: value}
+}
+
+func Ne(field string, value interface{}) FilterCondition {
+	return FilterCondition{Field: field, Op: FilterNe, Value: value}
+}
+
+func In(field string, values []string) FilterCondition {
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return FilterCondition{Field: field, Op: FilterIn, Value: list}
+}
+
+// Validate checks that the condition's value suits its operator
+func (c FilterCondition) Validate() error {
+	if c.Field == "" {
+		return NewValidationError("filter field is required")
+	}
+
+	switch c.Op {
+	case FilterEq, FilterNe:
+		if !IsFilterScalar(c.Value) {
+			return NewValidationError(fmt.Sprintf("filter %q on %s needs a string, number, or boolean value", c.Op, c.Field))
+		}
+	case FilterGt, FilterLt:
+		if _, ok := FilterNumber(c.Value); !ok {
+			return NewValidationError(fmt.Sprintf("filter %q on %s needs a number", c.Op, c.Field))
+		}
+	case FilterIn:
+		values, ok := c.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return NewValidationError(fmt.Sprintf("filter \"in\" on %s needs a non-empty list", c.Field))
+		}
+		for _, value := range values {
+			if !IsFilterScalar(value) {
+				return NewValidationError(fmt.Sprintf("filter \"in\" on %s needs strings, numbers, or booleans", c.Field))
+			}
+		}
+	case FilterExists:
+		if c.Value != nil {
+			return NewValidationError(fmt.Sprintf("filter \"exists\" on %s takes no value", c.Field))
+		}
+	case FilterPrefix:
+		if prefix, ok := c.Value.(string); !ok || prefix == "" {
+			return NewValidationError(fmt.Sprintf("filter \"prefix\" on %s needs a non-empty string", c.Field))
+		}
+	default:
+		return NewValidationError(fmt.Sprintf("unsupported filter op %q; use eq, ne, gt, lt, in, exists, or prefix", c.Op))
+	}
+	return nil
+}
+
+func (f Filter) Validate() error {
+	for _, condition := range f {
+		if err := condition.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsFilterScalar reports whether value can be compared by eq, ne, and in
+func IsFilterScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool:
+		return true
+	}
+	_, ok := FilterNumber(value)
+	return ok
+}
+
+// FilterNumber converts a numeric filter value. Values decoded from JSON are
+// float64, but conditions built in code may use any integer type
+func FilterNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case float32:
+		return FilterNumber(float64(v))
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FreshnessSource is a sitemap or RSS/Atom feed the freshness watcher polls.
+// Cached artifacts fetched from a URL the feed reports modified since they
+// were fetched are invalidated
+type FreshnessSource struct {
+	ID        uuid.UUID `json:"id"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	// Interval is a duration string, the time between checks
+	Interval string `json:"interval"`
+	Enabled  bool   `json:"enabled"`
+	// ETag and LastModified are the feed's validators from the last check,
+	// sent back so an unchanged feed isn't downloaded again
+	ETag            string     `json:"-"`
+	LastModified    string     `json:"-"`
+	LastCheckedAt   *time.Time `json:"last_checked_at,omitempty"`
+	NextCheckAt     time.Time  `json:"next_check_at"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastInvalidated int        `json:"last_invalidated"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type FreshnessSourceRequest struct {
+	Name string `json:"name" binding:"required"`
+	URL  string `json:"url" binding:"required"`
+	// Interval is a duration string such as "1h"; defaults to the server's check interval
+	Interval string `json:"interval"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// SourceVersion is what a fresh artifact records of the page it was fetched
+// from, for comparing against what a feed reports
+type SourceVersion struct {
+	SourceURL string
+	ETag      string
+	// LastModified is the page's Last-Modified header, FetchedAt when it was fetched
+	LastModified string
+	FetchedAt    string
+	UpdatedAt    time.Time
+}
+
+// FetchedSince reports when the artifact's content was current: the page's
+// Last-Modified time, else when it was fetched, else its last publish
+func (v SourceVersion) FetchedSince() time.Time {
+	if t, err := time.Parse(time.RFC1123, v.LastModified); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.RFC3339, v.FetchedAt); err == nil {
+		return t
+	}
+	return v.UpdatedAt
+}
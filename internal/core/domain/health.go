@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+	// HealthDegraded is a component that works but has failed recently
+	HealthDegraded = "degraded"
+)
+
+// DependencyHealth is the outcome of checking one backing service
+type DependencyHealth struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessResponse reports whether every dependency is reachable
+type ReadinessResponse struct {
+	Status       string             `json:"status"`
+	Timestamp    time.Time          `json:"timestamp"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+}
+
+func (r *ReadinessResponse) Ready() bool {
+	return r.Status == HealthUp
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ImportIDKey is the metadata field holding an imported record's original ID.
+// Imports upsert on it, so importing a corpus again replaces rather than duplicates
+const ImportIDKey = "import_id"
+
+// ImportRecord is one line of an exported vector store or RAG corpus. It
+// accepts the shapes LangChain and LlamaIndex export documents and nodes in,
+// as well as plain {id, content, metadata, embedding} records
+type ImportRecord struct {
+	// ID is the record's ID in generic and Pinecone-style exports. LangChain's
+	// serialized form uses id for the class path, so non-scalar values are ignored
+	ID interface{} `json:"id"`
+	// NodeID and DocID identify LlamaIndex nodes and legacy documents
+	NodeID string `json:"id_"`
+	DocID  string `json:"doc_id"`
+
+	// PageContent is a LangChain document's text, Text a LlamaIndex node's
+	PageContent string `json:"page_content"`
+	Text        string `json:"text"`
+	Content     string `json:"content"`
+
+	Metadata map[string]interface{} `json:"metadata"`
+	// ExtraInfo is the metadata of legacy LlamaIndex documents
+	ExtraInfo map[string]interface{} `json:"extra_info"`
+
+	Embedding []float32 `json:"embedding"`
+	Vector    []float32 `json:"vector"`
+	// Values is the embedding in Pinecone-style exports
+	Values []float32 `json:"values"`
+
+	// Kwargs holds the document in LangChain's serialized (dumpd) form
+	Kwargs *ImportRecord `json:"kwargs"`
+	// Data holds the node in LlamaIndex docstore entries
+	Data *ImportRecord `json:"__data__"`
+}
+
+// ImportOptions apply to every record of an import
+type ImportOptions struct {
+	Type ArtifactType
+	// Source names the corpus, keeping the IDs of separately imported corpora apart
+	Source string
+	Tags   []string
+	// Reembed discards exported embeddings and embeds the text with the configured model
+	Reembed bool
+}
+
+// unwrap returns the record a serialized wrapper holds
+func (r *ImportRecord) unwrap() *ImportRecord {
+	record := r
+	for {
+		switch {
+		case record.Kwargs != nil:
+			record = record.Kwargs
+		case record.Data != nil:
+			record = record.Data
+		default:
+			return record
+		}
+	}
+}
+
+// RecordID returns the record's original ID, or "" when it has none
+func (r *ImportRecord) RecordID() string {
+	record := r.unwrap()
+	switch {
+	case record.NodeID != "":
+		return record.NodeID
+	case record.DocID != "":
+		return record.DocID
+	}
+	switch id := record.ID.(type) {
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	return ""
+}
+
+// ExportedEmbedding returns the record's exported embedding, if it has one
+func (r *ImportRecord) ExportedEmbedding() []float32 {
+	record := r.unwrap()
+	for _, embedding := range [][]float32{record.Embedding, record.Vector, record.Values} {
+		if len(embedding) > 0 {
+			return embedding
+		}
+	}
+	return nil
+}
+
+// Artifact converts the record to the artifact it is published as
+func (r *ImportRecord) Artifact(options ImportOptions) (Artifact, error) {
+	record := r.unwrap()
+
+	metadata := make(map[string]interface{}, len(record.Metadata)+len(record.ExtraInfo)+2)
+	for key, value := range record.ExtraInfo {
+		metadata[key] = value
+	}
+	for key, value := range record.Metadata {
+		metadata[key] = value
+	}
+
+	text := firstNonBlank(record.PageContent, record.Text, record.Content)
+	if text == "" {
+		// Pinecone-style exports keep the text among the metadata
+		for _, key := range []string{"text", "page_content", "content"} {
+			if value, ok := metadata[key].(string); ok && strings.TrimSpace(value) != "" {
+				text = value
+				delete(metadata, key)
+				break
+			}
+		}
+	}
+	if text == "" {
+		return Artifact{}, NewValidationError("record has no text; expected page_content, text or content")
+	}
+
+	artifact := Artifact{
+		Type:     options.Type,
+		Content:  []byte(text),
+		Metadata: metadata,
+		Tags:     options.Tags,
+	}
+	if !options.Reembed {
+		artifact.Embedding = r.ExportedEmbedding()
+	}
+
+	if id := r.RecordID(); id != "" {
+		if options.Source != "" {
+			id = options.Source + "/" + id
+		}
+		metadata[ImportIDKey] = id
+		artifact.UpsertKey = ImportIDKey
+	}
+	if options.Source != "" {
+		metadata["import_source"] = options.Source
+	}
+
+	// LangChain loaders record where a document came from as its source
+	if _, ok := metadata["source_url"]; !ok {
+		if source, ok := metadata["source"].(string); ok && (strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")) {
+			metadata["source_url"] = source
+		}
+	}
+
+	return artifact, nil
+}
+
+func firstNonBlank(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvalidateRequest invalidates either the artifacts fetched from a source URL,
+// or those of an artifact type (any type when empty) older than a duration
+type InvalidateRequest struct {
+	SourceURL    string       `json:"source_url"`
+	ArtifactType ArtifactType `json:"artifact_type"`
+	// OlderThan is a duration string such as "168h", measured from the last publish
+	OlderThan string `json:"older_than"`
+}
+
+type InvalidateResponse struct {
+	Message     string `json:"message"`
+	Invalidated int    `json:"invalidated"`
+}
+
+// SourceChangeEvent reports that the content at SourceURL changed, such as a
+// CMS update or a crawl finding a page modified, so the artifacts of Namespace
+// fetched from it are stale
+type SourceChangeEvent struct {
+	// Namespace defaults to the default namespace
+	Namespace string `json:"namespace"`
+	SourceURL string `json:"source_url" binding:"required"`
+}
+
+// InvalidationPolicy is a standing freshness rule: the artifact sweeper marks
+// artifacts of ArtifactType (any type when empty) stale once they are older than MaxAge
+type InvalidationPolicy struct {
+	ID           uuid.UUID    `json:"id"`
+	Namespace    string       `json:"namespace"`
+	Name         string       `json:"name"`
+	ArtifactType ArtifactType `json:"artifact_type,omitempty"`
+	// MaxAge is a duration string, measured from an artifact's last publish
+	MaxAge    string    `json:"max_age"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type InvalidationPolicyRequest struct {
+	Name         string       `json:"name" binding:"required"`
+	ArtifactType ArtifactType `json:"artifact_type"`
+	// MaxAge is a duration string such as "168h"
+	MaxAge string `json:"max_age" binding:"required"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
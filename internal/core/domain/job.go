@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// StepJob is a queued execution of a workflow step
+type StepJob struct {
+	ID        uuid.UUID     `json:"id"`
+	StepID    uuid.UUID     `json:"step_id"`
+	Namespace string        `json:"namespace"`
+	Input     interface{}   `json:"input"`
+	Timeout   time.Duration `json:"timeout,omitempty"`
+	External  bool          `json:"external"`
+	Status    JobStatus     `json:"status"`
+	Attempts  int           `json:"attempts"`
+	LastError string        `json:"last_error,omitempty"`
+	RunAt     time.Time     `json:"run_at"`
+	LockedAt  *time.Time    `json:"locked_at,omitempty"`
+	// LeaseToken identifies the current lease on the job, taken by a worker or an external executor
+	LeaseToken *uuid.UUID `json:"lease_token,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
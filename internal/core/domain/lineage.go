@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLineageDepth = 3
+	MaxLineageDepth     = 10
+	// MaxLineageNodes bounds a lineage graph; traversal stops once it is reached
+	MaxLineageNodes = 500
+)
+
+// LineageDirection selects which side of an artifact's dependency graph to traverse
+type LineageDirection string
+
+const (
+	// LineageUpstream follows dependencies toward the sources an artifact derives from
+	LineageUpstream LineageDirection = "upstream"
+	// LineageDownstream follows dependents toward artifacts derived from it
+	LineageDownstream LineageDirection = "downstream"
+	LineageBoth       LineageDirection = "both"
+)
+
+func (d LineageDirection) IsValid() bool {
+	switch d {
+	case LineageUpstream, LineageDownstream, LineageBoth:
+		return true
+	}
+	return false
+}
+
+// LineageStatus is whether a lineage node is still live
+type LineageStatus string
+
+const (
+	LineageActive  LineageStatus = "active"
+	LineageExpired LineageStatus = "expired"
+	// LineageDeleted nodes are deleted but still restorable
+	LineageDeleted LineageStatus = "deleted"
+)
+
+// LineageNode is an artifact in a lineage graph. Depth is its distance from
+// the root, negative upstream and positive downstream
+type LineageNode struct {
+	ID        uuid.UUID     `json:"id"`
+	Type      ArtifactType  `json:"type"`
+	Status    LineageStatus `json:"status"`
+	Stale     bool          `json:"stale"`
+	Version   int           `json:"version"`
+	SourceURL string        `json:"source_url,omitempty"`
+	Depth     int           `json:"depth"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// LineageEdge records that To was derived from From
+type LineageEdge struct {
+	From uuid.UUID `json:"from"`
+	To   uuid.UUID `json:"to"`
+}
+
+// ArtifactLineage is the dependency graph around an artifact
+type ArtifactLineage struct {
+	Root      uuid.UUID        `json:"root"`
+	Direction LineageDirection `json:"direction"`
+	Depth     int              `json:"depth"`
+	Nodes     []*LineageNode   `json:"nodes"`
+	Edges     []LineageEdge    `json:"edges"`
+	// Truncated is set when the graph hit MaxLineageNodes before reaching Depth
+	Truncated bool `json:"truncated"`
+}
+
+// NormalizeLineageDepth clamps a requested traversal depth into the supported range
+func NormalizeLineageDepth(depth int) int {
+	if depth <= 0 {
+		return DefaultLineageDepth
+	}
+	if depth > MaxLineageDepth {
+		return MaxLineageDepth
+	}
+	return depth
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metadata keys recorded on LLM response cache entries
+const (
+	LLMPromptKey = "llm_prompt"
+	LLMModelKey  = "llm_model"
+	// LLMCacheKey identifies an entry by model and prompt, so storing an answer
+	// for the same prompt again replaces the cached one
+	LLMCacheKey = "llm_cache_key"
+)
+
+// LLMCacheStoreRequest caches a model's answer to a prompt
+type LLMCacheStoreRequest struct {
+	Prompt    string                 `json:"prompt"`
+	Model     string                 `json:"model"`
+	Answer    string                 `json:"answer"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	ExpiresAt *time.Time             `json:"expires_at,omitempty"`
+}
+
+type LLMCacheStoreResponse struct {
+	ID     uuid.UUID     `json:"id"`
+	Status PublishStatus `json:"status"`
+}
+
+// LLMCacheCheckRequest asks for a cached answer to a semantically equivalent
+// prompt sent to the same model
+type LLMCacheCheckRequest struct {
+	Prompt string `json:"prompt"`
+	Model  string `json:"model"`
+	// MinScore falls back to the server's LLM cache threshold when unset
+	MinScore *float32 `json:"min_score,omitempty"`
+}
+
+type LLMCacheCheckResponse struct {
+	Hit    bool   `json:"hit"`
+	Answer string `json:"answer,omitempty"`
+	// Prompt is the cached prompt the answer was given for
+	Prompt     string     `json:"prompt,omitempty"`
+	Score      float32    `json:"score,omitempty"`
+	ArtifactID *uuid.UUID `json:"artifact_id,omitempty"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// NegativeKind distinguishes what a negative cache entry remembers
+type NegativeKind string
+
+const (
+	// NegativeLookupMiss remembers a lookup that found no artifacts
+	NegativeLookupMiss NegativeKind = "lookup_miss"
+	// NegativeStepFailure remembers a step input whose execution failed
+	NegativeStepFailure NegativeKind = "step_failure"
+)
+
+// NegativeEntry is a known miss or failure, served instead of repeating the work until it expires
+type NegativeEntry struct {
+	Namespace string       `json:"namespace"`
+	Kind      NegativeKind `json:"kind"`
+	Key       string       `json:"key"`
+	Reason    string       `json:"reason,omitempty"`
+	Code      ErrorCode    `json:"code,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
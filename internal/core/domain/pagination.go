@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+var ErrInvalidCursor = NewValidationError("invalid cursor")
+
+// pageCursor is the decoded form of an opaque pagination cursor
+type pageCursor struct {
+	Offset int        `json:"o,omitempty"`
+	Time   *time.Time `json:"t,omitempty"`
+	ID     *uuid.UUID `json:"i,omitempty"`
+	Seq    *int64     `json:"s,omitempty"`
+}
+
+// PageKey is the position of the last row of a page in a listing ordered by
+// time and ID, both descending. The next page starts strictly after it, so
+// rows written in between do not shift or repeat entries
+type PageKey struct {
+	Time time.Time
+	ID   uuid.UUID
+}
+
+// EncodeCursor returns an opaque cursor pointing at the given offset
+func EncodeCursor(offset int) string {
+	data, _ := json.Marshal(pageCursor{Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the offset stored in a cursor; an empty cursor is the first page
+func DecodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return c.Offset, nil
+}
+
+// EncodeKeyCursor returns an opaque cursor resuming after the given row
+func EncodeKeyCursor(key PageKey) string {
+	data, _ := json.Marshal(pageCursor{Time: &key.Time, ID: &key.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeKeyCursor returns the position stored in a cursor; an empty cursor is
+// the first page and decodes to nil
+func DecodeKeyCursor(cursor string) (*PageKey, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Time == nil || c.ID == nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &PageKey{Time: *c.Time, ID: *c.ID}, nil
+}
+
+// EncodeSeqCursor returns an opaque cursor resuming after the row with the
+// given sequence number, for listings ordered by a per-parent counter
+func EncodeSeqCursor(seq int64) string {
+	data, _ := json.Marshal(pageCursor{Seq: &seq})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeSeqCursor returns the sequence number stored in a cursor; an empty
+// cursor is the first page and decodes to nil
+func DecodeSeqCursor(cursor string) (*int64, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Seq == nil || *c.Seq < 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return c.Seq, nil
+}
+
+// NormalizePageSize clamps a requested page size into the supported range
+func NormalizePageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,40 @@
+package domain
+
+// PIIKind identifies a class of sensitive data found in artifact content
+type PIIKind string
+
+const (
+	PIIEmail  PIIKind = "email"
+	PIIPhone  PIIKind = "phone"
+	PIISecret PIIKind = "secret"
+)
+
+// PIIPolicy decides what happens to an artifact whose content contains PII
+type PIIPolicy string
+
+const (
+	PIIPolicyOff    PIIPolicy = "off"
+	PIIPolicyFlag   PIIPolicy = "flag"
+	PIIPolicyRedact PIIPolicy = "redact"
+	PIIPolicyBlock  PIIPolicy = "block"
+)
+
+// PIIFinding is a byte range of content matched by a detector
+type PIIFinding struct {
+	Kind  PIIKind `json:"kind"`
+	Start int     `json:"start"`
+	End   int     `json:"end"`
+}
+
+// PIIKinds returns the distinct kinds present in findings, in first-seen order
+func PIIKinds(findings []PIIFinding) []PIIKind {
+	seen := make(map[PIIKind]bool)
+	var kinds []PIIKind
+	for _, f := range findings {
+		if !seen[f.Kind] {
+			seen[f.Kind] = true
+			kinds = append(kinds, f.Kind)
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,12 @@
+package domain
+
+// QueuedTask is a task taken from a durable task queue
+type QueuedTask struct {
+	ID      string
+	Queue   string
+	Payload []byte
+	// Attempt is 1 when the task is first delivered and increases each time it is retried
+	Attempt int
+	// Receipt identifies this delivery of the task to the queue that made it
+	Receipt string
+}
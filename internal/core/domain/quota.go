@@ -0,0 +1,53 @@
+package domain
+
+import "github.com/google/uuid"
+
+// QuotaLimits caps a namespace's or an API key's usage; a zero limit means unlimited
+type QuotaLimits struct {
+	MaxArtifacts        int64 `json:"max_artifacts"`
+	MaxBytes            int64 `json:"max_bytes"`
+	MaxEmbeddingsPerDay int64 `json:"max_embeddings_per_day"`
+}
+
+type QuotaUsage struct {
+	Artifacts       int64 `json:"artifacts"`
+	Bytes           int64 `json:"bytes"`
+	EmbeddingsToday int64 `json:"embeddings_today"`
+}
+
+// QuotaRemaining holds the headroom left per limit; nil means unlimited
+type QuotaRemaining struct {
+	Artifacts       *int64 `json:"artifacts"`
+	Bytes           *int64 `json:"bytes"`
+	EmbeddingsToday *int64 `json:"embeddings_today"`
+}
+
+type QuotaStatus struct {
+	Namespace string         `json:"namespace"`
+	Limits    QuotaLimits    `json:"limits"`
+	Usage     QuotaUsage     `json:"usage"`
+	Remaining QuotaRemaining `json:"remaining"`
+	// APIKey is present when the caller's API key has limits of its own
+	APIKey *KeyQuotaStatus `json:"api_key,omitempty"`
+}
+
+type KeyQuotaStatus struct {
+	KeyID     uuid.UUID      `json:"key_id"`
+	Limits    QuotaLimits    `json:"limits"`
+	Usage     QuotaUsage     `json:"usage"`
+	Remaining QuotaRemaining `json:"remaining"`
+}
+
+// NewQuotaExceededError reports that a write would take resource past its limit
+func NewQuotaExceededError(resource string, limit, used, requested int64) *Error {
+	return &Error{
+		Code:    CodeQuotaExceeded,
+		Message: resource + " quota exceeded",
+		Details: map[string]interface{}{
+			"resource":  resource,
+			"limit":     limit,
+			"used":      used,
+			"requested": requested,
+		},
+	}
+}
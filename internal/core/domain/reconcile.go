@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxReconcileSamples bounds how many IDs a reconciliation report lists per problem
+const MaxReconcileSamples = 100
+
+// ArtifactRef identifies an artifact together with the namespace it belongs to
+type ArtifactRef struct {
+	ID        uuid.UUID
+	Namespace string
+}
+
+// ReconcileReport describes one pass comparing Postgres artifacts against the
+// vector store. Missing vectors belong to indexed artifacts; orphaned vectors
+// have no artifact. Samples list the first problem IDs found
+type ReconcileReport struct {
+	StartedAt        time.Time   `json:"started_at"`
+	FinishedAt       time.Time   `json:"finished_at"`
+	Repair           bool        `json:"repair"`
+	ArtifactsChecked int         `json:"artifacts_checked"`
+	VectorsChecked   int         `json:"vectors_checked"`
+	MissingVectors   int         `json:"missing_vectors"`
+	OrphanedVectors  int         `json:"orphaned_vectors"`
+	Reindexed        int         `json:"reindexed"`
+	RemovedVectors   int         `json:"removed_vectors"`
+	MissingSamples   []uuid.UUID `json:"missing_samples"`
+	OrphanedSamples  []uuid.UUID `json:"orphaned_samples"`
+	Error            string      `json:"error,omitempty"`
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshHookKind string
+
+const (
+	// RefreshWebhook POSTs the stale artifact to a URL that responds with fresh content
+	RefreshWebhook RefreshHookKind = "webhook"
+	// RefreshStep re-derives the artifact by executing a step in a new session
+	RefreshStep RefreshHookKind = "step"
+)
+
+// RefreshHook re-fetches or re-derives stale artifacts of a namespace whose
+// source URL starts with SourcePrefix and whose type is ArtifactType; empty
+// filters match every artifact
+type RefreshHook struct {
+	ID           uuid.UUID       `json:"id"`
+	Namespace    string          `json:"namespace"`
+	Name         string          `json:"name"`
+	SourcePrefix string          `json:"source_prefix,omitempty"`
+	ArtifactType ArtifactType    `json:"artifact_type,omitempty"`
+	Kind         RefreshHookKind `json:"kind"`
+	URL          string          `json:"url,omitempty"`
+	StepType     string          `json:"step_type,omitempty"`
+	Enabled      bool            `json:"enabled"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// Matches reports whether the hook refreshes artifact
+func (h *RefreshHook) Matches(artifact *Artifact) bool {
+	if !h.Enabled || h.Namespace != artifact.Namespace {
+		return false
+	}
+	if h.ArtifactType != "" && h.ArtifactType != artifact.Type {
+		return false
+	}
+	if h.SourcePrefix != "" {
+		sourceURL, _ := artifact.Metadata["source_url"].(string)
+		return strings.HasPrefix(sourceURL, h.SourcePrefix)
+	}
+	return true
+}
+
+// specificity ranks hooks so the narrowest match handles an artifact
+func (h *RefreshHook) specificity() int {
+	score := len(h.SourcePrefix) * 2
+	if h.ArtifactType != "" {
+		score++
+	}
+	return score
+}
+
+// SelectRefreshHook returns the most specific hook matching artifact, or nil
+func SelectRefreshHook(hooks []*RefreshHook, artifact *Artifact) *RefreshHook {
+	var selected *RefreshHook
+	for _, hook := range hooks {
+		if hook.Matches(artifact) && (selected == nil || hook.specificity() > selected.specificity()) {
+			selected = hook
+		}
+	}
+	return selected
+}
+
+type RefreshHookRequest struct {
+	Name         string          `json:"name" binding:"required"`
+	SourcePrefix string          `json:"source_prefix"`
+	ArtifactType ArtifactType    `json:"artifact_type"`
+	Kind         RefreshHookKind `json:"kind" binding:"required"`
+	URL          string          `json:"url"`
+	StepType     string          `json:"step_type"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// RefreshCallback is the payload POSTed to a webhook refresh hook
+type RefreshCallback struct {
+	ID       uuid.UUID `json:"id"`
+	HookID   uuid.UUID `json:"hook_id"`
+	Artifact *Artifact `json:"artifact"`
+}
+
+// RefreshContent is the fresh content a refresh hook produced; Metadata is
+// merged over the stale artifact's metadata
+type RefreshContent struct {
+	Content  []byte                 `json:"content"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// RefreshResult reports the artifact a refresh swapped in
+type RefreshResult struct {
+	Artifact *Artifact `json:"artifact"`
+	HookID   uuid.UUID `json:"hook_id"`
+	// Changed is false when the hook returned the content the artifact already had
+	Changed bool `json:"changed"`
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// RetrieveRequest follows the retriever contract of RAG frameworks such as
+// LangChain and LlamaIndex: a query, how many documents to return, and
+// metadata filters
+type RetrieveRequest struct {
+	Query string `json:"query"`
+	// K falls back to the lookup default top_k when unset
+	K int `json:"k"`
+	// Filters maps metadata fields to the value they must equal, or to a list
+	// of values one of which they must equal
+	Filters map[string]interface{} `json:"filters,omitempty"`
+	// MinScore falls back to the server default when unset; 0 disables the threshold
+	MinScore     *float32     `json:"min_score,omitempty"`
+	ArtifactType ArtifactType `json:"artifact_type,omitempty"`
+}
+
+// LookupOptions translates the request into a lookup that returns content
+func (r RetrieveRequest) LookupOptions() LookupOptions {
+	fields := make([]string, 0, len(r.Filters))
+	for field := range r.Filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var filter Filter
+	for _, field := range fields {
+		if values, ok := r.Filters[field].([]interface{}); ok {
+			filter = append(filter, FilterCondition{Field: field, Op: FilterIn, Value: values})
+		} else {
+			filter = append(filter, Eq(field, r.Filters[field]))
+		}
+	}
+
+	return LookupOptions{
+		Query:          r.Query,
+		TopK:           r.K,
+		MinScore:       r.MinScore,
+		ArtifactType:   r.ArtifactType,
+		Filter:         filter,
+		IncludeContent: true,
+	}
+}
+
+// RetrievedDocument is a lookup result in the shape retrievers expect
+type RetrievedDocument struct {
+	ID       uuid.UUID              `json:"id"`
+	Text     string                 `json:"text"`
+	Metadata map[string]interface{} `json:"metadata"`
+	Score    float32                `json:"score"`
+}
+
+type RetrieveResponse struct {
+	Documents []RetrievedDocument `json:"documents"`
+}
+
+// NewRetrievedDocument converts a lookup result. A result matched through its
+// chunks returns the best chunk's text, so documents stay passage-sized
+func NewRetrievedDocument(result LookupResult) RetrievedDocument {
+	artifact := result.Artifact
+	metadata := make(map[string]interface{}, len(artifact.Metadata)+2)
+	for key, value := range artifact.Metadata {
+		metadata[key] = value
+	}
+	metadata["type"] = artifact.Type
+	if len(artifact.Tags) > 0 {
+		metadata["tags"] = artifact.Tags
+	}
+
+	text := artifact.Content
+	if len(result.Chunks) > 0 {
+		best := result.Chunks[0]
+		if best.Start >= 0 && best.Start <= best.End && best.End <= len(text) {
+			text = text[best.Start:best.End]
+			metadata["chunk_index"] = best.Index
+		}
+	}
+
+	return RetrievedDocument{
+		ID:       artifact.ID,
+		Text:     string(text),
+		Metadata: metadata,
+		Score:    result.Score,
+	}
+}
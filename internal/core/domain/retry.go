@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how failed step executions are retried
+type RetryPolicy struct {
+	MaxAttempts    int           `json:"max_attempts"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	Multiplier     float64       `json:"multiplier"`
+	RetryableCodes []ErrorCode   `json:"retryable_codes"`
+}
+
+// ShouldRetry reports whether another attempt is allowed after attempt failed with err
+func (p RetryPolicy) ShouldRetry(attempt int, err error) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	code := ErrorCodeOf(err)
+	for _, retryable := range p.RetryableCodes {
+		if retryable == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay before the attempt following attempt, with up to 20% jitter
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	delay += delay * 0.2 * rand.Float64()
+
+	return time.Duration(delay)
+}
+
+// StepAttempt records the outcome of one execution attempt of a step
+type StepAttempt struct {
+	Attempt    int       `json:"attempt"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+	Code       ErrorCode `json:"code,omitempty"`
+}
+
+// RetryLaterError asks the job runner to requeue the job after a delay
+type RetryLaterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryLaterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryLaterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "github.com/google/uuid"
+
+// StepCacheScope controls which earlier steps a step may reuse
+type StepCacheScope string
+
+const (
+	// CacheScopeGlobal reuses steps from any namespace; only for step types over
+	// public data. A step of another namespace is served as a copy of its output
+	// in the caller's namespace
+	CacheScopeGlobal    StepCacheScope = "global"
+	CacheScopeNamespace StepCacheScope = "namespace"
+	CacheScopeSession   StepCacheScope = "session"
+	// CacheScopeGoal reuses steps from sessions whose goals are similar to the current one
+	CacheScopeGoal StepCacheScope = "goal"
+)
+
+func (s StepCacheScope) Valid() bool {
+	switch s {
+	case CacheScopeGlobal, CacheScopeNamespace, CacheScopeSession, CacheScopeGoal:
+		return true
+	}
+	return false
+}
+
+// StepReuseFilter narrows which completed steps the step cache may return
+type StepReuseFilter struct {
+	// Compat limits reuse to compatible template versions; nil matches steps of any template
+	Compat *TemplateCompatibility
+	// SessionIDs limits reuse to steps of these sessions; nil matches any session
+	SessionIDs []uuid.UUID
+	// AllNamespaces matches steps outside the caller's namespace
+	AllNamespaces bool
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowSchedule runs a set of steps in a new session whenever its cron expression fires
+type WorkflowSchedule struct {
+	ID        uuid.UUID `json:"id"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	// Cron is a five-field expression (minute hour day-of-month month day-of-week) in UTC
+	Cron            string                 `json:"cron"`
+	Template        string                 `json:"template,omitempty"`
+	TemplateVersion int                    `json:"template_version,omitempty"`
+	Goal            string                 `json:"goal"`
+	Context         map[string]interface{} `json:"context,omitempty"`
+	Steps           []BatchStep            `json:"steps"`
+	Enabled         bool                   `json:"enabled"`
+	NextRunAt       time.Time              `json:"next_run_at"`
+	LastRunAt       *time.Time             `json:"last_run_at,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+type ScheduleRequest struct {
+	Name            string                 `json:"name" binding:"required"`
+	Cron            string                 `json:"cron" binding:"required"`
+	Template        string                 `json:"template"`
+	TemplateVersion int                    `json:"template_version"`
+	Goal            string                 `json:"goal" binding:"required"`
+	Context         map[string]interface{} `json:"context"`
+	Steps           []BatchStep            `json:"steps"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+type ScheduleRunStatus string
+
+const (
+	ScheduleRunRunning   ScheduleRunStatus = "running"
+	ScheduleRunSucceeded ScheduleRunStatus = "succeeded"
+	ScheduleRunFailed    ScheduleRunStatus = "failed"
+)
+
+// ScheduleRun is one execution of a schedule and the session it created
+type ScheduleRun struct {
+	ID         uuid.UUID         `json:"id"`
+	ScheduleID uuid.UUID         `json:"schedule_id"`
+	Namespace  string            `json:"namespace"`
+	SessionID  *uuid.UUID        `json:"session_id,omitempty"`
+	Status     ScheduleRunStatus `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
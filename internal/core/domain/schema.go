@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// StepSchema holds the JSON Schemas a step type's inputs and outputs must satisfy;
+// a nil schema accepts anything
+type StepSchema struct {
+	Namespace    string                 `json:"namespace"`
+	StepType     string                 `json:"step_type"`
+	InputSchema  map[string]interface{} `json:"input_schema,omitempty"`
+	OutputSchema map[string]interface{} `json:"output_schema,omitempty"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// SchemaViolation describes one place where a value fails its schema
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// NewSchemaValidationError reports the violations found validating subject (e.g. "input")
+func NewSchemaValidationError(subject string, violations []SchemaViolation) *Error {
+	return NewValidationError(subject+" does not match schema").WithDetail("violations", violations)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// ComponentKind separates backing services from the server's own background jobs
+type ComponentKind string
+
+const (
+	ComponentDependency ComponentKind = "dependency"
+	ComponentJob        ComponentKind = "job"
+)
+
+// ComponentStatus is a component's current state and its recent failures
+type ComponentStatus struct {
+	Name  string        `json:"name"`
+	Kind  ComponentKind `json:"kind"`
+	State string        `json:"state"`
+	// Operations and Errors count calls or job passes within the status window
+	Operations    int64      `json:"operations"`
+	Errors        int64      `json:"errors"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+}
+
+// SystemStatus reports every tracked component for operators
+type SystemStatus struct {
+	Status        string            `json:"status"`
+	WindowMinutes int               `json:"window_minutes"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+	Components    []ComponentStatus `json:"components"`
+}
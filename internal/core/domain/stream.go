@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// StepChunk is partial output a processor streams while a step runs; a retried
+// step streams again under the next attempt number
+type StepChunk struct {
+	StepID  uuid.UUID `json:"step_id"`
+	Attempt int       `json:"attempt"`
+	Content string    `json:"content"`
+}
+
+type chunkSinkKey struct{}
+
+type chunkSink struct {
+	stepID  uuid.UUID
+	attempt int
+	send    func(StepChunk)
+}
+
+// WithChunkSink returns a context whose step processors stream partial output to send
+func WithChunkSink(ctx context.Context, send func(StepChunk)) context.Context {
+	return context.WithValue(ctx, chunkSinkKey{}, &chunkSink{send: send})
+}
+
+// WithChunkAttempt labels chunks emitted under ctx with the step and attempt producing them
+func WithChunkAttempt(ctx context.Context, stepID uuid.UUID, attempt int) context.Context {
+	sink, ok := ctx.Value(chunkSinkKey{}).(*chunkSink)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, chunkSinkKey{}, &chunkSink{stepID: stepID, attempt: attempt, send: sink.send})
+}
+
+// EmitChunk streams partial output to the context's sink, if any
+func EmitChunk(ctx context.Context, content string) {
+	if sink, ok := ctx.Value(chunkSinkKey{}).(*chunkSink); ok {
+		sink.send(StepChunk{StepID: sink.stepID, Attempt: sink.attempt, Content: content})
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowTemplate is one immutable version of a named workflow definition
+type WorkflowTemplate struct {
+	ID          uuid.UUID `json:"id"`
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	Description string    `json:"description,omitempty"`
+	// Steps lists the step types the workflow may execute; empty allows any
+	Steps []string `json:"steps,omitempty"`
+	// CompatibleFrom is the oldest version whose cached step results this version reuses
+	CompatibleFrom int       `json:"compatible_from"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AllowsStep reports whether stepType is part of the template's definition
+func (t *WorkflowTemplate) AllowsStep(stepType string) bool {
+	if len(t.Steps) == 0 {
+		return true
+	}
+	for _, s := range t.Steps {
+		if s == stepType {
+			return true
+		}
+	}
+	return false
+}
+
+type PublishTemplateRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Steps       []string `json:"steps"`
+	// Breaking stops the new version reusing, and marks stale, results of earlier versions
+	Breaking bool `json:"breaking"`
+}
+
+type PublishTemplateResponse struct {
+	Template *WorkflowTemplate `json:"template"`
+	// Invalidated counts artifacts of earlier versions marked stale by a breaking change
+	Invalidated int64 `json:"invalidated"`
+}
+
+// TemplateCompatibility restricts step cache reuse to a range of template versions
+type TemplateCompatibility struct {
+	Name       string
+	MinVersion int
+	MaxVersion int
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const DefaultNamespace = "default"
+
+// Principal identifies the authenticated caller, the namespace it operates in,
+// and the scopes it was granted. Empty scope lists mean unrestricted.
+type Principal struct {
+	KeyID         string         `json:"key_id,omitempty"`
+	Namespace     string         `json:"namespace"`
+	Namespaces    []string       `json:"namespaces,omitempty"`
+	ArtifactTypes []ArtifactType `json:"artifact_types,omitempty"`
+	Operations    []Operation    `json:"operations,omitempty"`
+}
+
+// Allows reports whether the principal may perform op
+func (p *Principal) Allows(op Operation) bool {
+	if len(p.Operations) == 0 {
+		return true
+	}
+	for _, allowed := range p.Operations {
+		if allowed == op || allowed == OpAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsNamespace reports whether the principal may operate in namespace
+func (p *Principal) AllowsNamespace(namespace string) bool {
+	if len(p.Namespaces) == 0 {
+		return true
+	}
+	for _, allowed := range p.Namespaces {
+		if allowed == namespace || allowed == AllNamespaces {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsArtifactType reports whether the principal may access artifacts of type t
+func (p *Principal) AllowsArtifactType(t ArtifactType) bool {
+	if len(p.ArtifactTypes) == 0 {
+		return true
+	}
+	for _, allowed := range p.ArtifactTypes {
+		if allowed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether every scope of key lies within the principal's, so a
+// key the principal creates or manages reaches no further than the principal
+func (p *Principal) Covers(key *APIKey) bool {
+	for _, namespace := range key.Namespaces {
+		if namespace == AllNamespaces {
+			if !p.allNamespaces() {
+				return false
+			}
+		} else if !p.AllowsNamespace(namespace) {
+			return false
+		}
+	}
+	for _, op := range key.Operations {
+		if !p.Allows(op) {
+			return false
+		}
+	}
+	if len(p.ArtifactTypes) > 0 {
+		if len(key.ArtifactTypes) == 0 {
+			return false
+		}
+		for _, t := range key.ArtifactTypes {
+			if !p.AllowsArtifactType(t) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (p *Principal) allNamespaces() bool {
+	if len(p.Namespaces) == 0 {
+		return true
+	}
+	for _, allowed := range p.Namespaces {
+		if allowed == AllNamespaces {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the given principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, or nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// APIKeyIDFromContext returns the ID of the issued API key the caller
+// authenticated with; configured keys and signed URLs have none
+func APIKeyIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(principal.KeyID)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// NamespaceFromContext returns the caller's namespace, falling back to the default namespace
+func NamespaceFromContext(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil && principal.Namespace != "" {
+		return principal.Namespace
+	}
+	return DefaultNamespace
+}
@@ -0,0 +1,93 @@
+package domain
+
+import "testing"
+
+func TestPrincipalCovers(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		key       APIKey
+		covers    bool
+	}{
+		{
+			name:      "unrestricted principal covers any key",
+			principal: Principal{},
+			key:       APIKey{Namespaces: []string{AllNamespaces}, Operations: []Operation{OpAdmin}},
+			covers:    true,
+		},
+		{
+			name:      "same scopes",
+			principal: Principal{Namespaces: []string{"a"}, Operations: []Operation{OpRead, OpPublish}},
+			key:       APIKey{Namespaces: []string{"a"}, Operations: []Operation{OpRead}},
+			covers:    true,
+		},
+		{
+			name:      "namespace outside the principal's",
+			principal: Principal{Namespaces: []string{"a"}, Operations: []Operation{OpRead}},
+			key:       APIKey{Namespaces: []string{"a", "b"}, Operations: []Operation{OpRead}},
+			covers:    false,
+		},
+		{
+			name:      "all namespaces needs the principal to have all namespaces",
+			principal: Principal{Namespaces: []string{"a", "b"}},
+			key:       APIKey{Namespaces: []string{AllNamespaces}, Operations: []Operation{OpRead}},
+			covers:    false,
+		},
+		{
+			name:      "wildcard principal grants all namespaces",
+			principal: Principal{Namespaces: []string{AllNamespaces}},
+			key:       APIKey{Namespaces: []string{AllNamespaces, "a"}, Operations: []Operation{OpRead}},
+			covers:    true,
+		},
+		{
+			name:      "operation outside the principal's",
+			principal: Principal{Operations: []Operation{OpRead}},
+			key:       APIKey{Namespaces: []string{"a"}, Operations: []Operation{OpRead, OpDelete}},
+			covers:    false,
+		},
+		{
+			name:      "admin principal covers every operation",
+			principal: Principal{Operations: []Operation{OpAdmin}},
+			key:       APIKey{Namespaces: []string{"a"}, Operations: []Operation{OpDelete, OpInvalidate}},
+			covers:    true,
+		},
+		{
+			name:      "admin key needs an admin principal",
+			principal: Principal{Operations: []Operation{OpRead, OpPublish, OpDelete}},
+			key:       APIKey{Namespaces: []string{"a"}, Operations: []Operation{OpAdmin}},
+			covers:    false,
+		},
+		{
+			name:      "type-restricted principal covers a key with a subset of its types",
+			principal: Principal{ArtifactTypes: []ArtifactType{RAW, DERIVED}},
+			key:       APIKey{Namespaces: []string{"a"}, ArtifactTypes: []ArtifactType{RAW}, Operations: []Operation{OpRead}},
+			covers:    true,
+		},
+		{
+			name:      "type-restricted principal does not cover an unrestricted key",
+			principal: Principal{ArtifactTypes: []ArtifactType{RAW}},
+			key:       APIKey{Namespaces: []string{"a"}, Operations: []Operation{OpRead}},
+			covers:    false,
+		},
+		{
+			name:      "artifact type outside the principal's",
+			principal: Principal{ArtifactTypes: []ArtifactType{RAW}},
+			key:       APIKey{Namespaces: []string{"a"}, ArtifactTypes: []ArtifactType{RAW, ANSWER}, Operations: []Operation{OpRead}},
+			covers:    false,
+		},
+		{
+			name:      "unrestricted principal covers a type-restricted key",
+			principal: Principal{},
+			key:       APIKey{Namespaces: []string{"a"}, ArtifactTypes: []ArtifactType{ANSWER}, Operations: []Operation{OpRead}},
+			covers:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if covers := tt.principal.Covers(&tt.key); covers != tt.covers {
+				t.Fatalf("expected Covers=%v, got %v", tt.covers, covers)
+			}
+		})
+	}
+}
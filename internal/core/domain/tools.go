@@ -0,0 +1,30 @@
+package domain
+
+// Tool is a function definition in the OpenAI function-calling format, which
+// other agent frameworks accept as well
+type Tool struct {
+	// Type is always "function"
+	Type     string       `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+type ToolFunction struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Parameters is the JSON Schema of the call's arguments
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// ToolEndpoint is the HTTP call that runs a tool: its arguments are sent as
+// the JSON body
+type ToolEndpoint struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// ToolManifest lists the tools an agent may call with the requesting API key
+type ToolManifest struct {
+	Tools []Tool `json:"tools"`
+	// Endpoints maps each tool's name to the call that runs it
+	Endpoints map[string]ToolEndpoint `json:"endpoints"`
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"sync"
+)
+
+// Usage is the token consumption and cost attributed to a step or session
+type Usage struct {
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	EmbeddingTokens  int64   `json:"embedding_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// Validate rejects negative token counts and costs, which would otherwise
+// subtract from a step's and session's totals
+func (u Usage) Validate() error {
+	switch {
+	case u.PromptTokens < 0:
+		return NewValidationError("usage.prompt_tokens must not be negative")
+	case u.CompletionTokens < 0:
+		return NewValidationError("usage.completion_tokens must not be negative")
+	case u.EmbeddingTokens < 0:
+		return NewValidationError("usage.embedding_tokens must not be negative")
+	case u.CostUSD < 0:
+		return NewValidationError("usage.cost_usd must not be negative")
+	}
+	return nil
+}
+
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.EmbeddingTokens += other.EmbeddingTokens
+	u.CostUSD += other.CostUSD
+}
+
+// UsageMeter accumulates usage recorded by code running under a context
+type UsageMeter struct {
+	mu    sync.Mutex
+	total Usage
+}
+
+func (m *UsageMeter) Record(usage Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.total.Add(usage)
+}
+
+func (m *UsageMeter) Total() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.total
+}
+
+type usageMeterKey struct{}
+
+// WithUsageMeter returns a context whose recorded usage is collected by the returned meter
+func WithUsageMeter(ctx context.Context) (context.Context, *UsageMeter) {
+	meter := &UsageMeter{}
+	return context.WithValue(ctx, usageMeterKey{}, meter), meter
+}
+
+// RecordUsage adds usage to the context's meter, if any
+func RecordUsage(ctx context.Context, usage Usage) {
+	if meter, ok := ctx.Value(usageMeterKey{}).(*UsageMeter); ok {
+		meter.Record(usage)
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// DailyUsage is one namespace's activity on one day
+type DailyUsage struct {
+	Namespace string `json:"namespace"`
+	// Day is the date at midnight UTC
+	Day time.Time `json:"day"`
+	// Publishes counts new and republished artifacts, excluding chunks
+	Publishes int64 `json:"publishes"`
+	Lookups   int64 `json:"lookups"`
+	// CacheHits counts lookup and workflow step hits
+	CacheHits  int64 `json:"cache_hits"`
+	Embeddings int64 `json:"embeddings"`
+	// BytesStored is the artifact content size measured on that day, or nil if
+	// the aggregator did not run while the day was current
+	BytesStored *int64 `json:"bytes_stored,omitempty"`
+}
+
+// UsageTotals sums the counters of a usage report
+type UsageTotals struct {
+	Publishes  int64 `json:"publishes"`
+	Lookups    int64 `json:"lookups"`
+	CacheHits  int64 `json:"cache_hits"`
+	Embeddings int64 `json:"embeddings"`
+}
+
+// UsageReport lists daily usage between two dates, inclusive
+type UsageReport struct {
+	From   time.Time    `json:"from"`
+	To     time.Time    `json:"to"`
+	Days   []DailyUsage `json:"days"`
+	Totals UsageTotals  `json:"totals"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VectorPoint is a vector to upsert into the vector store
+type VectorPoint struct {
+	ID        uuid.UUID
+	Embedding []float32
+	Payload   map[string]interface{}
+}
+
+// VectorRecord is a stored vector's ID and payload, without the vector itself
+type VectorRecord struct {
+	ID      uuid.UUID
+	Payload map[string]interface{}
+}
+
+// OutboxEntry is a vector upsert queued with its artifact for the relay to retry
+type OutboxEntry struct {
+	ArtifactID uuid.UUID
+	Namespace  string
+	Embedding  []float32
+	QueuedAt   time.Time
+	Attempts   int
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCallbackURLs bounds how many webhook receivers a session may register
+const MaxCallbackURLs = 10
+
+type WebhookEventType string
+
+const (
+	EventStepCompleted     WebhookEventType = "step.completed"
+	EventStepFailed        WebhookEventType = "step.failed"
+	EventSessionCompleted  WebhookEventType = "session.completed"
+	EventSessionFailed     WebhookEventType = "session.failed"
+	EventArtifactPublished WebhookEventType = "artifact.published"
+	EventArtifactStale     WebhookEventType = "artifact.stale"
+)
+
+// Valid reports whether t is an event type subscriptions can ask for
+func (t WebhookEventType) Valid() bool {
+	switch t {
+	case EventStepCompleted, EventStepFailed, EventSessionCompleted, EventSessionFailed,
+		EventArtifactPublished, EventArtifactStale:
+		return true
+	default:
+		return false
+	}
+}
+
+// WebhookEvent is the payload POSTed to a session's callback URLs and to the
+// namespace's webhook subscriptions
+type WebhookEvent struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	Namespace string           `json:"namespace"`
+	SessionID *uuid.UUID       `json:"session_id,omitempty"`
+	Step      *WorkflowStep    `json:"step,omitempty"`
+	Session   *WorkflowSession `json:"session,omitempty"`
+	// ArtifactIDs lists the artifacts an artifact event is about
+	ArtifactIDs []uuid.UUID `json:"artifact_ids,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// WebhookSubscription delivers a namespace's events of EventTypes to URL,
+// signed with the subscription's own secret
+type WebhookSubscription struct {
+	ID         uuid.UUID          `json:"id"`
+	Namespace  string             `json:"namespace"`
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"event_types"`
+	// Secret is only returned when the subscription is created
+	Secret    string    `json:"secret,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookSubscriptionRequest struct {
+	URL        string             `json:"url" binding:"required"`
+	EventTypes []WebhookEventType `json:"event_types" binding:"required"`
+	// Secret is generated when empty on create and kept when empty on update
+	Secret string `json:"secret"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// WebhookDelivery records one attempt to deliver an event to a subscription
+type WebhookDelivery struct {
+	ID             uuid.UUID        `json:"id"`
+	SubscriptionID uuid.UUID        `json:"subscription_id"`
+	EventID        uuid.UUID        `json:"event_id"`
+	EventType      WebhookEventType `json:"event_type"`
+	Attempt        int              `json:"attempt"`
+	// StatusCode is zero when the receiver could not be reached
+	StatusCode int       `json:"status_code,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
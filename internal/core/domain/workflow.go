@@ -8,6 +8,7 @@ import (
 
 type WorkflowStep struct {
 	ID          uuid.UUID              `json:"id"`
+	Namespace   string                 `json:"namespace"`
 	SessionID   uuid.UUID              `json:"session_id"`
 	StepType    string                 `json:"step_type"`
 	ArtifactID  uuid.UUID              `json:"artifact_id"`
@@ -17,6 +18,14 @@ type WorkflowStep struct {
 	CreatedAt   time.Time              `json:"created_at"`
 	CompletedAt *time.Time             `json:"completed_at"`
 	Status      StepStatus             `json:"status"`
+	// TemplateName and TemplateVersion record the workflow definition the step ran under
+	TemplateName    string `json:"template_name,omitempty"`
+	TemplateVersion int    `json:"template_version,omitempty"`
+	// Usage accumulates tokens and cost over all of the step's attempts
+	Usage Usage `json:"usage"`
+	// Forced steps bypassed the cache and supersede earlier results for their input
+	Forced       bool       `json:"forced,omitempty"`
+	SupersededBy *uuid.UUID `json:"superseded_by,omitempty"`
 }
 
 type StepStatus string
@@ -24,18 +33,104 @@ type StepStatus string
 const (
 	StepPending   StepStatus = "pending"
 	StepRunning   StepStatus = "running"
+	StepRetrying  StepStatus = "retrying"
 	StepCompleted StepStatus = "completed"
 	StepFailed    StepStatus = "failed"
+	StepTimedOut  StepStatus = "timed_out"
+	// StepInterrupted steps were cut off by a shutdown and are resumed from the queue
+	StepInterrupted StepStatus = "interrupted"
 )
 
 type WorkflowSession struct {
 	ID        uuid.UUID              `json:"id"`
+	Namespace string                 `json:"namespace"`
 	Goal      string                 `json:"goal"`
 	Context   map[string]interface{} `json:"context"`
-	Steps     []WorkflowStep         `json:"steps"`
-	CreatedAt time.Time              `json:"created_at"`
-	UpdatedAt time.Time              `json:"updated_at"`
-	Status    SessionStatus          `json:"status"`
+	// CallbackURLs receive signed webhook events for the session and its steps
+	CallbackURLs []string       `json:"callback_urls,omitempty"`
+	Steps        []WorkflowStep `json:"steps"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	Status       SessionStatus  `json:"status"`
+	// TemplateName and TemplateVersion pin the session to a workflow definition
+	TemplateName    string `json:"template_name,omitempty"`
+	TemplateVersion int    `json:"template_version,omitempty"`
+	// ParentID is set on forks, which share the parent's steps completed up to ForkedAt
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	ForkedAt *time.Time `json:"forked_at,omitempty"`
+	// Usage totals the usage of the session's own steps, excluding inherited ones
+	Usage Usage `json:"usage"`
+	// Savings estimates what steps served from cache saved the session
+	Savings CacheSavings `json:"savings"`
+}
+
+// CacheSavings compares steps served from cache with steps executed. Saved usage
+// and time are what the reused steps cost when they originally ran
+type CacheSavings struct {
+	CacheHits     int64   `json:"cache_hits"`
+	ExecutedSteps int64   `json:"executed_steps"`
+	HitRate       float64 `json:"hit_rate"`
+	Saved         Usage   `json:"saved"`
+	SavedSeconds  float64 `json:"saved_seconds"`
+}
+
+// Finish derives the hit rate from the counts
+func (s *CacheSavings) Finish() {
+	if total := s.CacheHits + s.ExecutedSteps; total > 0 {
+		s.HitRate = float64(s.CacheHits) / float64(total)
+	}
+}
+
+// SavingsReport totals cache savings over the namespace's sessions created since a day
+type SavingsReport struct {
+	Since    time.Time `json:"since"`
+	Sessions int64     `json:"sessions"`
+	CacheSavings
+}
+
+// ForkSessionRequest overrides parts of the parent session in a fork
+type ForkSessionRequest struct {
+	Goal         string                 `json:"goal"`
+	Context      map[string]interface{} `json:"context"`
+	CallbackURLs []string               `json:"callback_urls"`
+	// UntilStepID shares only parent steps completed no later than this completed step
+	UntilStepID *uuid.UUID `json:"until_step_id"`
+}
+
+type CreateSessionRequest struct {
+	Goal         string                 `json:"goal" binding:"required"`
+	Context      map[string]interface{} `json:"context"`
+	CallbackURLs []string               `json:"callback_urls"`
+	// Template runs the session under a workflow template; TemplateVersion 0 means latest
+	Template        string `json:"template"`
+	TemplateVersion int    `json:"template_version"`
+}
+
+// SessionSearchResult is a past session whose goal resembles the query, with
+// the last ANSWER artifact it produced, if any
+type SessionSearchResult struct {
+	Session *WorkflowSession `json:"session"`
+	Answer  *Artifact        `json:"answer,omitempty"`
+	Score   float32          `json:"score"`
+}
+
+type SessionSearchResponse struct {
+	Results []SessionSearchResult `json:"results"`
+}
+
+// SessionProgress summarises a session's steps for dashboards
+type SessionProgress struct {
+	SessionID  uuid.UUID          `json:"session_id"`
+	Status     SessionStatus      `json:"status"`
+	StepCounts map[StepStatus]int `json:"step_counts"`
+	TotalSteps int                `json:"total_steps"`
+	// ExpectedSteps is the number of step types in the session's template, if it lists any
+	ExpectedSteps   int     `json:"expected_steps,omitempty"`
+	PercentComplete float64 `json:"percent_complete"`
+	ElapsedSeconds  float64 `json:"elapsed_seconds"`
+	// RemainingSteps and EstimatedRemainingSeconds are projected from completed steps
+	RemainingSteps            int      `json:"remaining_steps"`
+	EstimatedRemainingSeconds *float64 `json:"estimated_remaining_seconds,omitempty"`
 }
 
 type SessionStatus string
@@ -46,17 +141,113 @@ const (
 	SessionFailed    SessionStatus = "failed"
 )
 
+type ListSessionsResponse struct {
+	Sessions   []*WorkflowSession `json:"sessions"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
 type WorkflowStepRequest struct {
 	SessionID uuid.UUID              `json:"session_id"`
 	StepType  string                 `json:"step_type"`
 	Input     interface{}            `json:"input"`
 	Metadata  map[string]interface{} `json:"metadata"`
+	// Async enqueues the step for background execution instead of running it inline
+	Async bool `json:"async"`
+	// Timeout bounds each execution attempt, as a duration string such as "30s"
+	Timeout string `json:"timeout,omitempty"`
+	// Force executes fresh and, once it succeeds, supersedes the cached result for this input
+	Force bool `json:"force,omitempty"`
+	// Executor "external" queues the step for an external worker to claim
+	Executor string `json:"executor,omitempty"`
+}
+
+const ExecutorExternal = "external"
+
+// ClaimStepsRequest asks for queued external steps, optionally of given types
+type ClaimStepsRequest struct {
+	StepTypes []string `json:"step_types"`
+	Limit     int      `json:"limit"`
+}
+
+type ClaimStepsResponse struct {
+	Steps []ClaimedStep `json:"steps"`
+}
+
+// ClaimedStep is a step leased to an external executor along with its input
+type ClaimedStep struct {
+	Step    *WorkflowStep `json:"step"`
+	Input   interface{}   `json:"input"`
+	Attempt int           `json:"attempt"`
+	// LeaseToken must be sent back to complete the step
+	LeaseToken uuid.UUID `json:"lease_token"`
+}
+
+// CompleteStepRequest reports the outcome of a claimed step: an artifact on
+// success, or an error message on failure
+type CompleteStepRequest struct {
+	// LeaseToken is the token the step was claimed with
+	LeaseToken uuid.UUID   `json:"lease_token"`
+	Artifact   *StepOutput `json:"artifact"`
+	Error      string      `json:"error"`
+	// ErrorCode classifies Error for retry policies; defaults to internal
+	ErrorCode ErrorCode `json:"error_code"`
+	// Usage reports the tokens and cost the executor spent on the step
+	Usage *Usage `json:"usage"`
+}
+
+// RenewStepLeaseRequest extends the lease an external executor holds on a
+// claimed step, for steps running longer than the lease timeout
+type RenewStepLeaseRequest struct {
+	LeaseToken uuid.UUID `json:"lease_token"`
+}
+
+// StepOutput is the artifact payload produced by a step processor or an
+// external executor
+type StepOutput struct {
+	Type     ArtifactType           `json:"type"`
+	Content  string                 `json:"content"`
+	Metadata map[string]interface{} `json:"metadata"`
 }
 
 type WorkflowStepResponse struct {
 	Step     *WorkflowStep `json:"step"`
 	Artifact *Artifact     `json:"artifact"`
 	Cached   bool          `json:"cached"`
+	Queued   bool          `json:"queued,omitempty"`
+	// KnownFailure is set instead of a step when the same input recently failed
+	KnownFailure *NegativeEntry `json:"known_failure,omitempty"`
+}
+
+// BatchStepRequest executes several steps of one session; steps without
+// unfinished dependencies run concurrently
+type BatchStepRequest struct {
+	SessionID uuid.UUID   `json:"session_id"`
+	Steps     []BatchStep `json:"steps"`
+	// Parallelism lowers the server's concurrency limit for this batch
+	Parallelism int `json:"parallelism"`
+}
+
+type BatchStep struct {
+	WorkflowStepRequest
+	// Key names the step so later steps in the batch can depend on it
+	Key       string   `json:"key"`
+	DependsOn []string `json:"depends_on"`
+}
+
+type BatchStepResponse struct {
+	Results []BatchStepResult `json:"results"`
+}
+
+// BatchStepResult is the outcome of one batch step, in request order
+type BatchStepResult struct {
+	Key    string                `json:"key,omitempty"`
+	Result *WorkflowStepResponse `json:"result,omitempty"`
+	Error  *StepError            `json:"error,omitempty"`
+}
+
+type StepError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
 }
 
 type WorkflowLookupRequest struct {
@@ -64,6 +255,13 @@ type WorkflowLookupRequest struct {
 	StepType  string    `json:"step_type"`
 	Input     interface{} `json:"input"`
 	TopK      int       `json:"top_k"`
+	// StepTypes searches steps of any of these types alongside StepType
+	StepTypes []string `json:"step_types,omitempty"`
+	// AllStepTypes searches steps of every type
+	AllStepTypes bool `json:"all_step_types,omitempty"`
+	// MinScore is the input similarity a step needs to match, DefaultMinScore
+	// when unset; 0 disables the threshold
+	MinScore *float32 `json:"min_score,omitempty"`
 }
 
 type WorkflowLookupResponse struct {
@@ -72,6 +270,7 @@ type WorkflowLookupResponse struct {
 
 type WorkflowStepResult struct {
 	Step     *WorkflowStep `json:"step"`
+	StepType string        `json:"step_type"`
 	Artifact *Artifact     `json:"artifact"`
 	Score    float32       `json:"score"`
 }
\ No newline at end of file
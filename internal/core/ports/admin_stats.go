@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// AdminStatsRepository aggregates table contents; an empty namespace covers all of them
+type AdminStatsRepository interface {
+	ArtifactStats(ctx context.Context, namespace string) ([]domain.ArtifactTypeStats, domain.StorageStats, error)
+	SessionCounts(ctx context.Context, namespace string) (map[domain.SessionStatus]int64, error)
+	StepCounts(ctx context.Context, namespace string) ([]domain.StepTypeStats, error)
+}
+
+type AdminStatsService interface {
+	// Stats reports one namespace, or every namespace when it is empty
+	Stats(ctx context.Context, namespace string) (*domain.AdminStats, error)
+}
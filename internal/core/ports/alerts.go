@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// AlertSender delivers alerts to one kind of target, such as Slack or email
+type AlertSender interface {
+	// Name identifies the target in logs
+	Name() string
+	Send(ctx context.Context, alert *domain.Alert) error
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository interface {
+	Store(ctx context.Context, key *domain.APIKey) error
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	List(ctx context.Context) ([]*domain.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+type APIKeyService interface {
+	Create(ctx context.Context, req *domain.CreateAPIKeyRequest) (*domain.CreateAPIKeyResponse, error)
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
+	List(ctx context.Context) ([]*domain.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+// URLSigner issues and verifies signed, expiring URLs for unauthenticated reads
+type URLSigner interface {
+	Sign(path, namespace string, expires time.Time) string
+	Verify(path string, query url.Values) (string, error)
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore holds artifact content too large to keep in Postgres
+type BlobStore interface {
+	Put(ctx context.Context, key string, content []byte) error
+	// Get streams the object; the caller must close the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
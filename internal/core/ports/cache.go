@@ -2,36 +2,175 @@ package ports
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/anunay/mentis/internal/core/domain"
 	"github.com/google/uuid"
 )
 
+// ArtifactRepository getters return nil without an error when no row matches;
+// services turn that into domain.ErrNotFound
 type ArtifactRepository interface {
+	// Store writes an artifact, queuing its embedding, if any, in the vector
+	// outbox. It joins a transaction started by a Transactor
 	Store(ctx context.Context, artifact *domain.Artifact) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error)
+	// GetMetadataByID returns an artifact without loading its content
+	GetMetadataByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error)
+	// OpenContent streams an artifact's inline content in chunks
+	OpenContent(ctx context.Context, id uuid.UUID) (io.ReadCloser, error)
 	GetByContentHash(ctx context.Context, hash string) (*domain.Artifact, error)
-	List(ctx context.Context, limit, offset int) ([]*domain.Artifact, error)
+	GetBySourceURL(ctx context.Context, sourceURL string) (*domain.Artifact, error)
+	// ListSourceVersions returns the versions of fresh artifacts fetched from the source URLs
+	ListSourceVersions(ctx context.Context, sourceURLs []string) ([]domain.SourceVersion, error)
+	ExistingContentHashes(ctx context.Context, hashes []string) ([]string, error)
+	SearchText(ctx context.Context, query string, limit int, filter domain.TextSearchFilter) ([]domain.TextMatch, error)
+	GetByUpsertKey(ctx context.Context, key, value string) (*domain.Artifact, error)
+	// LockIdentity holds a lock on an upsert key or source URL in the caller's
+	// namespace until the surrounding transaction ends
+	LockIdentity(ctx context.Context, identity string) error
+	// Republish archives the artifact's current content as a version before replacing
+	// it, queuing the new embedding, if any, in the vector outbox. It joins a
+	// transaction started by a Transactor
+	Republish(ctx context.Context, artifact *domain.Artifact) error
+	// ListVersions returns archived versions newest first, below the given version; nil starts at the newest
+	ListVersions(ctx context.Context, artifactID uuid.UUID, limit int, before *int64) ([]*domain.ArtifactVersion, error)
+	GetVersion(ctx context.Context, artifactID uuid.UUID, version int) (*domain.ArtifactVersion, error)
+	List(ctx context.Context, limit int, after *domain.PageKey) ([]*domain.Artifact, error)
 	Update(ctx context.Context, artifact *domain.Artifact) error
+	// Delete soft-deletes an artifact, keeping it restorable until purged
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	GetDeleted(ctx context.Context, id uuid.UUID) (*domain.Artifact, error)
+	ListDeleted(ctx context.Context, limit int, after *domain.PageKey) ([]*domain.Artifact, error)
+	// PurgeDeleted permanently removes artifacts in any namespace deleted before the given time
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) ([]domain.RemovedArtifact, error)
 	StoreDependency(ctx context.Context, parentID, childID uuid.UUID) error
 	GetDependencies(ctx context.Context, artifactID uuid.UUID) ([]uuid.UUID, error)
 	GetDependents(ctx context.Context, artifactID uuid.UUID) ([]uuid.UUID, error)
+	ListLineageEdges(ctx context.Context, ids []uuid.UUID, upstream bool) ([]domain.LineageEdge, error)
+	GetLineageNodes(ctx context.Context, ids []uuid.UUID) ([]*domain.LineageNode, error)
 	MarkStale(ctx context.Context, artifactID uuid.UUID) error
-	MarkStaleBySourceURL(ctx context.Context, sourceURL string) error
+	// MarkStaleBySourceURL marks artifacts fetched from sourceURL stale and returns their IDs
+	MarkStaleBySourceURL(ctx context.Context, sourceURL string) ([]uuid.UUID, error)
+	// MarkStaleOlderThan marks artifacts of a type last published before the given time stale and returns their IDs
+	MarkStaleOlderThan(ctx context.Context, artifactType domain.ArtifactType, before time.Time) ([]uuid.UUID, error)
+	// MarkFresh clears the stale flag of an artifact whose refreshed content was unchanged
+	MarkFresh(ctx context.Context, artifactID uuid.UUID, expiresAt *time.Time, indexed bool) error
+	// ClaimStale locks stale artifacts in any namespace that a refresh hook matches
+	ClaimStale(ctx context.Context, retryBefore time.Time, limit int) ([]*domain.Artifact, error)
+	// ExpireDue marks artifacts in any namespace whose expiry has passed stale and returns their IDs
+	ExpireDue(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+	// RecordAccess adds hits to artifacts in any namespace and updates their last
+	// access, for least-recently-used eviction
+	RecordAccess(ctx context.Context, hits map[uuid.UUID]int64) error
+	ListMostAccessed(ctx context.Context, limit int) ([]*domain.ArtifactUsage, error)
+	SetPinned(ctx context.Context, id uuid.UUID, pinned bool) error
+	// StorageUsage returns the artifact count and content bytes across all namespaces
+	StorageUsage(ctx context.Context) (int64, int64, error)
+	// ListIndexed pages through indexed, undeleted artifacts of every namespace in ID order
+	ListIndexed(ctx context.Context, after uuid.UUID, limit int) ([]domain.ArtifactRef, error)
+	// ExistingIDs returns which of the IDs belong to artifacts in any namespace, deleted or not
+	ExistingIDs(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
+	// EvictLRU deletes up to limit unpinned artifacts in any namespace, least recently accessed first
+	EvictLRU(ctx context.Context, limit int) ([]domain.RemovedArtifact, error)
+}
+
+// VectorOutboxRepository holds vector upserts queued in the same statement as
+// their artifacts until the vector store has them
+type VectorOutboxRepository interface {
+	// Claim returns due entries in any namespace and postpones them by lease so
+	// other relays skip them meanwhile
+	Claim(ctx context.Context, lease time.Duration, limit int) ([]domain.OutboxEntry, error)
+	// Complete removes the entries of the artifacts queued no later than before
+	Complete(ctx context.Context, artifactIDs []uuid.UUID, before time.Time) error
+	// Retry records a failed attempt and schedules the next one
+	Retry(ctx context.Context, artifactID uuid.UUID, retryAt time.Time, reason string) error
+}
+
+// VectorBuffer accumulates vector upserts for bulk jobs and sends them in
+// batches without waiting for the vector store to apply them. Flush sends what
+// is left and waits until everything sent through the buffer is applied
+type VectorBuffer interface {
+	Add(ctx context.Context, point domain.VectorPoint) error
+	Flush(ctx context.Context) error
 }
 
 type VectorRepository interface {
 	Store(ctx context.Context, id uuid.UUID, embedding []float32, metadata map[string]interface{}) error
-	Search(ctx context.Context, query []float32, topK int, minScore float32, filter map[string]interface{}) ([]domain.LookupResult, error)
+	// StoreBatch upserts many vectors, split into requests of the configured batch size
+	StoreBatch(ctx context.Context, points []domain.VectorPoint) error
+	// NewBuffer starts a buffered, non-blocking series of upserts
+	NewBuffer() VectorBuffer
+	Search(ctx context.Context, query []float32, topK int, minScore float32, filter domain.Filter) ([]domain.LookupResult, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	Update(ctx context.Context, id uuid.UUID, embedding []float32, metadata map[string]interface{}) error
+	// SetPayload merges fields into the payload of existing vectors, leaving their embeddings alone
+	SetPayload(ctx context.Context, ids []uuid.UUID, payload map[string]interface{}) error
+	// Scroll pages through all vectors in ID order, starting at offset (uuid.Nil for the
+	// first page); the returned offset starts the next page and is uuid.Nil after the last
+	Scroll(ctx context.Context, offset uuid.UUID, limit int) ([]domain.VectorRecord, uuid.UUID, error)
+	// Existing returns which of the IDs have a vector
+	Existing(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
+	// Ping checks that the vector store is reachable
+	Ping(ctx context.Context) error
+	// Count returns the number of vectors in a namespace, or in all of them when it is empty
+	Count(ctx context.Context, namespace string) (int64, error)
+}
+
+// QueryExpander rephrases a lookup query to widen what it matches
+type QueryExpander interface {
+	Expand(ctx context.Context, query string) ([]string, error)
+}
+
+// ContentScanner detects sensitive data in artifact content before it is cached
+type ContentScanner interface {
+	Policy() domain.PIIPolicy
+	Scan(content []byte) []domain.PIIFinding
+	Redact(content []byte, findings []domain.PIIFinding) []byte
 }
 
 type CacheService interface {
 	Publish(ctx context.Context, artifacts []domain.Artifact) (*domain.PublishResponse, error)
 	Lookup(ctx context.Context, options domain.LookupOptions) (*domain.LookupResponse, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error)
+	// GetMetadata returns an artifact without its content, for callers that stream it with OpenContent
+	GetMetadata(ctx context.Context, id uuid.UUID) (*domain.Artifact, error)
+	List(ctx context.Context, limit int, cursor string) (*domain.ListArtifactsResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
-	Invalidate(ctx context.Context, sourceURL string) error
-}
\ No newline at end of file
+	Restore(ctx context.Context, id uuid.UUID) error
+	GetDeleted(ctx context.Context, id uuid.UUID) (*domain.Artifact, error)
+	ListDeleted(ctx context.Context, limit int, cursor string) (*domain.ListArtifactsResponse, error)
+	Invalidate(ctx context.Context, req *domain.InvalidateRequest) (*domain.InvalidateResponse, error)
+	SetPinned(ctx context.Context, id uuid.UUID, pinned bool) error
+	// OpenContent streams an artifact's content, including content offloaded to object storage
+	OpenContent(ctx context.Context, artifact *domain.Artifact) (io.ReadCloser, error)
+	ListVersions(ctx context.Context, id uuid.UUID, limit int, cursor string) (*domain.ListArtifactVersionsResponse, error)
+	GetVersion(ctx context.Context, id uuid.UUID, version int) (*domain.ArtifactVersion, error)
+	// MostAccessed lists the namespace's most reused artifacts
+	MostAccessed(ctx context.Context, limit int) ([]*domain.ArtifactUsage, error)
+	Lineage(ctx context.Context, id uuid.UUID, direction domain.LineageDirection, depth int) (*domain.ArtifactLineage, error)
+	// SwapContent replaces a stale artifact's content in place, reporting whether it changed
+	SwapContent(ctx context.Context, artifact *domain.Artifact, fresh *domain.RefreshContent) (*domain.Artifact, bool, error)
+	// StoreLLMAnswer and CheckLLMCache cache LLM answers by semantically matched prompt, per model
+	StoreLLMAnswer(ctx context.Context, req *domain.LLMCacheStoreRequest) (*domain.LLMCacheStoreResponse, error)
+	CheckLLMCache(ctx context.Context, req *domain.LLMCacheCheckRequest) (*domain.LLMCacheCheckResponse, error)
+}
+
+// ImportService publishes records exported from other vector stores and RAG
+// frameworks, keeping their embeddings where they fit the configured model
+type ImportService interface {
+	Import(ctx context.Context, records []domain.ImportRecord, options domain.ImportOptions) (*domain.PublishResponse, error)
+}
+
+// Reconciler repairs drift between artifacts and their vectors
+type Reconciler interface {
+	Run(ctx context.Context) *domain.ReconcileReport
+	LastReport() *domain.ReconcileReport
+}
+
+// ArtifactEvictor keeps artifact storage within its configured budget
+type ArtifactEvictor interface {
+	Stats(ctx context.Context) (*domain.EvictionStats, error)
+}
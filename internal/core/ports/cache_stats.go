@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type CacheStatsRepository interface {
+	// Increment adds to today's counters for the caller's namespace
+	Increment(ctx context.Context, kind domain.CacheStatKind, stepType string, hits, misses int64) error
+	// Counts sums counters since the given day, for the caller's namespace or all of them
+	Counts(ctx context.Context, since time.Time, allNamespaces bool) ([]domain.CacheStatCount, error)
+}
+
+type CacheStatsService interface {
+	Stats(ctx context.Context, days int) (*domain.CacheStatsResponse, error)
+	AllStats(ctx context.Context, days int) (*domain.CacheStatsResponse, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type SessionEventRepository interface {
+	Append(ctx context.Context, event *domain.SessionEvent) error
+	// AppendForSourceURL records an event on every session with a step whose artifact came from sourceURL
+	AppendForSourceURL(ctx context.Context, sourceURL string, eventType domain.SessionEventType, data map[string]interface{}) (int64, error)
+	// AppendForTemplate records an event on every session with steps run under templateName before beforeVersion
+	AppendForTemplate(ctx context.Context, templateName string, beforeVersion int, eventType domain.SessionEventType, data map[string]interface{}) (int64, error)
+	// List returns events in sequence order after the given sequence number; nil starts at the first event
+	List(ctx context.Context, sessionID uuid.UUID, limit int, after *int64) ([]*domain.SessionEvent, error)
+}
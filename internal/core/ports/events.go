@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// EventBroker publishes serialized domain events to a message broker
+type EventBroker interface {
+	// Publish sends payload to topic; key groups related events, such as
+	// those of one namespace, where the broker supports it
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+	Close() error
+}
+
+// EventSource consumes messages from a message broker
+type EventSource interface {
+	// Consume calls handle with the payload of each message on topic until ctx
+	// ends, which returns nil, or the connection to the broker fails
+	Consume(ctx context.Context, topic string, handle func(payload []byte)) error
+}
+
+// SourceChangeService invalidates artifacts of sources reported changed
+type SourceChangeService interface {
+	Handle(ctx context.Context, event *domain.SourceChangeEvent) (*domain.InvalidateResponse, error)
+	// VerifySignature authenticates a source change webhook by its signature
+	VerifySignature(timestamp, signature string, body []byte) error
+}
@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type FreshnessSourceRepository interface {
+	Store(ctx context.Context, source *domain.FreshnessSource) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.FreshnessSource, error)
+	List(ctx context.Context) ([]*domain.FreshnessSource, error)
+	Update(ctx context.Context, source *domain.FreshnessSource) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListDue returns up to limit enabled sources in any namespace due for a check
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.FreshnessSource, error)
+	// RecordCheck stores the outcome of a check of a source in any namespace:
+	// its validators, error, invalidation count and next check
+	RecordCheck(ctx context.Context, source *domain.FreshnessSource) error
+}
+
+type FreshnessSourceService interface {
+	Create(ctx context.Context, req *domain.FreshnessSourceRequest) (*domain.FreshnessSource, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.FreshnessSource, error)
+	List(ctx context.Context) ([]*domain.FreshnessSource, error)
+	Update(ctx context.Context, id uuid.UUID, req *domain.FreshnessSourceRequest) (*domain.FreshnessSource, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
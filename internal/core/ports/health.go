@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// HealthChecker reports whether a dependency is reachable
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+type HealthService interface {
+	// Ready checks every dependency concurrently
+	Ready(ctx context.Context) *domain.ReadinessResponse
+}
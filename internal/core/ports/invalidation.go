@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type InvalidationPolicyRepository interface {
+	Store(ctx context.Context, policy *domain.InvalidationPolicy) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.InvalidationPolicy, error)
+	List(ctx context.Context) ([]*domain.InvalidationPolicy, error)
+	Update(ctx context.Context, policy *domain.InvalidationPolicy) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Enforce marks up to limit artifacts in any namespace stale that an enabled
+	// policy considers too old, returning their IDs
+	Enforce(ctx context.Context, limit int) ([]uuid.UUID, error)
+}
+
+type InvalidationPolicyService interface {
+	Create(ctx context.Context, req *domain.InvalidationPolicyRequest) (*domain.InvalidationPolicy, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.InvalidationPolicy, error)
+	List(ctx context.Context) ([]*domain.InvalidationPolicy, error)
+	Update(ctx context.Context, id uuid.UUID, req *domain.InvalidationPolicyRequest) (*domain.InvalidationPolicy, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// LeaseRepository stores the named leases that elect one replica to run each
+// background job
+type LeaseRepository interface {
+	// Acquire takes or renews the lease for ttl and reports whether holder now
+	// has it. A lease held by someone else is only taken once it has expired
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Renew extends the given leases still held by holder and returns their names
+	Renew(ctx context.Context, names []string, holder string, ttl time.Duration) ([]string, error)
+	// Release gives up holder's leases so another replica can take them at once
+	Release(ctx context.Context, names []string, holder string) error
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type NegativeCacheRepository interface {
+	// Put records an entry, replacing any existing one for the same kind and key
+	Put(ctx context.Context, entry *domain.NegativeEntry) error
+	// Get returns the unexpired entry for kind and key, or nil
+	Get(ctx context.Context, kind domain.NegativeKind, key string) (*domain.NegativeEntry, error)
+	Delete(ctx context.Context, kind domain.NegativeKind, key string) error
+	// DeleteKind removes every entry of kind in the caller's namespace
+	DeleteKind(ctx context.Context, kind domain.NegativeKind) error
+}
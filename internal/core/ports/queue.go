@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// TaskQueue is a durable queue shared by every replica. A dequeued task is
+// leased to its consumer; one neither acknowledged nor retried before the lease
+// ends is delivered again, so tasks survive the consumer crashing
+type TaskQueue interface {
+	// Enqueue adds a task that is delivered once runAt has passed
+	Enqueue(ctx context.Context, queue string, payload []byte, runAt time.Time) error
+	// Dequeue leases up to limit due tasks, returning none when nothing is due
+	Dequeue(ctx context.Context, queue string, limit int) ([]*domain.QueuedTask, error)
+	// Ack removes a task once it is done with
+	Ack(ctx context.Context, task *domain.QueuedTask) error
+	// Retry delivers a task again at runAt as its next attempt
+	Retry(ctx context.Context, task *domain.QueuedTask, runAt time.Time) error
+	Close() error
+}
@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type QuotaRepository interface {
+	GetLimits(ctx context.Context, namespace string) (*domain.QuotaLimits, error)
+	SetLimits(ctx context.Context, namespace string, limits *domain.QuotaLimits) error
+	DeleteLimits(ctx context.Context, namespace string) error
+	GetUsage(ctx context.Context, namespace string) (*domain.QuotaUsage, error)
+	// ConsumeEmbeddings records count embeddings unless they would exceed limit,
+	// reporting whether they were recorded; a limit of zero or less is unlimited
+	ConsumeEmbeddings(ctx context.Context, namespace string, count, limit int64) (bool, error)
+	// LockUsage holds the namespace's, and the key's, quota lock until the
+	// surrounding transaction ends
+	LockUsage(ctx context.Context, namespace string, keyID *uuid.UUID) error
+	GetKeyLimits(ctx context.Context, keyID uuid.UUID) (*domain.QuotaLimits, error)
+	SetKeyLimits(ctx context.Context, keyID uuid.UUID, limits *domain.QuotaLimits) error
+	DeleteKeyLimits(ctx context.Context, keyID uuid.UUID) error
+	GetKeyUsage(ctx context.Context, keyID uuid.UUID) (*domain.QuotaUsage, error)
+	ConsumeKeyEmbeddings(ctx context.Context, keyID uuid.UUID, count, limit int64) (bool, error)
+}
+
+type QuotaService interface {
+	CheckArtifacts(ctx context.Context, count, bytes int64) error
+	ConsumeEmbeddings(ctx context.Context, count int64) error
+	Status(ctx context.Context) (*domain.QuotaStatus, error)
+	SetLimits(ctx context.Context, namespace string, limits *domain.QuotaLimits) error
+	ResetLimits(ctx context.Context, namespace string) error
+	SetKeyLimits(ctx context.Context, keyID uuid.UUID, limits *domain.QuotaLimits) error
+	ResetKeyLimits(ctx context.Context, keyID uuid.UUID) error
+}
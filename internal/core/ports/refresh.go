@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type RefreshHookRepository interface {
+	Store(ctx context.Context, hook *domain.RefreshHook) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.RefreshHook, error)
+	List(ctx context.Context) ([]*domain.RefreshHook, error)
+	Update(ctx context.Context, hook *domain.RefreshHook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type RefreshService interface {
+	CreateHook(ctx context.Context, req *domain.RefreshHookRequest) (*domain.RefreshHook, error)
+	GetHook(ctx context.Context, id uuid.UUID) (*domain.RefreshHook, error)
+	ListHooks(ctx context.Context) ([]*domain.RefreshHook, error)
+	UpdateHook(ctx context.Context, id uuid.UUID, req *domain.RefreshHookRequest) (*domain.RefreshHook, error)
+	DeleteHook(ctx context.Context, id uuid.UUID) error
+	// Refresh runs the matching hook for an artifact and swaps in its fresh content
+	Refresh(ctx context.Context, artifactID uuid.UUID) (*domain.RefreshResult, error)
+}
@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type ScheduleRepository interface {
+	Store(ctx context.Context, schedule *domain.WorkflowSchedule) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.WorkflowSchedule, error)
+	List(ctx context.Context) ([]*domain.WorkflowSchedule, error)
+	Update(ctx context.Context, schedule *domain.WorkflowSchedule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListDue returns enabled schedules across all namespaces that are due at now
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.WorkflowSchedule, error)
+	// Advance atomically moves a schedule's next run time, failing if it no longer
+	// equals from; a zero next disables the schedule
+	Advance(ctx context.Context, id uuid.UUID, from, next time.Time) (bool, error)
+	StoreRun(ctx context.Context, run *domain.ScheduleRun) error
+	UpdateRun(ctx context.Context, run *domain.ScheduleRun) error
+	ListRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]*domain.ScheduleRun, error)
+}
+
+type ScheduleService interface {
+	Create(ctx context.Context, req *domain.ScheduleRequest) (*domain.WorkflowSchedule, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.WorkflowSchedule, error)
+	List(ctx context.Context) ([]*domain.WorkflowSchedule, error)
+	Update(ctx context.Context, id uuid.UUID, req *domain.ScheduleRequest) (*domain.WorkflowSchedule, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListRuns(ctx context.Context, id uuid.UUID, limit int) ([]*domain.ScheduleRun, error)
+	// Run executes a schedule once in a new session and records the run
+	Run(ctx context.Context, schedule *domain.WorkflowSchedule) (*domain.ScheduleRun, error)
+}
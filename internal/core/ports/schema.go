@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type StepSchemaRepository interface {
+	Store(ctx context.Context, schema *domain.StepSchema) error
+	Get(ctx context.Context, stepType string) (*domain.StepSchema, error)
+	List(ctx context.Context) ([]*domain.StepSchema, error)
+	Delete(ctx context.Context, stepType string) error
+}
+
+type StepSchemaService interface {
+	Register(ctx context.Context, schema *domain.StepSchema) (*domain.StepSchema, error)
+	Get(ctx context.Context, stepType string) (*domain.StepSchema, error)
+	List(ctx context.Context) ([]*domain.StepSchema, error)
+	Delete(ctx context.Context, stepType string) error
+	ValidateInput(ctx context.Context, stepType string, input interface{}) error
+	ValidateOutput(ctx context.Context, stepType string, content []byte) error
+}
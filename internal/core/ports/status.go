@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type StatusService interface {
+	// Status reports every component, counting failures within window; zero
+	// uses the configured window
+	Status(ctx context.Context, window time.Duration) (*domain.SystemStatus, error)
+}
@@ -0,0 +1,8 @@
+package ports
+
+import "context"
+
+// Transactor makes the repository writes issued through fn's context atomic
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
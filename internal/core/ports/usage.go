@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type UsageRepository interface {
+	// Aggregate recomputes the daily summaries between two dates, inclusive,
+	// returning the number of rows written. Storage is measured as of today
+	Aggregate(ctx context.Context, from, to, today time.Time) (int64, error)
+	// Summaries lists daily summaries between two dates, inclusive, for one
+	// namespace or every namespace when it is empty
+	Summaries(ctx context.Context, from, to time.Time, namespace string) ([]domain.DailyUsage, error)
+}
+
+type UsageService interface {
+	Report(ctx context.Context, from, to time.Time, namespace string) (*domain.UsageReport, error)
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type WebhookSubscriptionRepository interface {
+	Store(ctx context.Context, subscription *domain.WebhookSubscription) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error)
+	List(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	Update(ctx context.Context, subscription *domain.WebhookSubscription) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListForEvent returns the enabled subscriptions of namespace to eventType
+	ListForEvent(ctx context.Context, namespace string, eventType domain.WebhookEventType) ([]*domain.WebhookSubscription, error)
+	// RecordDelivery logs a delivery attempt, keeping the subscription's most recent keep attempts
+	RecordDelivery(ctx context.Context, delivery *domain.WebhookDelivery, keep int) error
+	// ListDeliveries returns a subscription's most recent delivery attempts, newest first
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+type WebhookSubscriptionService interface {
+	Create(ctx context.Context, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscription, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error)
+	List(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	Update(ctx context.Context, id uuid.UUID, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscription, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]*domain.WebhookDelivery, error)
+}
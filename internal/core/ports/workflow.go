@@ -2,30 +2,82 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/anunay/mentis/internal/core/domain"
 	"github.com/google/uuid"
 )
 
+// WorkflowRepository getters return nil without an error when no row matches;
+// services turn that into domain.ErrNotFound
 type WorkflowRepository interface {
 	StoreSession(ctx context.Context, session *domain.WorkflowSession) error
 	GetSession(ctx context.Context, id uuid.UUID) (*domain.WorkflowSession, error)
 	UpdateSession(ctx context.Context, session *domain.WorkflowSession) error
+	ListSessions(ctx context.Context, limit int, after *domain.PageKey) ([]*domain.WorkflowSession, error)
 	StoreStep(ctx context.Context, step *domain.WorkflowStep) error
 	GetStep(ctx context.Context, id uuid.UUID) (*domain.WorkflowStep, error)
+	// UpdateStep joins a transaction started by a Transactor
 	UpdateStep(ctx context.Context, step *domain.WorkflowStep) error
+	// HeartbeatStep records that the step's inline execution is still running
+	HeartbeatStep(ctx context.Context, id uuid.UUID) error
+	// FailAbandonedSteps fails unfinished steps in any namespace last heartbeated,
+	// or created, before the given time that no job will resume, such as inline
+	// steps of a crashed instance
+	FailAbandonedSteps(ctx context.Context, before time.Time) ([]*domain.WorkflowStep, error)
 	GetStepsBySession(ctx context.Context, sessionID uuid.UUID) ([]*domain.WorkflowStep, error)
-	FindStepByInputHash(ctx context.Context, stepType, inputHash string) (*domain.WorkflowStep, error)
-	FindSimilarSteps(ctx context.Context, stepType string, embedding []float32, topK int) ([]domain.WorkflowStepResult, error)
+	// FindStepByInputHash finds the latest reusable completed step matching filter
+	FindStepByInputHash(ctx context.Context, stepType, inputHash string, filter domain.StepReuseFilter) (*domain.WorkflowStep, error)
+	// FindStepsByInputHashes finds the latest reusable completed step for each input hash, keyed by hash
+	FindStepsByInputHashes(ctx context.Context, stepType string, inputHashes []string, filter domain.StepReuseFilter) (map[string]*domain.WorkflowStep, error)
+	// SupersedeSteps hides earlier completed steps with the same type and input,
+	// among those the filter admits in the caller's namespace, from the cache;
+	// it joins a transaction started by a Transactor
+	SupersedeSteps(ctx context.Context, step *domain.WorkflowStep, filter domain.StepReuseFilter) (int64, error)
+	// RecordStepReuse adds a cache hit and what it saved to the session's totals
+	RecordStepReuse(ctx context.Context, sessionID uuid.UUID, saved domain.Usage, savedTime time.Duration) error
+	// Savings totals cache savings over the caller's sessions created since the given time
+	Savings(ctx context.Context, since time.Time) (*domain.SavingsReport, error)
+	// MarkTemplateArtifactsStale marks artifacts of steps run under template versions before version stale
+	MarkTemplateArtifactsStale(ctx context.Context, templateName string, beforeVersion int) (int64, error)
 }
 
 type WorkflowService interface {
-	CreateSession(ctx context.Context, goal string, context map[string]interface{}) (*domain.WorkflowSession, error)
+	CreateSession(ctx context.Context, req *domain.CreateSessionRequest) (*domain.WorkflowSession, error)
 	GetSession(ctx context.Context, id uuid.UUID) (*domain.WorkflowSession, error)
+	ForkSession(ctx context.Context, parentID uuid.UUID, req *domain.ForkSessionRequest) (*domain.WorkflowSession, error)
+	ListSessions(ctx context.Context, limit int, cursor string) (*domain.ListSessionsResponse, error)
+	SearchSessions(ctx context.Context, query string, topK int, minScore float32) (*domain.SessionSearchResponse, error)
+	GetSessionProgress(ctx context.Context, id uuid.UUID) (*domain.SessionProgress, error)
+	GetSavings(ctx context.Context, days int) (*domain.SavingsReport, error)
+	ListSessionEvents(ctx context.Context, sessionID uuid.UUID, limit int, cursor string) (*domain.ListSessionEventsResponse, error)
 	ExecuteStep(ctx context.Context, req *domain.WorkflowStepRequest) (*domain.WorkflowStepResponse, error)
+	ExecuteSteps(ctx context.Context, req *domain.BatchStepRequest) (*domain.BatchStepResponse, error)
+	GetStep(ctx context.Context, id uuid.UUID) (*domain.WorkflowStepResponse, error)
+	RunJob(ctx context.Context, job *domain.StepJob) error
+	// RecoverAbandonedSteps fails steps left unfinished longer than age with no
+	// job to resume them, and returns how many it failed
+	RecoverAbandonedSteps(ctx context.Context, age time.Duration) (int, error)
+	ClaimSteps(ctx context.Context, req *domain.ClaimStepsRequest) (*domain.ClaimStepsResponse, error)
+	CompleteStep(ctx context.Context, stepID uuid.UUID, req *domain.CompleteStepRequest) (*domain.WorkflowStepResponse, error)
+	// RenewStepLease extends an external executor's lease on a claimed step
+	RenewStepLease(ctx context.Context, stepID uuid.UUID, req *domain.RenewStepLeaseRequest) error
 	LookupStep(ctx context.Context, req *domain.WorkflowLookupRequest) (*domain.WorkflowLookupResponse, error)
 	CompleteSession(ctx context.Context, sessionID uuid.UUID) error
 	FailSession(ctx context.Context, sessionID uuid.UUID, reason string) error
+	PublishTemplate(ctx context.Context, req *domain.PublishTemplateRequest) (*domain.PublishTemplateResponse, error)
+	GetTemplate(ctx context.Context, name string, version int) (*domain.WorkflowTemplate, error)
+	ListTemplates(ctx context.Context) ([]*domain.WorkflowTemplate, error)
+	ListTemplateVersions(ctx context.Context, name string) ([]*domain.WorkflowTemplate, error)
+}
+
+// TemplateRepository stores immutable workflow template versions
+type TemplateRepository interface {
+	Store(ctx context.Context, template *domain.WorkflowTemplate) error
+	// Get returns the given version of a template, or the latest when version is 0
+	Get(ctx context.Context, name string, version int) (*domain.WorkflowTemplate, error)
+	ListLatest(ctx context.Context) ([]*domain.WorkflowTemplate, error)
+	ListVersions(ctx context.Context, name string) ([]*domain.WorkflowTemplate, error)
 }
 
 type EmbeddingService interface {
@@ -33,7 +85,45 @@ type EmbeddingService interface {
 	GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
 }
 
+// EmbeddingMonitor reports the health of embedding providers
+type EmbeddingMonitor interface {
+	ProviderStats() []domain.EmbeddingProviderStats
+}
+
 type HashService interface {
 	ComputeContentHash(content []byte) string
 	ComputeInputHash(input interface{}) string
-}
\ No newline at end of file
+}
+
+// WebhookNotifier delivers events in the background: to the given callback URLs
+// and the subscriptions of the event's namespace, or to a message broker
+type WebhookNotifier interface {
+	Notify(urls []string, event *domain.WebhookEvent)
+}
+
+// JobRepository persists the queue of asynchronous step executions
+type JobRepository interface {
+	// Enqueue joins a transaction started by a Transactor
+	Enqueue(ctx context.Context, job *domain.StepJob) error
+	// Claim locks due jobs for the internal worker pool
+	Claim(ctx context.Context, limit int) ([]*domain.StepJob, error)
+	// ClaimByID locks the given jobs that are still queued and due, for workers fed by a task queue
+	ClaimByID(ctx context.Context, ids []uuid.UUID) ([]*domain.StepJob, error)
+	// ClaimExternal locks due external jobs of the caller's namespace, optionally limited to step types
+	ClaimExternal(ctx context.Context, limit int, stepTypes []string) ([]*domain.StepJob, error)
+	// GetRunningByStep returns the claimed job of a step, or nil if the step isn't claimed
+	GetRunningByStep(ctx context.Context, stepID uuid.UUID) (*domain.StepJob, error)
+	// Complete, Fail, Retry, and Heartbeat take the token of the lease the job
+	// was claimed under and return a conflict once that lease is lost
+	Complete(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID) error
+	Fail(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID, reason string) error
+	Retry(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID, runAt time.Time, reason string) error
+	// Heartbeat renews the lease of a running job so it isn't requeued while it executes
+	Heartbeat(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID) error
+	RequeueExpired(ctx context.Context, lease time.Duration) (int64, error)
+}
+
+// StepProcessor executes the steps of one step type
+type StepProcessor interface {
+	Process(ctx context.Context, step *domain.WorkflowStep, input interface{}) (*domain.StepOutput, error)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// AccessTracker buffers artifact hits in memory and flushes them in one write
+// per interval, so busy artifacts are not updated on every read. Hits still
+// buffered when the process dies are lost
+type AccessTracker struct {
+	artifactRepo ports.ArtifactRepository
+	interval     time.Duration
+	maxPending   int
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]int64
+	full    chan struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewAccessTracker(artifactRepo ports.ArtifactRepository, cfg config.AccessConfig) *AccessTracker {
+	return &AccessTracker{
+		artifactRepo: artifactRepo,
+		interval:     cfg.FlushInterval,
+		maxPending:   cfg.MaxPending,
+		pending:      make(map[uuid.UUID]int64),
+		full:         make(chan struct{}, 1),
+	}
+}
+
+// Record counts a hit on each artifact
+func (t *AccessTracker) Record(ids ...uuid.UUID) {
+	if len(ids) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	for _, id := range ids {
+		t.pending[id]++
+	}
+	full := len(t.pending) >= t.maxPending
+	t.mu.Unlock()
+
+	// Flush early rather than let the buffer grow without bound
+	if full {
+		select {
+		case t.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t *AccessTracker) Start(ctx context.Context) {
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	status.Track(accessTrackerComponent)
+	t.wg.Add(1)
+	go t.loop(ctx)
+
+	logrus.WithField("interval", t.interval).Info("Access tracker started")
+}
+
+// Stop flushes the hits still buffered
+func (t *AccessTracker) Stop() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	t.flush(context.Background())
+	logrus.Info("Access tracker stopped")
+}
+
+func (t *AccessTracker) loop(ctx context.Context) {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-t.full:
+		}
+		t.flush(ctx)
+	}
+}
+
+func (t *AccessTracker) flush(ctx context.Context) {
+	t.mu.Lock()
+	hits := t.pending
+	t.pending = make(map[uuid.UUID]int64)
+	t.mu.Unlock()
+
+	if len(hits) == 0 {
+		return
+	}
+
+	err := t.artifactRepo.RecordAccess(ctx, hits)
+	status.RecordRun(ctx, accessTrackerComponent, err)
+	if err != nil {
+		logrus.WithError(err).WithField("artifacts", len(hits)).Warn("Failed to record artifact access")
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+)
+
+// AdminStatsService gathers cache and workflow totals for operator dashboards
+type AdminStatsService struct {
+	repo       ports.AdminStatsRepository
+	vectorRepo ports.VectorRepository
+}
+
+func NewAdminStatsService(repo ports.AdminStatsRepository, vectorRepo ports.VectorRepository) *AdminStatsService {
+	return &AdminStatsService{
+		repo:       repo,
+		vectorRepo: vectorRepo,
+	}
+}
+
+func (s *AdminStatsService) Stats(ctx context.Context, namespace string) (*domain.AdminStats, error) {
+	artifacts, storage, err := s.repo.ArtifactStats(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count artifacts: %w", err)
+	}
+	sessions, err := s.repo.SessionCounts(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sessions: %w", err)
+	}
+	steps, err := s.repo.StepCounts(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count steps: %w", err)
+	}
+	points, err := s.vectorRepo.Count(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	stepsByStatus := make(map[domain.StepStatus]int64)
+	for _, typeStats := range steps {
+		for status, count := range typeStats.ByStatus {
+			stepsByStatus[status] += count
+		}
+	}
+
+	return &domain.AdminStats{
+		Namespace:     namespace,
+		Artifacts:     artifacts,
+		Sessions:      sessions,
+		StepsByStatus: stepsByStatus,
+		StepsByType:   steps,
+		Storage:       storage,
+		VectorPoints:  points,
+		GeneratedAt:   time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// Alerter sends operators alerts of failed sessions, of step types failing
+// repeatedly, and of dependency outages to Slack and/or email. It receives
+// workflow events as one of the event notifiers. Alerts are sent in the
+// background; a repeat of an alert within the cooldown is suppressed and
+// counted on the next one sent
+type Alerter struct {
+	senders []ports.AlertSender
+	cfg     config.AlertsConfig
+	alerts  chan *domain.Alert
+
+	mu sync.Mutex
+	// stepFailures holds recent failure times per namespace and step type
+	stepFailures map[string][]time.Time
+	// lastSent and suppressed are per alert key
+	lastSent   map[string]time.Time
+	suppressed map[string]int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewAlerter(senders []ports.AlertSender, cfg config.AlertsConfig) *Alerter {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	return &Alerter{
+		senders:      senders,
+		cfg:          cfg,
+		alerts:       make(chan *domain.Alert, bufferSize),
+		stepFailures: make(map[string][]time.Time),
+		lastSent:     make(map[string]time.Time),
+		suppressed:   make(map[string]int),
+	}
+}
+
+func (a *Alerter) Start(ctx context.Context) {
+	ctx, a.cancel = context.WithCancel(ctx)
+
+	a.wg.Add(1)
+	go a.loop(ctx)
+
+	names := make([]string, len(a.senders))
+	for i, sender := range a.senders {
+		names[i] = sender.Name()
+	}
+	logrus.WithField("targets", names).Info("Alerter started")
+}
+
+// Stop sends the alerts still buffered, within a grace period
+func (a *Alerter) Stop() {
+	if a.cancel == nil {
+		return
+	}
+	a.cancel()
+	a.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	for ctx.Err() == nil {
+		select {
+		case alert := <-a.alerts:
+			a.send(ctx, alert)
+		default:
+			logrus.Info("Alerter stopped")
+			return
+		}
+	}
+}
+
+// Notify raises alerts for failed sessions and for step types whose failures
+// within the window reach the threshold
+func (a *Alerter) Notify(_ []string, event *domain.WebhookEvent) {
+	switch {
+	case event.Type == domain.EventSessionFailed && a.cfg.SessionFailures && event.Session != nil:
+		session := event.Session
+		reason, _ := session.Context["failure_reason"].(string)
+		a.Raise("session_failed:"+event.Namespace, &domain.Alert{
+			Kind:    domain.AlertSessionFailed,
+			Title:   "Workflow session failed",
+			Message: fmt.Sprintf("Session %s in namespace %s failed", session.ID, event.Namespace),
+			Fields: []domain.AlertField{
+				{Name: "Goal", Value: session.Goal},
+				{Name: "Reason", Value: reason},
+			},
+		})
+	case event.Type == domain.EventStepFailed && a.cfg.StepFailureThreshold > 0 && event.Step != nil:
+		a.recordStepFailure(event.Namespace, event.Step)
+	}
+}
+
+// recordStepFailure raises an alert once failures of the step's type reach
+// the threshold within the window, then counts afresh
+func (a *Alerter) recordStepFailure(namespace string, step *domain.WorkflowStep) {
+	key := namespace + "/" + step.StepType
+	now := time.Now()
+
+	a.mu.Lock()
+	failures := a.stepFailures[key][:0]
+	for _, failedAt := range a.stepFailures[key] {
+		if now.Sub(failedAt) < a.cfg.StepFailureWindow {
+			failures = append(failures, failedAt)
+		}
+	}
+	failures = append(failures, now)
+	count := len(failures)
+	if count >= a.cfg.StepFailureThreshold {
+		delete(a.stepFailures, key)
+	} else {
+		a.stepFailures[key] = failures
+	}
+	a.mu.Unlock()
+
+	if count < a.cfg.StepFailureThreshold {
+		return
+	}
+	a.Raise("step_failures:"+key, &domain.Alert{
+		Kind:  domain.AlertStepFailures,
+		Title: fmt.Sprintf("Repeated %s step failures", step.StepType),
+		Message: fmt.Sprintf("%d %s steps failed in namespace %s within %s",
+			count, step.StepType, namespace, a.cfg.StepFailureWindow),
+		Fields: []domain.AlertField{
+			{Name: "Last step", Value: step.ID.String()},
+			{Name: "Last error", Value: lastAttemptError(step)},
+		},
+	})
+}
+
+// Raise queues an alert unless one with the same key was sent within the
+// cooldown. It never blocks; alerts beyond the buffer are dropped
+func (a *Alerter) Raise(key string, alert *domain.Alert) {
+	now := time.Now()
+
+	a.mu.Lock()
+	if sent, ok := a.lastSent[key]; ok && now.Sub(sent) < a.cfg.Cooldown {
+		a.suppressed[key]++
+		a.mu.Unlock()
+		return
+	}
+	a.lastSent[key] = now
+	if suppressed := a.suppressed[key]; suppressed > 0 {
+		alert.Fields = append(alert.Fields, domain.AlertField{Name: "Suppressed since last alert", Value: strconv.Itoa(suppressed)})
+		delete(a.suppressed, key)
+	}
+	a.mu.Unlock()
+
+	alert.CreatedAt = now
+	select {
+	case a.alerts <- alert:
+	default:
+		logrus.WithField("alert", alert.Kind).Warn("Alert buffer full, dropping alert")
+	}
+}
+
+// Resolve ends the cooldown of key, so the next alert with it is sent at once
+func (a *Alerter) Resolve(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.lastSent, key)
+	delete(a.suppressed, key)
+}
+
+func (a *Alerter) loop(ctx context.Context) {
+	defer a.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alert := <-a.alerts:
+			// Shutdown doesn't cut a send short; Stop waits for it
+			a.send(context.WithoutCancel(ctx), alert)
+		}
+	}
+}
+
+// send delivers an alert to every target; a failing target doesn't keep it from the others
+func (a *Alerter) send(ctx context.Context, alert *domain.Alert) {
+	for _, sender := range a.senders {
+		sendCtx, cancel := context.WithTimeout(ctx, a.cfg.Timeout)
+		err := sender.Send(sendCtx, alert)
+		cancel()
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"alert":  alert.Kind,
+				"target": sender.Name(),
+			}).Warn("Failed to send alert")
+		}
+	}
+}
+
+// lastAttemptError returns the error of the step's latest attempt
+func lastAttemptError(step *domain.WorkflowStep) string {
+	attempts := stepAttempts(step)
+	if len(attempts) == 0 {
+		return ""
+	}
+	switch attempt := attempts[len(attempts)-1].(type) {
+	case domain.StepAttempt:
+		return attempt.Error
+	case map[string]interface{}:
+		message, _ := attempt["error"].(string)
+		return message
+	default:
+		return ""
+	}
+}
+
+// DependencyMonitor alerts when a dependency, such as Postgres, the vector
+// store or the embedding provider, goes down and again when it recovers. One
+// replica at a time watches, so an outage is reported once
+type DependencyMonitor struct {
+	alerter       *Alerter
+	statusService ports.StatusService
+	leader        *LeaderElector
+	interval      time.Duration
+
+	// down holds the dependencies last seen down, with their error
+	down map[string]string
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDependencyMonitor(alerter *Alerter, statusService ports.StatusService, leader *LeaderElector, cfg config.AlertsConfig) *DependencyMonitor {
+	return &DependencyMonitor{
+		alerter:       alerter,
+		statusService: statusService,
+		leader:        leader,
+		interval:      cfg.DependencyInterval,
+		down:          make(map[string]string),
+	}
+}
+
+func (m *DependencyMonitor) Start(ctx context.Context) {
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	status.Track(dependencyMonitorComponent)
+	m.wg.Add(1)
+	go m.loop(ctx)
+
+	logrus.WithField("interval", m.interval).Info("Dependency monitor started")
+}
+
+func (m *DependencyMonitor) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+	logrus.Info("Dependency monitor stopped")
+}
+
+func (m *DependencyMonitor) loop(ctx context.Context) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if m.leader.Leads(ctx, dependencyMonitorComponent) {
+			status.RecordRun(ctx, dependencyMonitorComponent, m.check(ctx))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check compares each dependency's state with the last check, alerting on changes
+func (m *DependencyMonitor) check(ctx context.Context) error {
+	report, err := m.statusService.Status(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, component := range report.Components {
+		if component.Kind != domain.ComponentDependency || ctx.Err() != nil {
+			continue
+		}
+		key := "dependency:" + component.Name
+		lastError, wasDown := m.down[component.Name]
+
+		switch {
+		case component.State == domain.HealthDown && !wasDown:
+			m.down[component.Name] = component.LastError
+			m.alerter.Raise(key, &domain.Alert{
+				Kind:    domain.AlertDependencyDown,
+				Title:   fmt.Sprintf("Dependency down: %s", component.Name),
+				Message: fmt.Sprintf("%s is unreachable; requests depending on it are failing", component.Name),
+				Fields:  []domain.AlertField{{Name: "Error", Value: component.LastError}},
+			})
+		case component.State != domain.HealthDown && wasDown:
+			delete(m.down, component.Name)
+			m.alerter.Resolve(key)
+			m.alerter.Raise(key+":recovered", &domain.Alert{
+				Kind:    domain.AlertDependencyRecovered,
+				Title:   fmt.Sprintf("Dependency recovered: %s", component.Name),
+				Message: fmt.Sprintf("%s is reachable again", component.Name),
+				Fields:  []domain.AlertField{{Name: "Last error", Value: lastError}},
+			})
+		}
+	}
+	return nil
+}
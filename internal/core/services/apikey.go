@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+const apiKeyPrefix = "mk_"
+
+type APIKeyService struct {
+	apiKeyRepo  ports.APIKeyRepository
+	hashService ports.HashService
+}
+
+func NewAPIKeyService(apiKeyRepo ports.APIKeyRepository, hashService ports.HashService) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		hashService: hashService,
+	}
+}
+
+func (s *APIKeyService) Create(ctx context.Context, req *domain.CreateAPIKeyRequest) (*domain.CreateAPIKeyResponse, error) {
+	if len(req.Namespaces) == 0 {
+		return nil, domain.NewValidationError("at least one namespace is required")
+	}
+	if len(req.Operations) == 0 {
+		return nil, domain.NewValidationError("at least one operation is required")
+	}
+	for _, op := range req.Operations {
+		if !domain.IsValidOperation(op) {
+			return nil, domain.NewValidationError("unknown operation").WithDetail("operation", op)
+		}
+	}
+
+	key := &domain.APIKey{
+		ID:            uuid.New(),
+		Name:          req.Name,
+		Namespaces:    req.Namespaces,
+		ArtifactTypes: req.ArtifactTypes,
+		Operations:    req.Operations,
+		CreatedAt:     time.Now(),
+		ExpiresAt:     req.ExpiresAt,
+	}
+	if !manages(ctx, key) {
+		return nil, &domain.Error{
+			Code:    domain.CodeForbidden,
+			Message: "API key scopes exceed the caller's own",
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	plaintext := apiKeyPrefix + hex.EncodeToString(secret)
+	key.Prefix = plaintext[:len(apiKeyPrefix)+8]
+	key.KeyHash = s.hashService.ComputeContentHash([]byte(plaintext))
+
+	if err := s.apiKeyRepo.Store(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store api key: %w", err)
+	}
+
+	return &domain.CreateAPIKeyResponse{
+		Key:    plaintext,
+		APIKey: key,
+	}, nil
+}
+
+// Authenticate returns the active key matching the plaintext key, or nil if none matches
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByHash(ctx, s.hashService.ComputeContentHash([]byte(key)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up api key: %w", err)
+	}
+
+	if apiKey == nil || apiKey.RevokedAt != nil {
+		return nil, nil
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, nil
+	}
+
+	return apiKey, nil
+}
+
+// List returns the keys the caller manages
+func (s *APIKeyService) List(ctx context.Context) ([]*domain.APIKey, error) {
+	keys, err := s.apiKeyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make([]*domain.APIKey, 0, len(keys))
+	for _, key := range keys {
+		if manages(ctx, key) {
+			managed = append(managed, key)
+		}
+	}
+	return managed, nil
+}
+
+// Revoke revokes a key the caller manages. Other keys are reported as not found
+func (s *APIKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	key, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to look up api key: %w", err)
+	}
+	if key == nil || !manages(ctx, key) {
+		return domain.NewNotFoundError("api key", id)
+	}
+	return s.apiKeyRepo.Revoke(ctx, id)
+}
+
+// manages reports whether the caller's scopes cover key's
+func manages(ctx context.Context, key *domain.APIKey) bool {
+	principal := domain.PrincipalFromContext(ctx)
+	return principal == nil || principal.Covers(key)
+}
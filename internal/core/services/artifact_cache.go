@@ -0,0 +1,219 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+// CachedArtifactRepository serves GetByID from an in-memory LRU in front of the
+// artifact repository, since lookups enrich every result by ID. Writes made
+// through it drop the artifacts they touch. Changes made by other instances, or
+// by statements outside the artifact repository such as template publishes
+// and invalidation policies, are only bounded by the TTL. Hit counts and last
+// access times of cached copies lag by up to the TTL as well
+type CachedArtifactRepository struct {
+	ports.ArtifactRepository
+
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[uuid.UUID]*list.Element
+}
+
+type artifactCacheEntry struct {
+	artifact  *domain.Artifact
+	expiresAt time.Time
+}
+
+// NewCachedArtifactRepository wraps repo; a TTL of zero returns repo unchanged
+func NewCachedArtifactRepository(repo ports.ArtifactRepository, cfg config.ArtifactCacheConfig) ports.ArtifactRepository {
+	if cfg.TTL <= 0 || cfg.MaxEntries <= 0 {
+		return repo
+	}
+	return &CachedArtifactRepository{
+		ArtifactRepository: repo,
+		ttl:                cfg.TTL,
+		maxEntries:         cfg.MaxEntries,
+		order:              list.New(),
+		entries:            make(map[uuid.UUID]*list.Element),
+	}
+}
+
+func (r *CachedArtifactRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	namespace := domain.NamespaceFromContext(ctx)
+	if artifact := r.get(id, namespace); artifact != nil {
+		return artifact, nil
+	}
+
+	artifact, err := r.ArtifactRepository.GetByID(ctx, id)
+	if err != nil || artifact == nil {
+		return artifact, err
+	}
+	r.put(artifact)
+	return cloneArtifact(artifact), nil
+}
+
+// GetMetadataByID serves a cached artifact without its content, and otherwise
+// leaves the fetch to the repository uncached
+func (r *CachedArtifactRepository) GetMetadataByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	if artifact := r.get(id, domain.NamespaceFromContext(ctx)); artifact != nil {
+		if artifact.ContentRef == "" {
+			artifact.ContentSize = int64(len(artifact.Content))
+		}
+		artifact.Content = nil
+		return artifact, nil
+	}
+	return r.ArtifactRepository.GetMetadataByID(ctx, id)
+}
+
+// get returns a copy of the cached artifact, or nil when it is missing,
+// expired, or belongs to another namespace
+func (r *CachedArtifactRepository) get(id uuid.UUID, namespace string) *domain.Artifact {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[id]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*artifactCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		r.order.Remove(element)
+		delete(r.entries, id)
+		return nil
+	}
+	if entry.artifact.Namespace != namespace {
+		return nil
+	}
+	r.order.MoveToFront(element)
+	return cloneArtifact(entry.artifact)
+}
+
+func (r *CachedArtifactRepository) put(artifact *domain.Artifact) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := &artifactCacheEntry{artifact: cloneArtifact(artifact), expiresAt: time.Now().Add(r.ttl)}
+	if element, ok := r.entries[artifact.ID]; ok {
+		element.Value = entry
+		r.order.MoveToFront(element)
+		return
+	}
+	r.entries[artifact.ID] = r.order.PushFront(entry)
+	for r.order.Len() > r.maxEntries {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*artifactCacheEntry).artifact.ID)
+	}
+}
+
+// Forget drops cached copies of the given artifacts
+func (r *CachedArtifactRepository) Forget(ids ...uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		if element, ok := r.entries[id]; ok {
+			r.order.Remove(element)
+			delete(r.entries, id)
+		}
+	}
+}
+
+func (r *CachedArtifactRepository) forgetRemoved(removed []domain.RemovedArtifact) {
+	for _, artifact := range removed {
+		r.Forget(artifact.ID)
+	}
+}
+
+func (r *CachedArtifactRepository) Store(ctx context.Context, artifact *domain.Artifact) error {
+	defer r.Forget(artifact.ID)
+	return r.ArtifactRepository.Store(ctx, artifact)
+}
+
+func (r *CachedArtifactRepository) Republish(ctx context.Context, artifact *domain.Artifact) error {
+	defer r.Forget(artifact.ID)
+	return r.ArtifactRepository.Republish(ctx, artifact)
+}
+
+func (r *CachedArtifactRepository) Update(ctx context.Context, artifact *domain.Artifact) error {
+	defer r.Forget(artifact.ID)
+	return r.ArtifactRepository.Update(ctx, artifact)
+}
+
+func (r *CachedArtifactRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer r.Forget(id)
+	return r.ArtifactRepository.Delete(ctx, id)
+}
+
+func (r *CachedArtifactRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	defer r.Forget(id)
+	return r.ArtifactRepository.Restore(ctx, id)
+}
+
+func (r *CachedArtifactRepository) SetPinned(ctx context.Context, id uuid.UUID, pinned bool) error {
+	defer r.Forget(id)
+	return r.ArtifactRepository.SetPinned(ctx, id, pinned)
+}
+
+func (r *CachedArtifactRepository) MarkStale(ctx context.Context, artifactID uuid.UUID) error {
+	defer r.Forget(artifactID)
+	return r.ArtifactRepository.MarkStale(ctx, artifactID)
+}
+
+func (r *CachedArtifactRepository) MarkFresh(ctx context.Context, artifactID uuid.UUID, expiresAt *time.Time, indexed bool) error {
+	defer r.Forget(artifactID)
+	return r.ArtifactRepository.MarkFresh(ctx, artifactID, expiresAt, indexed)
+}
+
+func (r *CachedArtifactRepository) MarkStaleBySourceURL(ctx context.Context, sourceURL string) ([]uuid.UUID, error) {
+	ids, err := r.ArtifactRepository.MarkStaleBySourceURL(ctx, sourceURL)
+	r.Forget(ids...)
+	return ids, err
+}
+
+func (r *CachedArtifactRepository) MarkStaleOlderThan(ctx context.Context, artifactType domain.ArtifactType, before time.Time) ([]uuid.UUID, error) {
+	ids, err := r.ArtifactRepository.MarkStaleOlderThan(ctx, artifactType, before)
+	r.Forget(ids...)
+	return ids, err
+}
+
+func (r *CachedArtifactRepository) ExpireDue(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	ids, err := r.ArtifactRepository.ExpireDue(ctx, now, limit)
+	r.Forget(ids...)
+	return ids, err
+}
+
+func (r *CachedArtifactRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) ([]domain.RemovedArtifact, error) {
+	removed, err := r.ArtifactRepository.PurgeDeleted(ctx, before, limit)
+	r.forgetRemoved(removed)
+	return removed, err
+}
+
+func (r *CachedArtifactRepository) EvictLRU(ctx context.Context, limit int) ([]domain.RemovedArtifact, error) {
+	removed, err := r.ArtifactRepository.EvictLRU(ctx, limit)
+	r.forgetRemoved(removed)
+	return removed, err
+}
+
+// cloneArtifact copies an artifact so callers can change what they get back,
+// as lookups do when they drop content. Content and embeddings are replaced
+// rather than modified in place, so their backing arrays are shared
+func cloneArtifact(artifact *domain.Artifact) *domain.Artifact {
+	clone := *artifact
+	clone.Metadata = maps.Clone(artifact.Metadata)
+	clone.Tags = slices.Clone(artifact.Tags)
+	clone.Dependencies = slices.Clone(artifact.Dependencies)
+	return &clone
+}
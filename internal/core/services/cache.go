@@ -1,188 +1,1227 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
+	"github.com/anunay/mentis/internal/config"
 	"github.com/anunay/mentis/internal/core/domain"
 	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/logging"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 type CacheService struct {
-	artifactRepo ports.ArtifactRepository
-	vectorRepo   ports.VectorRepository
-	hashService  ports.HashService
+	artifactRepo     ports.ArtifactRepository
+	vectorRepo       ports.VectorRepository
+	embeddingService ports.EmbeddingService
+	hashService      ports.HashService
+	contentScanner   ports.ContentScanner
+	quotaService     ports.QuotaService
+	transactor       ports.Transactor
+	eventRepo        ports.SessionEventRepository
+	webhooks         ports.WebhookNotifier
+	artifactTTLs     *ArtifactTTLs
+	negativeCache    *NegativeCache
+	cacheStats       *CacheStatsService
+	accessTracker    *AccessTracker
+	blobStore        ports.BlobStore
+	blobThreshold    int64
+	chunking         config.ChunkingConfig
+	embedBatchSize   int
+	queryExpander    ports.QueryExpander
+	lookupCache      *LookupCache
+	lookupLimits     config.LookupConfig
+
+	// inflight stores concurrent publishes of the same content once
+	inflight singleflight.Group
 }
 
 func NewCacheService(
 	artifactRepo ports.ArtifactRepository,
 	vectorRepo ports.VectorRepository,
+	embeddingService ports.EmbeddingService,
 	hashService ports.HashService,
+	contentScanner ports.ContentScanner,
+	quotaService ports.QuotaService,
+	transactor ports.Transactor,
+	eventRepo ports.SessionEventRepository,
+	webhooks ports.WebhookNotifier,
+	artifactTTLs *ArtifactTTLs,
+	negativeCache *NegativeCache,
+	cacheStats *CacheStatsService,
+	accessTracker *AccessTracker,
+	blobStore ports.BlobStore,
+	blobThreshold int64,
+	chunking config.ChunkingConfig,
+	embedBatchSize int,
+	queryExpander ports.QueryExpander,
+	lookupCache *LookupCache,
+	lookupLimits config.LookupConfig,
 ) *CacheService {
 	return &CacheService{
-		artifactRepo: artifactRepo,
-		vectorRepo:   vectorRepo,
-		hashService:  hashService,
+		artifactRepo:     artifactRepo,
+		vectorRepo:       vectorRepo,
+		embeddingService: embeddingService,
+		hashService:      hashService,
+		contentScanner:   contentScanner,
+		quotaService:     quotaService,
+		transactor:       transactor,
+		eventRepo:        eventRepo,
+		webhooks:         webhooks,
+		artifactTTLs:     artifactTTLs,
+		negativeCache:    negativeCache,
+		cacheStats:       cacheStats,
+		accessTracker:    accessTracker,
+		blobStore:        blobStore,
+		blobThreshold:    blobThreshold,
+		chunking:         chunking,
+		embedBatchSize:   embedBatchSize,
+		queryExpander:    queryExpander,
+		lookupCache:      lookupCache,
+		lookupLimits:     lookupLimits,
 	}
 }
 
 func (s *CacheService) Publish(ctx context.Context, artifacts []domain.Artifact) (*domain.PublishResponse, error) {
-	var published []uuid.UUID
-	var skipped []uuid.UUID
+	ctx, span := tracer.Start(ctx, "CacheService.Publish", trace.WithAttributes(attribute.Int("mentis.artifacts", len(artifacts))))
+	defer span.End()
+
+	response := &domain.PublishResponse{
+		Published: []uuid.UUID{},
+		Skipped:   []uuid.UUID{},
+		Replaced:  []uuid.UUID{},
+		Results:   make([]domain.PublishResult, len(artifacts)),
+	}
+	namespace := domain.NamespaceFromContext(ctx)
+
+	// A failing object is reported in its result and the rest are still published
+	var firstErr error
+	fail := func(i int, err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+		response.Results[i].Status = domain.PublishFailed
+		response.Results[i].Error = publishError(ctx, err)
+	}
+
+	prepared := make([]domain.Artifact, len(artifacts))
+	explicitIDs := make([]bool, len(artifacts))
+	var pending []*domain.Artifact
+	for i, artifact := range artifacts {
+		response.Results[i].Index = i
+		explicitIDs[i] = artifact.ID != uuid.Nil
 
-	for _, artifact := range artifacts {
 		// Set ID if not provided
 		if artifact.ID == uuid.Nil {
 			artifact.ID = uuid.New()
 		}
 
+		// Artifacts always belong to the caller's namespace
+		artifact.Namespace = namespace
+		artifact.Tags = normalizeTags(artifact.Tags)
+
 		// Set timestamps
 		if artifact.CreatedAt.IsZero() {
 			artifact.CreatedAt = time.Now()
 		}
 		artifact.UpdatedAt = time.Now()
 
+		if err := s.artifactTTLs.Apply(&artifact, artifact.UpdatedAt); err != nil {
+			fail(i, err)
+			continue
+		}
+
+		// Apply the PII policy before hashing so redacted content hashes consistently
+		if err := s.applyPIIPolicy(i, &artifact); err != nil {
+			fail(i, err)
+			continue
+		}
+
 		// Compute content hash if not provided
 		if artifact.ContentHash == "" {
 			artifact.ContentHash = s.hashService.ComputeContentHash(artifact.Content)
 		}
 
-		// Check if artifact already exists with same content hash
-		existing, err := s.artifactRepo.GetByContentHash(ctx, artifact.ContentHash)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check existing artifact: %w", err)
+		prepared[i] = artifact
+		pending = append(pending, &prepared[i])
+	}
+
+	// Embeddings missing from the request are generated together rather than per
+	// artifact; if that fails, only the objects left without one fail
+	embedErr := s.embedMissing(ctx, pending)
+
+	var points []domain.VectorPoint
+	pointIndexes := make(map[uuid.UUID]int)
+	for i := range prepared {
+		if response.Results[i].Status == domain.PublishFailed {
+			continue
 		}
+		artifact := &prepared[i]
 
-		if existing != nil {
-			skipped = append(skipped, existing.ID)
+		status, replaced, err := s.publishShared(ctx, artifact, explicitIDs[i], embedErr)
+		if err != nil {
+			fail(i, err)
 			continue
 		}
 
-		// Store artifact in database
-		if err := s.artifactRepo.Store(ctx, &artifact); err != nil {
-			return nil, fmt.Errorf("failed to store artifact: %w", err)
+		id := artifact.ID
+		response.Results[i].ID = &id
+		response.Results[i].Status = status
+		if status == domain.PublishSkipped {
+			response.Skipped = append(response.Skipped, id)
+			continue
 		}
 
-		// Store vector if embedding is provided
+		// Vectors are upserted in bulk once the artifacts are stored
 		if len(artifact.Embedding) > 0 {
-			if err := s.vectorRepo.Store(ctx, artifact.ID, artifact.Embedding, artifact.Metadata); err != nil {
-				return nil, fmt.Errorf("failed to store vector: %w", err)
+			points = append(points, domain.VectorPoint{ID: id, Embedding: artifact.Embedding, Payload: vectorPayload(artifact)})
+			pointIndexes[id] = i
+		}
+		response.Published = append(response.Published, id)
+		if replaced {
+			response.Replaced = append(response.Replaced, id)
+		}
+	}
+
+	// Stored artifacts stay published; their vectors were queued with them and the
+	// outbox relay retries the ones that failed
+	unstored, err := s.storeVectors(ctx, points)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("count", len(unstored)).Warn("Failed to store vectors of published artifacts")
+		for _, id := range unstored {
+			response.Results[pointIndexes[id]].Warning = "artifact stored but its vector was not; it is not searchable until the upsert is retried"
+		}
+	}
+
+	// New artifacts may answer lookups that recently found nothing
+	if len(response.Published) > 0 {
+		s.negativeCache.ForgetAll(ctx, domain.NegativeLookupMiss)
+		s.lookupCache.Invalidate(domain.NamespaceFromContext(ctx))
+		s.notifyArtifacts(ctx, domain.EventArtifactPublished, response.Published)
+	}
+
+	// With nothing published or skipped, the request as a whole failed
+	if firstErr != nil && len(response.Published) == 0 && len(response.Skipped) == 0 {
+		return nil, firstErr
+	}
+
+	return response, nil
+}
+
+// publishShared publishes an artifact once for all concurrent publishes of the
+// same content and upsert identity. The others report it skipped under the ID
+// it was stored with, as they would have had they come after it
+func (s *CacheService) publishShared(ctx context.Context, artifact *domain.Artifact, explicitID bool, embedErr error) (domain.PublishStatus, bool, error) {
+	key := artifact.Namespace + "\x00" + artifact.ContentHash
+	if artifact.UpsertKey != "" {
+		value, _ := artifact.Metadata[artifact.UpsertKey].(string)
+		key += "\x00" + artifact.UpsertKey + "\x00" + value
+	}
+
+	var led bool
+	var status domain.PublishStatus
+	var replaced bool
+	id, err, _ := s.inflight.Do(key, func() (interface{}, error) {
+		led = true
+		var err error
+		status, replaced, err = s.publishOne(ctx, artifact, explicitID, embedErr)
+		return artifact.ID, err
+	})
+	if led {
+		return status, replaced, err
+	}
+	// The leader's failure may be its own, such as a missing embedding
+	if err != nil {
+		return s.publishOne(ctx, artifact, explicitID, embedErr)
+	}
+	artifact.ID = id.(uuid.UUID)
+	return domain.PublishSkipped, false, nil
+}
+
+// publishOne stores a prepared artifact, or skips it when its content is
+// already cached. The artifact's ID is updated to the one it was published or
+// skipped under. Publishes of the same upsert key or source URL hold a lock on
+// it until they commit, so concurrent ones on any replica replace a single
+// artifact instead of each storing their own
+func (s *CacheService) publishOne(ctx context.Context, artifact *domain.Artifact, explicitID bool, embedErr error) (domain.PublishStatus, bool, error) {
+	identity := publishIdentity(artifact, explicitID)
+	if identity == "" {
+		return s.publishArtifact(ctx, artifact, explicitID, embedErr)
+	}
+
+	var status domain.PublishStatus
+	var replaced bool
+	err := s.transactor.InTx(ctx, func(ctx context.Context) error {
+		if err := s.artifactRepo.LockIdentity(ctx, identity); err != nil {
+			return fmt.Errorf("failed to lock artifact identity: %w", err)
+		}
+		var err error
+		status, replaced, err = s.publishArtifact(ctx, artifact, explicitID, embedErr)
+		return err
+	})
+	return status, replaced, err
+}
+
+// publishIdentity is what a publish replaces an existing artifact by, other
+// than its ID; empty when it only dedupes by content
+func publishIdentity(artifact *domain.Artifact, explicitID bool) string {
+	if explicitID {
+		return ""
+	}
+	if artifact.UpsertKey != "" {
+		value, _ := artifact.Metadata[artifact.UpsertKey].(string)
+		return "upsert:" + artifact.UpsertKey + "=" + value
+	}
+	if sourceURL, _ := artifact.Metadata["source_url"].(string); sourceURL != "" {
+		return "source_url:" + sourceURL
+	}
+	return ""
+}
+
+func (s *CacheService) publishArtifact(ctx context.Context, artifact *domain.Artifact, explicitID bool, embedErr error) (domain.PublishStatus, bool, error) {
+	if err := s.checkDependencies(ctx, artifact.Dependencies); err != nil {
+		return "", false, err
+	}
+
+	// New content under an existing identity becomes the artifact's next version
+	current, err := s.currentVersion(ctx, artifact, explicitID)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Upserts are identified by their key, so they only dedupe against the
+	// artifact they replace; other publishes skip content stored anywhere
+	if artifact.UpsertKey != "" {
+		if current != nil && current.ContentHash == artifact.ContentHash {
+			artifact.ID = current.ID
+			return domain.PublishSkipped, false, nil
+		}
+	} else {
+		existing, err := s.artifactRepo.GetByContentHash(ctx, artifact.ContentHash)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to check existing artifact: %w", err)
+		}
+		if existing != nil {
+			artifact.ID = existing.ID
+			return domain.PublishSkipped, false, nil
+		}
+	}
+
+	if embedErr != nil && s.needsEmbedding(artifact) {
+		return "", false, embedErr
+	}
+
+	// Chunks are split from the full content before it may be offloaded
+	content := artifact.Content
+	var spans []chunkSpan
+	if artifact.Chunk {
+		spans = splitChunks(content, s.chunking)
+	}
+
+	// A republish replaces an artifact rather than adding one. Checked here
+	// before any embeddings are spent, and again under the quota lock below
+	count := int64(len(spans))
+	if current == nil {
+		count++
+	}
+	bytes := int64(len(content)) + chunkBytes(spans)
+	if err := s.quotaService.CheckArtifacts(ctx, count, bytes); err != nil {
+		return "", false, err
+	}
+
+	chunkEmbeddings, err := s.embedChunks(ctx, content, spans)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Recorded with the artifact so a vector lost to a failure below is repaired
+	artifact.Indexed = len(artifact.Embedding) > 0
+
+	// The old chunks are listed before the new ones are stored beside them
+	var retired []uuid.UUID
+	if current != nil {
+		artifact.ID = current.ID
+		artifact.CreatedAt = current.CreatedAt
+		if retired, err = s.chunkIDs(ctx, artifact.ID); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to list chunks of republished artifact")
+		}
+	}
+
+	if err := s.offloadContent(ctx, artifact); err != nil {
+		return "", false, err
+	}
+
+	// The quota lock is held until the artifact and its chunks commit, so
+	// concurrent publishes can't all pass the check and overshoot together
+	var points []domain.VectorPoint
+	err = s.transactor.InTx(ctx, func(ctx context.Context) error {
+		if err := s.quotaService.CheckArtifacts(ctx, count, bytes); err != nil {
+			return err
+		}
+
+		if current != nil {
+			if err := s.artifactRepo.Republish(ctx, artifact); err != nil {
+				return fmt.Errorf("failed to republish artifact: %w", err)
 			}
+		} else if err := s.artifactRepo.Store(ctx, artifact); err != nil {
+			return fmt.Errorf("failed to store artifact: %w", err)
 		}
 
-		// Store dependencies
 		for _, depID := range artifact.Dependencies {
 			if err := s.artifactRepo.StoreDependency(ctx, depID, artifact.ID); err != nil {
-				return nil, fmt.Errorf("failed to store dependency: %w", err)
+				return fmt.Errorf("failed to store dependency: %w", err)
 			}
 		}
 
-		published = append(published, artifact.ID)
+		var err error
+		points, err = s.storeChunks(ctx, artifact, content, spans, chunkEmbeddings)
+		return err
+	})
+	if err != nil {
+		s.discardBlob(ctx, artifact)
+		return "", false, err
 	}
 
-	return &domain.PublishResponse{
-		Published: published,
-		Skipped:   skipped,
-	}, nil
+	if current != nil {
+		// The old vector and chunks describe the archived content
+		if len(artifact.Embedding) == 0 {
+			if err := s.vectorRepo.Delete(ctx, artifact.ID); err != nil {
+				logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to remove vector of republished artifact")
+			}
+		}
+		s.retireChunks(ctx, retired)
+	}
+
+	if _, err := s.storeVectors(ctx, points); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to store chunk vectors; they stay queued for retry")
+	}
+
+	return domain.PublishPublished, current != nil, nil
+}
+
+// checkDependencies requires every dependency to be an artifact of the caller's
+// namespace. Other IDs are reported alike, whether or not another namespace has them
+func (s *CacheService) checkDependencies(ctx context.Context, ids []uuid.UUID) error {
+	for _, id := range ids {
+		dependency, err := s.artifactRepo.GetMetadataByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to check dependency: %w", err)
+		}
+		if dependency == nil {
+			return domain.NewValidationError("dependency not found").WithDetail("dependency_id", id.String())
+		}
+	}
+	return nil
+}
+
+// publishError describes why an object failed to publish, hiding internal error text
+func publishError(ctx context.Context, err error) *domain.PublishError {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return &domain.PublishError{Code: domainErr.Code, Message: domainErr.Message, Details: domainErr.Details}
+	}
+	logging.FromContext(ctx).WithError(err).Error("Failed to publish artifact")
+	return &domain.PublishError{Code: domain.CodeInternal, Message: "internal server error"}
 }
 
 func (s *CacheService) Lookup(ctx context.Context, options domain.LookupOptions) (*domain.LookupResponse, error) {
+	ctx, span := tracer.Start(ctx, "CacheService.Lookup", trace.WithAttributes(
+		attribute.String("mentis.lookup.mode", string(options.Mode)),
+		attribute.Int("mentis.lookup.top_k", options.TopK),
+	))
+	defer span.End()
+
+	if options.Query == "" {
+		return nil, domain.NewValidationError("query is required")
+	}
 	if options.TopK == 0 {
-		options.TopK = 10
+		options.TopK = s.lookupLimits.DefaultTopK
+	}
+	if options.TopK < 0 || options.TopK > s.lookupLimits.MaxTopK {
+		return nil, domain.NewValidationError(fmt.Sprintf("top_k must be between 1 and %d", s.lookupLimits.MaxTopK))
+	}
+	if options.MinScore == nil {
+		minScore := float32(s.lookupLimits.DefaultMinScore)
+		options.MinScore = &minScore
+	}
+	if float64(*options.MinScore) < s.lookupLimits.MinScoreFloor {
+		return nil, domain.NewValidationError(fmt.Sprintf("min_score must be at least %g", s.lookupLimits.MinScoreFloor))
+	}
+	if options.Mode == "" {
+		options.Mode = domain.LookupVector
+	}
+	if options.Mode != domain.LookupVector && options.Mode != domain.LookupHybrid {
+		return nil, domain.NewValidationError("mode must be vector or hybrid")
+	}
+	if err := options.Filter.Validate(); err != nil {
+		return nil, err
 	}
-	if options.MinScore == 0 {
-		options.MinScore = 0.85
+	if len(options.Queries) >= domain.MaxLookupQueries {
+		return nil, domain.NewValidationError(fmt.Sprintf("at most %d additional queries are allowed", domain.MaxLookupQueries-1))
+	}
+	if options.Expand && s.queryExpander == nil {
+		return nil, domain.NewValidationError("query expansion is not configured")
+	}
+
+	offset, err := domain.DecodeCursor(options.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := options.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{domain.NamespaceFromContext(ctx)}
+	}
+
+	// Repeated identical lookups are answered from memory
+	responseKey := s.hashService.ComputeInputHash(map[string]interface{}{
+		"options":    options,
+		"namespaces": namespaces,
+	})
+	if response := s.lookupCache.Get(responseKey); response != nil {
+		if options.Cursor == "" {
+			s.cacheStats.RecordLookup(ctx, len(response.Results) > 0)
+		}
+		for _, result := range response.Results {
+			s.accessTracker.Record(result.Artifact.ID)
+		}
+		return response, nil
+	}
+	generation := s.lookupCache.Generation()
+
+	// Only a first page can be a miss; later pages of a hit are never remembered
+	missKey := ""
+	if options.Cursor == "" {
+		missKey = s.lookupKey(options)
+		if !options.SkipNegativeCache {
+			if entry := s.negativeCache.Check(ctx, domain.NegativeLookupMiss, missKey); entry != nil {
+				s.cacheStats.RecordLookup(ctx, false)
+				return &domain.LookupResponse{Results: []domain.LookupResult{}, KnownMiss: entry}, nil
+			}
+		}
 	}
 
-	// For now, we'll use a simple text embedding approach
-	// In production, you'd use a proper embedding service
-	queryEmbedding := s.generateSimpleEmbedding(options.Query)
+	queries := s.lookupQueries(ctx, options)
+	// An unavailable embedding provider degrades the lookup like a vector store outage
+	queryEmbeddings, embedErr := s.queryEmbeddings(ctx, queries)
+	if embedErr != nil && domain.ErrorCodeOf(embedErr) != domain.CodeUpstreamUnavailable {
+		return nil, embedErr
+	}
 
 	// Build filter
-	filter := make(map[string]interface{})
+	filter := domain.Filter{domain.In("namespace", namespaces)}
+	tags := normalizeTags(options.Tags)
+	if len(tags) > 0 {
+		filter = append(filter, domain.In("tags", tags))
+	}
 	if options.ArtifactType != "" {
-		filter["type"] = string(options.ArtifactType)
+		filter = append(filter, domain.Eq("type", string(options.ArtifactType)))
 	}
 	if !options.IncludeStale {
-		filter["stale"] = false
+		// Excludes stale rather than requiring false, so points stored before
+		// the field existed still match
+		filter = append(filter, domain.Ne("stale", true))
 	}
+	// Session goal and step input vectors share the namespace but are not artifacts
+	filter = append(filter, domain.Ne("kind", sessionGoalKind), domain.Ne("kind", stepInputKind))
+	filter = append(filter, options.Filter...)
 
-	// Search vectors
-	vectorResults, err := s.vectorRepo.Search(ctx, queryEmbedding, options.TopK, options.MinScore, filter)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search vectors: %w", err)
+	// Search vectors, fetching one extra result past the page to detect a next page
+	limit := offset + options.TopK + 1
+	if options.GroupBy != "" {
+		// Groups fold several matches into one result, so search deeper to fill the page
+		limit *= groupFetchFactor
+	}
+	var vectorResults []domain.LookupResult
+	vectorErr := embedErr
+	if vectorErr == nil {
+		results, err := s.searchQueries(ctx, queries, queryEmbeddings, limit, *options.MinScore, filter)
+		if err != nil {
+			vectorErr = domain.NewUpstreamError("vector store", err)
+		}
+		vectorResults = results
+	}
+	degraded := vectorErr != nil
+	if degraded {
+		// Fall back to full-text search alone so callers keep working through
+		// an embedding provider or vector store outage
+		logging.FromContext(ctx).WithError(vectorErr).Warn("Semantic search unavailable, falling back to full-text lookup")
+	}
+	var vectorRanks map[uuid.UUID]int
+	if options.Explain {
+		vectorRanks = rankArtifacts(vectorResults)
+	}
+
+	nextCursor := ""
+	var fused map[uuid.UUID]*fusedMatch
+	if options.Mode == domain.LookupHybrid || degraded {
+		// Keyword matches are fused with the vector results, paging over whole artifacts
+		matches, err := s.artifactRepo.SearchText(ctx, options.Query, limit, domain.TextSearchFilter{
+			Namespaces:   namespaces,
+			ArtifactType: options.ArtifactType,
+			Tags:         tags,
+			IncludeStale: options.IncludeStale,
+			Conditions:   options.Filter,
+		})
+		if err != nil {
+			if degraded {
+				return nil, vectorErr
+			}
+			return nil, fmt.Errorf("failed to search artifact text: %w", err)
+		}
+
+		ranked := fuseResults(vectorResults, matches)
+		if options.GroupBy == "" {
+			ranked, nextCursor = pageResults(ranked, offset, options.TopK)
+		}
+
+		vectorResults = nil
+		fused = make(map[uuid.UUID]*fusedMatch, len(ranked))
+		for _, match := range ranked {
+			vectorResults = append(vectorResults, match.entries...)
+			fused[match.id] = match
+		}
+	} else if options.GroupBy == "" {
+		vectorResults, nextCursor = pageResults(vectorResults, offset, options.TopK)
 	}
 
-	// Enrich results with full artifact data
+	// Enrich results with full artifact data, read from the namespace each belongs to;
+	// chunk matches are folded into a single result for their parent
 	var results []domain.LookupResult
+	resultIndex := make(map[uuid.UUID]int)
 	for _, vr := range vectorResults {
-		artifact, err := s.artifactRepo.GetByID(ctx, vr.Artifact.ID)
-		if err != nil {
+		namespace, _ := vr.Artifact.Metadata["namespace"].(string)
+		if namespace == "" {
+			namespace = domain.NamespaceFromContext(ctx)
+		}
+
+		id := vr.Artifact.ID
+		parentID, chunk, isChunk := chunkMatch(vr)
+		if isChunk {
+			id = parentID
+		}
+		if i, ok := resultIndex[id]; ok {
+			if isChunk {
+				results[i].Chunks = append(results[i].Chunks, chunk)
+			}
 			continue
 		}
 
-		if artifact == nil {
+		// Content is only loaded when the caller asked for it
+		getArtifact := s.artifactRepo.GetMetadataByID
+		if options.IncludeContent {
+			getArtifact = s.artifactRepo.GetByID
+		}
+		artifact, err := getArtifact(namespaceContext(ctx, namespace), id)
+		if err != nil {
 			continue
 		}
 
-		// Apply content/embedding inclusion options
-		if !options.IncludeContent {
-			artifact.Content = nil
+		if artifact == nil || (artifact.Stale && !options.IncludeStale) {
+			continue
 		}
+
+		// Apply the embedding inclusion option
 		if !options.IncludeEmbedding {
 			artifact.Embedding = nil
 		}
 
-		results = append(results, domain.LookupResult{
+		result := domain.LookupResult{
 			Artifact: artifact,
 			Score:    vr.Score,
-		})
+		}
+		if match, ok := fused[id]; ok {
+			result.Score = match.score
+			result.VectorScore = match.vectorScore
+			result.TextScore = match.textScore
+			if degraded {
+				result.Score = match.textScore
+			}
+		}
+		if isChunk {
+			result.Chunks = []domain.ChunkMatch{chunk}
+		}
+		if options.Explain {
+			result.Explanation = explainResult(options, tags, artifact, vr, fused[id], vectorRanks[id])
+		}
+		resultIndex[id] = len(results)
+		results = append(results, result)
 	}
 
-	return &domain.LookupResponse{
-		Results: results,
-	}, nil
+	// Grouped lookups page over groups rather than matches
+	if options.GroupBy != "" {
+		results, nextCursor = pageResults(groupResults(results, options.GroupBy), offset, options.TopK)
+	}
+
+	accessed := make([]uuid.UUID, len(results))
+	var contentBytes int64
+	for i, result := range results {
+		accessed[i] = result.Artifact.ID
+		if result.Explanation != nil {
+			result.Explanation.MatchedChunks = len(result.Chunks)
+		}
+
+		// Content that would push the response past its size limit is left out
+		size := int64(len(result.Artifact.Content))
+		if maxBytes := s.lookupLimits.MaxContentBytes; maxBytes > 0 && size > 0 {
+			if contentBytes+size > maxBytes {
+				result.Artifact.Content = nil
+				results[i].ContentOmitted = true
+			} else {
+				contentBytes += size
+			}
+		}
+	}
+	s.accessTracker.Record(accessed...)
+
+	// Later pages continue a lookup that was already counted
+	if options.Cursor == "" {
+		s.cacheStats.RecordLookup(ctx, len(results) > 0)
+	}
+	// Full-text search alone can miss what the vector store would find
+	if missKey != "" && len(results) == 0 && !degraded {
+		s.negativeCache.Record(ctx, domain.NegativeLookupMiss, missKey, "no artifacts matched the lookup", "")
+	}
+
+	response := &domain.LookupResponse{
+		Results:    results,
+		NextCursor: nextCursor,
+		Degraded:   degraded,
+	}
+	if !degraded {
+		s.lookupCache.Put(responseKey, generation, namespaces, response)
+	}
+	return response, nil
 }
 
 func (s *CacheService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
-	return s.artifactRepo.GetByID(ctx, id)
+	artifact, err := s.artifactRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if artifact != nil {
+		s.accessTracker.Record(artifact.ID)
+	}
+	return artifact, nil
 }
 
-func (s *CacheService) Delete(ctx context.Context, id uuid.UUID) error {
-	// Delete from vector store
-	if err := s.vectorRepo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete vector: %w", err)
+func (s *CacheService) GetMetadata(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	artifact, err := s.artifactRepo.GetMetadataByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	if artifact != nil {
+		s.accessTracker.Record(artifact.ID)
+	}
+	return artifact, nil
+}
 
-	// Delete from artifact store
+func (s *CacheService) MostAccessed(ctx context.Context, limit int) ([]*domain.ArtifactUsage, error) {
+	usages, err := s.artifactRepo.ListMostAccessed(ctx, domain.NormalizePageSize(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list most accessed artifacts: %w", err)
+	}
+	if usages == nil {
+		usages = []*domain.ArtifactUsage{}
+	}
+	return usages, nil
+}
+
+// currentVersion finds the artifact a publish replaces: the one with the given ID,
+// the one sharing its upsert key value, or else the latest one fetched from the
+// same source URL
+func (s *CacheService) currentVersion(ctx context.Context, artifact *domain.Artifact, explicitID bool) (*domain.Artifact, error) {
+	if explicitID {
+		current, err := s.artifactRepo.GetByID(ctx, artifact.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing artifact: %w", err)
+		}
+		return current, nil
+	}
+
+	if artifact.UpsertKey != "" {
+		value, _ := artifact.Metadata[artifact.UpsertKey].(string)
+		if value == "" {
+			return nil, domain.NewValidationError(fmt.Sprintf("upsert_key %q must name a non-empty string metadata field", artifact.UpsertKey))
+		}
+		current, err := s.artifactRepo.GetByUpsertKey(ctx, artifact.UpsertKey, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing artifact: %w", err)
+		}
+		return current, nil
+	}
+
+	sourceURL, _ := artifact.Metadata["source_url"].(string)
+	if sourceURL == "" {
+		return nil, nil
+	}
+
+	current, err := s.artifactRepo.GetBySourceURL(ctx, sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing artifact: %w", err)
+	}
+	return current, nil
+}
+
+// ListVersions pages through an artifact's archived versions, newest first
+func (s *CacheService) ListVersions(ctx context.Context, id uuid.UUID, limit int, cursor string) (*domain.ListArtifactVersionsResponse, error) {
+	limit = domain.NormalizePageSize(limit)
+	before, err := domain.DecodeSeqCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, err := s.artifactRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artifact: %w", err)
+	}
+	if artifact == nil {
+		return nil, domain.NewNotFoundError("artifact", id)
+	}
+
+	versions, err := s.artifactRepo.ListVersions(ctx, id, limit+1, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifact versions: %w", err)
+	}
+
+	response := &domain.ListArtifactVersionsResponse{Versions: versions}
+	if len(versions) > limit {
+		response.Versions = versions[:limit]
+		response.NextCursor = domain.EncodeSeqCursor(int64(response.Versions[limit-1].Version))
+	}
+
+	return response, nil
+}
+
+func (s *CacheService) GetVersion(ctx context.Context, id uuid.UUID, version int) (*domain.ArtifactVersion, error) {
+	artifactVersion, err := s.artifactRepo.GetVersion(ctx, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artifact version: %w", err)
+	}
+	if artifactVersion == nil {
+		return nil, domain.NewNotFoundError("artifact version", fmt.Sprintf("%s@%d", id, version))
+	}
+	return artifactVersion, nil
+}
+
+// offloadContent moves content above the blob threshold to object storage,
+// leaving only its key and size on the artifact
+func (s *CacheService) offloadContent(ctx context.Context, artifact *domain.Artifact) error {
+	if s.blobStore == nil || int64(len(artifact.Content)) <= s.blobThreshold {
+		return nil
+	}
+
+	// Keys are unique per artifact and content, so archived versions keep their blobs
+	key := artifact.Namespace + "/" + artifact.ID.String() + "/" + artifact.ContentHash
+	if err := s.blobStore.Put(ctx, key, artifact.Content); err != nil {
+		return domain.NewUpstreamError("blob store", err)
+	}
+
+	artifact.ContentRef = key
+	artifact.ContentSize = int64(len(artifact.Content))
+	artifact.Content = nil
+	return nil
+}
+
+// discardBlob removes content offloaded for an artifact that was not stored
+func (s *CacheService) discardBlob(ctx context.Context, artifact *domain.Artifact) {
+	if artifact.ContentRef == "" {
+		return
+	}
+	if err := s.blobStore.Delete(context.WithoutCancel(ctx), artifact.ContentRef); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to remove blob of unstored artifact")
+	}
+}
+
+// OpenContent streams an artifact's content from wherever it is stored. Inline
+// content of an artifact fetched without it is read from Postgres in chunks
+func (s *CacheService) OpenContent(ctx context.Context, artifact *domain.Artifact) (io.ReadCloser, error) {
+	if artifact.ContentRef == "" {
+		if artifact.Content == nil && artifact.ContentSize > 0 {
+			return s.artifactRepo.OpenContent(ctx, artifact.ID)
+		}
+		return io.NopCloser(bytes.NewReader(artifact.Content)), nil
+	}
+	if s.blobStore == nil {
+		return nil, domain.NewUpstreamError("blob store", fmt.Errorf("artifact content is offloaded but no blob store is configured"))
+	}
+
+	content, err := s.blobStore.Get(ctx, artifact.ContentRef)
+	if err != nil {
+		return nil, domain.NewUpstreamError("blob store", err)
+	}
+	return content, nil
+}
+
+// SetPinned exempts an artifact from storage eviction, or makes it evictable again
+func (s *CacheService) SetPinned(ctx context.Context, id uuid.UUID, pinned bool) error {
+	if err := s.artifactRepo.SetPinned(ctx, id, pinned); err != nil {
+		return err
+	}
+	s.lookupCache.Invalidate(domain.NamespaceFromContext(ctx))
+	return nil
+}
+
+func (s *CacheService) List(ctx context.Context, limit int, cursor string) (*domain.ListArtifactsResponse, error) {
+	limit = domain.NormalizePageSize(limit)
+	after, err := domain.DecodeKeyCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to know whether another page exists
+	artifacts, err := s.artifactRepo.List(ctx, limit+1, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+
+	response := &domain.ListArtifactsResponse{Artifacts: artifacts}
+	if len(artifacts) > limit {
+		response.Artifacts = artifacts[:limit]
+		last := artifacts[limit-1]
+		response.NextCursor = domain.EncodeKeyCursor(domain.PageKey{Time: last.CreatedAt, ID: last.ID})
+	}
+
+	return response, nil
+}
+
+// Delete soft-deletes an artifact. Its vector is kept so a restore is immediately
+// searchable again; lookups skip deleted artifacts and the sweeper purges both.
+func (s *CacheService) Delete(ctx context.Context, id uuid.UUID) error {
 	if err := s.artifactRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete artifact: %w", err)
 	}
+	s.lookupCache.Invalidate(domain.NamespaceFromContext(ctx))
+	return nil
+}
 
+func (s *CacheService) Restore(ctx context.Context, id uuid.UUID) error {
+	if err := s.artifactRepo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore artifact: %w", err)
+	}
+	s.lookupCache.Invalidate(domain.NamespaceFromContext(ctx))
 	return nil
 }
 
-func (s *CacheService) Invalidate(ctx context.Context, sourceURL string) error {
-	// Mark artifacts as stale
-	if err := s.artifactRepo.MarkStaleBySourceURL(ctx, sourceURL); err != nil {
-		return fmt.Errorf("failed to mark artifacts as stale: %w", err)
+func (s *CacheService) GetDeleted(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	return s.artifactRepo.GetDeleted(ctx, id)
+}
+
+// ListDeleted pages through restorable artifacts, most recently deleted first
+func (s *CacheService) ListDeleted(ctx context.Context, limit int, cursor string) (*domain.ListArtifactsResponse, error) {
+	limit = domain.NormalizePageSize(limit)
+	after, err := domain.DecodeKeyCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts, err := s.artifactRepo.ListDeleted(ctx, limit+1, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted artifacts: %w", err)
 	}
 
+	response := &domain.ListArtifactsResponse{Artifacts: artifacts}
+	if len(artifacts) > limit {
+		response.Artifacts = artifacts[:limit]
+		last := artifacts[limit-1]
+		response.NextCursor = domain.EncodeKeyCursor(domain.PageKey{Time: *last.DeletedAt, ID: last.ID})
+	}
+
+	return response, nil
+}
+
+// Invalidate marks artifacts stale, either those fetched from a source URL or
+// those of a type older than a given age
+func (s *CacheService) Invalidate(ctx context.Context, req *domain.InvalidateRequest) (*domain.InvalidateResponse, error) {
+	if (req.SourceURL == "") == (req.OlderThan == "") {
+		return nil, domain.NewValidationError("exactly one of source_url or older_than is required")
+	}
+	if req.SourceURL != "" && req.ArtifactType != "" {
+		return nil, domain.NewValidationError("artifact_type only applies with older_than")
+	}
+
+	var ids []uuid.UUID
+	if req.SourceURL != "" {
+		var err error
+		if ids, err = s.artifactRepo.MarkStaleBySourceURL(ctx, req.SourceURL); err != nil {
+			return nil, fmt.Errorf("failed to mark artifacts as stale: %w", err)
+		}
+	} else {
+		olderThan, err := time.ParseDuration(req.OlderThan)
+		if err != nil || olderThan <= 0 {
+			return nil, domain.NewValidationError("older_than must be a positive duration such as 168h")
+		}
+		if ids, err = s.artifactRepo.MarkStaleOlderThan(ctx, req.ArtifactType, time.Now().Add(-olderThan)); err != nil {
+			return nil, fmt.Errorf("failed to mark artifacts as stale: %w", err)
+		}
+	}
+
+	// Lookups filter on the vector payload, so it must agree with Postgres;
+	// a lagging payload is still caught by the stale check on enrichment
+	if err := s.vectorRepo.SetPayload(ctx, ids, map[string]interface{}{"stale": true}); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("count", len(ids)).Warn("Failed to mark vectors stale")
+	}
+	if len(ids) > 0 {
+		s.lookupCache.Invalidate(domain.NamespaceFromContext(ctx))
+		s.notifyArtifacts(ctx, domain.EventArtifactStale, ids)
+	}
+
+	// Leave a trace on every workflow session whose steps used the invalidated content
+	if req.SourceURL != "" {
+		if _, err := s.eventRepo.AppendForSourceURL(ctx, req.SourceURL, domain.SessionEventInvalidated, map[string]interface{}{
+			"source_url": req.SourceURL,
+		}); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("source_url", req.SourceURL).Warn("Failed to record invalidation events")
+		}
+	}
+
+	return &domain.InvalidateResponse{
+		Message:     "artifacts invalidated",
+		Invalidated: len(ids),
+	}, nil
+}
+
+// notifyArtifacts sends an artifact event to the namespace's webhook subscriptions
+func (s *CacheService) notifyArtifacts(ctx context.Context, eventType domain.WebhookEventType, ids []uuid.UUID) {
+	s.webhooks.Notify(nil, &domain.WebhookEvent{
+		ID:          uuid.New(),
+		Type:        eventType,
+		Namespace:   domain.NamespaceFromContext(ctx),
+		ArtifactIDs: ids,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// applyPIIPolicy enforces the PII policy on one object of a publish, reporting
+// a rejection against the object's index
+func (s *CacheService) applyPIIPolicy(index int, artifact *domain.Artifact) error {
+	err := enforcePIIPolicy(s.contentScanner, artifact)
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) && domainErr.Code == domain.CodeValidation {
+		return domainErr.WithDetail("index", index)
+	}
+	return err
+}
+
+func setMetadata(artifact *domain.Artifact, key string, value interface{}) {
+	if artifact.Metadata == nil {
+		artifact.Metadata = make(map[string]interface{})
+	}
+	artifact.Metadata[key] = value
+}
+
+// vectorPayload builds the vector store payload for an artifact, adding the
+// fields lookups filter on to the artifact's own metadata
+func vectorPayload(artifact *domain.Artifact) map[string]interface{} {
+	payload := make(map[string]interface{}, len(artifact.Metadata)+4)
+	for key, value := range artifact.Metadata {
+		payload[key] = value
+	}
+	payload["namespace"] = artifact.Namespace
+	payload["type"] = string(artifact.Type)
+	payload["stale"] = artifact.Stale
+
+	// Payload values must be generic lists to convert into vector store values
+	tags := make([]interface{}, len(artifact.Tags))
+	for i, tag := range artifact.Tags {
+		tags[i] = tag
+	}
+	payload["tags"] = tags
+	return payload
+}
+
+// lookupKey identifies a lookup by everything that decides its results
+func (s *CacheService) lookupKey(options domain.LookupOptions) string {
+	return s.hashService.ComputeInputHash(map[string]interface{}{
+		"query":         options.Query,
+		"top_k":         options.TopK,
+		"min_score":     options.MinScore,
+		"artifact_type": options.ArtifactType,
+		"include_stale": options.IncludeStale,
+		"tags":          normalizeTags(options.Tags),
+		"namespaces":    options.Namespaces,
+		"mode":          options.Mode,
+		"filter":        options.Filter,
+		"group_by":      options.GroupBy,
+		"queries":       options.Queries,
+		"expand":        options.Expand,
+	})
+}
+
+// pageResults returns the page of results starting at offset, with the cursor
+// of the next page when results continue past it
+func pageResults[T any](results []T, offset, size int) ([]T, string) {
+	if len(results) > offset+size {
+		return results[offset : offset+size], domain.EncodeCursor(offset + size)
+	}
+	if len(results) > offset {
+		return results[offset:], ""
+	}
+	return nil, ""
+}
+
+// normalizeTags trims tags and drops empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// namespaceContext returns ctx scoped to namespace, keeping the caller's principal otherwise
+func namespaceContext(ctx context.Context, namespace string) context.Context {
+	if namespace == domain.NamespaceFromContext(ctx) {
+		return ctx
+	}
+	principal := &domain.Principal{Namespace: namespace}
+	if caller := domain.PrincipalFromContext(ctx); caller != nil {
+		scoped := *caller
+		scoped.Namespace = namespace
+		principal = &scoped
+	}
+	return domain.WithPrincipal(ctx, principal)
+}
+
+// queryEmbedding embeds a lookup query in the same space as published chunks
+func (s *CacheService) queryEmbedding(ctx context.Context, query string) ([]float32, error) {
+	if s.embeddingService == nil {
+		return s.generateSimpleEmbedding(query), nil
+	}
+	if err := s.quotaService.ConsumeEmbeddings(ctx, 1); err != nil {
+		return nil, err
+	}
+	embedding, err := s.embeddingService.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, domain.NewUpstreamError("embedding provider", err)
+	}
+	return embedding, nil
+}
+
+// needsEmbedding reports whether publish generates an embedding for the
+// artifact. Chunked artifacts are embedded per chunk instead
+func (s *CacheService) needsEmbedding(artifact *domain.Artifact) bool {
+	return s.embeddingService != nil && len(artifact.Embedding) == 0 && !artifact.Chunk && len(artifact.Content) > 0
+}
+
+// embedMissing embeds the content of artifacts published without an embedding
+// in a single batched call. Content publish will skip as already cached is not
+// embedded again
+func (s *CacheService) embedMissing(ctx context.Context, artifacts []*domain.Artifact) error {
+	// Artifacts sharing content share one embedding
+	pending := make(map[string][]*domain.Artifact)
+	var hashes []string
+	for _, artifact := range artifacts {
+		if !s.needsEmbedding(artifact) {
+			continue
+		}
+		if _, ok := pending[artifact.ContentHash]; !ok {
+			hashes = append(hashes, artifact.ContentHash)
+		}
+		pending[artifact.ContentHash] = append(pending[artifact.ContentHash], artifact)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	cached, err := s.artifactRepo.ExistingContentHashes(ctx, hashes)
+	if err != nil {
+		return fmt.Errorf("failed to check existing artifacts: %w", err)
+	}
+	skip := make(map[string]bool, len(cached))
+	for _, hash := range cached {
+		skip[hash] = true
+	}
+
+	var texts []string
+	var embedded []string
+	for _, hash := range hashes {
+		// Upserts are not deduplicated against other artifacts, so they always need an embedding
+		if skip[hash] && !hasUpsert(pending[hash]) {
+			continue
+		}
+		texts = append(texts, string(pending[hash][0].Content))
+		embedded = append(embedded, hash)
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	if err := s.quotaService.ConsumeEmbeddings(ctx, int64(len(texts))); err != nil {
+		return err
+	}
+	embeddings, err := s.embeddingService.GenerateEmbeddings(ctx, texts)
+	if err != nil {
+		return domain.NewUpstreamError("embedding provider", err)
+	}
+	if len(embeddings) != len(texts) {
+		return domain.NewUpstreamError("embedding provider", fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings)))
+	}
+
+	for i, hash := range embedded {
+		for _, artifact := range pending[hash] {
+			artifact.Embedding = embeddings[i]
+		}
+	}
 	return nil
 }
 
+// hasUpsert reports whether any of the artifacts is published with an upsert key
+func hasUpsert(artifacts []*domain.Artifact) bool {
+	for _, artifact := range artifacts {
+		if artifact.UpsertKey != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// storeVectors upserts vectors in batches of the embedding batch size. A failed
+// batch does not stop the rest; the IDs it held are returned with the last error
+func (s *CacheService) storeVectors(ctx context.Context, points []domain.VectorPoint) ([]uuid.UUID, error) {
+	batchSize := s.embedBatchSize
+	if batchSize <= 0 {
+		batchSize = len(points)
+	}
+
+	var unstored []uuid.UUID
+	var lastErr error
+	for start := 0; start < len(points); start += batchSize {
+		end := min(start+batchSize, len(points))
+		if err := s.vectorRepo.StoreBatch(ctx, points[start:end]); err != nil {
+			for _, point := range points[start:end] {
+				unstored = append(unstored, point.ID)
+			}
+			lastErr = domain.NewUpstreamError("vector store", err)
+		}
+	}
+	return unstored, lastErr
+}
+
 // generateSimpleEmbedding creates a simple embedding for demonstration
 // This is kept as a fallback when no embedding service is available
 func (s *CacheService) generateSimpleEmbedding(text string) []float32 {
 	// This is a placeholder - create a simple hash-based embedding
 	hash := s.hashService.ComputeInputHash(text)
 	embedding := make([]float32, 1536)
-	
+
 	for i := 0; i < len(embedding) && i < len(hash); i++ {
 		embedding[i] = float32(hash[i]) / 255.0
 	}
-	
+
 	return embedding
-}
\ No newline at end of file
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// CacheScopes resolves the step cache scope for a step type
+type CacheScopes struct {
+	defaultScope    domain.StepCacheScope
+	stepTypes       map[string]domain.StepCacheScope
+	goalMinScore    float32
+	goalMaxSessions int
+}
+
+func NewCacheScopes(cfg config.StepCacheConfig) *CacheScopes {
+	scopes := &CacheScopes{
+		defaultScope:    toCacheScope(cfg.DefaultScope, domain.CacheScopeNamespace),
+		stepTypes:       make(map[string]domain.StepCacheScope, len(cfg.StepTypes)),
+		goalMinScore:    float32(cfg.GoalMinScore),
+		goalMaxSessions: cfg.GoalMaxSessions,
+	}
+	for stepType, scope := range cfg.StepTypes {
+		scopes.stepTypes[stepType] = toCacheScope(scope, scopes.defaultScope)
+	}
+	return scopes
+}
+
+func (c *CacheScopes) For(stepType string) domain.StepCacheScope {
+	if scope, ok := c.stepTypes[stepType]; ok {
+		return scope
+	}
+	return c.defaultScope
+}
+
+// toCacheScope parses a configured scope, falling back on unknown values
+func toCacheScope(value string, fallback domain.StepCacheScope) domain.StepCacheScope {
+	scope := domain.StepCacheScope(value)
+	if !scope.Valid() {
+		logrus.WithField("scope", value).Warnf("Unknown step cache scope, using %s", fallback)
+		return fallback
+	}
+	return scope
+}
+
+// reuseFilter narrows step reuse for a session to the step type's cache scope
+func (s *WorkflowService) reuseFilter(ctx context.Context, session *domain.WorkflowSession, stepType string, compat *domain.TemplateCompatibility) (domain.StepReuseFilter, error) {
+	filter := domain.StepReuseFilter{Compat: compat}
+
+	switch s.cacheScopes.For(stepType) {
+	case domain.CacheScopeGlobal:
+		filter.AllNamespaces = true
+	case domain.CacheScopeSession:
+		filter.SessionIDs = []uuid.UUID{session.ID}
+	case domain.CacheScopeGoal:
+		sessionIDs, err := s.similarGoalSessions(ctx, session)
+		if err != nil {
+			return filter, err
+		}
+		filter.SessionIDs = sessionIDs
+	}
+
+	return filter, nil
+}
+
+// similarGoalSessions returns the session and the past sessions whose goals are
+// most similar to its goal
+func (s *WorkflowService) similarGoalSessions(ctx context.Context, session *domain.WorkflowSession) ([]uuid.UUID, error) {
+	if err := s.quotaService.ConsumeEmbeddings(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	embedding, err := s.embeddingService.GenerateEmbedding(ctx, session.Goal)
+	if err != nil {
+		return nil, domain.NewUpstreamError("embedding provider", err)
+	}
+
+	matches, err := s.vectorRepo.Search(ctx, embedding, s.cacheScopes.goalMaxSessions, s.cacheScopes.goalMinScore, domain.Filter{
+		domain.Eq("namespace", session.Namespace),
+		domain.Eq("kind", sessionGoalKind),
+	})
+	if err != nil {
+		return nil, domain.NewUpstreamError("vector store", err)
+	}
+
+	sessionIDs := []uuid.UUID{session.ID}
+	for _, match := range matches {
+		if match.Artifact.ID != session.ID {
+			sessionIDs = append(sessionIDs, match.Artifact.ID)
+		}
+	}
+	return sessionIDs, nil
+}
+
+// supersedeFilter narrows what a forced step supersedes to the steps its cache
+// scope would have reused. Steps of other namespaces are never superseded; a
+// forced result is newer, so it is reused ahead of them anyway
+func (s *WorkflowService) supersedeFilter(ctx context.Context, step *domain.WorkflowStep) (domain.StepReuseFilter, error) {
+	session, err := s.workflowRepo.GetSession(ctx, step.SessionID)
+	if err != nil {
+		return domain.StepReuseFilter{}, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return domain.StepReuseFilter{}, domain.NewNotFoundError("session", step.SessionID)
+	}
+
+	var compat *domain.TemplateCompatibility
+	if session.TemplateName != "" {
+		template, err := s.GetTemplate(ctx, session.TemplateName, session.TemplateVersion)
+		if err != nil {
+			return domain.StepReuseFilter{}, err
+		}
+		compat = &domain.TemplateCompatibility{
+			Name:       template.Name,
+			MinVersion: template.CompatibleFrom,
+			MaxVersion: template.Version,
+		}
+	}
+
+	filter, err := s.reuseFilter(ctx, session, step.StepType, compat)
+	if err != nil {
+		return domain.StepReuseFilter{}, err
+	}
+	filter.AllNamespaces = false
+	return filter, nil
+}
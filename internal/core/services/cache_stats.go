@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultStatsDays = 7
+	maxStatsDays     = 90
+)
+
+// CacheStatsService counts cache hits and misses so users can see what the cache saves
+type CacheStatsService struct {
+	repo ports.CacheStatsRepository
+}
+
+func NewCacheStatsService(repo ports.CacheStatsRepository) *CacheStatsService {
+	return &CacheStatsService{repo: repo}
+}
+
+// RecordLookup counts an artifact lookup; failures to count are only logged
+func (s *CacheStatsService) RecordLookup(ctx context.Context, hit bool) {
+	s.record(ctx, domain.CacheStatLookup, "", hit)
+}
+
+// RecordStep counts a step served from cache or executed; failures to count are only logged
+func (s *CacheStatsService) RecordStep(ctx context.Context, stepType string, hit bool) {
+	s.record(ctx, domain.CacheStatStep, stepType, hit)
+}
+
+func (s *CacheStatsService) record(ctx context.Context, kind domain.CacheStatKind, stepType string, hit bool) {
+	var hits, misses int64 = 0, 1
+	if hit {
+		hits, misses = 1, 0
+	}
+	if err := s.repo.Increment(context.WithoutCancel(ctx), kind, stepType, hits, misses); err != nil {
+		logrus.WithError(err).WithField("kind", kind).Warn("Failed to record cache statistics")
+	}
+}
+
+// Stats reports the caller's namespace hit rates over the last days
+func (s *CacheStatsService) Stats(ctx context.Context, days int) (*domain.CacheStatsResponse, error) {
+	return s.summarize(ctx, days, false)
+}
+
+// AllStats reports hit rates across every namespace, with a per-namespace breakdown
+func (s *CacheStatsService) AllStats(ctx context.Context, days int) (*domain.CacheStatsResponse, error) {
+	return s.summarize(ctx, days, true)
+}
+
+func (s *CacheStatsService) summarize(ctx context.Context, days int, allNamespaces bool) (*domain.CacheStatsResponse, error) {
+	if days == 0 {
+		days = defaultStatsDays
+	}
+	if days < 1 || days > maxStatsDays {
+		return nil, domain.NewValidationError(fmt.Sprintf("days must be between 1 and %d", maxStatsDays))
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-days)
+
+	counts, err := s.repo.Counts(ctx, since, allNamespaces)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache statistics: %w", err)
+	}
+
+	response := &domain.CacheStatsResponse{
+		Since:      since,
+		CacheStats: domain.CacheStats{StepTypes: map[string]*domain.CacheHitRate{}},
+	}
+	if allNamespaces {
+		response.Namespaces = map[string]*domain.CacheStats{}
+	}
+
+	for _, count := range counts {
+		addCacheStat(&response.CacheStats, count)
+		if allNamespaces {
+			stats, ok := response.Namespaces[count.Namespace]
+			if !ok {
+				stats = &domain.CacheStats{StepTypes: map[string]*domain.CacheHitRate{}}
+				response.Namespaces[count.Namespace] = stats
+			}
+			addCacheStat(stats, count)
+		}
+	}
+
+	return response, nil
+}
+
+func addCacheStat(stats *domain.CacheStats, count domain.CacheStatCount) {
+	switch count.Kind {
+	case domain.CacheStatLookup:
+		stats.Lookups.Add(count.Hits, count.Misses)
+	case domain.CacheStatStep:
+		stats.Steps.Add(count.Hits, count.Misses)
+		rate, ok := stats.StepTypes[count.StepType]
+		if !ok {
+			rate = &domain.CacheHitRate{}
+			stats.StepTypes[count.StepType] = rate
+		}
+		rate.Add(count.Hits, count.Misses)
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+// memoryVectors is a vector store that matches every point its filter admits
+type memoryVectors struct {
+	ports.VectorRepository
+	points map[uuid.UUID]map[string]interface{}
+}
+
+func (m *memoryVectors) Search(ctx context.Context, query []float32, topK int, minScore float32, filter domain.Filter) ([]domain.LookupResult, error) {
+	var results []domain.LookupResult
+	for id, payload := range m.points {
+		if matchesFilter(payload, filter) {
+			results = append(results, domain.LookupResult{
+				Artifact: &domain.Artifact{ID: id, Metadata: payload},
+				Score:    1,
+			})
+		}
+	}
+	return results, nil
+}
+
+func matchesFilter(payload map[string]interface{}, filter domain.Filter) bool {
+	for _, condition := range filter {
+		value, ok := payload[condition.Field]
+		switch condition.Op {
+		case domain.FilterEq:
+			if !ok || fmt.Sprint(value) != fmt.Sprint(condition.Value) {
+				return false
+			}
+		case domain.FilterNe:
+			if ok && fmt.Sprint(value) == fmt.Sprint(condition.Value) {
+				return false
+			}
+		case domain.FilterIn:
+			found := false
+			for _, candidate := range condition.Value.([]interface{}) {
+				found = found || (ok && fmt.Sprint(value) == fmt.Sprint(candidate))
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// anyArtifacts finds an artifact for every ID, as if point IDs collided with artifacts
+type anyArtifacts struct {
+	ports.ArtifactRepository
+}
+
+func (anyArtifacts) GetMetadataByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	return &domain.Artifact{ID: id, Namespace: domain.DefaultNamespace}, nil
+}
+
+type discardStats struct {
+	ports.CacheStatsRepository
+}
+
+func (discardStats) Increment(ctx context.Context, kind domain.CacheStatKind, stepType string, hits, misses int64) error {
+	return nil
+}
+
+func TestLookupExcludesStepInputVectors(t *testing.T) {
+	artifactID, stepID, sessionID := uuid.New(), uuid.New(), uuid.New()
+	vectors := &memoryVectors{points: map[uuid.UUID]map[string]interface{}{
+		artifactID: {"namespace": domain.DefaultNamespace, "type": "RAW"},
+		stepID:     {"namespace": domain.DefaultNamespace, "kind": stepInputKind, "step_type": "summarize"},
+		sessionID:  {"namespace": domain.DefaultNamespace, "kind": sessionGoalKind},
+	}}
+
+	service := NewCacheService(
+		anyArtifacts{}, vectors, nil, NewHashService(), nil, nil, nil, nil, nil, nil,
+		NewNegativeCache(nil, config.NegativeCacheConfig{}),
+		NewCacheStatsService(discardStats{}),
+		NewAccessTracker(nil, config.AccessConfig{MaxPending: 100}),
+		nil, 0, config.ChunkingConfig{}, 0, nil,
+		NewLookupCache(config.LookupCacheConfig{}),
+		config.LookupConfig{DefaultTopK: 10, MaxTopK: 100},
+	)
+
+	response, err := service.Lookup(context.Background(), domain.LookupOptions{Query: "summarize the report"})
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	if len(response.Results) != 1 || response.Results[0].Artifact.ID != artifactID {
+		t.Fatalf("expected only artifact %s, got %d results", artifactID, len(response.Results))
+	}
+	for _, result := range response.Results {
+		if result.Artifact.ID == stepID {
+			t.Fatalf("lookup returned step input point %s", stepID)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+)
+
+// chunkSpan is a byte range of an artifact's content
+type chunkSpan struct {
+	start int
+	end   int
+}
+
+// splitChunks splits content longer than the chunk size into overlapping spans,
+// ending spans after whitespace where possible and never inside a rune
+func splitChunks(content []byte, cfg config.ChunkingConfig) []chunkSpan {
+	size := cfg.Size
+	if size <= 0 || len(content) <= size {
+		return nil
+	}
+	overlap := cfg.Overlap
+	if overlap < 0 || overlap >= size/2 {
+		overlap = 0
+	}
+
+	var spans []chunkSpan
+	start := 0
+	for start < len(content) {
+		if cfg.MaxChunks > 0 && len(spans) == cfg.MaxChunks {
+			break
+		}
+
+		end := start + size
+		if end >= len(content) {
+			end = len(content)
+		} else if cut := bytes.LastIndexAny(content[start+size/2:end], " \t\r\n"); cut >= 0 {
+			end = start + size/2 + cut + 1
+		} else {
+			end = runeStart(content, end)
+		}
+		spans = append(spans, chunkSpan{start: start, end: end})
+
+		if end == len(content) {
+			break
+		}
+		start = runeStart(content, end-overlap)
+	}
+	return spans
+}
+
+// runeStart moves i back to the first byte of the rune containing it
+func runeStart(content []byte, i int) int {
+	for i > 0 && !utf8.RuneStart(content[i]) {
+		i--
+	}
+	return i
+}
+
+// chunkBytes totals the content stored across chunk spans
+func chunkBytes(spans []chunkSpan) int64 {
+	var total int64
+	for _, span := range spans {
+		total += int64(span.end - span.start)
+	}
+	return total
+}
+
+// embedChunks generates one embedding per chunk span
+func (s *CacheService) embedChunks(ctx context.Context, content []byte, spans []chunkSpan) ([][]float32, error) {
+	if len(spans) == 0 {
+		return nil, nil
+	}
+	if s.embeddingService == nil {
+		return nil, domain.NewValidationError("chunking requires an embedding provider")
+	}
+	if err := s.quotaService.ConsumeEmbeddings(ctx, int64(len(spans))); err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(spans))
+	for i, span := range spans {
+		texts[i] = string(content[span.start:span.end])
+	}
+	embeddings, err := s.embeddingService.GenerateEmbeddings(ctx, texts)
+	if err != nil {
+		return nil, domain.NewUpstreamError("embedding provider", err)
+	}
+	if len(embeddings) != len(spans) {
+		return nil, domain.NewUpstreamError("embedding provider", fmt.Errorf("expected %d embeddings, got %d", len(spans), len(embeddings)))
+	}
+	return embeddings, nil
+}
+
+// chunkPayload builds a chunk's vector payload, carrying over the parent's
+// metadata so lookup filters apply to its chunks too
+func chunkPayload(parent, chunk *domain.Artifact) map[string]interface{} {
+	payload := vectorPayload(chunk)
+	for key, value := range parent.Metadata {
+		if _, ok := payload[key]; !ok {
+			payload[key] = value
+		}
+	}
+	return payload
+}
+
+// storeChunks stores each span of the parent's content as an artifact that
+// depends on the parent, returning the vectors that point lookups back at the
+// parent. The rows join a surrounding transaction; the vectors are queued with
+// them and stored by the caller once it commits
+func (s *CacheService) storeChunks(ctx context.Context, parent *domain.Artifact, content []byte, spans []chunkSpan, embeddings [][]float32) ([]domain.VectorPoint, error) {
+	points := make([]domain.VectorPoint, 0, len(spans))
+	for i, span := range spans {
+		chunkContent := content[span.start:span.end]
+		chunk := domain.Artifact{
+			ID:          uuid.New(),
+			Namespace:   parent.Namespace,
+			Type:        parent.Type,
+			ContentHash: s.hashService.ComputeContentHash(chunkContent),
+			Content:     chunkContent,
+			Metadata: map[string]interface{}{
+				domain.ChunkOfKey:    parent.ID.String(),
+				domain.ChunkIndexKey: i,
+				domain.ChunkStartKey: span.start,
+				domain.ChunkEndKey:   span.end,
+			},
+			Tags:      parent.Tags,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			ExpiresAt: parent.ExpiresAt,
+			Embedding: embeddings[i],
+			Indexed:   true,
+		}
+
+		if err := s.artifactRepo.Store(ctx, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to store chunk: %w", err)
+		}
+		if err := s.artifactRepo.StoreDependency(ctx, parent.ID, chunk.ID); err != nil {
+			return nil, fmt.Errorf("failed to store chunk dependency: %w", err)
+		}
+		points = append(points, domain.VectorPoint{ID: chunk.ID, Embedding: embeddings[i], Payload: chunkPayload(parent, &chunk)})
+	}
+	return points, nil
+}
+
+// chunkIDs lists the chunk artifacts split from a parent's content
+func (s *CacheService) chunkIDs(ctx context.Context, parentID uuid.UUID) ([]uuid.UUID, error) {
+	children, err := s.artifactRepo.GetDependencies(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []uuid.UUID
+	for _, childID := range children {
+		child, err := s.artifactRepo.GetByID(ctx, childID)
+		if err != nil || child == nil || child.Metadata[domain.ChunkOfKey] != parentID.String() {
+			continue
+		}
+		chunks = append(chunks, child.ID)
+	}
+	return chunks, nil
+}
+
+// retireChunks deletes the chunks of an artifact's previous content, listed
+// by chunkIDs before its new chunks were stored
+func (s *CacheService) retireChunks(ctx context.Context, chunks []uuid.UUID) {
+	for _, chunkID := range chunks {
+		if err := s.artifactRepo.Delete(ctx, chunkID); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("artifact_id", chunkID).Warn("Failed to delete chunk of republished artifact")
+			continue
+		}
+		if err := s.vectorRepo.Delete(ctx, chunkID); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("artifact_id", chunkID).Warn("Failed to remove vector of retired chunk")
+		}
+	}
+}
+
+// chunkMatch reads the parent and offsets of a chunk from its vector payload
+func chunkMatch(result domain.LookupResult) (uuid.UUID, domain.ChunkMatch, bool) {
+	metadata := result.Artifact.Metadata
+	parent, _ := metadata[domain.ChunkOfKey].(string)
+	parentID, err := uuid.Parse(parent)
+	if err != nil {
+		return uuid.Nil, domain.ChunkMatch{}, false
+	}
+	return parentID, domain.ChunkMatch{
+		ArtifactID: result.Artifact.ID,
+		Index:      payloadInt(metadata[domain.ChunkIndexKey]),
+		Start:      payloadInt(metadata[domain.ChunkStartKey]),
+		End:        payloadInt(metadata[domain.ChunkEndKey]),
+		Score:      result.Score,
+	}, true
+}
+
+// payloadInt converts a numeric payload value, which may round-trip as any number type
+func payloadInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2024-01-01 is a Monday
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatalf("invalid time %q: %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from string
+		next string
+	}{
+		{"minute step", "*/15 * * * *", "2024-01-01 10:07", "2024-01-01 10:15"},
+		{"strictly after", "@hourly", "2024-01-01 10:00", "2024-01-01 11:00"},
+		{"daily rolls over", "0 0 * * *", "2024-01-01 10:00", "2024-01-02 00:00"},
+		{"weekdays skip the weekend", "0 9 * * 1-5", "2024-01-05 10:00", "2024-01-08 09:00"},
+		{"day-of-month list", "0 0 1,15 * *", "2024-01-02 00:00", "2024-01-15 00:00"},
+		{"restricted day fields match either", "0 0 13 * 5", "2024-01-01 00:00", "2024-01-05 00:00"},
+		{"stepped day of month is unrestricted", "0 0 */2 * 1", "2024-01-01 00:00", "2024-01-15 00:00"},
+		{"stepped day of week is unrestricted", "0 0 1 * */2", "2024-01-01 00:00", "2024-02-01 00:00"},
+		{"sunday as seven", "0 0 * * 7", "2024-01-01 00:00", "2024-01-07 00:00"},
+		{"leap day", "0 0 29 2 *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"month range", "30 6 1 6-8 *", "2024-01-01 00:00", "2024-06-01 06:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.expr, err)
+			}
+			if next := schedule.Next(at(tt.from)); !next.Equal(at(tt.next)) {
+				t.Fatalf("expected %s, got %s", tt.next, next.Format("2006-01-02 15:04"))
+			}
+		})
+	}
+}
+
+func TestCronNeverFires(t *testing.T) {
+	schedule, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if next := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Fatalf("expected no next run, got %s", next)
+	}
+}
+
+func TestParseCronRejects(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
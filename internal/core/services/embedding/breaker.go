@@ -0,0 +1,104 @@
+package embedding
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// circuitBreaker stops calling a provider after consecutive failures. Once the
+// cooldown passes a single probe is let through: success closes the circuit,
+// failure opens it for another cooldown
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    domain.CircuitState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     domain.CircuitClosed,
+	}
+}
+
+// allow reports whether a call may go to the provider, returning a typed
+// upstream error while the circuit is open
+func (b *circuitBreaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case domain.CircuitOpen:
+		if wait := b.cooldown - time.Since(b.openedAt); wait > 0 {
+			return b.openError(wait)
+		}
+		b.state = domain.CircuitHalfOpen
+		return nil
+	case domain.CircuitHalfOpen:
+		// The probe is still in flight
+		return b.openError(0)
+	}
+	return nil
+}
+
+// record counts the outcome of a call that allow let through
+func (b *circuitBreaker) record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.state = domain.CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == domain.CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = domain.CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// abandon releases a call that ended without an outcome, so a cancelled probe
+// does not hold the circuit half-open; the next call probes again
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == domain.CircuitHalfOpen {
+		b.state = domain.CircuitOpen
+	}
+}
+
+func (b *circuitBreaker) snapshot() (domain.CircuitState, *time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == domain.CircuitClosed {
+		return b.state, nil
+	}
+	openedAt := b.openedAt
+	return b.state, &openedAt
+}
+
+func (b *circuitBreaker) openError(retryAfter time.Duration) *domain.Error {
+	return (&domain.Error{
+		Code:    domain.CodeUpstreamUnavailable,
+		Message: "embedding provider circuit is open after repeated failures",
+	}).WithDetail("retry_after", retryAfter.Round(time.Second).String())
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/anunay/mentis/internal/config"
 )
@@ -18,7 +17,7 @@ type GeminiProvider struct {
 	client *http.Client
 }
 
-func NewGeminiProvider(cfg config.GeminiConfig) (*GeminiProvider, error) {
+func NewGeminiProvider(cfg config.GeminiConfig, client *http.Client) (*GeminiProvider, error) {
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("Gemini API key is required")
 	}
@@ -26,9 +25,7 @@ func NewGeminiProvider(cfg config.GeminiConfig) (*GeminiProvider, error) {
 	return &GeminiProvider{
 		apiKey: cfg.APIKey,
 		model:  cfg.Model,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: client,
 	}, nil
 }
 
@@ -0,0 +1,34 @@
+package embedding
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/anunay/mentis/internal/config"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// newHTTPClient builds the client the providers share. Keeping idle connections
+// per host lets concurrent embedding calls reuse connections instead of
+// dialing and handshaking each time; the phase timeouts fail a stuck connection
+// well before the overall request timeout
+func newHTTPClient(cfg config.EmbeddingHTTPConfig) *http.Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: cfg.KeepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:     cfg.HTTP2,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+	}
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: otelhttp.NewTransport(transport),
+	}
+}
@@ -2,12 +2,25 @@ package embedding
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
+	"unicode/utf8"
 
 	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
 	"github.com/anunay/mentis/internal/core/ports"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// SpanName names the span around each provider request
+const SpanName = "embedding.generate"
+
+var tracer = otel.Tracer("github.com/anunay/mentis/internal/core/services/embedding")
+
 type Provider interface {
 	GenerateEmbedding(ctx context.Context, text string) ([]float32, error)
 	GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
@@ -16,10 +29,20 @@ type Provider interface {
 }
 
 type Service struct {
-	provider Provider
+	provider        Provider
+	providerName    string
+	costPer1KTokens float64
+	batchSize       int
+	breaker         *circuitBreaker
+	metrics         providerMetrics
 }
 
-func NewService(cfg config.EmbeddingConfig) (ports.EmbeddingService, error) {
+var (
+	_ ports.EmbeddingService = (*Service)(nil)
+	_ ports.EmbeddingMonitor = (*Service)(nil)
+)
+
+func NewService(cfg config.EmbeddingConfig) (*Service, error) {
 	var provider Provider
 	var err error
 
@@ -28,17 +51,17 @@ func NewService(cfg config.EmbeddingConfig) (ports.EmbeddingService, error) {
 		if cfg.OpenAI.APIKey == "" {
 			return nil, fmt.Errorf("OpenAI API key is required")
 		}
-		provider, err = NewOpenAIProvider(cfg.OpenAI)
+		provider, err = NewOpenAIProvider(cfg.OpenAI, newHTTPClient(cfg.HTTP))
 	case "gemini":
 		if cfg.Gemini.APIKey == "" {
 			return nil, fmt.Errorf("Gemini API key is required")
 		}
-		provider, err = NewGeminiProvider(cfg.Gemini)
+		provider, err = NewGeminiProvider(cfg.Gemini, newHTTPClient(cfg.HTTP))
 	case "openai_compatible":
 		if cfg.Compatible.BaseURL == "" {
 			return nil, fmt.Errorf("Base URL is required for OpenAI-compatible provider")
 		}
-		provider, err = NewOpenAICompatibleProvider(cfg.Compatible)
+		provider, err = NewOpenAICompatibleProvider(cfg.Compatible, newHTTPClient(cfg.HTTP))
 	case "mock":
 		provider = NewMockProvider()
 	default:
@@ -49,15 +72,115 @@ func NewService(cfg config.EmbeddingConfig) (ports.EmbeddingService, error) {
 		return nil, fmt.Errorf("failed to create embedding provider: %w", err)
 	}
 
-	return &Service{provider: provider}, nil
+	return &Service{
+		provider:        provider,
+		providerName:    cfg.Provider,
+		costPer1KTokens: cfg.CostPer1KTokens,
+		batchSize:       cfg.BatchSize,
+		breaker:         newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}, nil
 }
 
 func (s *Service) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
-	return s.provider.GenerateEmbedding(ctx, text)
+	var embedding []float32
+	meterCtx, meter := domain.WithUsageMeter(ctx)
+	err := s.call(meterCtx, 1, func(ctx context.Context) (err error) {
+		embedding, err = s.provider.GenerateEmbedding(ctx, text)
+		return err
+	})
+	if err == nil {
+		s.recordUsage(ctx, meter.Total(), text)
+	}
+	return embedding, err
 }
 
+// GenerateEmbeddings embeds texts in order, splitting them into provider
+// requests of at most the configured batch size
 func (s *Service) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
-	return s.provider.GenerateEmbeddings(ctx, texts)
+	batchSize := s.batchSize
+	if batchSize <= 0 {
+		batchSize = len(texts)
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		end := min(start+batchSize, len(texts))
+		batch := texts[start:end]
+
+		var batchEmbeddings [][]float32
+		meterCtx, meter := domain.WithUsageMeter(ctx)
+		err := s.call(meterCtx, len(batch), func(ctx context.Context) (err error) {
+			batchEmbeddings, err = s.provider.GenerateEmbeddings(ctx, batch)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(batchEmbeddings) != len(batch) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(batch), len(batchEmbeddings))
+		}
+		s.recordUsage(ctx, meter.Total(), batch...)
+		embeddings = append(embeddings, batchEmbeddings...)
+	}
+	return embeddings, nil
+}
+
+// call sends one request to the provider through the circuit breaker, tracing
+// it and recording its latency and outcome
+func (s *Service) call(ctx context.Context, texts int, request func(ctx context.Context) error) error {
+	if err := s.breaker.allow(); err != nil {
+		s.metrics.reject()
+		return err
+	}
+
+	ctx, span := tracer.Start(ctx, SpanName, trace.WithAttributes(
+		attribute.String("mentis.embedding.provider", s.providerName),
+		attribute.String("mentis.embedding.model", s.provider.GetModelName()),
+		attribute.Int("mentis.embedding.texts", texts),
+	))
+	start := time.Now()
+	err := request(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+
+	// A caller giving up says nothing about the provider's health
+	if errors.Is(err, context.Canceled) {
+		s.breaker.abandon()
+		return err
+	}
+	s.metrics.record(latency, err)
+	s.breaker.record(err != nil)
+	return err
+}
+
+// ProviderStats reports request counts, latency, and circuit state for the provider
+func (s *Service) ProviderStats() []domain.EmbeddingProviderStats {
+	stats := s.metrics.snapshot()
+	stats.Provider = s.providerName
+	stats.Model = s.provider.GetModelName()
+	stats.Circuit, stats.CircuitOpenedAt = s.breaker.snapshot()
+	return []domain.EmbeddingProviderStats{stats}
+}
+
+// recordUsage prices the tokens a provider reported, estimating them from the
+// input length for providers that do not report usage
+func (s *Service) recordUsage(ctx context.Context, usage domain.Usage, texts ...string) {
+	if usage.EmbeddingTokens == 0 {
+		for _, text := range texts {
+			usage.EmbeddingTokens += estimateTokens(text)
+		}
+	}
+	usage.CostUSD = float64(usage.EmbeddingTokens) / 1000 * s.costPer1KTokens
+	domain.RecordUsage(ctx, usage)
+}
+
+// estimateTokens approximates a token count at roughly four characters per token
+func estimateTokens(text string) int64 {
+	return int64((utf8.RuneCountInString(text) + 3) / 4)
 }
 
 func (s *Service) GetDimensions() int {
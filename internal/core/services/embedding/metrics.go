@@ -0,0 +1,62 @@
+package embedding
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// providerMetrics counts a provider's calls, failures, and latency since startup
+type providerMetrics struct {
+	mu           sync.Mutex
+	requests     int64
+	failures     int64
+	rejected     int64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	lastError    string
+	lastErrorAt  time.Time
+}
+
+func (m *providerMetrics) record(latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests++
+	m.totalLatency += latency
+	m.maxLatency = max(m.maxLatency, latency)
+	if err != nil {
+		m.failures++
+		m.lastError = err.Error()
+		m.lastErrorAt = time.Now().UTC()
+	}
+}
+
+func (m *providerMetrics) reject() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rejected++
+}
+
+func (m *providerMetrics) snapshot() domain.EmbeddingProviderStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := domain.EmbeddingProviderStats{
+		Requests:     m.requests,
+		Failures:     m.failures,
+		Rejected:     m.rejected,
+		MaxLatencyMS: float64(m.maxLatency.Microseconds()) / 1000,
+		LastError:    m.lastError,
+	}
+	if m.requests > 0 {
+		stats.ErrorRate = float64(m.failures) / float64(m.requests)
+		stats.AvgLatencyMS = float64(m.totalLatency.Microseconds()) / 1000 / float64(m.requests)
+	}
+	if !m.lastErrorAt.IsZero() {
+		lastErrorAt := m.lastErrorAt
+		stats.LastErrorAt = &lastErrorAt
+	}
+	return stats
+}
@@ -7,9 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
 )
 
 type OpenAIProvider struct {
@@ -18,7 +18,7 @@ type OpenAIProvider struct {
 	client *http.Client
 }
 
-func NewOpenAIProvider(cfg config.OpenAIConfig) (*OpenAIProvider, error) {
+func NewOpenAIProvider(cfg config.OpenAIConfig, client *http.Client) (*OpenAIProvider, error) {
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("OpenAI API key is required")
 	}
@@ -26,9 +26,7 @@ func NewOpenAIProvider(cfg config.OpenAIConfig) (*OpenAIProvider, error) {
 	return &OpenAIProvider{
 		apiKey: cfg.APIKey,
 		model:  cfg.Model,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: client,
 	}, nil
 }
 
@@ -103,6 +101,8 @@ func (p *OpenAIProvider) GenerateEmbeddings(ctx context.Context, texts []string)
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	domain.RecordUsage(ctx, domain.Usage{EmbeddingTokens: int64(embeddingResp.Usage.TotalTokens)})
+
 	embeddings := make([][]float32, len(embeddingResp.Data))
 	for i, data := range embeddingResp.Data {
 		embeddings[i] = data.Embedding
@@ -8,9 +8,9 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
 )
 
 type OpenAICompatibleProvider struct {
@@ -20,7 +20,7 @@ type OpenAICompatibleProvider struct {
 	client  *http.Client
 }
 
-func NewOpenAICompatibleProvider(cfg config.OpenAICompatibleConfig) (*OpenAICompatibleProvider, error) {
+func NewOpenAICompatibleProvider(cfg config.OpenAICompatibleConfig, client *http.Client) (*OpenAICompatibleProvider, error) {
 	if cfg.BaseURL == "" {
 		return nil, fmt.Errorf("base URL is required for OpenAI-compatible provider")
 	}
@@ -35,9 +35,7 @@ func NewOpenAICompatibleProvider(cfg config.OpenAICompatibleConfig) (*OpenAIComp
 		baseURL: baseURL,
 		apiKey:  cfg.APIKey,
 		model:   cfg.Model,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: client,
 	}, nil
 }
 
@@ -118,6 +116,8 @@ func (p *OpenAICompatibleProvider) GenerateEmbeddings(ctx context.Context, texts
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	domain.RecordUsage(ctx, domain.Usage{EmbeddingTokens: int64(embeddingResp.Usage.TotalTokens)})
+
 	embeddings := make([][]float32, len(embeddingResp.Data))
 	for i, data := range embeddingResp.Data {
 		embeddings[i] = data.Embedding
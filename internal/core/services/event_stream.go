@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/sirupsen/logrus"
+)
+
+// drainTimeout bounds publishing the events still buffered on shutdown
+const drainTimeout = 5 * time.Second
+
+// EventStreamer publishes domain events to a message broker so other systems
+// can react to them without polling. Events are buffered and published in
+// order in the background; when the broker falls behind and the buffer fills,
+// further events are dropped rather than slowing requests down
+type EventStreamer struct {
+	broker      ports.EventBroker
+	topicPrefix string
+	timeout     time.Duration
+	events      chan *domain.WebhookEvent
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewEventStreamer(broker ports.EventBroker, cfg config.EventsConfig) *EventStreamer {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	return &EventStreamer{
+		broker:      broker,
+		topicPrefix: cfg.TopicPrefix,
+		timeout:     cfg.Timeout,
+		events:      make(chan *domain.WebhookEvent, bufferSize),
+	}
+}
+
+func (s *EventStreamer) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go s.loop(ctx)
+
+	logrus.WithField("topic_prefix", s.topicPrefix).Info("Event streamer started")
+}
+
+// Stop publishes the events still buffered, within a grace period, and closes the broker
+func (s *EventStreamer) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	s.drain()
+
+	if err := s.broker.Close(); err != nil {
+		logrus.WithError(err).Warn("Failed to close event broker")
+	}
+	logrus.Info("Event streamer stopped")
+}
+
+// Notify queues event for the broker; callback URLs are the webhook dispatcher's concern
+func (s *EventStreamer) Notify(_ []string, event *domain.WebhookEvent) {
+	select {
+	case s.events <- event:
+	default:
+		logrus.WithFields(logrus.Fields{"event_id": event.ID, "event": event.Type}).Warn("Event buffer full, dropping event")
+	}
+}
+
+func (s *EventStreamer) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-s.events:
+			// Shutdown doesn't cut a publish short; Stop waits for it
+			s.publish(context.WithoutCancel(ctx), event)
+		}
+	}
+}
+
+// drain publishes buffered events until the buffer is empty or the grace period ends
+func (s *EventStreamer) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	for ctx.Err() == nil {
+		select {
+		case event := <-s.events:
+			s.publish(ctx, event)
+		default:
+			return
+		}
+	}
+}
+
+// publish sends event to the topic of its type, keyed by namespace so a
+// namespace's events stay in order on partitioned brokers
+func (s *EventStreamer) publish(ctx context.Context, event *domain.WebhookEvent) {
+	log := logrus.WithFields(logrus.Fields{"event_id": event.ID, "event": event.Type})
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal event")
+		return
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	topic := s.topicPrefix + "." + string(event.Type)
+	if err := s.broker.Publish(ctx, topic, event.Namespace, payload); err != nil {
+		log.WithError(err).WithField("topic", topic).Warn("Failed to publish event")
+	}
+}
+
+// Notifiers hands each event to every notifier, such as the webhook
+// dispatcher and the event streamer
+type Notifiers []ports.WebhookNotifier
+
+func (n Notifiers) Notify(urls []string, event *domain.WebhookEvent) {
+	for _, notifier := range n {
+		notifier.Notify(urls, event)
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// ArtifactEvictor periodically deletes least-recently-accessed unpinned artifacts
+// while storage exceeds the configured budget
+type ArtifactEvictor struct {
+	artifactRepo ports.ArtifactRepository
+	vectorRepo   ports.VectorRepository
+	blobStore    ports.BlobStore
+	lookupCache  *LookupCache
+	leader       *LeaderElector
+	cfg          config.EvictionConfig
+
+	runs             atomic.Int64
+	evictedArtifacts atomic.Int64
+	evictedBytes     atomic.Int64
+	lastRunAt        atomic.Pointer[time.Time]
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewArtifactEvictor(artifactRepo ports.ArtifactRepository, vectorRepo ports.VectorRepository, blobStore ports.BlobStore, lookupCache *LookupCache, leader *LeaderElector, cfg config.EvictionConfig) *ArtifactEvictor {
+	return &ArtifactEvictor{
+		artifactRepo: artifactRepo,
+		vectorRepo:   vectorRepo,
+		blobStore:    blobStore,
+		lookupCache:  lookupCache,
+		leader:       leader,
+		cfg:          cfg,
+	}
+}
+
+func (e *ArtifactEvictor) Start(ctx context.Context) {
+	ctx, e.cancel = context.WithCancel(ctx)
+
+	status.Track(evictorComponent)
+	e.wg.Add(1)
+	go e.loop(ctx)
+
+	logrus.WithFields(logrus.Fields{
+		"max_artifacts": e.cfg.MaxArtifacts,
+		"max_bytes":     e.cfg.MaxBytes,
+		"interval":      e.cfg.Interval,
+	}).Info("Artifact evictor started")
+}
+
+func (e *ArtifactEvictor) Stop() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.wg.Wait()
+	logrus.Info("Artifact evictor stopped")
+}
+
+// Stats reports the budget, current storage usage, and evictions since start
+func (e *ArtifactEvictor) Stats(ctx context.Context) (*domain.EvictionStats, error) {
+	count, bytes, err := e.artifactRepo.StorageUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure artifact storage: %w", err)
+	}
+
+	return &domain.EvictionStats{
+		MaxArtifacts:     e.cfg.MaxArtifacts,
+		MaxBytes:         e.cfg.MaxBytes,
+		Artifacts:        count,
+		Bytes:            bytes,
+		Runs:             e.runs.Load(),
+		EvictedArtifacts: e.evictedArtifacts.Load(),
+		EvictedBytes:     e.evictedBytes.Load(),
+		LastRunAt:        e.lastRunAt.Load(),
+	}, nil
+}
+
+func (e *ArtifactEvictor) loop(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if e.leader.Leads(ctx, evictorComponent) {
+			status.RecordRun(ctx, evictorComponent, e.evict(ctx))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// evict deletes artifacts in batches until storage is back within budget
+func (e *ArtifactEvictor) evict(ctx context.Context) error {
+	evicted := 0
+	var failure error
+
+	for ctx.Err() == nil {
+		count, bytes, err := e.artifactRepo.StorageUsage(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).Error("Failed to measure artifact storage")
+			}
+			failure = err
+			break
+		}
+		if !e.overBudget(count, bytes) {
+			break
+		}
+
+		artifacts, err := e.artifactRepo.EvictLRU(ctx, e.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).Error("Failed to evict artifacts")
+			}
+			failure = err
+			break
+		}
+
+		for _, artifact := range artifacts {
+			removeArtifactData(ctx, e.vectorRepo, e.blobStore, artifact)
+			e.evictedBytes.Add(artifact.Bytes)
+		}
+		e.evictedArtifacts.Add(int64(len(artifacts)))
+		evicted += len(artifacts)
+
+		// Everything left is pinned or locked by a concurrent evictor
+		if len(artifacts) == 0 {
+			logrus.Warn("Artifact storage exceeds its budget but nothing more can be evicted")
+			break
+		}
+	}
+
+	now := time.Now()
+	e.lastRunAt.Store(&now)
+	e.runs.Add(1)
+
+	if evicted > 0 {
+		e.lookupCache.InvalidateAll()
+		logrus.WithField("count", evicted).Info("Evicted least recently used artifacts")
+	}
+	return failure
+}
+
+func (e *ArtifactEvictor) overBudget(count, bytes int64) bool {
+	return (e.cfg.MaxArtifacts > 0 && count > e.cfg.MaxArtifacts) ||
+		(e.cfg.MaxBytes > 0 && bytes > e.cfg.MaxBytes)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ExecutionManager tracks in-flight step executions so shutdown can stop new
+// work, wait for running steps, and interrupt those that outlive the deadline
+type ExecutionManager struct {
+	mu       sync.Mutex
+	stopping chan struct{}
+	running  map[uuid.UUID]context.CancelCauseFunc
+	wg       sync.WaitGroup
+}
+
+func NewExecutionManager() *ExecutionManager {
+	return &ExecutionManager{
+		stopping: make(chan struct{}),
+		running:  make(map[uuid.UUID]context.CancelCauseFunc),
+	}
+}
+
+// Track registers an execution of a step; the returned context is cancelled with
+// domain.ErrStepInterrupted if shutdown gives up on it, and done must be called
+// when the execution ends
+func (m *ExecutionManager) Track(ctx context.Context, stepID uuid.UUID) (context.Context, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isStopping() {
+		return nil, nil, domain.ErrShuttingDown
+	}
+
+	execCtx, cancel := context.WithCancelCause(ctx)
+	m.running[stepID] = cancel
+	m.wg.Add(1)
+
+	done := func() {
+		m.mu.Lock()
+		delete(m.running, stepID)
+		m.mu.Unlock()
+		cancel(nil)
+		m.wg.Done()
+	}
+	return execCtx, done, nil
+}
+
+// StopAccepting makes further Track calls fail with domain.ErrShuttingDown
+func (m *ExecutionManager) StopAccepting() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.isStopping() {
+		close(m.stopping)
+	}
+}
+
+// Drain stops accepting executions and waits for running ones until ctx is done,
+// then interrupts the rest and waits for them to record it. It returns how many
+// executions were interrupted.
+func (m *ExecutionManager) Drain(ctx context.Context) int {
+	m.StopAccepting()
+
+	finished := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return 0
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	interrupted := len(m.running)
+	for stepID, cancel := range m.running {
+		logrus.WithField("step_id", stepID).Warn("Interrupting step execution for shutdown")
+		cancel(domain.ErrStepInterrupted)
+	}
+	m.mu.Unlock()
+
+	<-finished
+	return interrupted
+}
+
+func (m *ExecutionManager) isStopping() bool {
+	select {
+	case <-m.stopping:
+		return true
+	default:
+		return false
+	}
+}
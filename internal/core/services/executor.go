@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxClaimLimit bounds how many steps an external executor can lease at once
+const maxClaimLimit = 100
+
+// ClaimSteps leases queued external steps to the calling executor; unfinished
+// leases return to the queue after the worker lease timeout
+func (s *WorkflowService) ClaimSteps(ctx context.Context, req *domain.ClaimStepsRequest) (*domain.ClaimStepsResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 1
+	}
+	if limit > maxClaimLimit {
+		limit = maxClaimLimit
+	}
+
+	jobs, err := s.jobRepo.ClaimExternal(ctx, limit, req.StepTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim steps: %w", err)
+	}
+
+	response := &domain.ClaimStepsResponse{Steps: []domain.ClaimedStep{}}
+	for _, job := range jobs {
+		step, err := s.workflowRepo.GetStep(ctx, job.StepID)
+		if err != nil || step == nil {
+			logging.FromContext(ctx).WithError(err).WithField("job_id", job.ID).Warn("Dropping step job without a step")
+			s.jobRepo.Fail(ctx, job.ID, job.LeaseToken, "step not found")
+			continue
+		}
+
+		step.Status = domain.StepRunning
+		if err := s.workflowRepo.UpdateStep(ctx, step); err != nil {
+			return nil, fmt.Errorf("failed to update step: %w", err)
+		}
+
+		s.recordStepEvent(ctx, domain.SessionEventStepStarted, step, map[string]interface{}{
+			"attempt":  job.Attempts,
+			"external": true,
+		})
+
+		claimed := domain.ClaimedStep{
+			Step:    step,
+			Input:   job.Input,
+			Attempt: job.Attempts,
+		}
+		if job.LeaseToken != nil {
+			claimed.LeaseToken = *job.LeaseToken
+		}
+		response.Steps = append(response.Steps, claimed)
+	}
+
+	return response, nil
+}
+
+// CompleteStep records the result an external executor reports for a claimed step
+func (s *WorkflowService) CompleteStep(ctx context.Context, stepID uuid.UUID, req *domain.CompleteStepRequest) (*domain.WorkflowStepResponse, error) {
+	if (req.Artifact == nil) == (req.Error == "") {
+		return nil, domain.NewValidationError("exactly one of artifact or error is required")
+	}
+	if req.LeaseToken == uuid.Nil {
+		return nil, domain.NewValidationError("lease_token is required")
+	}
+	if req.Usage != nil {
+		if err := req.Usage.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	step, err := s.workflowRepo.GetStep(ctx, stepID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step: %w", err)
+	}
+	if step == nil {
+		return nil, domain.NewNotFoundError("step", stepID)
+	}
+
+	job, err := s.jobRepo.GetRunningByStep(ctx, stepID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step job: %w", err)
+	}
+	if job == nil || !job.External {
+		return nil, domain.NewConflictError("step is not claimed by an external executor", nil)
+	}
+	// A lease that expired and was claimed again belongs to the new executor.
+	// Renewing it checks the token and keeps it from expiring while the result
+	// is recorded; the job's final update checks it again
+	if err := s.jobRepo.Heartbeat(ctx, job.ID, &req.LeaseToken); err != nil {
+		return nil, err
+	}
+
+	attempt := domain.StepAttempt{
+		Attempt:   len(stepAttempts(step)) + 1,
+		StartedAt: time.Now(),
+	}
+	if job.LockedAt != nil {
+		attempt.StartedAt = *job.LockedAt
+	}
+
+	if req.Usage != nil {
+		step.Usage.Add(*req.Usage)
+	}
+
+	meterCtx, meter := domain.WithUsageMeter(ctx)
+	var artifact *domain.Artifact
+	var stepErr error
+	if req.Error != "" {
+		code := req.ErrorCode
+		if code == "" {
+			code = domain.CodeInternal
+		}
+		stepErr = &domain.Error{Code: code, Message: req.Error}
+	} else {
+		artifactType := req.Artifact.Type
+		if artifactType == "" {
+			artifactType = domain.DERIVED
+		}
+		artifact, stepErr = s.newStepArtifact(meterCtx, step, artifactType, []byte(req.Artifact.Content), req.Artifact.Metadata)
+		if stepErr == nil {
+			stepErr = s.schemaService.ValidateOutput(meterCtx, step.StepType, artifact.Content)
+		}
+	}
+	step.Usage.Add(meter.Total())
+
+	outcomeErr := s.finishAttempt(ctx, step, attempt, artifact, stepErr)
+	if err := s.resolveExternalJob(ctx, job, step, attempt.Attempt, stepErr, outcomeErr); err != nil {
+		return nil, err
+	}
+	if stepErr == nil && outcomeErr == nil {
+		s.indexStepInput(ctx, step, job.Input)
+	}
+
+	// A reported failure is a successful report; storage and validation failures are the caller's
+	if outcomeErr != nil && req.Error == "" {
+		return nil, outcomeErr
+	}
+
+	return &domain.WorkflowStepResponse{
+		Step:     step,
+		Artifact: artifact,
+	}, nil
+}
+
+// resolveExternalJob completes, reschedules, or fails the job of an externally
+// executed step under the executor's lease. Losing the lease is returned as a
+// conflict; other failures are logged
+func (s *WorkflowService) resolveExternalJob(ctx context.Context, job *domain.StepJob, step *domain.WorkflowStep, attempt int, stepErr, outcomeErr error) error {
+	log := logging.FromContext(ctx).WithFields(logrus.Fields{"job_id": job.ID, "step_id": step.ID})
+
+	var err error
+	switch {
+	case stepErr == nil && outcomeErr == nil:
+		err = s.jobRepo.Complete(ctx, job.ID, job.LeaseToken)
+	case stepErr != nil && s.retryPolicies.For(step.StepType).ShouldRetry(attempt, stepErr):
+		after := s.retryPolicies.For(step.StepType).Backoff(attempt)
+		err = s.jobRepo.Retry(ctx, job.ID, job.LeaseToken, time.Now().Add(after), stepErr.Error())
+	default:
+		reason := outcomeErr
+		if stepErr != nil {
+			reason = stepErr
+		}
+		err = s.jobRepo.Fail(ctx, job.ID, job.LeaseToken, reason.Error())
+	}
+
+	if errors.Is(err, domain.ErrConflict) {
+		return err
+	}
+	if err != nil {
+		log.WithError(err).Error("Failed to update external step job")
+	}
+	return nil
+}
+
+// RenewStepLease extends an external executor's lease on a claimed step, so a
+// step running longer than the lease timeout isn't returned to the queue
+func (s *WorkflowService) RenewStepLease(ctx context.Context, stepID uuid.UUID, req *domain.RenewStepLeaseRequest) error {
+	if req.LeaseToken == uuid.Nil {
+		return domain.NewValidationError("lease_token is required")
+	}
+
+	job, err := s.jobRepo.GetRunningByStep(ctx, stepID)
+	if err != nil {
+		return fmt.Errorf("failed to get step job: %w", err)
+	}
+	if job == nil || !job.External {
+		return domain.NewConflictError("step is not claimed by an external executor", nil)
+	}
+	return s.jobRepo.Heartbeat(ctx, job.ID, &req.LeaseToken)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+)
+
+// lookupQueries lists the distinct phrasings a lookup searches, the original
+// query first. Expansion is best effort: the lookup goes ahead without
+// paraphrases when the model fails
+func (s *CacheService) lookupQueries(ctx context.Context, options domain.LookupOptions) []string {
+	queries := []string{options.Query}
+	seen := map[string]bool{strings.ToLower(options.Query): true}
+	add := func(candidates []string) {
+		for _, query := range candidates {
+			query = strings.TrimSpace(query)
+			key := strings.ToLower(query)
+			if query == "" || seen[key] || len(queries) >= domain.MaxLookupQueries {
+				continue
+			}
+			seen[key] = true
+			queries = append(queries, query)
+		}
+	}
+
+	add(options.Queries)
+	if options.Expand {
+		paraphrases, err := s.queryExpander.Expand(ctx, options.Query)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Warn("Failed to expand lookup query")
+		}
+		add(paraphrases)
+	}
+	return queries
+}
+
+// queryEmbeddings embeds lookup queries in a single batch
+func (s *CacheService) queryEmbeddings(ctx context.Context, queries []string) ([][]float32, error) {
+	if s.embeddingService == nil || len(queries) == 1 {
+		embeddings := make([][]float32, len(queries))
+		for i, query := range queries {
+			embedding, err := s.queryEmbedding(ctx, query)
+			if err != nil {
+				return nil, err
+			}
+			embeddings[i] = embedding
+		}
+		return embeddings, nil
+	}
+
+	if err := s.quotaService.ConsumeEmbeddings(ctx, int64(len(queries))); err != nil {
+		return nil, err
+	}
+	embeddings, err := s.embeddingService.GenerateEmbeddings(ctx, queries)
+	if err != nil {
+		return nil, domain.NewUpstreamError("embedding provider", err)
+	}
+	return embeddings, nil
+}
+
+// searchQueries searches with each query embedding and merges the results,
+// keeping each vector's best score, best first. Results record the phrasing
+// that scored best in their explanation
+func (s *CacheService) searchQueries(ctx context.Context, queries []string, embeddings [][]float32, limit int, minScore float32, filter domain.Filter) ([]domain.LookupResult, error) {
+	if len(embeddings) == 1 {
+		return s.vectorRepo.Search(ctx, embeddings[0], limit, minScore, filter)
+	}
+
+	best := make(map[uuid.UUID]int)
+	var merged []domain.LookupResult
+	for q, embedding := range embeddings {
+		results, err := s.vectorRepo.Search(ctx, embedding, limit, minScore, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			result.Explanation = &domain.ScoreExplanation{MatchedQuery: queries[q]}
+			i, ok := best[result.Artifact.ID]
+			if !ok {
+				best[result.Artifact.ID] = len(merged)
+				merged = append(merged, result)
+			} else if result.Score > merged[i].Score {
+				merged[i] = result
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Score > merged[j].Score
+	})
+	if len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged, nil
+}
@@ -0,0 +1,134 @@
+package expansion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const systemPrompt = "You rewrite search queries for a semantic cache. Reply with %d alternative phrasings of the user's query, one per line, without numbering or commentary. Keep each close in meaning to the original."
+
+// Expander asks an OpenAI-compatible chat completions endpoint for paraphrases
+type Expander struct {
+	baseURL     string
+	apiKey      string
+	model       string
+	paraphrases int
+	client      *http.Client
+}
+
+// NewQueryExpander returns nil when no expansion endpoint is configured
+func NewQueryExpander(cfg config.ExpansionConfig) (ports.QueryExpander, error) {
+	if cfg.BaseURL == "" {
+		return nil, nil
+	}
+	if cfg.Paraphrases <= 0 {
+		return nil, fmt.Errorf("query expansion paraphrases must be positive")
+	}
+
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if !strings.HasSuffix(baseURL, "/v1") {
+		baseURL += "/v1"
+	}
+
+	return &Expander{
+		baseURL:     baseURL,
+		apiKey:      cfg.APIKey,
+		model:       cfg.Model,
+		paraphrases: cfg.Paraphrases,
+		client:      &http.Client{Timeout: cfg.Timeout, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+	}, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Expand returns up to the configured number of paraphrases of query
+func (e *Expander) Expand(ctx context.Context, query string) ([]string, error) {
+	jsonData, err := json.Marshal(chatRequest{
+		Model: e.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: fmt.Sprintf(systemPrompt, e.paraphrases)},
+			{Role: "user", Content: query},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var chatResp chatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("no completion returned")
+	}
+
+	domain.RecordUsage(ctx, domain.Usage{
+		PromptTokens:     int64(chatResp.Usage.PromptTokens),
+		CompletionTokens: int64(chatResp.Usage.CompletionTokens),
+	})
+
+	var paraphrases []string
+	for _, line := range strings.Split(chatResp.Choices[0].Message.Content, "\n") {
+		// Models number their lines despite being asked not to
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*0123456789.)"))
+		if line == "" || strings.EqualFold(line, query) {
+			continue
+		}
+		paraphrases = append(paraphrases, line)
+		if len(paraphrases) == e.paraphrases {
+			break
+		}
+	}
+	return paraphrases, nil
+}
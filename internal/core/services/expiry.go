@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// ArtifactTTLs resolves the default lifetime of artifacts by type
+type ArtifactTTLs struct {
+	ttls map[domain.ArtifactType]time.Duration
+}
+
+func NewArtifactTTLs(cfg config.ExpiryConfig) *ArtifactTTLs {
+	ttls := make(map[domain.ArtifactType]time.Duration, len(cfg.TTLs))
+	for artifactType, value := range cfg.TTLs {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			logrus.WithField("artifact_type", artifactType).Warnf("Ignoring invalid artifact TTL %q", value)
+			continue
+		}
+		ttls[domain.ArtifactType(artifactType)] = ttl
+	}
+	return &ArtifactTTLs{ttls: ttls}
+}
+
+// Apply validates an artifact's explicit expiry or defaults it from the type's TTL
+func (t *ArtifactTTLs) Apply(artifact *domain.Artifact, now time.Time) error {
+	if artifact.ExpiresAt != nil {
+		if !artifact.ExpiresAt.After(now) {
+			return domain.NewValidationError("expires_at must be in the future")
+		}
+		return nil
+	}
+
+	if ttl, ok := t.ttls[artifact.Type]; ok {
+		expiresAt := now.Add(ttl)
+		artifact.ExpiresAt = &expiresAt
+	}
+	return nil
+}
+
+// ArtifactSweeper periodically marks expired artifacts stale and removes their vectors,
+// enforces invalidation policies, and purges deleted artifacts once they are past
+// their retention
+type ArtifactSweeper struct {
+	artifactRepo     ports.ArtifactRepository
+	policyRepo       ports.InvalidationPolicyRepository
+	vectorRepo       ports.VectorRepository
+	blobStore        ports.BlobStore
+	lookupCache      *LookupCache
+	interval         time.Duration
+	batchSize        int
+	deletedRetention time.Duration
+	leader           *LeaderElector
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewArtifactSweeper(artifactRepo ports.ArtifactRepository, policyRepo ports.InvalidationPolicyRepository, vectorRepo ports.VectorRepository, blobStore ports.BlobStore, lookupCache *LookupCache, leader *LeaderElector, cfg config.ExpiryConfig) *ArtifactSweeper {
+	return &ArtifactSweeper{
+		artifactRepo:     artifactRepo,
+		policyRepo:       policyRepo,
+		vectorRepo:       vectorRepo,
+		blobStore:        blobStore,
+		lookupCache:      lookupCache,
+		interval:         cfg.SweepInterval,
+		batchSize:        cfg.SweepBatch,
+		deletedRetention: cfg.DeletedRetention,
+		leader:           leader,
+	}
+}
+
+func (s *ArtifactSweeper) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	status.Track(sweeperComponent)
+	s.wg.Add(1)
+	go s.loop(ctx)
+
+	logrus.WithField("interval", s.interval).Info("Artifact expiry sweeper started")
+}
+
+func (s *ArtifactSweeper) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	logrus.Info("Artifact expiry sweeper stopped")
+}
+
+func (s *ArtifactSweeper) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if s.leader.Leads(ctx, sweeperComponent) {
+			status.RecordRun(ctx, sweeperComponent, errors.Join(s.sweep(ctx), s.enforcePolicies(ctx), s.purge(ctx)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep expires artifacts in batches until none are due
+func (s *ArtifactSweeper) sweep(ctx context.Context) error {
+	now := time.Now()
+	expired := 0
+	var failure error
+
+	for ctx.Err() == nil {
+		ids, err := s.artifactRepo.ExpireDue(ctx, now, s.batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).Error("Failed to expire artifacts")
+			}
+			failure = err
+			break
+		}
+
+		for _, id := range ids {
+			// The artifact is already stale, so a leftover vector only points at stale content
+			if err := s.vectorRepo.Delete(ctx, id); err != nil {
+				logrus.WithError(err).WithField("artifact_id", id).Warn("Failed to remove vector of expired artifact")
+			}
+		}
+
+		expired += len(ids)
+		if len(ids) < s.batchSize {
+			break
+		}
+	}
+
+	if expired > 0 {
+		s.lookupCache.InvalidateAll()
+		logrus.WithField("count", expired).Info("Expired artifacts")
+	}
+	return failure
+}
+
+// enforcePolicies marks artifacts stale that an invalidation policy considers too
+// old; their vectors stay, flagged stale, so a refresh or republish revives them
+func (s *ArtifactSweeper) enforcePolicies(ctx context.Context) error {
+	invalidated := 0
+	var failure error
+
+	for ctx.Err() == nil {
+		ids, err := s.policyRepo.Enforce(ctx, s.batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).Error("Failed to enforce invalidation policies")
+			}
+			failure = err
+			break
+		}
+
+		if err := s.vectorRepo.SetPayload(ctx, ids, map[string]interface{}{"stale": true}); err != nil {
+			logrus.WithError(err).WithField("count", len(ids)).Warn("Failed to mark vectors stale")
+		}
+
+		invalidated += len(ids)
+		if len(ids) < s.batchSize {
+			break
+		}
+	}
+
+	if invalidated > 0 {
+		s.lookupCache.InvalidateAll()
+		logrus.WithField("count", invalidated).Info("Invalidated artifacts by policy")
+	}
+	return failure
+}
+
+// purge permanently removes deleted artifacts past their retention, with their vectors
+func (s *ArtifactSweeper) purge(ctx context.Context) error {
+	before := time.Now().Add(-s.deletedRetention)
+	purged := 0
+	var failure error
+
+	for ctx.Err() == nil {
+		removed, err := s.artifactRepo.PurgeDeleted(ctx, before, s.batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).Error("Failed to purge deleted artifacts")
+			}
+			failure = err
+			break
+		}
+
+		for _, artifact := range removed {
+			removeArtifactData(ctx, s.vectorRepo, s.blobStore, artifact)
+		}
+
+		purged += len(removed)
+		if len(removed) < s.batchSize {
+			break
+		}
+	}
+
+	if purged > 0 {
+		s.lookupCache.InvalidateAll()
+		logrus.WithField("count", purged).Info("Purged deleted artifacts")
+	}
+	return failure
+}
+
+// removeArtifactData deletes the vector and offloaded blobs of a permanently
+// removed artifact; leftovers are only logged since nothing references them
+func removeArtifactData(ctx context.Context, vectorRepo ports.VectorRepository, blobStore ports.BlobStore, artifact domain.RemovedArtifact) {
+	if err := vectorRepo.Delete(ctx, artifact.ID); err != nil {
+		logrus.WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to remove vector of removed artifact")
+	}
+	if blobStore == nil {
+		return
+	}
+	for _, key := range artifact.BlobKeys {
+		if err := blobStore.Delete(ctx, key); err != nil {
+			logrus.WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to remove blob of removed artifact")
+		}
+	}
+}
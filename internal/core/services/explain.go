@@ -0,0 +1,54 @@
+package services
+
+import (
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// rankArtifacts numbers the artifacts of vector results from 1, best first,
+// ranking chunk matches as their parent
+func rankArtifacts(results []domain.LookupResult) map[uuid.UUID]int {
+	ranks := make(map[uuid.UUID]int)
+	for _, vr := range results {
+		id := vr.Artifact.ID
+		if parentID, _, isChunk := chunkMatch(vr); isChunk {
+			id = parentID
+		}
+		if _, ok := ranks[id]; !ok {
+			ranks[id] = len(ranks) + 1
+		}
+	}
+	return ranks
+}
+
+// explainResult describes how a lookup ranked a result. vr is the artifact's
+// best vector result, or a stand-in when only its text matched in hybrid mode
+func explainResult(options domain.LookupOptions, tags []string, artifact *domain.Artifact, vr domain.LookupResult, match *fusedMatch, vectorRank int) *domain.ScoreExplanation {
+	explanation := &domain.ScoreExplanation{
+		Mode:           options.Mode,
+		VectorScore:    vr.Score,
+		VectorRank:     vectorRank,
+		MatchedQuery:   options.Query,
+		MatchedFilters: options.Filter,
+	}
+	if vr.Explanation != nil {
+		explanation.MatchedQuery = vr.Explanation.MatchedQuery
+	}
+	if match != nil {
+		explanation.VectorScore = match.vectorScore
+		explanation.TextScore = match.textScore
+		explanation.TextRank = match.textRank
+		explanation.FusedScore = match.score
+	}
+
+	// Lookups match artifacts carrying any of the requested tags
+	for _, tag := range artifact.Tags {
+		for _, requested := range tags {
+			if tag == requested {
+				explanation.MatchedTags = append(explanation.MatchedTags, tag)
+				break
+			}
+		}
+	}
+	return explanation
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"golang.org/x/net/html/charset"
+)
+
+// feedEntry is a page a sitemap or feed lists, with when it was last
+// modified; Modified is zero when the feed doesn't say
+type feedEntry struct {
+	URL      string
+	Modified time.Time
+}
+
+// parsedFeed holds a feed's pages, or for a sitemap index the sitemaps it lists
+type parsedFeed struct {
+	Entries  []feedEntry
+	Sitemaps []feedEntry
+}
+
+type sitemapURLSet struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+type sitemapIndex struct {
+	Sitemaps []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"sitemap"`
+}
+
+// rssItem covers RSS 2.0 items and RSS 1.0 (RDF) items, which date with dc:date
+type rssItem struct {
+	Link    string `xml:"link"`
+	PubDate string `xml:"pubDate"`
+	Date    string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type rssFeed struct {
+	Items []rssItem `xml:"channel>item"`
+}
+
+type rdfFeed struct {
+	Items []rssItem `xml:"item"`
+}
+
+type atomFeed struct {
+	Entries []struct {
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+// parseFeed reads a sitemap, sitemap index, RSS or Atom feed, told apart by
+// the root element
+func parseFeed(r io.Reader) (*parsedFeed, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	// Feeds in the wild often use HTML entities and unescaped ampersands
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	root, err := rootElement(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &parsedFeed{}
+	switch root.Name.Local {
+	case "urlset":
+		var set sitemapURLSet
+		if err := decoder.DecodeElement(&set, &root); err != nil {
+			return nil, invalidFeed(err)
+		}
+		for _, u := range set.URLs {
+			feed.Entries = appendEntry(feed.Entries, u.Loc, u.LastMod)
+		}
+	case "sitemapindex":
+		var index sitemapIndex
+		if err := decoder.DecodeElement(&index, &root); err != nil {
+			return nil, invalidFeed(err)
+		}
+		for _, sitemap := range index.Sitemaps {
+			feed.Sitemaps = appendEntry(feed.Sitemaps, sitemap.Loc, sitemap.LastMod)
+		}
+	case "rss":
+		var rss rssFeed
+		if err := decoder.DecodeElement(&rss, &root); err != nil {
+			return nil, invalidFeed(err)
+		}
+		feed.Entries = appendItems(feed.Entries, rss.Items)
+	case "RDF":
+		var rdf rdfFeed
+		if err := decoder.DecodeElement(&rdf, &root); err != nil {
+			return nil, invalidFeed(err)
+		}
+		feed.Entries = appendItems(feed.Entries, rdf.Items)
+	case "feed":
+		var atom atomFeed
+		if err := decoder.DecodeElement(&atom, &root); err != nil {
+			return nil, invalidFeed(err)
+		}
+		for _, entry := range atom.Entries {
+			modified := entry.Updated
+			if modified == "" {
+				modified = entry.Published
+			}
+			for _, link := range entry.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					feed.Entries = appendEntry(feed.Entries, link.Href, modified)
+					break
+				}
+			}
+		}
+	default:
+		return nil, domain.NewValidationError("not a sitemap, RSS or Atom feed").WithDetail("root", root.Name.Local)
+	}
+
+	return feed, nil
+}
+
+func rootElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return xml.StartElement{}, domain.NewValidationError("feed is empty")
+			}
+			return xml.StartElement{}, invalidFeed(err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+func invalidFeed(err error) error {
+	return domain.NewValidationError("feed is not valid XML").WithDetail("error", err.Error())
+}
+
+func appendItems(entries []feedEntry, items []rssItem) []feedEntry {
+	for _, item := range items {
+		modified := item.PubDate
+		if modified == "" {
+			modified = item.Date
+		}
+		entries = appendEntry(entries, item.Link, modified)
+	}
+	return entries
+}
+
+func appendEntry(entries []feedEntry, loc, modified string) []feedEntry {
+	loc = strings.TrimSpace(loc)
+	if loc == "" {
+		return entries
+	}
+	return append(entries, feedEntry{URL: loc, Modified: parseFeedTime(modified)})
+}
+
+// feedTimeLayouts are the W3C datetime forms sitemaps and Atom use, then the
+// RFC 822 forms of RSS
+var feedTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+}
+
+// parseFeedTime parses a modification time, returning zero when it is missing or unrecognized
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/egress"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxChildSitemaps bounds the sitemaps of an index read in one check
+	maxChildSitemaps = 50
+	// sourceVersionBatch bounds the URLs compared against the cache per query
+	sourceVersionBatch = 500
+)
+
+// FreshnessWatcher polls the configured sitemaps and RSS/Atom feeds and
+// invalidates the cached artifacts of every page a feed reports modified
+// since it was fetched. Pages listed without a modification time are compared
+// by ETag when their artifact recorded one
+type FreshnessWatcher struct {
+	sourceRepo   ports.FreshnessSourceRepository
+	artifactRepo ports.ArtifactRepository
+	cacheService ports.CacheService
+	leader       *LeaderElector
+	client       *http.Client
+	cfg          config.FreshnessConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewFreshnessWatcher(sourceRepo ports.FreshnessSourceRepository, artifactRepo ports.ArtifactRepository, cacheService ports.CacheService, leader *LeaderElector, guard *egress.Guard, cfg config.FreshnessConfig) *FreshnessWatcher {
+	return &FreshnessWatcher{
+		sourceRepo:   sourceRepo,
+		artifactRepo: artifactRepo,
+		cacheService: cacheService,
+		leader:       leader,
+		client:       guard.Client(cfg.Timeout, true),
+		cfg:          cfg,
+	}
+}
+
+func (w *FreshnessWatcher) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	status.Track(freshnessComponent)
+	w.wg.Add(1)
+	go w.loop(ctx)
+
+	logrus.WithField("interval", w.cfg.Interval).Info("Freshness watcher started")
+}
+
+func (w *FreshnessWatcher) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+	logrus.Info("Freshness watcher stopped")
+}
+
+func (w *FreshnessWatcher) loop(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if w.leader.Leads(ctx, freshnessComponent) {
+			status.RecordRun(ctx, freshnessComponent, w.tick(ctx))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick checks every due source; only failing to list them fails the pass, a
+// failed check is recorded on its source
+func (w *FreshnessWatcher) tick(ctx context.Context) error {
+	sources, err := w.sourceRepo.ListDue(ctx, time.Now(), w.cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to list due freshness sources")
+		}
+		return err
+	}
+
+	for _, source := range sources {
+		log := logrus.WithFields(logrus.Fields{
+			"source_id": source.ID,
+			"namespace": source.Namespace,
+			"url":       source.URL,
+		})
+
+		invalidated, err := w.check(namespaceContext(ctx, source.Namespace), source)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		now := time.Now()
+		interval, _ := time.ParseDuration(source.Interval)
+		source.LastCheckedAt = &now
+		source.NextCheckAt = now.Add(max(interval, minCheckInterval))
+		source.LastInvalidated = invalidated
+		source.LastError = ""
+		if err != nil {
+			source.LastError = err.Error()
+			log.WithError(err).Warn("Freshness check failed")
+		} else if invalidated > 0 {
+			log.WithField("invalidated", invalidated).Info("Invalidated artifacts of modified pages")
+		}
+
+		if err := w.sourceRepo.RecordCheck(ctx, source); err != nil {
+			log.WithError(err).Error("Failed to record freshness check")
+		}
+	}
+	return nil
+}
+
+// check reads the source's feed, unless unchanged since the last check, and
+// invalidates the artifacts of the pages modified since they were fetched.
+// The feed's validators are only kept once its pages were all compared, so a
+// failed check reads the feed again
+func (w *FreshnessWatcher) check(ctx context.Context, source *domain.FreshnessSource) (int, error) {
+	body, etag, lastModified, err := w.fetch(ctx, source.URL, source.ETag, source.LastModified)
+	if err != nil || body == nil {
+		return 0, err
+	}
+
+	feed, err := parseFeed(bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	entries := feed.Entries
+	for i, sitemap := range feed.Sitemaps {
+		if i == maxChildSitemaps {
+			logrus.WithField("url", source.URL).Warnf("Sitemap index lists more than %d sitemaps; checking the first ones", maxChildSitemaps)
+			break
+		}
+		body, _, _, err := w.fetch(ctx, sitemap.URL, "", "")
+		if err != nil {
+			return 0, fmt.Errorf("sitemap %s: %w", sitemap.URL, err)
+		}
+		child, err := parseFeed(bytes.NewReader(body))
+		if err != nil {
+			return 0, fmt.Errorf("sitemap %s: %w", sitemap.URL, err)
+		}
+		entries = append(entries, child.Entries...)
+	}
+
+	invalidated, err := w.invalidateModified(ctx, entries)
+	if err != nil {
+		return invalidated, err
+	}
+
+	source.ETag = etag
+	source.LastModified = lastModified
+	return invalidated, nil
+}
+
+// invalidateModified compares the pages against the fresh artifacts fetched
+// from them and invalidates those modified since
+func (w *FreshnessWatcher) invalidateModified(ctx context.Context, entries []feedEntry) (int, error) {
+	// A page listed more than once counts with its latest modification
+	modified := make(map[string]time.Time, len(entries))
+	urls := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		latest, seen := modified[entry.URL]
+		if !seen {
+			urls = append(urls, entry.URL)
+		}
+		if !seen || entry.Modified.After(latest) {
+			modified[entry.URL] = entry.Modified
+		}
+	}
+
+	changed := make(map[string]bool)
+	var order []string
+	for start := 0; start < len(urls); start += sourceVersionBatch {
+		versions, err := w.artifactRepo.ListSourceVersions(ctx, urls[start:min(start+sourceVersionBatch, len(urls))])
+		if err != nil {
+			return 0, fmt.Errorf("failed to list cached source versions: %w", err)
+		}
+
+		for _, version := range versions {
+			if changed[version.SourceURL] {
+				continue
+			}
+			if w.modifiedSince(ctx, version, modified[version.SourceURL]) {
+				changed[version.SourceURL] = true
+				order = append(order, version.SourceURL)
+			}
+		}
+	}
+
+	invalidated := 0
+	for _, sourceURL := range order {
+		response, err := w.cacheService.Invalidate(ctx, &domain.InvalidateRequest{SourceURL: sourceURL})
+		if err != nil {
+			return invalidated, fmt.Errorf("failed to invalidate %s: %w", sourceURL, err)
+		}
+		invalidated += response.Invalidated
+	}
+	return invalidated, nil
+}
+
+// modifiedSince reports whether the page changed after the artifact was
+// fetched, by the feed's modification time or else by the page's ETag
+func (w *FreshnessWatcher) modifiedSince(ctx context.Context, version domain.SourceVersion, modified time.Time) bool {
+	if !modified.IsZero() {
+		return modified.After(version.FetchedSince())
+	}
+	if !w.cfg.CheckETags || version.ETag == "" {
+		return false
+	}
+
+	etag, err := w.pageETag(ctx, version.SourceURL)
+	if err != nil {
+		logrus.WithError(err).WithField("url", version.SourceURL).Debug("Failed to check page ETag")
+		return false
+	}
+	return etag != "" && weakETag(etag) != weakETag(version.ETag)
+}
+
+// pageETag requests a page's current ETag
+func (w *FreshnessWatcher) pageETag(ctx context.Context, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", w.cfg.UserAgent)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("page returned status %d", resp.StatusCode)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// weakETag drops the weak validator prefix, as a page served compressed or
+// not may mark the same version weak or strong
+func weakETag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+// fetch reads a feed, sending the validators of the last read; a nil body
+// without an error means the feed is unchanged. Gzipped sitemaps are decompressed
+func (w *FreshnessWatcher) fetch(ctx context.Context, feedURL, etag, lastModified string) ([]byte, string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, "", "", domain.NewValidationError("invalid feed URL").WithDetail("url", feedURL)
+	}
+	req.Header.Set("User-Agent", w.cfg.UserAgent)
+	req.Header.Set("Accept", "application/xml, text/xml, application/rss+xml, application/atom+xml;q=0.9, */*;q=0.5")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, "", "", domain.NewUpstreamError("feed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, lastModified, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", "", domain.NewUpstreamError("feed", fmt.Errorf("feed returned status %d", resp.StatusCode))
+	}
+
+	var reader io.Reader = resp.Body
+	if strings.HasSuffix(req.URL.Path, ".gz") || resp.Header.Get("Content-Type") == "application/x-gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, "", "", domain.NewValidationError("feed is not valid gzip").WithDetail("url", feedURL)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, w.cfg.MaxBytes+1))
+	if err != nil {
+		return nil, "", "", domain.NewUpstreamError("feed", err)
+	}
+	if int64(len(body)) > w.cfg.MaxBytes {
+		return nil, "", "", &domain.Error{
+			Code:    domain.CodePayloadTooLarge,
+			Message: "feed is larger than the freshness limit",
+			Details: map[string]interface{}{"url": feedURL, "max_bytes": w.cfg.MaxBytes},
+		}
+	}
+
+	return body, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+// minCheckInterval keeps sources from being polled more than once a minute
+const minCheckInterval = time.Minute
+
+// FreshnessSourceService manages the sitemaps and feeds the freshness watcher polls
+type FreshnessSourceService struct {
+	sourceRepo    ports.FreshnessSourceRepository
+	checkInterval time.Duration
+}
+
+func NewFreshnessSourceService(sourceRepo ports.FreshnessSourceRepository, cfg config.FreshnessConfig) *FreshnessSourceService {
+	return &FreshnessSourceService{
+		sourceRepo:    sourceRepo,
+		checkInterval: cfg.CheckInterval,
+	}
+}
+
+func (s *FreshnessSourceService) Create(ctx context.Context, req *domain.FreshnessSourceRequest) (*domain.FreshnessSource, error) {
+	now := time.Now()
+	source := &domain.FreshnessSource{
+		ID:        uuid.New(),
+		Namespace: domain.NamespaceFromContext(ctx),
+		CreatedAt: now,
+	}
+
+	if err := s.apply(source, req, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.sourceRepo.Store(ctx, source); err != nil {
+		return nil, fmt.Errorf("failed to store freshness source: %w", err)
+	}
+
+	return source, nil
+}
+
+func (s *FreshnessSourceService) Get(ctx context.Context, id uuid.UUID) (*domain.FreshnessSource, error) {
+	source, err := s.sourceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get freshness source: %w", err)
+	}
+	if source == nil {
+		return nil, domain.NewNotFoundError("freshness source", id)
+	}
+	return source, nil
+}
+
+func (s *FreshnessSourceService) List(ctx context.Context) ([]*domain.FreshnessSource, error) {
+	sources, err := s.sourceRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list freshness sources: %w", err)
+	}
+	return sources, nil
+}
+
+func (s *FreshnessSourceService) Update(ctx context.Context, id uuid.UUID, req *domain.FreshnessSourceRequest) (*domain.FreshnessSource, error) {
+	source, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(source, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.sourceRepo.Update(ctx, source); err != nil {
+		return nil, fmt.Errorf("failed to update freshness source: %w", err)
+	}
+
+	return source, nil
+}
+
+func (s *FreshnessSourceService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.sourceRepo.Delete(ctx, id)
+}
+
+// apply validates a source request and copies it onto source, which is then
+// checked on the watcher's next pass
+func (s *FreshnessSourceService) apply(source *domain.FreshnessSource, req *domain.FreshnessSourceRequest, now time.Time) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return domain.NewValidationError("url must be an absolute http(s) URL of a sitemap or feed")
+	}
+
+	interval := s.checkInterval
+	if req.Interval != "" {
+		if interval, err = time.ParseDuration(req.Interval); err != nil {
+			return domain.NewValidationError("interval must be a duration such as 1h")
+		}
+	}
+	if interval < minCheckInterval {
+		return domain.NewValidationError(fmt.Sprintf("interval must be at least %s", minCheckInterval))
+	}
+
+	source.Name = req.Name
+	source.URL = req.URL
+	source.Interval = interval.Truncate(time.Second).String()
+	source.Enabled = req.Enabled == nil || *req.Enabled
+	source.NextCheckAt = now
+	source.UpdatedAt = now
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// groupFetchFactor is how many more matches a grouped lookup searches than it returns
+const groupFetchFactor = 5
+
+// groupResults keeps the best of the results sharing a value of the metadata
+// field, counting the results folded into it. Results are already best first;
+// results without the field each form their own group
+func groupResults(results []domain.LookupResult, field string) []domain.LookupResult {
+	grouped := make([]domain.LookupResult, 0, len(results))
+	groups := make(map[string]int)
+	for _, result := range results {
+		value := metadataField(result.Artifact.Metadata, field)
+		if value == nil {
+			result.GroupCount = 1
+			grouped = append(grouped, result)
+			continue
+		}
+
+		key := fmt.Sprint(value)
+		if i, ok := groups[key]; ok {
+			grouped[i].GroupCount++
+			continue
+		}
+		result.GroupCount = 1
+		groups[key] = len(grouped)
+		grouped = append(grouped, result)
+	}
+	return grouped
+}
+
+// metadataField reads a dotted path from artifact metadata
+func metadataField(metadata map[string]interface{}, field string) interface{} {
+	var value interface{} = metadata
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
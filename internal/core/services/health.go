@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+)
+
+// healthProbeText is embedded when the readiness probe checks the embedding provider
+const healthProbeText = "health check"
+
+type dependencyCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type HealthService struct {
+	checks  []dependencyCheck
+	timeout time.Duration
+}
+
+func NewHealthService(
+	database ports.HealthChecker,
+	vectorRepo ports.VectorRepository,
+	embeddingService ports.EmbeddingService,
+	cfg config.HealthConfig,
+) *HealthService {
+	checks := []dependencyCheck{
+		{name: "postgres", check: database.Ping},
+		{name: "vector", check: vectorRepo.Ping},
+	}
+	if cfg.CheckEmbedding {
+		checks = append(checks, dependencyCheck{name: "embedding", check: func(ctx context.Context) error {
+			_, err := embeddingService.GenerateEmbedding(ctx, healthProbeText)
+			return err
+		}})
+	}
+
+	return &HealthService{
+		checks:  checks,
+		timeout: cfg.Timeout,
+	}
+}
+
+func (s *HealthService) Ready(ctx context.Context) *domain.ReadinessResponse {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	dependencies := make([]domain.DependencyHealth, len(s.checks))
+	var wg sync.WaitGroup
+	for i, dependency := range s.checks {
+		wg.Add(1)
+		go func(i int, dependency dependencyCheck) {
+			defer wg.Done()
+			dependencies[i] = runCheck(ctx, dependency)
+		}(i, dependency)
+	}
+	wg.Wait()
+
+	response := &domain.ReadinessResponse{
+		Status:       domain.HealthUp,
+		Timestamp:    time.Now().UTC(),
+		Dependencies: dependencies,
+	}
+	for _, dependency := range dependencies {
+		if dependency.Status != domain.HealthUp {
+			response.Status = domain.HealthDown
+		}
+	}
+	return response
+}
+
+func runCheck(ctx context.Context, dependency dependencyCheck) domain.DependencyHealth {
+	start := time.Now()
+	err := dependency.check(ctx)
+	result := domain.DependencyHealth{
+		Name:      dependency.name,
+		Status:    domain.HealthUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = domain.HealthDown
+		result.Error = err.Error()
+	}
+	return result
+}
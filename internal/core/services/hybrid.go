@@ -0,0 +1,68 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// rrfK damps the weight of top ranks in reciprocal rank fusion
+const rrfK = 60
+
+// fusedMatch is an artifact ranked by hybrid search. Entries are its vector
+// results, including chunk matches, or a stand-in when only its text matched
+type fusedMatch struct {
+	id          uuid.UUID
+	entries     []domain.LookupResult
+	score       float32
+	vectorScore float32
+	textScore   float32
+	vectorRank  int
+	textRank    int
+}
+
+// fuseResults merges vector results and full-text matches by reciprocal rank
+// fusion, best first. Chunk results rank as their parent artifact
+func fuseResults(vectorResults []domain.LookupResult, matches []domain.TextMatch) []*fusedMatch {
+	byID := make(map[uuid.UUID]*fusedMatch)
+	var fused []*fusedMatch
+
+	for _, vr := range vectorResults {
+		id := vr.Artifact.ID
+		if parentID, _, isChunk := chunkMatch(vr); isChunk {
+			id = parentID
+		}
+
+		match, ok := byID[id]
+		if !ok {
+			match = &fusedMatch{id: id, vectorScore: vr.Score, vectorRank: len(fused) + 1}
+			match.score = 1 / float32(rrfK+match.vectorRank)
+			byID[id] = match
+			fused = append(fused, match)
+		}
+		match.entries = append(match.entries, vr)
+	}
+
+	for rank, tm := range matches {
+		match, ok := byID[tm.ID]
+		if !ok {
+			match = &fusedMatch{
+				id: tm.ID,
+				entries: []domain.LookupResult{{
+					Artifact: &domain.Artifact{ID: tm.ID, Metadata: map[string]interface{}{"namespace": tm.Namespace}},
+				}},
+			}
+			byID[tm.ID] = match
+			fused = append(fused, match)
+		}
+		match.textScore = tm.Rank
+		match.textRank = rank + 1
+		match.score += 1 / float32(rrfK+match.textRank)
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].score > fused[j].score
+	})
+	return fused
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Importer publishes records exported from other vector stores and RAG
+// frameworks. Exported embeddings are stored as they are, so a corpus moves
+// without being embedded again; records without one are embedded on publish
+type Importer struct {
+	cacheService ports.CacheService
+	// dimensions is the configured embedding model's; 0 skips the check
+	dimensions int
+}
+
+func NewImporter(cacheService ports.CacheService, dimensions int) *Importer {
+	return &Importer{cacheService: cacheService, dimensions: dimensions}
+}
+
+// Import converts the records to artifacts and publishes them. A record that
+// cannot be converted fails on its own, as a publish object would
+func (i *Importer) Import(ctx context.Context, records []domain.ImportRecord, options domain.ImportOptions) (*domain.PublishResponse, error) {
+	ctx, span := tracer.Start(ctx, "Importer.Import", trace.WithAttributes(attribute.Int("mentis.records", len(records))))
+	defer span.End()
+
+	switch options.Type {
+	case domain.RAW, domain.DERIVED, domain.REASONING, domain.ANSWER:
+	default:
+		return nil, domain.NewValidationError("invalid artifact type").WithDetail("type", options.Type)
+	}
+
+	response := &domain.PublishResponse{
+		Published: []uuid.UUID{},
+		Skipped:   []uuid.UUID{},
+		Replaced:  []uuid.UUID{},
+		Results:   make([]domain.PublishResult, 0, len(records)),
+	}
+
+	var firstErr error
+	var artifacts []domain.Artifact
+	var indexes []int
+	for index := range records {
+		artifact, err := i.convert(&records[index], options)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			response.Results = append(response.Results, domain.PublishResult{
+				Index:  index,
+				Status: domain.PublishFailed,
+				Error:  publishError(ctx, err),
+			})
+			continue
+		}
+		artifacts = append(artifacts, artifact)
+		indexes = append(indexes, index)
+	}
+
+	if len(artifacts) == 0 {
+		return nil, firstErr
+	}
+
+	published, err := i.cacheService.Publish(ctx, artifacts)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range published.Results {
+		result.Index = indexes[result.Index]
+		response.Results = append(response.Results, result)
+	}
+	response.Published = published.Published
+	response.Skipped = published.Skipped
+	response.Replaced = published.Replaced
+
+	sort.Slice(response.Results, func(a, b int) bool { return response.Results[a].Index < response.Results[b].Index })
+	return response, nil
+}
+
+// convert turns a record into the artifact it is published as, rejecting an
+// embedding the configured model could not have produced
+func (i *Importer) convert(record *domain.ImportRecord, options domain.ImportOptions) (domain.Artifact, error) {
+	artifact, err := record.Artifact(options)
+	if err != nil {
+		return domain.Artifact{}, err
+	}
+	if i.dimensions > 0 && len(artifact.Embedding) > 0 && len(artifact.Embedding) != i.dimensions {
+		return domain.Artifact{}, domain.NewValidationError("embedding dimensions do not match the configured model; import with reembed=true to embed the text again").
+			WithDetail("dimensions", len(artifact.Embedding)).
+			WithDetail("expected", i.dimensions)
+	}
+	return artifact, nil
+}
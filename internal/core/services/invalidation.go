@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+// InvalidationPolicyService manages the standing freshness rules the artifact sweeper enforces
+type InvalidationPolicyService struct {
+	policyRepo ports.InvalidationPolicyRepository
+}
+
+func NewInvalidationPolicyService(policyRepo ports.InvalidationPolicyRepository) *InvalidationPolicyService {
+	return &InvalidationPolicyService{
+		policyRepo: policyRepo,
+	}
+}
+
+func (s *InvalidationPolicyService) Create(ctx context.Context, req *domain.InvalidationPolicyRequest) (*domain.InvalidationPolicy, error) {
+	now := time.Now()
+	policy := &domain.InvalidationPolicy{
+		ID:        uuid.New(),
+		Namespace: domain.NamespaceFromContext(ctx),
+		CreatedAt: now,
+	}
+
+	if err := applyInvalidationPolicy(policy, req, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Store(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to store invalidation policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (s *InvalidationPolicyService) Get(ctx context.Context, id uuid.UUID) (*domain.InvalidationPolicy, error) {
+	policy, err := s.policyRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invalidation policy: %w", err)
+	}
+	if policy == nil {
+		return nil, domain.NewNotFoundError("invalidation policy", id)
+	}
+	return policy, nil
+}
+
+func (s *InvalidationPolicyService) List(ctx context.Context) ([]*domain.InvalidationPolicy, error) {
+	policies, err := s.policyRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invalidation policies: %w", err)
+	}
+	return policies, nil
+}
+
+func (s *InvalidationPolicyService) Update(ctx context.Context, id uuid.UUID, req *domain.InvalidationPolicyRequest) (*domain.InvalidationPolicy, error) {
+	policy, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyInvalidationPolicy(policy, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Update(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to update invalidation policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (s *InvalidationPolicyService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.policyRepo.Delete(ctx, id)
+}
+
+// applyInvalidationPolicy validates a policy request and copies it onto policy
+func applyInvalidationPolicy(policy *domain.InvalidationPolicy, req *domain.InvalidationPolicyRequest, now time.Time) error {
+	maxAge, err := time.ParseDuration(req.MaxAge)
+	if err != nil || maxAge < time.Second {
+		return domain.NewValidationError("max_age must be a duration of at least 1s, such as 168h")
+	}
+
+	policy.Name = req.Name
+	policy.ArtifactType = req.ArtifactType
+	policy.MaxAge = maxAge.Truncate(time.Second).String()
+	policy.Enabled = req.Enabled == nil || *req.Enabled
+	policy.UpdatedAt = now
+
+	return nil
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// A small JSON Schema validator covering the keywords step schemas use:
+// type, enum, const, required, properties, additionalProperties, items,
+// minItems, maxItems, minLength, maxLength, pattern, minimum and maximum.
+// Schemas using any other keyword are rejected when they are registered, so
+// nothing a schema asks for is silently skipped. Values are expected in
+// encoding/json's generic form.
+
+// validateSchema returns every violation of schema by value, rooted at path
+func validateSchema(schema map[string]interface{}, value interface{}, path string) []domain.SchemaViolation {
+	var violations []domain.SchemaViolation
+	fail := func(format string, args ...interface{}) {
+		violations = append(violations, domain.SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		fail("expected %v, got %s", t, jsonType(value))
+		return violations
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		fail("value is not one of %v", enum)
+	}
+	if c, ok := schema["const"]; ok && !equalValues(c, value) {
+		fail("value must be %v", c)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if key, ok := name.(string); ok {
+					if _, present := v[key]; !present {
+						fail("missing required property %q", key)
+					}
+				}
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, item := range v {
+			if propSchema, ok := properties[key].(map[string]interface{}); ok {
+				violations = append(violations, validateSchema(propSchema, item, path+"."+key)...)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					violations = append(violations, domain.SchemaViolation{Path: path + "." + key, Message: "additional property is not allowed"})
+				}
+			case map[string]interface{}:
+				violations = append(violations, validateSchema(extra, item, path+"."+key)...)
+			}
+		}
+
+	case []interface{}:
+		if min, ok := schemaNumber(schema, "minItems"); ok && float64(len(v)) < min {
+			fail("expected at least %v items", min)
+		}
+		if max, ok := schemaNumber(schema, "maxItems"); ok && float64(len(v)) > max {
+			fail("expected at most %v items", max)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				violations = append(violations, validateSchema(items, item, path+"["+strconv.Itoa(i)+"]")...)
+			}
+		}
+
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := schemaNumber(schema, "minLength"); ok && length < min {
+			fail("expected at least %v characters", min)
+		}
+		if max, ok := schemaNumber(schema, "maxLength"); ok && length > max {
+			fail("expected at most %v characters", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("value does not match pattern %q", pattern)
+			}
+		}
+
+	case float64:
+		if min, ok := schemaNumber(schema, "minimum"); ok && v < min {
+			fail("value must be >= %v", min)
+		}
+		if max, ok := schemaNumber(schema, "maximum"); ok && v > max {
+			fail("value must be <= %v", max)
+		}
+	}
+
+	return violations
+}
+
+// checkSchema rejects schemas using keywords the validator doesn't implement,
+// or values it can't interpret
+func checkSchema(schema map[string]interface{}, path string) error {
+	for keyword := range schema {
+		if !supportedKeywords[keyword] && !annotationKeywords[keyword] {
+			return fmt.Errorf("%s: unsupported keyword %q", path, keyword)
+		}
+	}
+
+	if t, ok := schema["type"]; ok {
+		types, isList := t.([]interface{})
+		if !isList {
+			types = []interface{}{t}
+		}
+		for _, name := range types {
+			if s, _ := name.(string); !validSchemaTypes[s] {
+				return fmt.Errorf("%s: unsupported type %v", path, name)
+			}
+		}
+	}
+
+	if enum, ok := schema["enum"]; ok {
+		if _, isList := enum.([]interface{}); !isList {
+			return fmt.Errorf("%s: enum must be an array", path)
+		}
+	}
+
+	if required, ok := schema["required"]; ok {
+		names, isList := required.([]interface{})
+		if !isList {
+			return fmt.Errorf("%s: required must be an array", path)
+		}
+		for _, name := range names {
+			if _, isString := name.(string); !isString {
+				return fmt.Errorf("%s: required must list property names", path)
+			}
+		}
+	}
+
+	if pattern, ok := schema["pattern"]; ok {
+		s, isString := pattern.(string)
+		if !isString {
+			return fmt.Errorf("%s: pattern must be a string", path)
+		}
+		if _, err := regexp.Compile(s); err != nil {
+			return fmt.Errorf("%s: invalid pattern: %v", path, err)
+		}
+	}
+
+	for _, keyword := range []string{"minItems", "maxItems", "minLength", "maxLength", "minimum", "maximum"} {
+		if value, ok := schema[keyword]; ok {
+			if _, isNumber := value.(float64); !isNumber {
+				return fmt.Errorf("%s: %s must be a number", path, keyword)
+			}
+		}
+	}
+
+	if properties, ok := schema["properties"]; ok {
+		props, isObject := properties.(map[string]interface{})
+		if !isObject {
+			return fmt.Errorf("%s: properties must be an object", path)
+		}
+		for key, prop := range props {
+			propSchema, isObject := prop.(map[string]interface{})
+			if !isObject {
+				return fmt.Errorf("%s.%s: schema must be an object", path, key)
+			}
+			if err := checkSchema(propSchema, path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if items, ok := schema["items"]; ok {
+		itemSchema, isObject := items.(map[string]interface{})
+		if !isObject {
+			return fmt.Errorf("%s: items must be an object", path)
+		}
+		if err := checkSchema(itemSchema, path+"[]"); err != nil {
+			return err
+		}
+	}
+
+	switch extra := schema["additionalProperties"].(type) {
+	case nil, bool:
+	case map[string]interface{}:
+		if err := checkSchema(extra, path+".*"); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%s: additionalProperties must be a boolean or an object", path)
+	}
+
+	return nil
+}
+
+// supportedKeywords are the keywords validateSchema enforces
+var supportedKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "required": true,
+	"properties": true, "additionalProperties": true, "items": true,
+	"minItems": true, "maxItems": true, "minLength": true, "maxLength": true,
+	"pattern": true, "minimum": true, "maximum": true,
+}
+
+// annotationKeywords describe a schema without constraining values
+var annotationKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true,
+	"description": true, "default": true, "examples": true,
+}
+
+var validSchemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+func matchesType(t interface{}, value interface{}) bool {
+	if types, ok := t.([]interface{}); ok {
+		for _, name := range types {
+			if matchesType(name, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	name, _ := t.(string)
+	actual := jsonType(value)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func schemaNumber(schema map[string]interface{}, keyword string) (float64, bool) {
+	n, ok := schema[keyword].(float64)
+	return n, ok
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if equalValues(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares JSON values structurally: objects by their members,
+// arrays element by element, and scalars by value
+func equalValues(a, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			other, present := bv[key]
+			if !present || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equalValues(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case nil, bool, string, float64:
+		return a == b
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// decodeJSON parses a schema or value into encoding/json's generic form
+func decodeJSON(t *testing.T, raw string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		t.Fatalf("invalid JSON %s: %v", raw, err)
+	}
+	return value
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		valid  bool
+	}{
+		{"type matches", `{"type": "string"}`, `"a"`, true},
+		{"type mismatch", `{"type": "string"}`, `1`, false},
+		{"integer is a number", `{"type": "number"}`, `3`, true},
+		{"fraction is not an integer", `{"type": "integer"}`, `1.5`, false},
+		{"type list", `{"type": ["string", "null"]}`, `null`, true},
+		{"enum member", `{"enum": ["a", "b"]}`, `"b"`, true},
+		{"enum non-member", `{"enum": ["a", "b"]}`, `"c"`, false},
+		{"enum object member", `{"enum": [{"a": 1, "b": [2, 3]}]}`, `{"b": [2, 3], "a": 1}`, true},
+		{"enum object differs", `{"enum": [{"a": 1}]}`, `{"a": "1"}`, false},
+		{"const array", `{"const": [1, "x"]}`, `[1, "x"]`, true},
+		{"const array order", `{"const": [1, 2]}`, `[2, 1]`, false},
+		{"const string is not a number", `{"const": "1"}`, `1`, false},
+		{"const nested map", `{"const": {"a": {"b": null}}}`, `{"a": {"b": null}}`, true},
+		{"const extra member", `{"const": {"a": 1}}`, `{"a": 1, "b": 2}`, false},
+		{"required present", `{"required": ["a"]}`, `{"a": 1}`, true},
+		{"required missing", `{"required": ["a"]}`, `{}`, false},
+		{"property schema", `{"properties": {"a": {"type": "string"}}}`, `{"a": 1}`, false},
+		{"additional properties allowed", `{"properties": {"a": {}}}`, `{"b": 1}`, true},
+		{"additional properties denied", `{"properties": {"a": {}}, "additionalProperties": false}`, `{"b": 1}`, false},
+		{"additional properties schema", `{"additionalProperties": {"type": "number"}}`, `{"b": "x"}`, false},
+		{"items", `{"items": {"type": "number"}}`, `[1, "2"]`, false},
+		{"min items", `{"minItems": 2}`, `[1]`, false},
+		{"max items", `{"maxItems": 1}`, `[1, 2]`, false},
+		{"min length counts runes", `{"minLength": 2}`, `"éé"`, true},
+		{"max length", `{"maxLength": 1}`, `"ab"`, false},
+		{"pattern match", `{"pattern": "^a+$"}`, `"aaa"`, true},
+		{"pattern mismatch", `{"pattern": "^a+$"}`, `"ab"`, false},
+		{"minimum", `{"minimum": 1}`, `0`, false},
+		{"maximum", `{"maximum": 1}`, `1`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := decodeJSON(t, tt.schema).(map[string]interface{})
+			if err := checkSchema(schema, "schema"); err != nil {
+				t.Fatalf("schema rejected: %v", err)
+			}
+			violations := validateSchema(schema, decodeJSON(t, tt.value), "value")
+			if valid := len(violations) == 0; valid != tt.valid {
+				t.Fatalf("expected valid=%v, got violations %v", tt.valid, violations)
+			}
+		})
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		valid  bool
+	}{
+		{"supported keywords", `{"type": "object", "required": ["a"], "properties": {"a": {"type": "string", "pattern": "^x"}}}`, true},
+		{"annotations", `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "t", "description": "d", "default": 1, "examples": [1]}`, true},
+		{"unknown type", `{"type": "date"}`, false},
+		{"unsupported keyword", `{"oneOf": [{"type": "string"}]}`, false},
+		{"unsupported format", `{"type": "string", "format": "email"}`, false},
+		{"unsupported nested keyword", `{"properties": {"a": {"exclusiveMinimum": 0}}}`, false},
+		{"unsupported item keyword", `{"items": {"uniqueItems": true}}`, false},
+		{"invalid pattern", `{"pattern": "("}`, false},
+		{"non-numeric bound", `{"minimum": "1"}`, false},
+		{"enum not a list", `{"enum": "a"}`, false},
+		{"required not names", `{"required": [1]}`, false},
+		{"additional properties not a schema", `{"additionalProperties": "no"}`, false},
+		{"property not a schema", `{"properties": {"a": true}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := decodeJSON(t, tt.schema).(map[string]interface{})
+			err := checkSchema(schema, "schema")
+			if valid := err == nil; valid != tt.valid {
+				t.Fatalf("expected valid=%v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
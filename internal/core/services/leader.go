@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// releaseTimeout bounds giving up leases on shutdown
+	releaseTimeout = 5 * time.Second
+	// minLeaseTTL keeps renewals, every third of the lease, from hammering Postgres
+	minLeaseTTL = 3 * time.Second
+)
+
+// LeaderElector lets one replica at a time run each background job that would
+// do duplicate or conflicting work if replicas ran it together. A replica
+// leads a job while it holds the job's lease in Postgres; held leases are
+// renewed in the background, so a pass longer than the lease keeps it, and a
+// replica that stops renewing loses its jobs once the lease expires
+type LeaderElector struct {
+	leaseRepo ports.LeaseRepository
+	enabled   bool
+	ttl       time.Duration
+	holder    string
+
+	mu   sync.Mutex
+	held map[string]bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewLeaderElector(leaseRepo ports.LeaseRepository, cfg config.LeaderConfig) *LeaderElector {
+	ttl := cfg.LeaseTTL
+	if ttl < minLeaseTTL {
+		ttl = minLeaseTTL
+	}
+	instance := cfg.InstanceID
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	return &LeaderElector{
+		leaseRepo: leaseRepo,
+		enabled:   cfg.Enabled,
+		ttl:       ttl,
+		// The suffix tells apart replicas sharing a hostname or instance ID
+		holder: instance + "-" + uuid.NewString()[:8],
+		held:   make(map[string]bool),
+	}
+}
+
+func (e *LeaderElector) Start(ctx context.Context) {
+	if !e.enabled {
+		logrus.Info("Leader election disabled; this replica runs every background job")
+		return
+	}
+	ctx, e.cancel = context.WithCancel(ctx)
+
+	e.wg.Add(1)
+	go e.loop(ctx)
+
+	logrus.WithFields(logrus.Fields{"holder": e.holder, "lease_ttl": e.ttl}).Info("Leader election started")
+}
+
+// Stop stops renewing and releases held leases, so other replicas take the
+// jobs over without waiting for them to expire. Call it after the jobs stopped
+func (e *LeaderElector) Stop() {
+	if e.cancel == nil {
+		return
+	}
+	e.cancel()
+	e.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := e.leaseRepo.Release(ctx, e.heldJobs(), e.holder); err != nil {
+		logrus.WithError(err).Warn("Failed to release background job leases")
+	}
+	logrus.Info("Leader election stopped")
+}
+
+// Leads reports whether this replica should run a pass of job now, taking or
+// renewing its lease. Without election every replica leads. A failed lease
+// check is recorded as a failed pass of the job
+func (e *LeaderElector) Leads(ctx context.Context, job string) bool {
+	if !e.enabled {
+		return true
+	}
+
+	acquired, err := e.leaseRepo.Acquire(ctx, job, e.holder, e.ttl)
+	if err != nil {
+		status.RecordRun(ctx, job, err)
+		if ctx.Err() == nil {
+			logrus.WithError(err).WithField("job", job).Warn("Failed to check background job lease")
+		}
+		return false
+	}
+	e.setHeld(job, acquired)
+	return acquired
+}
+
+func (e *LeaderElector) loop(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.renew(ctx)
+		}
+	}
+}
+
+// renew extends held leases; jobs whose lease was lost pass to whichever
+// replica takes it next
+func (e *LeaderElector) renew(ctx context.Context) {
+	jobs := e.heldJobs()
+	if len(jobs) == 0 {
+		return
+	}
+
+	renewed, err := e.leaseRepo.Renew(ctx, jobs, e.holder, e.ttl)
+	if err != nil {
+		if ctx.Err() == nil {
+			logrus.WithError(err).Warn("Failed to renew background job leases")
+		}
+		return
+	}
+	for _, job := range jobs {
+		if !slices.Contains(renewed, job) {
+			e.setHeld(job, false)
+		}
+	}
+}
+
+func (e *LeaderElector) heldJobs() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	jobs := make([]string, 0, len(e.held))
+	for job := range e.held {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// setHeld records whether this replica holds job's lease, logging changes
+func (e *LeaderElector) setHeld(job string, held bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.held[job] == held {
+		return
+	}
+	if held {
+		e.held[job] = true
+		logrus.WithField("job", job).Info("Took over background job")
+	} else {
+		delete(e.held, job)
+		logrus.WithField("job", job).Info("Background job passed to another replica")
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// Lineage returns the dependency graph around an artifact, traversed level by
+// level up to depth in the requested direction
+func (s *CacheService) Lineage(ctx context.Context, id uuid.UUID, direction domain.LineageDirection, depth int) (*domain.ArtifactLineage, error) {
+	if direction == "" {
+		direction = domain.LineageBoth
+	}
+	if !direction.IsValid() {
+		return nil, domain.NewValidationError("direction must be upstream, downstream, or both")
+	}
+	depth = domain.NormalizeLineageDepth(depth)
+
+	root, err := s.artifactRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artifact: %w", err)
+	}
+	if root == nil {
+		return nil, domain.NewNotFoundError("artifact", id)
+	}
+
+	lineage := &domain.ArtifactLineage{
+		Root:      id,
+		Direction: direction,
+		Depth:     depth,
+		Edges:     []domain.LineageEdge{},
+	}
+	depths := map[uuid.UUID]int{id: 0}
+	seen := make(map[domain.LineageEdge]bool)
+
+	if direction != domain.LineageDownstream {
+		if err := s.traverseLineage(ctx, lineage, depths, seen, true); err != nil {
+			return nil, err
+		}
+	}
+	if direction != domain.LineageUpstream {
+		if err := s.traverseLineage(ctx, lineage, depths, seen, false); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(depths))
+	for nodeID := range depths {
+		ids = append(ids, nodeID)
+	}
+	nodes, err := s.artifactRepo.GetLineageNodes(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lineage nodes: %w", err)
+	}
+	for _, node := range nodes {
+		node.Depth = depths[node.ID]
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
+		}
+		return nodes[i].ID.String() < nodes[j].ID.String()
+	})
+	lineage.Nodes = nodes
+
+	return lineage, nil
+}
+
+// traverseLineage walks dependency edges breadth first from the root, recording
+// each newly reached artifact's distance in depths
+func (s *CacheService) traverseLineage(ctx context.Context, lineage *domain.ArtifactLineage, depths map[uuid.UUID]int, seen map[domain.LineageEdge]bool, upstream bool) error {
+	sign := 1
+	if upstream {
+		sign = -1
+	}
+
+	frontier := []uuid.UUID{lineage.Root}
+	for level := 1; level <= lineage.Depth && len(frontier) > 0; level++ {
+		edges, err := s.artifactRepo.ListLineageEdges(ctx, frontier, upstream)
+		if err != nil {
+			return fmt.Errorf("failed to list lineage edges: %w", err)
+		}
+
+		var next []uuid.UUID
+		for _, edge := range edges {
+			if seen[edge] {
+				continue
+			}
+
+			neighbor := edge.To
+			if upstream {
+				neighbor = edge.From
+			}
+			if _, ok := depths[neighbor]; !ok {
+				if len(depths) >= domain.MaxLineageNodes {
+					lineage.Truncated = true
+					continue
+				}
+				depths[neighbor] = sign * level
+				next = append(next, neighbor)
+			}
+
+			seen[edge] = true
+			lineage.Edges = append(lineage.Edges, edge)
+		}
+		frontier = next
+	}
+	return nil
+}
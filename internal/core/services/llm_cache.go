@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// StoreLLMAnswer caches a model's answer to a prompt as an ANSWER artifact.
+// Its vector embeds the prompt rather than the answer, so checks match
+// prompts, and the model is recorded to partition the cache by model
+func (s *CacheService) StoreLLMAnswer(ctx context.Context, req *domain.LLMCacheStoreRequest) (*domain.LLMCacheStoreResponse, error) {
+	if req.Prompt == "" || req.Model == "" || req.Answer == "" {
+		return nil, domain.NewValidationError("prompt, model and answer are required")
+	}
+
+	embedding, err := s.queryEmbedding(ctx, req.Prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]interface{}, len(req.Metadata)+3)
+	for key, value := range req.Metadata {
+		metadata[key] = value
+	}
+	metadata[domain.LLMPromptKey] = req.Prompt
+	metadata[domain.LLMModelKey] = req.Model
+	metadata[domain.LLMCacheKey] = s.hashService.ComputeInputHash(map[string]interface{}{"model": req.Model, "prompt": req.Prompt})
+
+	response, err := s.Publish(ctx, []domain.Artifact{{
+		Type:      domain.ANSWER,
+		Content:   []byte(req.Answer),
+		Embedding: embedding,
+		Metadata:  metadata,
+		ExpiresAt: req.ExpiresAt,
+		UpsertKey: domain.LLMCacheKey,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	result := response.Results[0]
+	if result.Status == domain.PublishFailed {
+		return nil, &domain.Error{Code: result.Error.Code, Message: result.Error.Message, Details: result.Error.Details}
+	}
+	return &domain.LLMCacheStoreResponse{ID: *result.ID, Status: result.Status}, nil
+}
+
+// CheckLLMCache returns the cached answer to the most similar prompt sent to
+// the same model, if it is similar enough
+func (s *CacheService) CheckLLMCache(ctx context.Context, req *domain.LLMCacheCheckRequest) (*domain.LLMCacheCheckResponse, error) {
+	if req.Prompt == "" || req.Model == "" {
+		return nil, domain.NewValidationError("prompt and model are required")
+	}
+
+	minScore := req.MinScore
+	if minScore == nil {
+		threshold := float32(s.lookupLimits.LLMCacheMinScore)
+		minScore = &threshold
+	}
+
+	response, err := s.Lookup(ctx, domain.LookupOptions{
+		Query:          req.Prompt,
+		TopK:           1,
+		MinScore:       minScore,
+		ArtifactType:   domain.ANSWER,
+		Filter:         domain.Filter{domain.Eq(domain.LLMModelKey, req.Model)},
+		IncludeContent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Results) == 0 {
+		return &domain.LLMCacheCheckResponse{}, nil
+	}
+
+	best := response.Results[0]
+	prompt, _ := best.Artifact.Metadata[domain.LLMPromptKey].(string)
+	id := best.Artifact.ID
+	return &domain.LLMCacheCheckResponse{
+		Hit:        true,
+		Answer:     string(best.Artifact.Content),
+		Prompt:     prompt,
+		Score:      best.Score,
+		ArtifactID: &id,
+	}, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// LookupCache keeps recent lookup responses in memory so repeated identical
+// lookups skip the embedding provider and vector store. Entries live for a
+// short TTL and are dropped as soon as artifacts in a namespace they searched
+// change on this instance, and all of them when this instance's sweeper,
+// evictor or outbox relay changes artifacts or vectors; changes made through
+// other instances are only bounded by the TTL
+type LookupCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*lookupCacheEntry
+	// generation advances on every invalidation, so a lookup that raced one
+	// is not stored
+	generation uint64
+}
+
+type lookupCacheEntry struct {
+	response   *domain.LookupResponse
+	namespaces []string
+	expiresAt  time.Time
+}
+
+func NewLookupCache(cfg config.LookupCacheConfig) *LookupCache {
+	return &LookupCache{
+		ttl:        cfg.TTL,
+		maxEntries: cfg.MaxEntries,
+		entries:    make(map[string]*lookupCacheEntry),
+	}
+}
+
+// Generation is passed back to Put by a lookup that read it before searching
+func (c *LookupCache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Get returns the unexpired response stored under key, or nil
+func (c *LookupCache) Get(key string) *domain.LookupResponse {
+	if c.ttl <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil
+	}
+	return cloneLookupResponse(entry.response)
+}
+
+// Put stores a response for the TTL unless artifacts changed since generation
+func (c *LookupCache) Put(key string, generation uint64, namespaces []string, response *domain.LookupResponse) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[key] = &lookupCacheEntry{
+		response:   cloneLookupResponse(response),
+		namespaces: namespaces,
+		expiresAt:  time.Now().Add(c.ttl),
+	}
+}
+
+// Invalidate drops the responses of every lookup that searched namespace
+func (c *LookupCache) Invalidate(namespace string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for key, entry := range c.entries {
+		for _, ns := range entry.namespaces {
+			if ns == namespace {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// InvalidateAll drops every response, for changes spanning namespaces such as
+// expiry and eviction sweeps
+func (c *LookupCache) InvalidateAll() {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	clear(c.entries)
+}
+
+// evict makes room for an entry, dropping expired entries or else an arbitrary one
+func (c *LookupCache) evict() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			return
+		}
+		delete(c.entries, key)
+	}
+}
+
+// cloneLookupResponse copies a response deeply enough that neither the lookup
+// that stored it nor callers served from the cache can change the cached copy
+func cloneLookupResponse(response *domain.LookupResponse) *domain.LookupResponse {
+	clone := *response
+	clone.Results = make([]domain.LookupResult, len(response.Results))
+	for i, result := range response.Results {
+		if result.Artifact != nil {
+			result.Artifact = cloneArtifact(result.Artifact)
+		}
+		result.Chunks = slices.Clone(result.Chunks)
+		if result.Explanation != nil {
+			explanation := *result.Explanation
+			explanation.MatchedTags = slices.Clone(explanation.MatchedTags)
+			explanation.MatchedFilters = slices.Clone(explanation.MatchedFilters)
+			result.Explanation = &explanation
+		}
+		clone.Results[i] = result
+	}
+	if response.KnownMiss != nil {
+		knownMiss := *response.KnownMiss
+		clone.KnownMiss = &knownMiss
+	}
+	return &clone
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/logging"
+)
+
+// NegativeCache remembers lookups that found nothing and step inputs that failed,
+// so repeated requests get the known outcome instead of redoing the work. Failures
+// to read or write it only cost the saved work.
+type NegativeCache struct {
+	repo ports.NegativeCacheRepository
+	ttls map[domain.NegativeKind]time.Duration
+}
+
+func NewNegativeCache(repo ports.NegativeCacheRepository, cfg config.NegativeCacheConfig) *NegativeCache {
+	return &NegativeCache{
+		repo: repo,
+		ttls: map[domain.NegativeKind]time.Duration{
+			domain.NegativeLookupMiss:  cfg.MissTTL,
+			domain.NegativeStepFailure: cfg.FailureTTL,
+		},
+	}
+}
+
+// Check returns the unexpired entry for kind and key, or nil
+func (n *NegativeCache) Check(ctx context.Context, kind domain.NegativeKind, key string) *domain.NegativeEntry {
+	if n.ttls[kind] <= 0 {
+		return nil
+	}
+
+	entry, err := n.repo.Get(ctx, kind, key)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("kind", kind).Warn("Failed to check negative cache")
+		return nil
+	}
+	return entry
+}
+
+// Record remembers a miss or failure for the kind's TTL
+func (n *NegativeCache) Record(ctx context.Context, kind domain.NegativeKind, key, reason string, code domain.ErrorCode) {
+	ttl := n.ttls[kind]
+	if ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	entry := &domain.NegativeEntry{
+		Namespace: domain.NamespaceFromContext(ctx),
+		Kind:      kind,
+		Key:       key,
+		Reason:    reason,
+		Code:      code,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := n.repo.Put(context.WithoutCancel(ctx), entry); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("kind", kind).Warn("Failed to record negative cache entry")
+	}
+}
+
+// Forget drops the entry for kind and key once the work has succeeded
+func (n *NegativeCache) Forget(ctx context.Context, kind domain.NegativeKind, key string) {
+	if n.ttls[kind] <= 0 {
+		return
+	}
+	if err := n.repo.Delete(context.WithoutCancel(ctx), kind, key); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("kind", kind).Warn("Failed to clear negative cache entry")
+	}
+}
+
+// ForgetAll drops every entry of kind in the caller's namespace
+func (n *NegativeCache) ForgetAll(ctx context.Context, kind domain.NegativeKind) {
+	if n.ttls[kind] <= 0 {
+		return
+	}
+	if err := n.repo.DeleteKind(context.WithoutCancel(ctx), kind); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("kind", kind).Warn("Failed to clear negative cache entries")
+	}
+}
+
+// stepFailureKey identifies a step input for remembering its failure
+func stepFailureKey(stepType, inputHash string) string {
+	return stepType + ":" + inputHash
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// outboxLease is how long a claimed entry is hidden from other relays while
+// its batch is upserted
+const outboxLease = time.Minute
+
+// OutboxRelay upserts the vectors that artifact writes queued in the outbox and
+// that were not stored right away, retrying failures with backoff until the
+// vector store accepts them. Payloads are built from the artifact as it is at
+// relay time
+type OutboxRelay struct {
+	outboxRepo   ports.VectorOutboxRepository
+	artifactRepo ports.ArtifactRepository
+	vectorRepo   ports.VectorRepository
+	lookupCache  *LookupCache
+	cfg          config.OutboxConfig
+	backoff      domain.RetryPolicy
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewOutboxRelay(outboxRepo ports.VectorOutboxRepository, artifactRepo ports.ArtifactRepository, vectorRepo ports.VectorRepository, lookupCache *LookupCache, cfg config.OutboxConfig) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo:   outboxRepo,
+		artifactRepo: artifactRepo,
+		vectorRepo:   vectorRepo,
+		lookupCache:  lookupCache,
+		cfg:          cfg,
+		backoff: domain.RetryPolicy{
+			InitialBackoff: cfg.InitialBackoff,
+			MaxBackoff:     cfg.MaxBackoff,
+			Multiplier:     2,
+		},
+	}
+}
+
+func (r *OutboxRelay) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	status.Track(outboxRelayComponent)
+	r.wg.Add(1)
+	go r.loop(ctx)
+
+	logrus.WithFields(logrus.Fields{
+		"interval":   r.cfg.Interval,
+		"batch_size": r.cfg.BatchSize,
+	}).Info("Vector outbox relay started")
+}
+
+func (r *OutboxRelay) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	logrus.Info("Vector outbox relay stopped")
+}
+
+func (r *OutboxRelay) loop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			status.RecordRun(ctx, outboxRelayComponent, r.relay(ctx))
+		}
+	}
+}
+
+// relay upserts due entries a batch at a time until none are left
+func (r *OutboxRelay) relay(ctx context.Context) error {
+	for ctx.Err() == nil {
+		entries, err := r.outboxRepo.Claim(ctx, outboxLease, r.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).Error("Failed to claim vector outbox entries")
+			}
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		r.relayBatch(ctx, entries)
+		if len(entries) < r.cfg.BatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *OutboxRelay) relayBatch(ctx context.Context, entries []domain.OutboxEntry) {
+	var points []domain.VectorPoint
+	var done []uuid.UUID
+	var before time.Time
+	for _, entry := range entries {
+		if entry.QueuedAt.After(before) {
+			before = entry.QueuedAt
+		}
+
+		payload, err := r.payload(ctx, entry)
+		if err != nil {
+			r.retry(ctx, entry, err)
+			continue
+		}
+		// Deleted artifacts have nothing left to index
+		if payload == nil {
+			done = append(done, entry.ArtifactID)
+			continue
+		}
+		points = append(points, domain.VectorPoint{ID: entry.ArtifactID, Embedding: entry.Embedding, Payload: payload})
+	}
+
+	if len(points) > 0 {
+		if err := r.vectorRepo.StoreBatch(ctx, points); err != nil {
+			for _, entry := range entries {
+				if containsPoint(points, entry.ArtifactID) {
+					r.retry(ctx, entry, err)
+				}
+			}
+		} else {
+			for _, point := range points {
+				done = append(done, point.ID)
+			}
+			r.lookupCache.InvalidateAll()
+			logrus.WithField("count", len(points)).Info("Relayed queued vectors to the vector store")
+		}
+	}
+
+	// Entries queued again since they were claimed are kept for the next pass
+	if err := r.outboxRepo.Complete(ctx, done, before); err != nil {
+		logrus.WithError(err).Warn("Failed to remove relayed vector outbox entries")
+	}
+}
+
+// payload builds the vector payload from the artifact's current state, or
+// returns nil when the artifact no longer exists
+func (r *OutboxRelay) payload(ctx context.Context, entry domain.OutboxEntry) (map[string]interface{}, error) {
+	ctx = namespaceContext(ctx, entry.Namespace)
+	artifact, err := r.artifactRepo.GetMetadataByID(ctx, entry.ArtifactID)
+	if err != nil || artifact == nil {
+		return nil, err
+	}
+
+	// Chunks carry their parent's metadata so lookup filters apply to them
+	if parentID, ok := artifact.Metadata[domain.ChunkOfKey].(string); ok {
+		id, err := uuid.Parse(parentID)
+		if err != nil {
+			return nil, err
+		}
+		parent, err := r.artifactRepo.GetMetadataByID(ctx, id)
+		if err != nil || parent == nil {
+			return nil, err
+		}
+		return chunkPayload(parent, artifact), nil
+	}
+	return vectorPayload(artifact), nil
+}
+
+func (r *OutboxRelay) retry(ctx context.Context, entry domain.OutboxEntry, cause error) {
+	retryAt := time.Now().Add(r.backoff.Backoff(entry.Attempts + 1))
+	if err := r.outboxRepo.Retry(ctx, entry.ArtifactID, retryAt, cause.Error()); err != nil {
+		logrus.WithError(err).WithField("artifact_id", entry.ArtifactID).Warn("Failed to reschedule vector outbox entry")
+	}
+	logrus.WithError(cause).WithFields(logrus.Fields{
+		"artifact_id": entry.ArtifactID,
+		"attempts":    entry.Attempts + 1,
+		"retry_at":    retryAt,
+	}).Warn("Failed to relay queued vector")
+}
+
+func containsPoint(points []domain.VectorPoint, id uuid.UUID) bool {
+	for _, point := range points {
+		if point.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboxVectorRepository removes outbox entries of vectors stored directly, so
+// the relay only handles upserts that failed. Entries queued while the upsert
+// was in flight are kept
+type OutboxVectorRepository struct {
+	ports.VectorRepository
+	outboxRepo ports.VectorOutboxRepository
+}
+
+func NewOutboxVectorRepository(vectorRepo ports.VectorRepository, outboxRepo ports.VectorOutboxRepository) *OutboxVectorRepository {
+	return &OutboxVectorRepository{VectorRepository: vectorRepo, outboxRepo: outboxRepo}
+}
+
+func (r *OutboxVectorRepository) Store(ctx context.Context, id uuid.UUID, embedding []float32, metadata map[string]interface{}) error {
+	before := time.Now()
+	if err := r.VectorRepository.Store(ctx, id, embedding, metadata); err != nil {
+		return err
+	}
+	r.complete(ctx, []uuid.UUID{id}, before)
+	return nil
+}
+
+func (r *OutboxVectorRepository) StoreBatch(ctx context.Context, points []domain.VectorPoint) error {
+	before := time.Now()
+	if err := r.VectorRepository.StoreBatch(ctx, points); err != nil {
+		return err
+	}
+	ids := make([]uuid.UUID, len(points))
+	for i, point := range points {
+		ids[i] = point.ID
+	}
+	r.complete(ctx, ids, before)
+	return nil
+}
+
+// complete is best effort; an entry left behind is upserted again by the relay
+func (r *OutboxVectorRepository) complete(ctx context.Context, ids []uuid.UUID, before time.Time) {
+	if err := r.outboxRepo.Complete(ctx, ids, before); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("count", len(ids)).Warn("Failed to remove stored vectors from the outbox")
+	}
+}
+
+func (r *OutboxVectorRepository) NewBuffer() ports.VectorBuffer {
+	return &outboxBuffer{VectorBuffer: r.VectorRepository.NewBuffer(), outboxRepo: r.outboxRepo}
+}
+
+// outboxBuffer removes the outbox entries of a buffer's points once a flush
+// confirms them. After any failed batch it leaves them all to the relay
+type outboxBuffer struct {
+	ports.VectorBuffer
+	outboxRepo ports.VectorOutboxRepository
+
+	mu     sync.Mutex
+	ids    []uuid.UUID
+	before time.Time
+	failed bool
+}
+
+func (b *outboxBuffer) Add(ctx context.Context, point domain.VectorPoint) error {
+	b.mu.Lock()
+	if len(b.ids) == 0 {
+		b.before = time.Now()
+	}
+	b.ids = append(b.ids, point.ID)
+	b.mu.Unlock()
+
+	err := b.VectorBuffer.Add(ctx, point)
+	if err != nil {
+		b.mu.Lock()
+		b.failed = true
+		b.mu.Unlock()
+	}
+	return err
+}
+
+func (b *outboxBuffer) Flush(ctx context.Context) error {
+	if err := b.VectorBuffer.Flush(ctx); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	ids, before, failed := b.ids, b.before, b.failed
+	b.ids, b.failed = nil, false
+	b.mu.Unlock()
+
+	if !failed {
+		if err := b.outboxRepo.Complete(ctx, ids, before); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("count", len(ids)).Warn("Failed to remove stored vectors from the outbox")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+// scriptedOutbox hands out the given batches in order and records what the
+// relay completes and retries
+type scriptedOutbox struct {
+	batches [][]domain.OutboxEntry
+	claims  int
+
+	completed []uuid.UUID
+	before    time.Time
+	retried   map[uuid.UUID]outboxRetry
+}
+
+type outboxRetry struct {
+	at     time.Time
+	reason string
+}
+
+func (o *scriptedOutbox) Claim(ctx context.Context, lease time.Duration, limit int) ([]domain.OutboxEntry, error) {
+	o.claims++
+	if len(o.batches) == 0 {
+		return nil, nil
+	}
+	batch := o.batches[0]
+	o.batches = o.batches[1:]
+	return batch, nil
+}
+
+func (o *scriptedOutbox) Complete(ctx context.Context, artifactIDs []uuid.UUID, before time.Time) error {
+	o.completed = append(o.completed, artifactIDs...)
+	o.before = before
+	return nil
+}
+
+func (o *scriptedOutbox) Retry(ctx context.Context, artifactID uuid.UUID, retryAt time.Time, reason string) error {
+	if o.retried == nil {
+		o.retried = make(map[uuid.UUID]outboxRetry)
+	}
+	o.retried[artifactID] = outboxRetry{at: retryAt, reason: reason}
+	return nil
+}
+
+// namespacedArtifacts finds artifacts only in their own namespace, as the
+// repository does
+type namespacedArtifacts struct {
+	ports.ArtifactRepository
+	artifacts map[uuid.UUID]*domain.Artifact
+	failing   map[uuid.UUID]error
+}
+
+func (a *namespacedArtifacts) GetMetadataByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	if err := a.failing[id]; err != nil {
+		return nil, err
+	}
+	artifact := a.artifacts[id]
+	if artifact == nil || artifact.Namespace != domain.NamespaceFromContext(ctx) {
+		return nil, nil
+	}
+	return artifact, nil
+}
+
+// batchVectors records the points of every batch it accepts
+type batchVectors struct {
+	ports.VectorRepository
+	err    error
+	stored map[uuid.UUID]domain.VectorPoint
+}
+
+func (v *batchVectors) StoreBatch(ctx context.Context, points []domain.VectorPoint) error {
+	if v.err != nil {
+		return v.err
+	}
+	if v.stored == nil {
+		v.stored = make(map[uuid.UUID]domain.VectorPoint)
+	}
+	for _, point := range points {
+		v.stored[point.ID] = point
+	}
+	return nil
+}
+
+func newTestOutboxRelay(outbox *scriptedOutbox, artifacts *namespacedArtifacts, vectors *batchVectors, batchSize int) *OutboxRelay {
+	return NewOutboxRelay(outbox, artifacts, vectors, NewLookupCache(config.LookupCacheConfig{}), config.OutboxConfig{
+		BatchSize:      batchSize,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	})
+}
+
+func TestOutboxRelayStoresCurrentPayloads(t *testing.T) {
+	parent := &domain.Artifact{ID: uuid.New(), Namespace: "team-a", Type: domain.RAW, Metadata: map[string]interface{}{"source": "wiki"}}
+	chunk := &domain.Artifact{ID: uuid.New(), Namespace: "team-a", Type: domain.RAW, Metadata: map[string]interface{}{domain.ChunkOfKey: parent.ID.String()}}
+	deletedID := uuid.New()
+	queuedAt := time.Now().Add(-time.Minute)
+
+	outbox := &scriptedOutbox{batches: [][]domain.OutboxEntry{{
+		{ArtifactID: parent.ID, Namespace: "team-a", Embedding: []float32{1}, QueuedAt: queuedAt.Add(-time.Second)},
+		{ArtifactID: chunk.ID, Namespace: "team-a", Embedding: []float32{2}, QueuedAt: queuedAt},
+		{ArtifactID: deletedID, Namespace: "team-a", Embedding: []float32{3}, QueuedAt: queuedAt.Add(-2 * time.Second)},
+	}}}
+	artifacts := &namespacedArtifacts{artifacts: map[uuid.UUID]*domain.Artifact{parent.ID: parent, chunk.ID: chunk}}
+	vectors := &batchVectors{}
+
+	if err := newTestOutboxRelay(outbox, artifacts, vectors, 10).relay(context.Background()); err != nil {
+		t.Fatalf("relay: %v", err)
+	}
+
+	if len(vectors.stored) != 2 {
+		t.Fatalf("expected the parent and chunk vectors to be stored, got %d", len(vectors.stored))
+	}
+	if payload := vectors.stored[parent.ID].Payload; payload["namespace"] != "team-a" || payload["source"] != "wiki" {
+		t.Fatalf("expected the parent's payload, got %v", payload)
+	}
+	if payload := vectors.stored[chunk.ID].Payload; payload["source"] != "wiki" || payload[domain.ChunkOfKey] != parent.ID.String() {
+		t.Fatalf("expected the chunk to carry its parent's metadata, got %v", payload)
+	}
+	if len(outbox.completed) != 3 {
+		t.Fatalf("expected every entry to be completed, deleted artifacts included, got %v", outbox.completed)
+	}
+	if !outbox.before.Equal(queuedAt) {
+		t.Fatalf("expected completion to keep entries queued after %s, got %s", queuedAt, outbox.before)
+	}
+	if len(outbox.retried) != 0 {
+		t.Fatalf("expected no retries, got %v", outbox.retried)
+	}
+}
+
+func TestOutboxRelayRetriesFailedUpserts(t *testing.T) {
+	stored := &domain.Artifact{ID: uuid.New(), Namespace: domain.DefaultNamespace}
+	deletedID := uuid.New()
+	outbox := &scriptedOutbox{batches: [][]domain.OutboxEntry{{
+		{ArtifactID: stored.ID, Namespace: domain.DefaultNamespace, Attempts: 2},
+		{ArtifactID: deletedID, Namespace: domain.DefaultNamespace},
+	}}}
+	artifacts := &namespacedArtifacts{artifacts: map[uuid.UUID]*domain.Artifact{stored.ID: stored}}
+	vectors := &batchVectors{err: errors.New("vector store unavailable")}
+
+	start := time.Now()
+	if err := newTestOutboxRelay(outbox, artifacts, vectors, 10).relay(context.Background()); err != nil {
+		t.Fatalf("relay: %v", err)
+	}
+
+	retry, ok := outbox.retried[stored.ID]
+	if !ok || retry.reason != "vector store unavailable" {
+		t.Fatalf("expected the failed upsert to be retried with its cause, got %v", outbox.retried)
+	}
+	// The third attempt waits four times the initial backoff, plus up to 20% jitter
+	if wait := retry.at.Sub(start); wait < 4*time.Second || wait > 5*time.Second {
+		t.Fatalf("expected a backoff of about 4s, got %s", wait)
+	}
+	if len(outbox.completed) != 1 || outbox.completed[0] != deletedID {
+		t.Fatalf("expected only the deleted artifact's entry to be completed, got %v", outbox.completed)
+	}
+}
+
+func TestOutboxRelayRetriesEntriesWithoutPayload(t *testing.T) {
+	good := &domain.Artifact{ID: uuid.New(), Namespace: domain.DefaultNamespace}
+	badID := uuid.New()
+	outbox := &scriptedOutbox{batches: [][]domain.OutboxEntry{{
+		{ArtifactID: good.ID, Namespace: domain.DefaultNamespace},
+		{ArtifactID: badID, Namespace: domain.DefaultNamespace},
+	}}}
+	artifacts := &namespacedArtifacts{
+		artifacts: map[uuid.UUID]*domain.Artifact{good.ID: good},
+		failing:   map[uuid.UUID]error{badID: errors.New("database unavailable")},
+	}
+	vectors := &batchVectors{}
+
+	if err := newTestOutboxRelay(outbox, artifacts, vectors, 10).relay(context.Background()); err != nil {
+		t.Fatalf("relay: %v", err)
+	}
+
+	if _, ok := vectors.stored[good.ID]; !ok || len(vectors.stored) != 1 {
+		t.Fatalf("expected only the readable artifact to be stored, got %v", vectors.stored)
+	}
+	if _, ok := outbox.retried[badID]; !ok || len(outbox.retried) != 1 {
+		t.Fatalf("expected only the unreadable entry to be retried, got %v", outbox.retried)
+	}
+	if len(outbox.completed) != 1 || outbox.completed[0] != good.ID {
+		t.Fatalf("expected only the stored entry to be completed, got %v", outbox.completed)
+	}
+}
+
+func TestOutboxRelayDrainsFullBatches(t *testing.T) {
+	entry := func() domain.OutboxEntry {
+		return domain.OutboxEntry{ArtifactID: uuid.New(), Namespace: domain.DefaultNamespace}
+	}
+	outbox := &scriptedOutbox{batches: [][]domain.OutboxEntry{
+		{entry(), entry()},
+		{entry(), entry()},
+		{entry()},
+		{entry()},
+	}}
+	relay := newTestOutboxRelay(outbox, &namespacedArtifacts{}, &batchVectors{}, 2)
+
+	if err := relay.relay(context.Background()); err != nil {
+		t.Fatalf("relay: %v", err)
+	}
+
+	// A short batch means nothing else was due, so the last batch waits for the next tick
+	if outbox.claims != 3 || len(outbox.batches) != 1 {
+		t.Fatalf("expected three claims ending at the short batch, got %d with %d batches left", outbox.claims, len(outbox.batches))
+	}
+	if len(outbox.completed) != 5 {
+		t.Fatalf("expected five completed entries, got %d", len(outbox.completed))
+	}
+}
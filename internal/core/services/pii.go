@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+)
+
+var piiDetectors = map[domain.PIIKind][]*regexp.Regexp{
+	domain.PIIEmail: {
+		regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	},
+	domain.PIIPhone: {
+		regexp.MustCompile(`(?:\+\d{1,3}[\s.\-]?)?(?:\(\d{3}\)|\b\d{3})[\s.\-]\d{3}[\s.\-]\d{4}\b`),
+	},
+	domain.PIISecret: {
+		regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+		regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{20,}`),
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}`),
+		regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9\-]{10,}`),
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`(?i)\b(?:api[_\-]?key|secret|token|password)\s*[:=]\s*['"]?[A-Za-z0-9_\-./+]{8,}`),
+	},
+}
+
+// PIIScanner detects emails, phone numbers, and credentials in artifact content
+type PIIScanner struct {
+	policy    domain.PIIPolicy
+	detectors map[domain.PIIKind][]*regexp.Regexp
+}
+
+func NewPIIScanner(cfg config.PIIConfig) *PIIScanner {
+	detectors := make(map[domain.PIIKind][]*regexp.Regexp)
+	for _, kind := range cfg.Detectors {
+		kind = strings.TrimSpace(kind)
+		if patterns, ok := piiDetectors[domain.PIIKind(kind)]; ok {
+			detectors[domain.PIIKind(kind)] = patterns
+		}
+	}
+
+	return &PIIScanner{
+		policy:    domain.PIIPolicy(cfg.Policy),
+		detectors: detectors,
+	}
+}
+
+func (s *PIIScanner) Policy() domain.PIIPolicy {
+	if s.policy == "" {
+		return domain.PIIPolicyOff
+	}
+	return s.policy
+}
+
+// Scan returns all non-overlapping findings in content, ordered by position
+func (s *PIIScanner) Scan(content []byte) []domain.PIIFinding {
+	var findings []domain.PIIFinding
+	for kind, patterns := range s.detectors {
+		for _, pattern := range patterns {
+			for _, loc := range pattern.FindAllIndex(content, -1) {
+				findings = append(findings, domain.PIIFinding{Kind: kind, Start: loc[0], End: loc[1]})
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Start != findings[j].Start {
+			return findings[i].Start < findings[j].Start
+		}
+		return findings[i].End > findings[j].End
+	})
+
+	// Keep the first (longest) match at each position and drop overlaps
+	merged := findings[:0]
+	end := -1
+	for _, f := range findings {
+		if f.Start < end {
+			continue
+		}
+		merged = append(merged, f)
+		end = f.End
+	}
+
+	return merged
+}
+
+// Redact replaces every finding with a [REDACTED:<kind>] marker
+func (s *PIIScanner) Redact(content []byte, findings []domain.PIIFinding) []byte {
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		b.Write(content[last:f.Start])
+		b.WriteString("[REDACTED:" + string(f.Kind) + "]")
+		last = f.End
+	}
+	b.Write(content[last:])
+	return []byte(b.String())
+}
+
+// enforcePIIPolicy scans an artifact's content and every string in its metadata
+// values, then flags, redacts, or rejects the artifact according to the
+// scanner's policy. A nil scanner enforces nothing
+func enforcePIIPolicy(scanner ports.ContentScanner, artifact *domain.Artifact) error {
+	if scanner == nil || scanner.Policy() == domain.PIIPolicyOff {
+		return nil
+	}
+
+	policy := scanner.Policy()
+	switch policy {
+	case domain.PIIPolicyFlag, domain.PIIPolicyRedact, domain.PIIPolicyBlock:
+	default:
+		// Refuse rather than store content an unknown policy was meant to handle
+		return fmt.Errorf("unknown PII policy %q", policy)
+	}
+	redact := policy == domain.PIIPolicyRedact
+
+	findings := scanner.Scan(artifact.Content)
+	kinds := domain.PIIKinds(findings)
+	if redact && len(findings) > 0 {
+		artifact.Content = scanner.Redact(artifact.Content, findings)
+		// Any client-supplied hash described the unredacted content
+		artifact.ContentHash = ""
+	}
+
+	for key, value := range artifact.Metadata {
+		artifact.Metadata[key] = scanPIIValue(scanner, value, redact, &kinds)
+	}
+
+	if len(kinds) == 0 {
+		return nil
+	}
+
+	switch policy {
+	case domain.PIIPolicyBlock:
+		return domain.NewValidationError("artifact content contains sensitive data").
+			WithDetail("pii_kinds", kinds)
+	case domain.PIIPolicyRedact:
+		setMetadata(artifact, "pii_redacted", kinds)
+	case domain.PIIPolicyFlag:
+		setMetadata(artifact, "pii_detected", kinds)
+	}
+	return nil
+}
+
+// scanPIIValue scans the strings in a metadata value, nested lists and objects
+// included, adding the kinds found to kinds. Lists and objects are copied, so
+// redacting never alters a value the caller still holds
+func scanPIIValue(scanner ports.ContentScanner, value interface{}, redact bool, kinds *[]domain.PIIKind) interface{} {
+	switch v := value.(type) {
+	case string:
+		findings := scanner.Scan([]byte(v))
+		for _, kind := range domain.PIIKinds(findings) {
+			if !containsPIIKind(*kinds, kind) {
+				*kinds = append(*kinds, kind)
+			}
+		}
+		if redact && len(findings) > 0 {
+			return string(scanner.Redact([]byte(v), findings))
+		}
+		return v
+	case []string:
+		scanned := make([]string, len(v))
+		for i, item := range v {
+			scanned[i] = scanPIIValue(scanner, item, redact, kinds).(string)
+		}
+		return scanned
+	case []interface{}:
+		scanned := make([]interface{}, len(v))
+		for i, item := range v {
+			scanned[i] = scanPIIValue(scanner, item, redact, kinds)
+		}
+		return scanned
+	case map[string]interface{}:
+		scanned := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			scanned[key] = scanPIIValue(scanner, item, redact, kinds)
+		}
+		return scanned
+	default:
+		return value
+	}
+}
+
+func containsPIIKind(kinds []domain.PIIKind, kind domain.PIIKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
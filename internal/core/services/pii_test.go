@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+)
+
+func TestEnforcePIIPolicyRedactsMetadata(t *testing.T) {
+	scanner := NewPIIScanner(config.PIIConfig{Policy: "redact", Detectors: []string{"email", "phone"}})
+	nested := map[string]interface{}{"owner": "bob@example.com"}
+	artifact := &domain.Artifact{
+		Content:     []byte("call 555-123-4567"),
+		ContentHash: "client-hash",
+		Metadata: map[string]interface{}{
+			"author":   "alice@example.com",
+			"contacts": []interface{}{"none", nested},
+			"count":    3,
+		},
+	}
+
+	if err := enforcePIIPolicy(scanner, artifact); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(artifact.Content) != "call [REDACTED:phone]" || artifact.ContentHash != "" {
+		t.Fatalf("expected redacted content and a cleared hash, got %q, %q", artifact.Content, artifact.ContentHash)
+	}
+	want := map[string]interface{}{
+		"author":       "[REDACTED:email]",
+		"contacts":     []interface{}{"none", map[string]interface{}{"owner": "[REDACTED:email]"}},
+		"count":        3,
+		"pii_redacted": []domain.PIIKind{domain.PIIPhone, domain.PIIEmail},
+	}
+	if !reflect.DeepEqual(artifact.Metadata, want) {
+		t.Fatalf("expected %v, got %v", want, artifact.Metadata)
+	}
+	if nested["owner"] != "bob@example.com" {
+		t.Fatalf("expected the caller's nested value to be left alone, got %v", nested["owner"])
+	}
+}
+
+func TestEnforcePIIPolicyBlocksMetadata(t *testing.T) {
+	scanner := NewPIIScanner(config.PIIConfig{Policy: "block", Detectors: []string{"email"}})
+	artifact := &domain.Artifact{
+		Content:  []byte("clean"),
+		Metadata: map[string]interface{}{"author": "alice@example.com"},
+	}
+
+	err := enforcePIIPolicy(scanner, artifact)
+	if !errors.Is(err, domain.ErrValidation) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
+
+func TestEnforcePIIPolicyOff(t *testing.T) {
+	artifact := &domain.Artifact{Content: []byte("alice@example.com")}
+	for _, scanner := range []ports.ContentScanner{nil, NewPIIScanner(config.PIIConfig{Policy: "off", Detectors: []string{"email"}})} {
+		if err := enforcePIIPolicy(scanner, artifact); err != nil || string(artifact.Content) != "alice@example.com" {
+			t.Fatalf("expected content untouched, got %q, %v", artifact.Content, err)
+		}
+	}
+}
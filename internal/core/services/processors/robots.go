@@ -0,0 +1,199 @@
+package processors
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// maxRobotsBytes bounds the robots.txt read, as crawlers commonly do
+const maxRobotsBytes = 512 << 10
+
+// robotsRules are the Allow and Disallow paths of the robots.txt group that
+// applies to the scraper's user agent
+type robotsRules struct {
+	allow    []string
+	disallow []string
+}
+
+// allows reports whether path may be fetched: the longest matching rule wins,
+// and Allow wins a tie
+func (r robotsRules) allows(path string) bool {
+	longest := func(patterns []string) int {
+		best := -1
+		for _, pattern := range patterns {
+			if len(pattern) > best && robotsMatch(pattern, path) {
+				best = len(pattern)
+			}
+		}
+		return best
+	}
+	return longest(r.allow) >= longest(r.disallow)
+}
+
+// robotsMatch matches a robots.txt path pattern, in which * matches any
+// characters and a trailing $ anchors the end, against the start of path
+func robotsMatch(pattern, path string) bool {
+	if pattern == "" {
+		return true
+	}
+	switch {
+	case pattern[0] == '*':
+		for i := 0; i <= len(path); i++ {
+			if robotsMatch(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	case pattern == "$":
+		return path == ""
+	}
+	return path != "" && path[0] == pattern[0] && robotsMatch(pattern[1:], path[1:])
+}
+
+// parseRobots returns the rules of the group naming userAgent's product
+// token, preferring the most specific name, or else of the * group
+func parseRobots(r io.Reader, userAgent string) robotsRules {
+	token := strings.ToLower(strings.SplitN(userAgent, "/", 2)[0])
+
+	type group struct {
+		agents []string
+		rules  robotsRules
+	}
+	var groups []*group
+	var current *group
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if current == nil || inRules {
+				current = &group{}
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			// An empty Disallow allows everything, which no rule expresses as well
+			if value == "" {
+				continue
+			}
+			if key == "allow" {
+				current.rules.allow = append(current.rules.allow, value)
+			} else {
+				current.rules.disallow = append(current.rules.disallow, value)
+			}
+		}
+	}
+
+	var matched *group
+	matchedLen := -1
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			switch {
+			case agent == "*" && matchedLen < 0:
+				matched, matchedLen = g, 0
+			case agent != "*" && strings.Contains(token, agent) && len(agent) > matchedLen:
+				matched, matchedLen = g, len(agent)
+			}
+		}
+	}
+	if matched == nil {
+		return robotsRules{}
+	}
+	return matched.rules
+}
+
+type robotsEntry struct {
+	rules     robotsRules
+	fetchedAt time.Time
+}
+
+// robotsCache fetches and caches the robots.txt rules of each site
+type robotsCache struct {
+	client    *http.Client
+	userAgent string
+	ttl       time.Duration
+
+	mu      sync.Mutex
+	entries map[string]robotsEntry
+}
+
+func newRobotsCache(client *http.Client, userAgent string, ttl time.Duration) *robotsCache {
+	return &robotsCache{
+		client:    client,
+		userAgent: userAgent,
+		ttl:       ttl,
+		entries:   make(map[string]robotsEntry),
+	}
+}
+
+// allowed reports whether the site's robots.txt lets the scraper fetch target
+func (c *robotsCache) allowed(ctx context.Context, target *url.URL) (bool, error) {
+	site := target.Scheme + "://" + target.Host
+
+	c.mu.Lock()
+	entry, ok := c.entries[site]
+	c.mu.Unlock()
+
+	if !ok || time.Since(entry.fetchedAt) > c.ttl {
+		rules, err := c.fetch(ctx, site)
+		if err != nil {
+			return false, err
+		}
+		entry = robotsEntry{rules: rules, fetchedAt: time.Now()}
+
+		c.mu.Lock()
+		c.entries[site] = entry
+		c.mu.Unlock()
+	}
+
+	return entry.rules.allows(target.RequestURI()), nil
+}
+
+// fetch reads a site's robots.txt. A missing one allows everything; one that
+// can't be read right now fails the fetch so it is retried later
+func (c *robotsCache) fetch(ctx context.Context, site string) (robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, site+"/robots.txt", nil)
+	if err != nil {
+		return robotsRules{}, fmt.Errorf("failed to create robots.txt request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return robotsRules{}, domain.NewUpstreamError("robots.txt", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return parseRobots(io.LimitReader(resp.Body, maxRobotsBytes), c.userAgent), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return robotsRules{}, nil
+	default:
+		return robotsRules{}, domain.NewUpstreamError("robots.txt", fmt.Errorf("site returned status %d", resp.StatusCode))
+	}
+}
@@ -0,0 +1,182 @@
+package processors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/egress"
+	"golang.org/x/net/html/charset"
+)
+
+// ScrapeProcessor executes scrape steps: it fetches the URL given as the
+// step's input, as a string or as {"url": "..."}, and returns the page's
+// readable text as a RAW artifact carrying the URL as source_url, so the
+// page can be invalidated and refreshed by source like published content
+type ScrapeProcessor struct {
+	client    *http.Client
+	userAgent string
+	maxBytes  int64
+	// robots is nil when robots.txt is not respected
+	robots *robotsCache
+}
+
+func NewScrapeProcessor(cfg config.ScrapeConfig, guard *egress.Guard) *ScrapeProcessor {
+	client := guard.Client(cfg.Timeout, true)
+
+	processor := &ScrapeProcessor{
+		client:    client,
+		userAgent: cfg.UserAgent,
+		maxBytes:  cfg.MaxBytes,
+	}
+	if cfg.RespectRobots {
+		processor.robots = newRobotsCache(client, cfg.UserAgent, cfg.RobotsCacheTTL)
+	}
+	return processor
+}
+
+func (p *ScrapeProcessor) Process(ctx context.Context, step *domain.WorkflowStep, input interface{}) (*domain.StepOutput, error) {
+	target, err := scrapeTarget(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.robots != nil {
+		allowed, err := p.robots.allowed(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, &domain.Error{
+				Code:    domain.CodeForbidden,
+				Message: "robots.txt disallows fetching the page",
+				Details: map[string]interface{}{"url": target.String()},
+			}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, domain.NewUpstreamError("scrape target", err)
+	}
+	defer resp.Body.Close()
+
+	if err := statusError(resp, target); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBytes+1))
+	if err != nil {
+		return nil, domain.NewUpstreamError("scrape target", err)
+	}
+	if int64(len(body)) > p.maxBytes {
+		return nil, &domain.Error{
+			Code:    domain.CodePayloadTooLarge,
+			Message: "page is larger than the scrape limit",
+			Details: map[string]interface{}{"url": target.String(), "max_bytes": p.maxBytes},
+		}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	// Pages are stored as UTF-8 whatever charset they were served in
+	decoded, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		decoded = bytes.NewReader(body)
+	}
+
+	metadata := map[string]interface{}{
+		"source_url":   target.String(),
+		"content_type": mediaType,
+		"status_code":  resp.StatusCode,
+		"fetched_at":   time.Now().UTC().Format(time.RFC3339),
+	}
+	if final := resp.Request.URL.String(); final != target.String() {
+		metadata["final_url"] = final
+	}
+	// The page's validators let the freshness watcher tell when it changes
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		metadata["etag"] = etag
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		metadata["last_modified"] = lastModified
+	}
+
+	var text string
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml" || mediaType == "":
+		var title string
+		title, text = extractText(decoded)
+		if title != "" {
+			metadata["title"] = title
+		}
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" || mediaType == "application/xml":
+		raw, err := io.ReadAll(decoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode page: %w", err)
+		}
+		text = strings.TrimSpace(string(raw))
+	default:
+		return nil, domain.NewValidationError("unsupported content type for scraping").WithDetail("content_type", mediaType)
+	}
+
+	if text == "" {
+		return nil, domain.NewValidationError("page has no readable text").WithDetail("url", target.String())
+	}
+
+	return &domain.StepOutput{
+		Type:     domain.RAW,
+		Content:  text,
+		Metadata: metadata,
+	}, nil
+}
+
+// scrapeTarget reads the URL to fetch from a scrape step's input
+func scrapeTarget(input interface{}) (*url.URL, error) {
+	var raw string
+	switch input := input.(type) {
+	case string:
+		raw = input
+	case map[string]interface{}:
+		raw, _ = input["url"].(string)
+	}
+	if raw == "" {
+		return nil, domain.NewValidationError(`scrape input must be a URL or {"url": "..."}`)
+	}
+
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, domain.NewValidationError("scrape url must be an absolute http(s) URL").WithDetail("url", raw)
+	}
+	return target, nil
+}
+
+// statusError maps an unsuccessful response to an error: rate limiting and
+// server errors may pass and are retryable, other client errors are not
+func statusError(resp *http.Response, target *url.URL) error {
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return domain.NewUpstreamError("scrape target", fmt.Errorf("page returned status %d", resp.StatusCode))
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return domain.NewNotFoundError("page", target.String())
+	default:
+		return domain.NewValidationError(fmt.Sprintf("page returned status %d", resp.StatusCode)).WithDetail("url", target.String())
+	}
+}
@@ -0,0 +1,93 @@
+package processors
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedElements hold no readable text
+var skippedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Canvas:   true,
+}
+
+// blockElements start a new line of text
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Hr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Li: true, atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Table: true, atom.Tr: true, atom.Pre: true, atom.Blockquote: true,
+	atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true,
+	atom.Main: true, atom.Aside: true, atom.Nav: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Form: true, atom.Address: true, atom.Details: true, atom.Summary: true,
+}
+
+// extractText returns the title and readable text of an HTML document: the
+// text outside scripts, styles and other non-content elements, one line per
+// block element, with runs of whitespace collapsed
+func extractText(r io.Reader) (title, text string) {
+	tokenizer := html.NewTokenizer(r)
+
+	var lines []string
+	var line, titleText strings.Builder
+	skipDepth := 0
+	inTitle := false
+
+	flush := func() {
+		if trimmed := strings.Join(strings.Fields(line.String()), " "); trimmed != "" {
+			lines = append(lines, trimmed)
+		}
+		line.Reset()
+	}
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			// io.EOF or a malformed document; either way, keep what was read
+			flush()
+			return strings.Join(strings.Fields(titleText.String()), " "), strings.Join(lines, "\n")
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch {
+			case token.DataAtom == atom.Title:
+				inTitle = true
+			case skippedElements[token.DataAtom]:
+				// Elements written self-closing have no end tag
+				if token.Type != html.SelfClosingTagToken {
+					skipDepth++
+				}
+			case blockElements[token.DataAtom]:
+				flush()
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch {
+			case token.DataAtom == atom.Title:
+				inTitle = false
+			case skippedElements[token.DataAtom]:
+				if skipDepth > 0 {
+					skipDepth--
+				}
+			case blockElements[token.DataAtom]:
+				flush()
+			}
+		case html.TextToken:
+			switch {
+			case inTitle:
+				titleText.Write(tokenizer.Text())
+			case skipDepth == 0:
+				line.Write(tokenizer.Text())
+				line.WriteByte(' ')
+			}
+		}
+	}
+}
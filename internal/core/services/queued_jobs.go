@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+)
+
+// stepJobQueue is the task queue internal step jobs are dispatched through
+const stepJobQueue = "step_jobs"
+
+// QueuedJobRepository dispatches internal step jobs to workers through a task
+// queue. Jobs stay recorded in the wrapped repository, which external executors
+// claim from and expired leases are recovered in; the queue carries their IDs.
+// Workers fall back to claiming from the repository when the queue has nothing,
+// which picks up jobs the queue missed, such as those requeued after a crash
+type QueuedJobRepository struct {
+	ports.JobRepository
+	queue ports.TaskQueue
+}
+
+func NewQueuedJobRepository(jobRepo ports.JobRepository, queue ports.TaskQueue) *QueuedJobRepository {
+	return &QueuedJobRepository{JobRepository: jobRepo, queue: queue}
+}
+
+// Enqueue dispatches the job as soon as it is recorded. Inside a transaction a
+// worker may dequeue it before it commits; the worker drops the task and the
+// job is claimed from the repository instead
+func (r *QueuedJobRepository) Enqueue(ctx context.Context, job *domain.StepJob) error {
+	if err := r.JobRepository.Enqueue(ctx, job); err != nil {
+		return err
+	}
+	if !job.External {
+		r.dispatch(ctx, job.ID, job.RunAt)
+	}
+	return nil
+}
+
+func (r *QueuedJobRepository) Retry(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID, runAt time.Time, reason string) error {
+	if err := r.JobRepository.Retry(ctx, id, leaseToken, runAt, reason); err != nil {
+		return err
+	}
+	r.dispatch(ctx, id, runAt)
+	return nil
+}
+
+// Claim locks the jobs the queue hands out. Tasks are acknowledged once their
+// jobs are claimed, after which the jobs' own leases protect them
+func (r *QueuedJobRepository) Claim(ctx context.Context, limit int) ([]*domain.StepJob, error) {
+	tasks, err := r.queue.Dequeue(ctx, stepJobQueue, limit)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("Failed to dequeue step jobs; claiming from the repository")
+		return r.JobRepository.Claim(ctx, limit)
+	}
+	if len(tasks) == 0 {
+		return r.JobRepository.Claim(ctx, limit)
+	}
+
+	ids := make([]uuid.UUID, 0, len(tasks))
+	for _, task := range tasks {
+		if id, err := uuid.Parse(string(task.Payload)); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	jobs, err := r.JobRepository.ClaimByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jobs already claimed or finished are dropped with their tasks
+	for _, task := range tasks {
+		if err := r.queue.Ack(ctx, task); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("task_id", task.ID).Warn("Failed to acknowledge step job task")
+		}
+	}
+	if len(jobs) == 0 {
+		return r.JobRepository.Claim(ctx, limit)
+	}
+	return jobs, nil
+}
+
+// dispatch is best effort; a job the queue doesn't get is claimed from the repository
+func (r *QueuedJobRepository) dispatch(ctx context.Context, id uuid.UUID, runAt time.Time) {
+	if err := r.queue.Enqueue(ctx, stepJobQueue, []byte(id.String()), runAt); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("job_id", id).Warn("Failed to queue step job")
+	}
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+type QuotaService struct {
+	quotaRepo  ports.QuotaRepository
+	apiKeyRepo ports.APIKeyRepository
+	transactor ports.Transactor
+	defaults   domain.QuotaLimits
+}
+
+func NewQuotaService(quotaRepo ports.QuotaRepository, apiKeyRepo ports.APIKeyRepository, transactor ports.Transactor, cfg config.QuotaConfig) *QuotaService {
+	return &QuotaService{
+		quotaRepo:  quotaRepo,
+		apiKeyRepo: apiKeyRepo,
+		transactor: transactor,
+		defaults: domain.QuotaLimits{
+			MaxArtifacts:        cfg.MaxArtifacts,
+			MaxBytes:            cfg.MaxBytes,
+			MaxEmbeddingsPerDay: cfg.MaxEmbeddingsPerDay,
+		},
+	}
+}
+
+// CheckArtifacts rejects a write of count artifacts totalling bytes that would
+// exceed the namespace's limits, or the caller's API key's. Inside a
+// transaction it holds the quota lock until the transaction ends, so writes
+// that store their artifacts in that transaction can't overshoot together
+func (s *QuotaService) CheckArtifacts(ctx context.Context, count, bytes int64) error {
+	namespace := domain.NamespaceFromContext(ctx)
+
+	limits, err := s.limits(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	keyID, keyLimits, err := s.keyLimits(ctx)
+	if err != nil {
+		return err
+	}
+	if !limitsArtifacts(limits) && !limitsArtifacts(keyLimits) {
+		return nil
+	}
+
+	var lockKey *uuid.UUID
+	if limitsArtifacts(keyLimits) {
+		lockKey = &keyID
+	}
+	if err := s.quotaRepo.LockUsage(ctx, namespace, lockKey); err != nil {
+		return fmt.Errorf("failed to lock quota usage: %w", err)
+	}
+
+	if limitsArtifacts(limits) {
+		usage, err := s.quotaRepo.GetUsage(ctx, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to get quota usage: %w", err)
+		}
+		if err := checkArtifactLimits(limits, usage, count, bytes); err != nil {
+			return err
+		}
+	}
+
+	if limitsArtifacts(keyLimits) {
+		usage, err := s.quotaRepo.GetKeyUsage(ctx, keyID)
+		if err != nil {
+			return fmt.Errorf("failed to get API key quota usage: %w", err)
+		}
+		if err := checkArtifactLimits(keyLimits, usage, count, bytes); err != nil {
+			return err.WithDetail("key_id", keyID.String())
+		}
+	}
+
+	return nil
+}
+
+// ConsumeEmbeddings records count embedding generations, rejecting them if the
+// namespace's or the caller's API key's daily limit would be exceeded
+func (s *QuotaService) ConsumeEmbeddings(ctx context.Context, count int64) error {
+	namespace := domain.NamespaceFromContext(ctx)
+
+	limits, err := s.limits(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	keyID, keyLimits, err := s.keyLimits(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Each check and its increment are one statement, so concurrent requests
+	// can't both pass it; the transaction keeps the two counters in step
+	return s.transactor.InTx(ctx, func(ctx context.Context) error {
+		ok, err := s.quotaRepo.ConsumeEmbeddings(ctx, namespace, count, limits.MaxEmbeddingsPerDay)
+		if err != nil {
+			return fmt.Errorf("failed to record embedding usage: %w", err)
+		}
+		if !ok {
+			var used int64
+			if usage, err := s.quotaRepo.GetUsage(ctx, namespace); err == nil {
+				used = usage.EmbeddingsToday
+			}
+			return domain.NewQuotaExceededError("embeddings", limits.MaxEmbeddingsPerDay, used, count)
+		}
+
+		// Issued keys count their embeddings even without limits of their own
+		if keyID == uuid.Nil {
+			return nil
+		}
+		var limit int64
+		if keyLimits != nil {
+			limit = keyLimits.MaxEmbeddingsPerDay
+		}
+		ok, err = s.quotaRepo.ConsumeKeyEmbeddings(ctx, keyID, count, limit)
+		if err != nil {
+			return fmt.Errorf("failed to record API key embedding usage: %w", err)
+		}
+		if !ok {
+			var used int64
+			if usage, err := s.quotaRepo.GetKeyUsage(ctx, keyID); err == nil {
+				used = usage.EmbeddingsToday
+			}
+			return domain.NewQuotaExceededError("embeddings", limit, used, count).WithDetail("key_id", keyID.String())
+		}
+		return nil
+	})
+}
+
+func (s *QuotaService) Status(ctx context.Context) (*domain.QuotaStatus, error) {
+	namespace := domain.NamespaceFromContext(ctx)
+
+	limits, err := s.limits(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.quotaRepo.GetUsage(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+
+	status := &domain.QuotaStatus{
+		Namespace: namespace,
+		Limits:    *limits,
+		Usage:     *usage,
+		Remaining: remainingQuota(limits, usage),
+	}
+
+	keyID, keyLimits, err := s.keyLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if keyLimits != nil {
+		keyUsage, err := s.quotaRepo.GetKeyUsage(ctx, keyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get API key quota usage: %w", err)
+		}
+		status.APIKey = &domain.KeyQuotaStatus{
+			KeyID:     keyID,
+			Limits:    *keyLimits,
+			Usage:     *keyUsage,
+			Remaining: remainingQuota(keyLimits, keyUsage),
+		}
+	}
+
+	return status, nil
+}
+
+func (s *QuotaService) SetLimits(ctx context.Context, namespace string, limits *domain.QuotaLimits) error {
+	if err := validateLimits(limits); err != nil {
+		return err
+	}
+	return s.quotaRepo.SetLimits(ctx, namespace, limits)
+}
+
+func (s *QuotaService) ResetLimits(ctx context.Context, namespace string) error {
+	return s.quotaRepo.DeleteLimits(ctx, namespace)
+}
+
+// SetKeyLimits caps an API key's usage across all of its namespaces, on top of
+// each namespace's own quota
+func (s *QuotaService) SetKeyLimits(ctx context.Context, keyID uuid.UUID, limits *domain.QuotaLimits) error {
+	if err := validateLimits(limits); err != nil {
+		return err
+	}
+	if err := s.checkKey(ctx, keyID); err != nil {
+		return err
+	}
+	return s.quotaRepo.SetKeyLimits(ctx, keyID, limits)
+}
+
+func (s *QuotaService) ResetKeyLimits(ctx context.Context, keyID uuid.UUID) error {
+	if err := s.checkKey(ctx, keyID); err != nil {
+		return err
+	}
+	return s.quotaRepo.DeleteKeyLimits(ctx, keyID)
+}
+
+// checkKey hides keys the caller could not have issued, as revocation does
+func (s *QuotaService) checkKey(ctx context.Context, keyID uuid.UUID) error {
+	key, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to get API key: %w", err)
+	}
+	if key == nil || !manages(ctx, key) {
+		return domain.NewNotFoundError("api_key", keyID.String())
+	}
+	return nil
+}
+
+// limits returns the namespace's override, or the configured defaults
+func (s *QuotaService) limits(ctx context.Context, namespace string) (*domain.QuotaLimits, error) {
+	limits, err := s.quotaRepo.GetLimits(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota limits: %w", err)
+	}
+	if limits == nil {
+		defaults := s.defaults
+		return &defaults, nil
+	}
+	return limits, nil
+}
+
+// keyLimits returns the caller's API key and its limits, or nil limits when the
+// caller has no issued key or the key has no limits of its own
+func (s *QuotaService) keyLimits(ctx context.Context) (uuid.UUID, *domain.QuotaLimits, error) {
+	keyID, ok := domain.APIKeyIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, nil, nil
+	}
+	limits, err := s.quotaRepo.GetKeyLimits(ctx, keyID)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to get API key quota limits: %w", err)
+	}
+	return keyID, limits, nil
+}
+
+func validateLimits(limits *domain.QuotaLimits) error {
+	if limits.MaxArtifacts < 0 || limits.MaxBytes < 0 || limits.MaxEmbeddingsPerDay < 0 {
+		return domain.NewValidationError("quota limits must not be negative")
+	}
+	return nil
+}
+
+func limitsArtifacts(limits *domain.QuotaLimits) bool {
+	return limits != nil && (limits.MaxArtifacts > 0 || limits.MaxBytes > 0)
+}
+
+func checkArtifactLimits(limits *domain.QuotaLimits, usage *domain.QuotaUsage, count, bytes int64) *domain.Error {
+	if limits.MaxArtifacts > 0 && usage.Artifacts+count > limits.MaxArtifacts {
+		return domain.NewQuotaExceededError("artifacts", limits.MaxArtifacts, usage.Artifacts, count)
+	}
+	if limits.MaxBytes > 0 && usage.Bytes+bytes > limits.MaxBytes {
+		return domain.NewQuotaExceededError("bytes", limits.MaxBytes, usage.Bytes, bytes)
+	}
+	return nil
+}
+
+func remainingQuota(limits *domain.QuotaLimits, usage *domain.QuotaUsage) domain.QuotaRemaining {
+	return domain.QuotaRemaining{
+		Artifacts:       remaining(limits.MaxArtifacts, usage.Artifacts),
+		Bytes:           remaining(limits.MaxBytes, usage.Bytes),
+		EmbeddingsToday: remaining(limits.MaxEmbeddingsPerDay, usage.EmbeddingsToday),
+	}
+}
+
+func remaining(limit, used int64) *int64 {
+	if limit == 0 {
+		return nil
+	}
+	left := limit - used
+	if left < 0 {
+		left = 0
+	}
+	return &left
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Reconciler periodically compares artifacts in Postgres with vectors in the
+// vector store. Indexed artifacts without a vector are re-embedded and vectors
+// without an artifact are deleted when repair is enabled; otherwise they are
+// only reported
+type Reconciler struct {
+	artifactRepo ports.ArtifactRepository
+	vectorRepo   ports.VectorRepository
+	cacheService *CacheService
+	interval     time.Duration
+	batchSize    int
+	repair       bool
+	leader       *LeaderElector
+
+	// runMu serializes passes; mu guards the last report
+	runMu sync.Mutex
+	mu    sync.RWMutex
+	last  *domain.ReconcileReport
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewReconciler(artifactRepo ports.ArtifactRepository, vectorRepo ports.VectorRepository, cacheService *CacheService, leader *LeaderElector, cfg config.ReconcileConfig) *Reconciler {
+	return &Reconciler{
+		artifactRepo: artifactRepo,
+		vectorRepo:   vectorRepo,
+		cacheService: cacheService,
+		interval:     cfg.Interval,
+		batchSize:    cfg.BatchSize,
+		repair:       cfg.Repair,
+		leader:       leader,
+	}
+}
+
+func (r *Reconciler) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	status.Track(reconcilerComponent)
+	r.wg.Add(1)
+	go r.loop(ctx)
+
+	logrus.WithFields(logrus.Fields{"interval": r.interval, "repair": r.repair}).Info("Reconciler started")
+}
+
+func (r *Reconciler) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	logrus.Info("Reconciler stopped")
+}
+
+// LastReport returns the report of the most recent pass, or nil before the first
+func (r *Reconciler) LastReport() *domain.ReconcileReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.last
+}
+
+func (r *Reconciler) loop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Passes requested through the API run on the replica that got the request
+			if r.leader.Leads(ctx, reconcilerComponent) {
+				r.Run(ctx)
+			}
+		}
+	}
+}
+
+// Run performs one reconciliation pass and records its report. Concurrent
+// calls wait for the running pass to finish
+func (r *Reconciler) Run(ctx context.Context) *domain.ReconcileReport {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	report := &domain.ReconcileReport{
+		StartedAt:       time.Now(),
+		Repair:          r.repair,
+		MissingSamples:  []uuid.UUID{},
+		OrphanedSamples: []uuid.UUID{},
+	}
+
+	err := r.checkArtifacts(ctx, report)
+	if err == nil {
+		err = r.checkVectors(ctx, report)
+	}
+	status.RecordRun(ctx, reconcilerComponent, err)
+	if err != nil {
+		report.Error = err.Error()
+		if ctx.Err() == nil {
+			logrus.WithError(err).Error("Reconciliation failed")
+		}
+	}
+	report.FinishedAt = time.Now()
+
+	if report.MissingVectors > 0 || report.OrphanedVectors > 0 {
+		logrus.WithFields(logrus.Fields{
+			"missing_vectors":  report.MissingVectors,
+			"orphaned_vectors": report.OrphanedVectors,
+			"reindexed":        report.Reindexed,
+			"removed_vectors":  report.RemovedVectors,
+		}).Warn("Reconciliation found drift between artifacts and vectors")
+	}
+
+	r.mu.Lock()
+	r.last = report
+	r.mu.Unlock()
+
+	return report
+}
+
+// checkArtifacts finds indexed artifacts whose vector is missing
+func (r *Reconciler) checkArtifacts(ctx context.Context, report *domain.ReconcileReport) error {
+	after := uuid.Nil
+	for {
+		refs, err := r.artifactRepo.ListIndexed(ctx, after, r.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list indexed artifacts: %w", err)
+		}
+		if len(refs) == 0 {
+			return nil
+		}
+		after = refs[len(refs)-1].ID
+		report.ArtifactsChecked += len(refs)
+
+		ids := make([]uuid.UUID, len(refs))
+		for i, ref := range refs {
+			ids[i] = ref.ID
+		}
+		existing, err := r.vectorRepo.Existing(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to check vectors: %w", err)
+		}
+		found := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			found[id] = true
+		}
+
+		// Re-embedded vectors are sent in the background and confirmed once per page
+		buffer := r.vectorRepo.NewBuffer()
+		reindexed := 0
+		for _, ref := range refs {
+			if found[ref.ID] {
+				continue
+			}
+			report.MissingVectors++
+			if len(report.MissingSamples) < domain.MaxReconcileSamples {
+				report.MissingSamples = append(report.MissingSamples, ref.ID)
+			}
+			if !r.repair {
+				continue
+			}
+
+			nsCtx := domain.WithPrincipal(ctx, &domain.Principal{Namespace: ref.Namespace})
+			point, err := r.cacheService.reindexPoint(nsCtx, ref.ID)
+			if err == nil && point != nil {
+				err = buffer.Add(ctx, *point)
+			}
+			if err != nil {
+				logrus.WithError(err).WithField("artifact_id", ref.ID).Warn("Failed to reindex artifact")
+				continue
+			}
+			if point != nil {
+				reindexed++
+			}
+		}
+		if reindexed > 0 {
+			if err := buffer.Flush(ctx); err != nil {
+				logrus.WithError(err).WithField("count", reindexed).Warn("Failed to store reindexed vectors")
+			} else {
+				report.Reindexed += reindexed
+			}
+		}
+
+		if len(refs) < r.batchSize {
+			return nil
+		}
+	}
+}
+
+// checkVectors finds artifact vectors whose artifact no longer exists
+func (r *Reconciler) checkVectors(ctx context.Context, report *domain.ReconcileReport) error {
+	offset := uuid.Nil
+	for {
+		records, next, err := r.vectorRepo.Scroll(ctx, offset, r.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to scroll vectors: %w", err)
+		}
+		report.VectorsChecked += len(records)
+
+		// Session goal and step input vectors share the store but are not artifacts
+		ids := make([]uuid.UUID, 0, len(records))
+		for _, record := range records {
+			if kind, _ := record.Payload["kind"].(string); kind == sessionGoalKind || kind == stepInputKind {
+				continue
+			}
+			ids = append(ids, record.ID)
+		}
+
+		existing, err := r.artifactRepo.ExistingIDs(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to check artifacts: %w", err)
+		}
+		found := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			found[id] = true
+		}
+
+		for _, id := range ids {
+			if found[id] {
+				continue
+			}
+			report.OrphanedVectors++
+			if len(report.OrphanedSamples) < domain.MaxReconcileSamples {
+				report.OrphanedSamples = append(report.OrphanedSamples, id)
+			}
+			if !r.repair {
+				continue
+			}
+
+			if err := r.vectorRepo.Delete(ctx, id); err != nil {
+				logrus.WithError(err).WithField("vector_id", id).Warn("Failed to delete orphaned vector")
+				continue
+			}
+			report.RemovedVectors++
+		}
+
+		if next == uuid.Nil {
+			return nil
+		}
+		offset = next
+	}
+}
+
+// reindexPoint re-embeds an artifact's content into the vector to store again;
+// it returns nil when the artifact no longer exists
+func (s *CacheService) reindexPoint(ctx context.Context, id uuid.UUID) (*domain.VectorPoint, error) {
+	artifact, err := s.artifactRepo.GetMetadataByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if artifact == nil {
+		return nil, nil
+	}
+
+	content, err := readArtifactContent(ctx, s, artifact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	// LLM cache entries are found by the prompt they answer
+	text := string(content)
+	if prompt, ok := artifact.Metadata[domain.LLMPromptKey].(string); ok {
+		text = prompt
+	}
+	embedding, err := s.queryEmbedding(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.VectorPoint{ID: artifact.ID, Embedding: embedding, Payload: vectorPayload(artifact)}, nil
+}
@@ -0,0 +1,446 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/egress"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRefreshResponseBytes bounds the fresh content a webhook refresh hook may return
+const maxRefreshResponseBytes = 64 << 20
+
+// RefreshService manages refresh hooks and runs them to replace stale artifacts
+// with freshly fetched or derived content
+type RefreshService struct {
+	hookRepo        ports.RefreshHookRepository
+	artifactRepo    ports.ArtifactRepository
+	cacheService    ports.CacheService
+	workflowService ports.WorkflowService
+	guard           *egress.Guard
+	client          *http.Client
+	secret          []byte
+}
+
+func NewRefreshService(
+	hookRepo ports.RefreshHookRepository,
+	artifactRepo ports.ArtifactRepository,
+	cacheService ports.CacheService,
+	workflowService ports.WorkflowService,
+	guard *egress.Guard,
+	cfg config.RefreshConfig,
+	webhookCfg config.WebhookConfig,
+) *RefreshService {
+	return &RefreshService{
+		hookRepo:        hookRepo,
+		artifactRepo:    artifactRepo,
+		cacheService:    cacheService,
+		workflowService: workflowService,
+		guard:           guard,
+		client:          guard.Client(cfg.Timeout, false),
+		secret:          []byte(webhookCfg.Secret),
+	}
+}
+
+func (s *RefreshService) CreateHook(ctx context.Context, req *domain.RefreshHookRequest) (*domain.RefreshHook, error) {
+	now := time.Now()
+	hook := &domain.RefreshHook{
+		ID:        uuid.New(),
+		Namespace: domain.NamespaceFromContext(ctx),
+		CreatedAt: now,
+	}
+
+	if err := applyRefreshHook(hook, req, now, s.guard); err != nil {
+		return nil, err
+	}
+
+	if err := s.hookRepo.Store(ctx, hook); err != nil {
+		return nil, fmt.Errorf("failed to store refresh hook: %w", err)
+	}
+
+	return hook, nil
+}
+
+func (s *RefreshService) GetHook(ctx context.Context, id uuid.UUID) (*domain.RefreshHook, error) {
+	hook, err := s.hookRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh hook: %w", err)
+	}
+	if hook == nil {
+		return nil, domain.NewNotFoundError("refresh hook", id)
+	}
+	return hook, nil
+}
+
+func (s *RefreshService) ListHooks(ctx context.Context) ([]*domain.RefreshHook, error) {
+	hooks, err := s.hookRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refresh hooks: %w", err)
+	}
+	return hooks, nil
+}
+
+func (s *RefreshService) UpdateHook(ctx context.Context, id uuid.UUID, req *domain.RefreshHookRequest) (*domain.RefreshHook, error) {
+	hook, err := s.GetHook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyRefreshHook(hook, req, time.Now(), s.guard); err != nil {
+		return nil, err
+	}
+
+	if err := s.hookRepo.Update(ctx, hook); err != nil {
+		return nil, fmt.Errorf("failed to update refresh hook: %w", err)
+	}
+
+	return hook, nil
+}
+
+func (s *RefreshService) DeleteHook(ctx context.Context, id uuid.UUID) error {
+	return s.hookRepo.Delete(ctx, id)
+}
+
+// Refresh runs the most specific hook matching an artifact, whether or not it is stale
+func (s *RefreshService) Refresh(ctx context.Context, artifactID uuid.UUID) (*domain.RefreshResult, error) {
+	artifact, err := s.artifactRepo.GetByID(ctx, artifactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artifact: %w", err)
+	}
+	if artifact == nil {
+		return nil, domain.NewNotFoundError("artifact", artifactID)
+	}
+	if _, isChunk := artifact.Metadata[domain.ChunkOfKey]; isChunk {
+		return nil, domain.NewValidationError("chunks are refreshed with their parent artifact")
+	}
+
+	hooks, err := s.ListHooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hook := domain.SelectRefreshHook(hooks, artifact)
+	if hook == nil {
+		return nil, domain.NewValidationError("no enabled refresh hook matches the artifact")
+	}
+
+	return s.refresh(ctx, hook, artifact)
+}
+
+// refresh obtains fresh content from hook and swaps it into artifact
+func (s *RefreshService) refresh(ctx context.Context, hook *domain.RefreshHook, artifact *domain.Artifact) (*domain.RefreshResult, error) {
+	var fresh *domain.RefreshContent
+	var err error
+	switch hook.Kind {
+	case domain.RefreshWebhook:
+		fresh, err = s.callWebhook(ctx, hook, artifact)
+	case domain.RefreshStep:
+		fresh, err = s.runStep(ctx, hook, artifact)
+	default:
+		err = fmt.Errorf("unknown refresh hook kind %q", hook.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed, changed, err := s.cacheService.SwapContent(ctx, artifact, fresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.RefreshResult{
+		Artifact: refreshed,
+		HookID:   hook.ID,
+		Changed:  changed,
+	}, nil
+}
+
+// callWebhook POSTs the artifact, signed like workflow webhooks, and reads the
+// fresh content from the response; 204 No Content means the content is unchanged
+func (s *RefreshService) callWebhook(ctx context.Context, hook *domain.RefreshHook, artifact *domain.Artifact) (*domain.RefreshContent, error) {
+	callback := &domain.RefreshCallback{
+		ID:       uuid.New(),
+		HookID:   hook.ID,
+		Artifact: artifact,
+	}
+	body, err := json.Marshal(callback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal refresh callback: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Mentis-Event", "artifact.refresh")
+	req.Header.Set("X-Mentis-Delivery", callback.ID.String())
+	req.Header.Set("X-Mentis-Timestamp", timestamp)
+	req.Header.Set("X-Mentis-Signature", "sha256="+signPayload(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, domain.NewUpstreamError("refresh hook", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, domain.NewUpstreamError("refresh hook", fmt.Errorf("receiver returned status %d", resp.StatusCode))
+	}
+
+	var fresh domain.RefreshContent
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRefreshResponseBytes)).Decode(&fresh); err != nil {
+		return nil, domain.NewUpstreamError("refresh hook", fmt.Errorf("invalid response: %w", err))
+	}
+	return &fresh, nil
+}
+
+// runStep re-derives the artifact by forcing a fresh execution of the hook's
+// step type in a session of its own
+func (s *RefreshService) runStep(ctx context.Context, hook *domain.RefreshHook, artifact *domain.Artifact) (*domain.RefreshContent, error) {
+	session, err := s.workflowService.CreateSession(ctx, &domain.CreateSessionRequest{
+		Goal: fmt.Sprintf("Refresh artifact %s", artifact.ID),
+		Context: map[string]interface{}{
+			"refresh_hook_id": hook.ID.String(),
+			"artifact_id":     artifact.ID.String(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh session: %w", err)
+	}
+
+	response, err := s.workflowService.ExecuteStep(ctx, &domain.WorkflowStepRequest{
+		SessionID: session.ID,
+		StepType:  hook.StepType,
+		Input: map[string]interface{}{
+			"artifact_id": artifact.ID.String(),
+			"type":        artifact.Type,
+			"metadata":    artifact.Metadata,
+		},
+		Force: true,
+	})
+	if err == nil && (response == nil || response.Artifact == nil) {
+		err = fmt.Errorf("refresh step produced no artifact")
+	}
+	if err != nil {
+		s.workflowService.FailSession(ctx, session.ID, err.Error())
+		return nil, err
+	}
+
+	content, err := readArtifactContent(ctx, s.cacheService, response.Artifact)
+	if err != nil {
+		s.workflowService.FailSession(ctx, session.ID, err.Error())
+		return nil, err
+	}
+	if err := s.workflowService.CompleteSession(ctx, session.ID); err != nil {
+		logrus.WithError(err).WithField("session_id", session.ID).Warn("Failed to complete refresh session")
+	}
+
+	fresh := &domain.RefreshContent{Content: content}
+	if response.Step != nil {
+		fresh.Metadata = map[string]interface{}{"refreshed_by_step": response.Step.ID.String()}
+	}
+	return fresh, nil
+}
+
+// readArtifactContent reads an artifact's content from wherever it is stored
+func readArtifactContent(ctx context.Context, cacheService ports.CacheService, artifact *domain.Artifact) ([]byte, error) {
+	reader, err := cacheService.OpenContent(ctx, artifact)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// applyRefreshHook validates a hook request and copies it onto hook
+func applyRefreshHook(hook *domain.RefreshHook, req *domain.RefreshHookRequest, now time.Time, guard *egress.Guard) error {
+	switch req.Kind {
+	case domain.RefreshWebhook:
+		if req.URL == "" {
+			return domain.NewValidationError("url is required for webhook refresh hooks")
+		}
+		if err := validateCallbackURLs([]string{req.URL}, guard); err != nil {
+			return err
+		}
+	case domain.RefreshStep:
+		if req.StepType == "" {
+			return domain.NewValidationError("step_type is required for step refresh hooks")
+		}
+	default:
+		return domain.NewValidationError("kind must be webhook or step")
+	}
+
+	hook.Name = req.Name
+	hook.SourcePrefix = req.SourcePrefix
+	hook.ArtifactType = req.ArtifactType
+	hook.Kind = req.Kind
+	hook.URL = req.URL
+	hook.StepType = req.StepType
+	hook.Enabled = req.Enabled == nil || *req.Enabled
+	hook.UpdatedAt = now
+
+	return nil
+}
+
+// SwapContent replaces a stale artifact's content with fresh content under the
+// same identity, archiving the stale content as a version and renewing its
+// expiry, vector, and chunks. Nil fresh content, or content identical to what
+// the artifact holds, only marks the artifact fresh again.
+func (s *CacheService) SwapContent(ctx context.Context, current *domain.Artifact, fresh *domain.RefreshContent) (*domain.Artifact, bool, error) {
+	artifact := *current
+	artifact.UpdatedAt = time.Now()
+	artifact.ExpiresAt = nil
+	artifact.Stale = false
+	artifact.Embedding = nil
+	if err := s.artifactTTLs.Apply(&artifact, artifact.UpdatedAt); err != nil {
+		return nil, false, err
+	}
+
+	changed := false
+	if fresh != nil {
+		artifact.Content = fresh.Content
+		artifact.ContentRef = ""
+		artifact.ContentSize = 0
+		artifact.ContentHash = ""
+		artifact.Metadata = make(map[string]interface{}, len(current.Metadata)+len(fresh.Metadata))
+		for key, value := range current.Metadata {
+			artifact.Metadata[key] = value
+		}
+		for key, value := range fresh.Metadata {
+			artifact.Metadata[key] = value
+		}
+
+		if err := s.applyPIIPolicy(0, &artifact); err != nil {
+			return nil, false, err
+		}
+		artifact.ContentHash = s.hashService.ComputeContentHash(artifact.Content)
+		changed = artifact.ContentHash != current.ContentHash
+	}
+	if !changed {
+		artifact.Content = current.Content
+		artifact.ContentRef = current.ContentRef
+		artifact.ContentSize = current.ContentSize
+		artifact.ContentHash = current.ContentHash
+		artifact.Metadata = current.Metadata
+	}
+
+	// Unchanged offloaded content is re-read so its vector and chunks can be rebuilt
+	content := artifact.Content
+	if artifact.ContentRef != "" {
+		var err error
+		if content, err = readArtifactContent(ctx, s, &artifact); err != nil {
+			return nil, false, err
+		}
+	}
+
+	chunked, err := s.chunkIDs(ctx, artifact.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list chunks: %w", err)
+	}
+	var spans []chunkSpan
+	if len(chunked) > 0 {
+		spans = splitChunks(content, s.chunking)
+	}
+
+	// Checked before any embeddings are spent, and again under the quota lock below
+	bytes := int64(len(content)) + chunkBytes(spans)
+	if changed {
+		if err := s.quotaService.CheckArtifacts(ctx, int64(len(spans)), bytes); err != nil {
+			return nil, false, err
+		}
+	}
+
+	chunkEmbeddings, err := s.embedChunks(ctx, content, spans)
+	if err != nil {
+		return nil, false, err
+	}
+	// Chunked artifacts are found through their chunks, the rest through their own vector
+	if len(spans) == 0 && s.embeddingService != nil {
+		if artifact.Embedding, err = s.queryEmbedding(ctx, string(content)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	artifact.Indexed = len(artifact.Embedding) > 0 || (!changed && current.Indexed)
+	if changed {
+		if err := s.offloadContent(ctx, &artifact); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var points []domain.VectorPoint
+	err = s.transactor.InTx(ctx, func(ctx context.Context) error {
+		if changed {
+			if err := s.quotaService.CheckArtifacts(ctx, int64(len(spans)), bytes); err != nil {
+				return err
+			}
+			if err := s.artifactRepo.Republish(ctx, &artifact); err != nil {
+				return fmt.Errorf("failed to republish artifact: %w", err)
+			}
+		} else if err := s.artifactRepo.MarkFresh(ctx, artifact.ID, artifact.ExpiresAt, artifact.Indexed); err != nil {
+			return fmt.Errorf("failed to mark artifact fresh: %w", err)
+		}
+
+		if len(spans) == 0 {
+			return nil
+		}
+		var err error
+		points, err = s.storeChunks(ctx, &artifact, content, spans, chunkEmbeddings)
+		return err
+	})
+	if err != nil {
+		if changed {
+			s.discardBlob(ctx, &artifact)
+		}
+		return nil, false, err
+	}
+
+	switch {
+	case len(artifact.Embedding) > 0:
+		// A republished vector that fails here stays queued for the outbox relay
+		if err := s.vectorRepo.Store(ctx, artifact.ID, artifact.Embedding, vectorPayload(&artifact)); err != nil && changed {
+			logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to store vector of refreshed artifact; it stays queued for retry")
+		} else if err != nil {
+			return nil, false, domain.NewUpstreamError("vector store", err)
+		}
+	case changed:
+		// The old vector describes the archived content
+		if err := s.vectorRepo.Delete(ctx, artifact.ID); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to remove vector of refreshed artifact")
+		}
+	default:
+		if err := s.vectorRepo.SetPayload(ctx, []uuid.UUID{artifact.ID}, map[string]interface{}{"stale": false}); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to mark vector fresh")
+		}
+	}
+
+	if len(spans) > 0 {
+		s.retireChunks(ctx, chunked)
+		if _, err := s.storeVectors(ctx, points); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to store chunk vectors; they stay queued for retry")
+		}
+	}
+
+	s.negativeCache.ForgetAll(ctx, domain.NegativeLookupMiss)
+	s.lookupCache.Invalidate(artifact.Namespace)
+
+	artifact.Embedding = nil
+	return &artifact, changed, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// ArtifactRefresher periodically claims stale artifacts that a refresh hook
+// matches and runs the hook to swap in fresh content. Failed refreshes are
+// retried after the retry interval; instances never claim the same artifact
+type ArtifactRefresher struct {
+	artifactRepo   ports.ArtifactRepository
+	hookRepo       ports.RefreshHookRepository
+	refreshService *RefreshService
+	interval       time.Duration
+	batchSize      int
+	retryInterval  time.Duration
+	leader         *LeaderElector
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewArtifactRefresher(artifactRepo ports.ArtifactRepository, hookRepo ports.RefreshHookRepository, refreshService *RefreshService, leader *LeaderElector, cfg config.RefreshConfig) *ArtifactRefresher {
+	return &ArtifactRefresher{
+		artifactRepo:   artifactRepo,
+		hookRepo:       hookRepo,
+		refreshService: refreshService,
+		interval:       cfg.Interval,
+		batchSize:      cfg.BatchSize,
+		retryInterval:  cfg.RetryInterval,
+		leader:         leader,
+	}
+}
+
+func (r *ArtifactRefresher) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	status.Track(refresherComponent)
+	r.wg.Add(1)
+	go r.loop(ctx)
+
+	logrus.WithField("interval", r.interval).Info("Artifact refresher started")
+}
+
+func (r *ArtifactRefresher) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	logrus.Info("Artifact refresher stopped")
+}
+
+func (r *ArtifactRefresher) loop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if r.leader.Leads(ctx, refresherComponent) {
+			status.RecordRun(ctx, refresherComponent, r.tick(ctx))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick refreshes a batch of stale artifacts; a failing refresh hook is the hook
+// owner's problem, so only failing to claim the batch fails the pass
+func (r *ArtifactRefresher) tick(ctx context.Context) error {
+	artifacts, err := r.artifactRepo.ClaimStale(ctx, time.Now().Add(-r.retryInterval), r.batchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to claim stale artifacts")
+		}
+		return err
+	}
+
+	// Hooks are read once per namespace per tick
+	hooks := make(map[string][]*domain.RefreshHook)
+	refreshed := 0
+	for _, artifact := range artifacts {
+		log := logrus.WithFields(logrus.Fields{
+			"artifact_id": artifact.ID,
+			"namespace":   artifact.Namespace,
+		})
+		nsCtx := domain.WithPrincipal(ctx, &domain.Principal{Namespace: artifact.Namespace})
+
+		namespaceHooks, ok := hooks[artifact.Namespace]
+		if !ok {
+			namespaceHooks, err = r.hookRepo.List(nsCtx)
+			if err != nil {
+				log.WithError(err).Error("Failed to list refresh hooks")
+				continue
+			}
+			hooks[artifact.Namespace] = namespaceHooks
+		}
+
+		// The hook may have been disabled since the artifact was claimed
+		hook := domain.SelectRefreshHook(namespaceHooks, artifact)
+		if hook == nil {
+			continue
+		}
+
+		result, err := r.refreshService.refresh(nsCtx, hook, artifact)
+		if err != nil {
+			log.WithError(err).WithField("hook_id", hook.ID).Warn("Failed to refresh stale artifact")
+			continue
+		}
+		refreshed++
+		log.WithFields(logrus.Fields{"hook_id": hook.ID, "changed": result.Changed}).Debug("Refreshed stale artifact")
+	}
+
+	if refreshed > 0 {
+		logrus.WithField("count", refreshed).Info("Refreshed stale artifacts")
+	}
+	return nil
+}
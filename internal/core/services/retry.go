@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// RetryPolicies resolves the retry policy for a step type
+type RetryPolicies struct {
+	defaultPolicy domain.RetryPolicy
+	stepTypes     map[string]domain.RetryPolicy
+}
+
+// NewRetryPolicies rejects policies whose backoffs aren't valid durations, so
+// a typo fails startup instead of silently retrying with the default backoff
+func NewRetryPolicies(cfg config.RetryConfig) (*RetryPolicies, error) {
+	defaultPolicy, err := toRetryPolicy(cfg.Default, domain.RetryPolicy{MaxAttempts: 1})
+	if err != nil {
+		return nil, fmt.Errorf("default retry policy: %w", err)
+	}
+
+	stepTypes := make(map[string]domain.RetryPolicy, len(cfg.StepTypes))
+	for stepType, policyCfg := range cfg.StepTypes {
+		if stepTypes[stepType], err = toRetryPolicy(policyCfg, defaultPolicy); err != nil {
+			return nil, fmt.Errorf("retry policy for %s: %w", stepType, err)
+		}
+	}
+
+	return &RetryPolicies{
+		defaultPolicy: defaultPolicy,
+		stepTypes:     stepTypes,
+	}, nil
+}
+
+func (p *RetryPolicies) For(stepType string) domain.RetryPolicy {
+	if policy, ok := p.stepTypes[stepType]; ok {
+		return policy
+	}
+	return p.defaultPolicy
+}
+
+// toRetryPolicy converts a configured policy, inheriting unset fields from base
+func toRetryPolicy(cfg config.RetryPolicyConfig, base domain.RetryPolicy) (domain.RetryPolicy, error) {
+	policy := base
+
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoff != "" {
+		d, err := parseBackoff(cfg.InitialBackoff)
+		if err != nil {
+			return policy, fmt.Errorf("invalid initial_backoff: %w", err)
+		}
+		policy.InitialBackoff = d
+	}
+	if cfg.MaxBackoff != "" {
+		d, err := parseBackoff(cfg.MaxBackoff)
+		if err != nil {
+			return policy, fmt.Errorf("invalid max_backoff: %w", err)
+		}
+		policy.MaxBackoff = d
+	}
+	if cfg.Multiplier > 0 {
+		policy.Multiplier = cfg.Multiplier
+	}
+	if len(cfg.RetryableErrors) > 0 {
+		policy.RetryableCodes = make([]domain.ErrorCode, len(cfg.RetryableErrors))
+		for i, code := range cfg.RetryableErrors {
+			policy.RetryableCodes[i] = domain.ErrorCode(code)
+		}
+	}
+
+	return policy, nil
+}
+
+func parseBackoff(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s is negative", value)
+	}
+	return d, nil
+}
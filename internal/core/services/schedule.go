@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+type ScheduleService struct {
+	scheduleRepo    ports.ScheduleRepository
+	workflowService ports.WorkflowService
+}
+
+func NewScheduleService(scheduleRepo ports.ScheduleRepository, workflowService ports.WorkflowService) *ScheduleService {
+	return &ScheduleService{
+		scheduleRepo:    scheduleRepo,
+		workflowService: workflowService,
+	}
+}
+
+func (s *ScheduleService) Create(ctx context.Context, req *domain.ScheduleRequest) (*domain.WorkflowSchedule, error) {
+	now := time.Now()
+	schedule := &domain.WorkflowSchedule{
+		ID:        uuid.New(),
+		Namespace: domain.NamespaceFromContext(ctx),
+		CreatedAt: now,
+	}
+
+	if err := s.apply(ctx, schedule, req, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.Store(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to store schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *ScheduleService) Get(ctx context.Context, id uuid.UUID) (*domain.WorkflowSchedule, error) {
+	schedule, err := s.scheduleRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	if schedule == nil {
+		return nil, domain.NewNotFoundError("schedule", id)
+	}
+	return schedule, nil
+}
+
+func (s *ScheduleService) List(ctx context.Context) ([]*domain.WorkflowSchedule, error) {
+	schedules, err := s.scheduleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func (s *ScheduleService) Update(ctx context.Context, id uuid.UUID, req *domain.ScheduleRequest) (*domain.WorkflowSchedule, error) {
+	schedule, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, schedule, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *ScheduleService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.scheduleRepo.Delete(ctx, id)
+}
+
+func (s *ScheduleService) ListRuns(ctx context.Context, id uuid.UUID, limit int) ([]*domain.ScheduleRun, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.scheduleRepo.ListRuns(ctx, id, domain.NormalizePageSize(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedule runs: %w", err)
+	}
+	return runs, nil
+}
+
+// Run creates a session for the schedule, executes its steps as a batch, and
+// completes or fails the session depending on the step results
+func (s *ScheduleService) Run(ctx context.Context, schedule *domain.WorkflowSchedule) (*domain.ScheduleRun, error) {
+	ctx = domain.WithPrincipal(ctx, &domain.Principal{Namespace: schedule.Namespace})
+
+	run := &domain.ScheduleRun{
+		ID:         uuid.New(),
+		ScheduleID: schedule.ID,
+		Namespace:  schedule.Namespace,
+		Status:     domain.ScheduleRunRunning,
+		StartedAt:  time.Now(),
+	}
+	if err := s.scheduleRepo.StoreRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to store schedule run: %w", err)
+	}
+
+	runErr := s.execute(ctx, schedule, run)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Status = domain.ScheduleRunSucceeded
+	if runErr != nil {
+		run.Status = domain.ScheduleRunFailed
+		run.Error = runErr.Error()
+	}
+
+	if err := s.scheduleRepo.UpdateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to update schedule run: %w", err)
+	}
+
+	return run, nil
+}
+
+func (s *ScheduleService) execute(ctx context.Context, schedule *domain.WorkflowSchedule, run *domain.ScheduleRun) error {
+	sessionContext := make(map[string]interface{}, len(schedule.Context)+2)
+	for k, v := range schedule.Context {
+		sessionContext[k] = v
+	}
+	sessionContext["schedule_id"] = schedule.ID.String()
+	sessionContext["schedule_run_id"] = run.ID.String()
+
+	session, err := s.workflowService.CreateSession(ctx, &domain.CreateSessionRequest{
+		Goal:            schedule.Goal,
+		Context:         sessionContext,
+		Template:        schedule.Template,
+		TemplateVersion: schedule.TemplateVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	run.SessionID = &session.ID
+	if err := s.scheduleRepo.UpdateRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to update schedule run: %w", err)
+	}
+
+	response, err := s.workflowService.ExecuteSteps(ctx, &domain.BatchStepRequest{
+		SessionID: session.ID,
+		Steps:     schedule.Steps,
+	})
+	if err != nil {
+		s.workflowService.FailSession(ctx, session.ID, err.Error())
+		return fmt.Errorf("failed to execute steps: %w", err)
+	}
+
+	var failures []string
+	for i, result := range response.Results {
+		if result.Error != nil {
+			name := result.Key
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			failures = append(failures, fmt.Sprintf("%s: %s", name, result.Error.Message))
+		}
+	}
+
+	if len(failures) > 0 {
+		reason := "steps failed: " + strings.Join(failures, "; ")
+		s.workflowService.FailSession(ctx, session.ID, reason)
+		return fmt.Errorf("%s", reason)
+	}
+
+	return s.workflowService.CompleteSession(ctx, session.ID)
+}
+
+// apply validates a schedule request and copies it onto schedule, recomputing the next run
+func (s *ScheduleService) apply(ctx context.Context, schedule *domain.WorkflowSchedule, req *domain.ScheduleRequest, now time.Time) error {
+	cron, err := parseCron(req.Cron)
+	if err != nil {
+		return domain.NewValidationError(fmt.Sprintf("invalid cron expression: %v", err))
+	}
+	next := cron.Next(now)
+	if next.IsZero() {
+		return domain.NewValidationError("cron expression never fires")
+	}
+	if len(req.Steps) == 0 {
+		return domain.NewValidationError("steps are required")
+	}
+	if req.Template != "" {
+		if _, err := s.workflowService.GetTemplate(ctx, req.Template, req.TemplateVersion); err != nil {
+			return err
+		}
+	}
+
+	schedule.Name = req.Name
+	schedule.Cron = req.Cron
+	schedule.Template = req.Template
+	schedule.TemplateVersion = req.TemplateVersion
+	schedule.Goal = req.Goal
+	schedule.Context = req.Context
+	schedule.Steps = req.Steps
+	schedule.Enabled = req.Enabled == nil || *req.Enabled
+	schedule.NextRunAt = next
+	schedule.UpdatedAt = now
+
+	return nil
+}
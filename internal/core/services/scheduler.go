@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// dueBatchSize bounds how many due schedules one scheduler tick starts
+const dueBatchSize = 20
+
+// Scheduler periodically starts runs of due workflow schedules. Several
+// instances may run at once; each due run is started by exactly one of them
+type Scheduler struct {
+	scheduleRepo    ports.ScheduleRepository
+	scheduleService ports.ScheduleService
+	leader          *LeaderElector
+	interval        time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(scheduleRepo ports.ScheduleRepository, scheduleService ports.ScheduleService, leader *LeaderElector, cfg config.SchedulerConfig) *Scheduler {
+	return &Scheduler{
+		scheduleRepo:    scheduleRepo,
+		scheduleService: scheduleService,
+		leader:          leader,
+		interval:        cfg.Interval,
+	}
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	status.Track(schedulerComponent)
+	s.wg.Add(1)
+	go s.loop(ctx)
+
+	logrus.WithField("interval", s.interval).Info("Workflow scheduler started")
+}
+
+// Stop stops scheduling new runs and waits for running ones to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	logrus.Info("Workflow scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if s.leader.Leads(ctx, schedulerComponent) {
+			status.RecordRun(ctx, schedulerComponent, s.tick(ctx))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick starts every due schedule; only failing to list them fails the pass
+func (s *Scheduler) tick(ctx context.Context) error {
+	now := time.Now()
+
+	schedules, err := s.scheduleRepo.ListDue(ctx, now, dueBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to list due schedules")
+		}
+		return err
+	}
+
+	for _, schedule := range schedules {
+		log := logrus.WithFields(logrus.Fields{
+			"schedule_id": schedule.ID,
+			"namespace":   schedule.Namespace,
+		})
+
+		cron, err := parseCron(schedule.Cron)
+		if err != nil {
+			log.WithError(err).Error("Skipping schedule with invalid cron expression")
+			continue
+		}
+
+		// Missed runs while the server was down collapse into this one
+		next := cron.Next(now)
+		claimed, err := s.scheduleRepo.Advance(ctx, schedule.ID, schedule.NextRunAt, next)
+		if err != nil {
+			log.WithError(err).Error("Failed to advance schedule")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if next.IsZero() {
+			log.Warn("Disabled schedule whose cron expression never fires again")
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			// Runs finish even if the scheduler is stopping
+			run, err := s.scheduleService.Run(context.WithoutCancel(ctx), schedule)
+			if err != nil {
+				log.WithError(err).Error("Scheduled run failed to start")
+				return
+			}
+			log.WithFields(logrus.Fields{"run_id": run.ID, "status": run.Status}).Info("Scheduled run finished")
+		}()
+	}
+	return nil
+}
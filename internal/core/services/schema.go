@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+)
+
+// StepSchemaService manages per-step-type input/output schemas and validates against them
+type StepSchemaService struct {
+	schemaRepo ports.StepSchemaRepository
+}
+
+func NewStepSchemaService(schemaRepo ports.StepSchemaRepository) *StepSchemaService {
+	return &StepSchemaService{
+		schemaRepo: schemaRepo,
+	}
+}
+
+func (s *StepSchemaService) Register(ctx context.Context, schema *domain.StepSchema) (*domain.StepSchema, error) {
+	if schema.StepType == "" {
+		return nil, domain.NewValidationError("step_type is required")
+	}
+	if schema.InputSchema != nil {
+		if err := checkSchema(schema.InputSchema, "input_schema"); err != nil {
+			return nil, domain.NewValidationError(err.Error())
+		}
+	}
+	if schema.OutputSchema != nil {
+		if err := checkSchema(schema.OutputSchema, "output_schema"); err != nil {
+			return nil, domain.NewValidationError(err.Error())
+		}
+	}
+
+	schema.Namespace = domain.NamespaceFromContext(ctx)
+	schema.UpdatedAt = time.Now()
+
+	if err := s.schemaRepo.Store(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to store step schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+func (s *StepSchemaService) Get(ctx context.Context, stepType string) (*domain.StepSchema, error) {
+	schema, err := s.schemaRepo.Get(ctx, stepType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step schema: %w", err)
+	}
+	if schema == nil {
+		return nil, domain.NewNotFoundError("step schema", stepType)
+	}
+	return schema, nil
+}
+
+func (s *StepSchemaService) List(ctx context.Context) ([]*domain.StepSchema, error) {
+	schemas, err := s.schemaRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list step schemas: %w", err)
+	}
+	return schemas, nil
+}
+
+func (s *StepSchemaService) Delete(ctx context.Context, stepType string) error {
+	return s.schemaRepo.Delete(ctx, stepType)
+}
+
+// ValidateInput checks a step request's input against the step type's input schema
+func (s *StepSchemaService) ValidateInput(ctx context.Context, stepType string, input interface{}) error {
+	schema, err := s.schemaRepo.Get(ctx, stepType)
+	if err != nil {
+		return fmt.Errorf("failed to get step schema: %w", err)
+	}
+	if schema == nil || schema.InputSchema == nil {
+		return nil
+	}
+
+	if violations := validateSchema(schema.InputSchema, input, "input"); len(violations) > 0 {
+		return domain.NewSchemaValidationError("input", violations)
+	}
+	return nil
+}
+
+// ValidateOutput checks artifact content, which must be JSON, against the step type's output schema
+func (s *StepSchemaService) ValidateOutput(ctx context.Context, stepType string, content []byte) error {
+	schema, err := s.schemaRepo.Get(ctx, stepType)
+	if err != nil {
+		return fmt.Errorf("failed to get step schema: %w", err)
+	}
+	if schema == nil || schema.OutputSchema == nil {
+		return nil
+	}
+
+	var output interface{}
+	if err := json.Unmarshal(content, &output); err != nil {
+		return domain.NewSchemaValidationError("output", []domain.SchemaViolation{
+			{Path: "output", Message: "output is not valid JSON"},
+		})
+	}
+
+	if violations := validateSchema(schema.OutputSchema, output, "output"); len(violations) > 0 {
+		return domain.NewSchemaValidationError("output", violations)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ListSessionEvents returns a session's timeline in the order events were recorded
+func (s *WorkflowService) ListSessionEvents(ctx context.Context, sessionID uuid.UUID, limit int, cursor string) (*domain.ListSessionEventsResponse, error) {
+	limit = domain.NormalizePageSize(limit)
+	after, err := domain.DecodeSeqCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.workflowRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return nil, domain.NewNotFoundError("session", sessionID)
+	}
+
+	// Fetch one extra row to know whether another page exists
+	events, err := s.eventRepo.List(ctx, sessionID, limit+1, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session events: %w", err)
+	}
+
+	response := &domain.ListSessionEventsResponse{Events: events}
+	if len(events) > limit {
+		response.Events = events[:limit]
+		response.NextCursor = domain.EncodeSeqCursor(response.Events[limit-1].Sequence)
+	}
+
+	return response, nil
+}
+
+// recordEvent appends to a session's timeline; the timeline is diagnostic, so
+// failures are logged rather than failing the operation that triggered them
+func (s *WorkflowService) recordEvent(ctx context.Context, sessionID uuid.UUID, eventType domain.SessionEventType, stepID *uuid.UUID, data map[string]interface{}) {
+	event := &domain.SessionEvent{
+		ID:        uuid.New(),
+		Namespace: domain.NamespaceFromContext(ctx),
+		SessionID: sessionID,
+		Type:      eventType,
+		StepID:    stepID,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.eventRepo.Append(context.WithoutCancel(ctx), event); err != nil {
+		logging.FromContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"type":       eventType,
+		}).Warn("Failed to record session event")
+	}
+}
+
+// recordStepEvent appends an event about one of the session's steps
+func (s *WorkflowService) recordStepEvent(ctx context.Context, eventType domain.SessionEventType, step *domain.WorkflowStep, data map[string]interface{}) {
+	stepID := step.ID
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["step_type"] = step.StepType
+	s.recordEvent(ctx, step.SessionID, eventType, &stepID, data)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// GetSessionProgress counts a session's steps by status and projects the work
+// left, measured against its template's steps when it has any
+func (s *WorkflowService) GetSessionProgress(ctx context.Context, id uuid.UUID) (*domain.SessionProgress, error) {
+	session, err := s.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &domain.SessionProgress{
+		SessionID:  session.ID,
+		Status:     session.Status,
+		StepCounts: make(map[domain.StepStatus]int),
+		TotalSteps: len(session.Steps),
+	}
+
+	end := time.Now()
+	if session.Status != domain.SessionActive {
+		end = session.UpdatedAt
+	}
+	progress.ElapsedSeconds = end.Sub(session.CreatedAt).Seconds()
+
+	var completedDuration time.Duration
+	var timedSteps int
+	completedTypes := make(map[string]bool)
+	for _, step := range session.Steps {
+		progress.StepCounts[step.Status]++
+		if step.Status != domain.StepCompleted {
+			continue
+		}
+		completedTypes[step.StepType] = true
+		// Inherited steps ran in the parent and say little about this session's pace
+		if step.SessionID == session.ID && step.CompletedAt != nil {
+			completedDuration += step.CompletedAt.Sub(step.CreatedAt)
+			timedSteps++
+		}
+	}
+
+	template, err := s.sessionTemplate(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := progress.StepCounts[domain.StepCompleted]
+	switch {
+	case template != nil && len(template.Steps) > 0:
+		progress.ExpectedSteps = len(template.Steps)
+		done := 0
+		for _, stepType := range template.Steps {
+			if completedTypes[stepType] {
+				done++
+			}
+		}
+		progress.PercentComplete = percent(done, progress.ExpectedSteps)
+		progress.RemainingSteps = progress.ExpectedSteps - done
+	default:
+		progress.PercentComplete = percent(completed, progress.TotalSteps)
+		progress.RemainingSteps = progress.StepCounts[domain.StepPending] +
+			progress.StepCounts[domain.StepRunning] +
+			progress.StepCounts[domain.StepRetrying] +
+			progress.StepCounts[domain.StepInterrupted]
+	}
+
+	switch session.Status {
+	case domain.SessionCompleted:
+		progress.PercentComplete = 100
+		progress.RemainingSteps = 0
+	case domain.SessionFailed:
+		progress.RemainingSteps = 0
+	}
+
+	if timedSteps > 0 {
+		estimate := (completedDuration / time.Duration(timedSteps) * time.Duration(progress.RemainingSteps)).Seconds()
+		progress.EstimatedRemainingSeconds = &estimate
+	}
+
+	return progress, nil
+}
+
+// sessionTemplate returns the template version a session is pinned to, if any
+func (s *WorkflowService) sessionTemplate(ctx context.Context, session *domain.WorkflowSession) (*domain.WorkflowTemplate, error) {
+	if session.TemplateName == "" {
+		return nil, nil
+	}
+	return s.GetTemplate(ctx, session.TemplateName, session.TemplateVersion)
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
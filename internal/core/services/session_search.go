@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sessionGoalKind tags session goal vectors, which share the vector store with artifacts
+const sessionGoalKind = "session_goal"
+
+// SearchSessions finds past sessions with goals similar to query, along with
+// the final answer each one produced
+func (s *WorkflowService) SearchSessions(ctx context.Context, query string, topK int, minScore float32) (*domain.SessionSearchResponse, error) {
+	ctx, span := tracer.Start(ctx, "WorkflowService.SearchSessions", trace.WithAttributes(attribute.Int("mentis.top_k", topK)))
+	defer span.End()
+
+	if query == "" {
+		return nil, domain.NewValidationError("query is required")
+	}
+	if topK <= 0 {
+		topK = 10
+	}
+
+	if err := s.quotaService.ConsumeEmbeddings(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	embedding, err := s.embeddingService.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, domain.NewUpstreamError("embedding provider", err)
+	}
+
+	matches, err := s.vectorRepo.Search(ctx, embedding, topK, minScore, domain.Filter{
+		domain.Eq("namespace", domain.NamespaceFromContext(ctx)),
+		domain.Eq("kind", sessionGoalKind),
+	})
+	if err != nil {
+		return nil, domain.NewUpstreamError("vector store", err)
+	}
+
+	response := &domain.SessionSearchResponse{Results: []domain.SessionSearchResult{}}
+	for _, match := range matches {
+		session, err := s.workflowRepo.GetSession(ctx, match.Artifact.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session: %w", err)
+		}
+		if session == nil {
+			// The session was deleted after its goal was indexed
+			continue
+		}
+
+		answer, err := s.finalAnswer(ctx, session)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Results = append(response.Results, domain.SessionSearchResult{
+			Session: session,
+			Answer:  answer,
+			Score:   match.Score,
+		})
+	}
+
+	return response, nil
+}
+
+// finalAnswer returns the ANSWER artifact of the session's most recent completed
+// step that produced one
+func (s *WorkflowService) finalAnswer(ctx context.Context, session *domain.WorkflowSession) (*domain.Artifact, error) {
+	steps, err := s.sessionSteps(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if step.Status != domain.StepCompleted || step.ArtifactID == uuid.Nil {
+			continue
+		}
+
+		artifact, err := s.artifactRepo.GetByID(ctx, step.ArtifactID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get artifact: %w", err)
+		}
+		if artifact != nil && artifact.Type == domain.ANSWER {
+			return artifact, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// indexSessionGoal embeds a session's goal for SearchSessions; indexing is best
+// effort so an embedding outage doesn't block session creation
+func (s *WorkflowService) indexSessionGoal(ctx context.Context, session *domain.WorkflowSession) {
+	logger := logging.FromContext(ctx).WithField("session_id", session.ID)
+
+	if err := s.quotaService.ConsumeEmbeddings(ctx, 1); err != nil {
+		logger.WithError(err).Warn("Skipping session goal indexing")
+		return
+	}
+
+	embedding, err := s.embeddingService.GenerateEmbedding(ctx, session.Goal)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to embed session goal")
+		return
+	}
+
+	if err := s.vectorRepo.Store(ctx, session.ID, embedding, map[string]interface{}{
+		"namespace": session.Namespace,
+		"kind":      sessionGoalKind,
+	}); err != nil {
+		logger.WithError(err).Warn("Failed to index session goal")
+	}
+}
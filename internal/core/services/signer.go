@@ -0,0 +1,62 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// URLSigner issues and verifies HMAC-signed, expiring URLs
+type URLSigner struct {
+	secret []byte
+}
+
+// NewURLSigner creates a signer keyed by secret, which must not be empty
+func NewURLSigner(secret string) (*URLSigner, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("signing secret is required")
+	}
+	return &URLSigner{secret: []byte(secret)}, nil
+}
+
+// Sign returns the query string granting access to path in namespace until expires
+func (s *URLSigner) Sign(path, namespace string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	values := url.Values{}
+	values.Set("ns", namespace)
+	values.Set("expires", exp)
+	values.Set("signature", s.signature(path, namespace, exp))
+	return values.Encode()
+}
+
+// Verify checks a signature for path and returns the namespace it was issued for
+func (s *URLSigner) Verify(path string, query url.Values) (string, error) {
+	namespace := query.Get("ns")
+	exp := query.Get("expires")
+	signature := query.Get("signature")
+
+	expiresUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid expiry")
+	}
+	if time.Now().Unix() > expiresUnix {
+		return "", fmt.Errorf("signed URL expired")
+	}
+
+	expected := s.signature(path, namespace, exp)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", fmt.Errorf("invalid signature")
+	}
+
+	return namespace, nil
+}
+
+func (s *URLSigner) signature(path, namespace, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("GET\n" + path + "\n" + namespace + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
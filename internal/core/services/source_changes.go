@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// signatureTolerance bounds the age of a signed source-change webhook, so a
+// captured request can't be replayed later
+const signatureTolerance = 5 * time.Minute
+
+// SourceChangeConsumer invalidates the artifacts fetched from sources reported
+// changed, keeping the cache fresh without manual invalidation calls. Events
+// arrive from a message broker, consumed in the background, or through the
+// signed webhook receiver
+type SourceChangeConsumer struct {
+	cacheService ports.CacheService
+	source       ports.EventSource
+	cfg          config.SourceEventsConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSourceChangeConsumer(cacheService ports.CacheService, source ports.EventSource, cfg config.SourceEventsConfig) *SourceChangeConsumer {
+	return &SourceChangeConsumer{
+		cacheService: cacheService,
+		source:       source,
+		cfg:          cfg,
+	}
+}
+
+// Start consumes the broker topic; replicas share the work through the
+// configured consumer group, so no leader election is needed
+func (c *SourceChangeConsumer) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	status.Track(sourceChangeComponent)
+	c.wg.Add(1)
+	go c.loop(ctx)
+
+	logrus.WithFields(logrus.Fields{
+		"broker": c.cfg.Broker,
+		"topic":  c.cfg.Topic,
+		"group":  c.cfg.Group,
+	}).Info("Source change consumer started")
+}
+
+func (c *SourceChangeConsumer) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	logrus.Info("Source change consumer stopped")
+}
+
+// loop consumes until shutdown, reconnecting after the broker fails
+func (c *SourceChangeConsumer) loop(ctx context.Context) {
+	defer c.wg.Done()
+
+	for {
+		err := c.source.Consume(ctx, c.cfg.Topic, func(payload []byte) {
+			c.handleMessage(ctx, payload)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		status.RecordRun(ctx, sourceChangeComponent, err)
+		logrus.WithError(err).WithField("retry_in", c.cfg.RetryInterval).Warn("Source change consumer disconnected")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.cfg.RetryInterval):
+		}
+	}
+}
+
+// handleMessage invalidates for one broker message; malformed messages are
+// skipped, as redelivering them would not help
+func (c *SourceChangeConsumer) handleMessage(ctx context.Context, payload []byte) {
+	var event domain.SourceChangeEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.SourceURL == "" {
+		logrus.WithError(err).Warn("Ignoring malformed source change event")
+		return
+	}
+
+	_, err := c.Handle(ctx, &event)
+	status.RecordRun(ctx, sourceChangeComponent, err)
+	if err != nil && ctx.Err() == nil {
+		logrus.WithError(err).WithField("source_url", event.SourceURL).Error("Failed to invalidate changed source")
+	}
+}
+
+// Handle marks stale the artifacts of the event's namespace fetched from its source URL
+func (c *SourceChangeConsumer) Handle(ctx context.Context, event *domain.SourceChangeEvent) (*domain.InvalidateResponse, error) {
+	namespace := event.Namespace
+	if namespace == "" {
+		namespace = domain.DefaultNamespace
+	}
+
+	response, err := c.cacheService.Invalidate(namespaceContext(ctx, namespace), &domain.InvalidateRequest{SourceURL: event.SourceURL})
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"namespace":   namespace,
+		"source_url":  event.SourceURL,
+		"invalidated": response.Invalidated,
+	}).Info("Invalidated artifacts of changed source")
+	return response, nil
+}
+
+// VerifySignature checks a webhook signature, sha256=<hex> of the HMAC-SHA256
+// of "<timestamp>.<body>" under the webhook secret, as sent with Mentis' own
+// webhooks, and rejects timestamps outside the tolerance
+func (c *SourceChangeConsumer) VerifySignature(timestamp, signature string, body []byte) error {
+	if c.cfg.WebhookSecret == "" {
+		return &domain.Error{Code: domain.CodeForbidden, Message: "source change webhooks are disabled"}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return &domain.Error{Code: domain.CodeUnauthorized, Message: "missing or invalid signature timestamp"}
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return &domain.Error{Code: domain.CodeUnauthorized, Message: "signature timestamp is too old or in the future"}
+	}
+
+	expected := signPayload([]byte(c.cfg.WebhookSecret), timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimPrefix(signature, "sha256="))) {
+		return &domain.Error{Code: domain.CodeUnauthorized, Message: "invalid signature"}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+)
+
+// Background jobs as named in the component status report
+const (
+	sweeperComponent           = "expiry_sweeper"
+	evictorComponent           = "evictor"
+	schedulerComponent         = "scheduler"
+	refresherComponent         = "refresher"
+	reconcilerComponent        = "reconciler"
+	usageComponent             = "usage_aggregator"
+	stepWorkersComponent       = "step_workers"
+	accessTrackerComponent     = "access_tracker"
+	outboxRelayComponent       = "outbox_relay"
+	sourceChangeComponent      = "source_change_consumer"
+	freshnessComponent         = "freshness_watcher"
+	dependencyMonitorComponent = "dependency_monitor"
+)
+
+// dependencies are always reported, in this order, ahead of the jobs
+var dependencies = []string{status.Postgres, status.Vector, status.Embedding}
+
+// StatusService combines live dependency checks with the recent call and job
+// outcomes collected by the status package
+type StatusService struct {
+	health    ports.HealthService
+	embedding ports.EmbeddingMonitor
+	window    time.Duration
+}
+
+func NewStatusService(health ports.HealthService, embedding ports.EmbeddingMonitor, cfg config.StatusConfig) *StatusService {
+	return &StatusService{
+		health:    health,
+		embedding: embedding,
+		window:    cfg.Window,
+	}
+}
+
+func (s *StatusService) Status(ctx context.Context, window time.Duration) (*domain.SystemStatus, error) {
+	if window == 0 {
+		window = s.window
+	}
+	if window < time.Minute || window > status.MaxWindow {
+		return nil, domain.NewValidationError(fmt.Sprintf("window must be between 1 and %d minutes", int(status.MaxWindow.Minutes())))
+	}
+
+	readiness := s.health.Ready(ctx)
+	checks := make(map[string]domain.DependencyHealth, len(readiness.Dependencies))
+	for _, dependency := range readiness.Dependencies {
+		checks[dependency.Name] = dependency
+	}
+
+	snapshot := status.Snapshot(window)
+	recorded := make(map[string]domain.ComponentStatus, len(snapshot))
+	for _, component := range snapshot {
+		recorded[component.Name] = component
+	}
+
+	report := &domain.SystemStatus{
+		Status:        domain.HealthUp,
+		WindowMinutes: int(window.Minutes()),
+		GeneratedAt:   time.Now().UTC(),
+		Components:    []domain.ComponentStatus{},
+	}
+
+	for _, name := range dependencies {
+		component := recorded[name]
+		component.Name = name
+		component.Kind = domain.ComponentDependency
+		component.State = domain.HealthUp
+
+		if check, ok := checks[name]; ok && check.Status != domain.HealthUp {
+			component.State = domain.HealthDown
+			component.LastError = check.Error
+			component.LastErrorAt = &readiness.Timestamp
+		}
+		if name == status.Embedding && component.State == domain.HealthUp {
+			component.State = s.embeddingState()
+		}
+		if component.State == domain.HealthUp && component.Errors > 0 {
+			component.State = domain.HealthDegraded
+		}
+		report.Components = append(report.Components, component)
+		if component.State == domain.HealthDown {
+			report.Status = domain.HealthDown
+		}
+	}
+
+	// Everything else recorded is a background job, sorted by name
+	for _, component := range snapshot {
+		if isDependency(component.Name) {
+			continue
+		}
+		component.Kind = domain.ComponentJob
+		component.State = domain.HealthUp
+		if component.LastErrorAt != nil && (component.LastSuccessAt == nil || component.LastErrorAt.After(*component.LastSuccessAt)) {
+			component.State = domain.HealthDown
+		} else if component.Errors > 0 {
+			component.State = domain.HealthDegraded
+		}
+		report.Components = append(report.Components, component)
+	}
+
+	// A failing job or a dependency with recent errors degrades the server
+	if report.Status == domain.HealthUp {
+		for _, component := range report.Components {
+			if component.State != domain.HealthUp {
+				report.Status = domain.HealthDegraded
+				break
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func isDependency(name string) bool {
+	for _, dependency := range dependencies {
+		if name == dependency {
+			return true
+		}
+	}
+	return false
+}
+
+// embeddingState is down when every provider's circuit is open and degraded
+// when any provider's is not closed
+func (s *StatusService) embeddingState() string {
+	providers := s.embedding.ProviderStats()
+	open := 0
+	state := domain.HealthUp
+	for _, provider := range providers {
+		switch provider.Circuit {
+		case domain.CircuitOpen:
+			open++
+			state = domain.HealthDegraded
+		case domain.CircuitHalfOpen:
+			state = domain.HealthDegraded
+		}
+	}
+	if len(providers) > 0 && open == len(providers) {
+		return domain.HealthDown
+	}
+	return state
+}
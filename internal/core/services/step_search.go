@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stepInputKind tags step input vectors, which share the vector store with artifacts
+const stepInputKind = "step_input"
+
+// LookupStep finds completed steps whose input is similar to the request's
+func (s *WorkflowService) LookupStep(ctx context.Context, req *domain.WorkflowLookupRequest) (*domain.WorkflowLookupResponse, error) {
+	ctx, span := tracer.Start(ctx, "WorkflowService.LookupStep", trace.WithAttributes(attribute.String("mentis.step_type", req.StepType)))
+	defer span.End()
+
+	// An empty list searches every step type
+	var stepTypes []string
+	if !req.AllStepTypes {
+		stepTypes = normalizeTags(append([]string{req.StepType}, req.StepTypes...))
+		if len(stepTypes) == 0 {
+			return nil, domain.NewValidationError("step_type, step_types, or all_step_types is required")
+		}
+	}
+	if req.TopK <= 0 {
+		req.TopK = 10
+	}
+	minScore := domain.DefaultMinScore
+	if req.MinScore != nil {
+		minScore = *req.MinScore
+	}
+
+	if err := s.quotaService.ConsumeEmbeddings(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	embedding, err := s.embeddingService.GenerateEmbedding(ctx, stepInputText(req.Input))
+	if err != nil {
+		return nil, domain.NewUpstreamError("embedding provider", err)
+	}
+
+	filter := domain.Filter{
+		domain.Eq("namespace", domain.NamespaceFromContext(ctx)),
+		domain.Eq("kind", stepInputKind),
+	}
+	if len(stepTypes) > 0 {
+		filter = append(filter, domain.In("step_type", stepTypes))
+	}
+	matches, err := s.vectorRepo.Search(ctx, embedding, req.TopK, minScore, filter)
+	if err != nil {
+		return nil, domain.NewUpstreamError("vector store", err)
+	}
+
+	results := []domain.WorkflowStepResult{}
+	for _, match := range matches {
+		step, err := s.workflowRepo.GetStep(ctx, match.Artifact.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get step: %w", err)
+		}
+		// Superseded results are no longer reused
+		if step == nil || step.Status != domain.StepCompleted || step.SupersededBy != nil {
+			continue
+		}
+
+		result := domain.WorkflowStepResult{
+			Step:     step,
+			StepType: step.StepType,
+			Score:    match.Score,
+		}
+		if step.ArtifactID != uuid.Nil {
+			artifact, err := s.artifactRepo.GetByID(ctx, step.ArtifactID)
+			if err == nil {
+				result.Artifact = artifact
+			}
+		}
+		results = append(results, result)
+	}
+
+	return &domain.WorkflowLookupResponse{
+		Results: results,
+	}, nil
+}
+
+// indexStepInput embeds a completed step's input for LookupStep; indexing is
+// best effort so an embedding outage doesn't fail the step
+func (s *WorkflowService) indexStepInput(ctx context.Context, step *domain.WorkflowStep, input interface{}) {
+	logger := logging.FromContext(ctx).WithField("step_id", step.ID)
+
+	if err := s.quotaService.ConsumeEmbeddings(ctx, 1); err != nil {
+		logger.WithError(err).Warn("Skipping step input indexing")
+		return
+	}
+
+	embedding, err := s.embeddingService.GenerateEmbedding(ctx, stepInputText(input))
+	if err != nil {
+		logger.WithError(err).Warn("Failed to embed step input")
+		return
+	}
+
+	if err := s.vectorRepo.Store(ctx, step.ID, embedding, map[string]interface{}{
+		"namespace":  step.Namespace,
+		"kind":       stepInputKind,
+		"step_type":  step.StepType,
+		"session_id": step.SessionID.String(),
+	}); err != nil {
+		logger.WithError(err).Warn("Failed to index step input")
+	}
+}
+
+// stepInputText renders a step input for embedding. Strings are embedded as
+// they are; other inputs as JSON, whose object keys are sorted
+func stepInputText(input interface{}) string {
+	if text, ok := input.(string); ok {
+		return text
+	}
+	encoded, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Sprintf("%v", input)
+	}
+	return string(encoded)
+}
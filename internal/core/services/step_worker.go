@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// StepWorkerPool executes queued steps on a bounded number of workers and
+// periodically requeues jobs whose workers died mid-execution and fails steps
+// that nothing will resume
+type StepWorkerPool struct {
+	jobRepo         ports.JobRepository
+	workflowService ports.WorkflowService
+	concurrency     int
+	pollInterval    time.Duration
+	leaseTimeout    time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewStepWorkerPool(jobRepo ports.JobRepository, workflowService ports.WorkflowService, cfg config.WorkerConfig) *StepWorkerPool {
+	return &StepWorkerPool{
+		jobRepo:         jobRepo,
+		workflowService: workflowService,
+		concurrency:     cfg.Concurrency,
+		pollInterval:    cfg.PollInterval,
+		leaseTimeout:    cfg.LeaseTimeout,
+	}
+}
+
+// Start launches the workers and the lease recovery loop
+func (p *StepWorkerPool) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	status.Track(stepWorkersComponent)
+
+	// Recover jobs abandoned by a previous crash before taking new work
+	p.recoverExpired(ctx)
+
+	for i := 0; i < p.concurrency; i++ {
+		p.wg.Add(1)
+		go p.work(ctx, i)
+	}
+
+	p.wg.Add(1)
+	go p.recoveryLoop(ctx)
+
+	logrus.WithField("concurrency", p.concurrency).Info("Step worker pool started")
+}
+
+// Stop signals workers to exit and waits for in-flight jobs to finish
+func (p *StepWorkerPool) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	logrus.Info("Step worker pool stopped")
+}
+
+func (p *StepWorkerPool) work(ctx context.Context, worker int) {
+	defer p.wg.Done()
+
+	for {
+		jobs, err := p.jobRepo.Claim(ctx, 1)
+		if err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to claim step job")
+			status.Record(stepWorkersComponent, err)
+		}
+
+		if len(jobs) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.pollInterval):
+			}
+			continue
+		}
+
+		for _, job := range jobs {
+			// Jobs run to completion on a context detached from shutdown
+			jobCtx := context.WithoutCancel(ctx)
+			log := logrus.WithFields(logrus.Fields{
+				"worker":  worker,
+				"job_id":  job.ID,
+				"step_id": job.StepID,
+				"attempt": job.Attempts,
+			})
+
+			stopHeartbeat := p.heartbeat(jobCtx, job, log)
+			err := p.workflowService.RunJob(jobCtx, job)
+			stopHeartbeat()
+			if err != nil {
+				var retry *domain.RetryLaterError
+				if errors.As(err, &retry) {
+					log.WithError(err).WithField("retry_in", retry.After).Warn("Step job failed, retrying")
+					if err := p.jobRepo.Retry(jobCtx, job.ID, job.LeaseToken, time.Now().Add(retry.After), err.Error()); err != nil {
+						log.WithError(err).Error("Failed to reschedule job")
+					}
+					continue
+				}
+
+				log.WithError(err).Warn("Step job failed")
+				if err := p.jobRepo.Fail(jobCtx, job.ID, job.LeaseToken, err.Error()); err != nil {
+					log.WithError(err).Error("Failed to record job failure")
+				}
+				continue
+			}
+
+			if err := p.jobRepo.Complete(jobCtx, job.ID, job.LeaseToken); err != nil {
+				log.WithError(err).Error("Failed to record job completion")
+			}
+		}
+	}
+}
+
+// heartbeat renews the job's lease while it executes, so steps running longer
+// than the lease timeout aren't requeued and run twice. The returned function
+// stops it
+func (p *StepWorkerPool) heartbeat(ctx context.Context, job *domain.StepJob, log *logrus.Entry) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(p.leaseTimeout / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.jobRepo.Heartbeat(ctx, job.ID, job.LeaseToken); err != nil && ctx.Err() == nil {
+					log.WithError(err).Warn("Failed to renew step job lease")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (p *StepWorkerPool) recoveryLoop(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.leaseTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.recoverExpired(ctx)
+		}
+	}
+}
+
+func (p *StepWorkerPool) recoverExpired(ctx context.Context) {
+	count, err := p.jobRepo.RequeueExpired(ctx, p.leaseTimeout)
+	status.RecordRun(ctx, stepWorkersComponent, err)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to requeue expired step jobs")
+		return
+	}
+	if count > 0 {
+		logrus.WithField("count", count).Warn("Requeued step jobs with expired leases")
+	}
+
+	// Inline steps have no job to requeue; those left unfinished by a crash fail
+	failed, err := p.workflowService.RecoverAbandonedSteps(ctx, p.leaseTimeout)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to recover abandoned steps")
+		return
+	}
+	if failed > 0 {
+		logrus.WithField("count", failed).Warn("Failed steps abandoned mid-execution")
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/google/uuid"
+)
+
+// PublishTemplate stores the next version of a workflow template; a breaking
+// version stops reuse of, and marks stale, results produced under earlier versions
+func (s *WorkflowService) PublishTemplate(ctx context.Context, req *domain.PublishTemplateRequest) (*domain.PublishTemplateResponse, error) {
+	if req.Name == "" {
+		return nil, domain.NewValidationError("name is required")
+	}
+
+	latest, err := s.templateRepo.Get(ctx, req.Name, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	template := &domain.WorkflowTemplate{
+		ID:             uuid.New(),
+		Namespace:      domain.NamespaceFromContext(ctx),
+		Name:           req.Name,
+		Version:        1,
+		Description:    req.Description,
+		Steps:          req.Steps,
+		CompatibleFrom: 1,
+		CreatedAt:      time.Now(),
+	}
+	if latest != nil {
+		template.Version = latest.Version + 1
+		template.CompatibleFrom = latest.CompatibleFrom
+	}
+	if req.Breaking {
+		template.CompatibleFrom = template.Version
+	}
+
+	// A concurrent publish of the same version fails on the unique constraint
+	if err := s.templateRepo.Store(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to store template: %w", err)
+	}
+
+	response := &domain.PublishTemplateResponse{Template: template}
+	if req.Breaking && latest != nil {
+		invalidated, err := s.workflowRepo.MarkTemplateArtifactsStale(ctx, template.Name, template.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to invalidate previous results: %w", err)
+		}
+		response.Invalidated = invalidated
+		if _, err := s.eventRepo.AppendForTemplate(ctx, template.Name, template.Version, domain.SessionEventInvalidated, map[string]interface{}{
+			"template":         template.Name,
+			"breaking_version": template.Version,
+		}); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("template", template.Name).Warn("Failed to record template invalidation events")
+		}
+	}
+
+	return response, nil
+}
+
+// GetTemplate returns a template version, or the latest when version is 0
+func (s *WorkflowService) GetTemplate(ctx context.Context, name string, version int) (*domain.WorkflowTemplate, error) {
+	template, err := s.templateRepo.Get(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	if template == nil {
+		if version > 0 {
+			return nil, domain.NewNotFoundError("workflow template", fmt.Sprintf("%s v%d", name, version))
+		}
+		return nil, domain.NewNotFoundError("workflow template", name)
+	}
+	return template, nil
+}
+
+func (s *WorkflowService) ListTemplates(ctx context.Context) ([]*domain.WorkflowTemplate, error) {
+	templates, err := s.templateRepo.ListLatest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (s *WorkflowService) ListTemplateVersions(ctx context.Context, name string) ([]*domain.WorkflowTemplate, error) {
+	templates, err := s.templateRepo.ListVersions(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list template versions: %w", err)
+	}
+	if len(templates) == 0 {
+		return nil, domain.NewNotFoundError("workflow template", name)
+	}
+	return templates, nil
+}
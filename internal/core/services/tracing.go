@@ -0,0 +1,6 @@
+package services
+
+import "go.opentelemetry.io/otel"
+
+// tracer records service-level spans; it is a no-op unless tracing is enabled
+var tracer = otel.Tracer("github.com/anunay/mentis/internal/core/services")
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// UsageService periodically rolls daily usage up into a summary table and
+// reports from it. Each pass recomputes the last lookbackDays days, so a day is
+// final once it falls out of that window
+type UsageService struct {
+	repo         ports.UsageRepository
+	interval     time.Duration
+	lookbackDays int
+	leader       *LeaderElector
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewUsageService(repo ports.UsageRepository, leader *LeaderElector, cfg config.UsageConfig) *UsageService {
+	lookbackDays := cfg.LookbackDays
+	if lookbackDays < 1 {
+		lookbackDays = 1
+	}
+	return &UsageService{
+		repo:         repo,
+		interval:     cfg.Interval,
+		lookbackDays: lookbackDays,
+		leader:       leader,
+	}
+}
+
+func (s *UsageService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	status.Track(usageComponent)
+	s.wg.Add(1)
+	go s.loop(ctx)
+
+	logrus.WithFields(logrus.Fields{"interval": s.interval, "lookback_days": s.lookbackDays}).Info("Usage aggregator started")
+}
+
+func (s *UsageService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	logrus.Info("Usage aggregator stopped")
+}
+
+func (s *UsageService) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	// Today's summary is available as soon as the server is up
+	s.aggregate(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.aggregate(ctx)
+		}
+	}
+}
+
+func (s *UsageService) aggregate(ctx context.Context) {
+	if !s.leader.Leads(ctx, usageComponent) {
+		return
+	}
+	today := utcDay(time.Now())
+	rows, err := s.repo.Aggregate(ctx, today.AddDate(0, 0, 1-s.lookbackDays), today, today)
+	status.RecordRun(ctx, usageComponent, err)
+	if err != nil {
+		if ctx.Err() == nil {
+			logrus.WithError(err).Error("Usage aggregation failed")
+		}
+		return
+	}
+	logrus.WithField("rows", rows).Debug("Aggregated daily usage")
+}
+
+// Report lists daily usage between from and to, inclusive. Zero dates default
+// to the last defaultStatsDays days; today's figures are as of the last pass
+func (s *UsageService) Report(ctx context.Context, from, to time.Time, namespace string) (*domain.UsageReport, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	to = utcDay(to)
+	if from.IsZero() {
+		from = to.AddDate(0, 0, 1-defaultStatsDays)
+	}
+	from = utcDay(from)
+
+	if from.After(to) {
+		return nil, domain.NewValidationError("from must not be after to")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxStatsDays {
+		return nil, domain.NewValidationError(fmt.Sprintf("a usage report covers at most %d days", maxStatsDays))
+	}
+
+	days, err := s.repo.Summaries(ctx, from, to, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage summaries: %w", err)
+	}
+
+	report := &domain.UsageReport{From: from, To: to, Days: days}
+	for _, day := range days {
+		report.Totals.Publishes += day.Publishes
+		report.Totals.Lookups += day.Lookups
+		report.Totals.CacheHits += day.CacheHits
+		report.Totals.Embeddings += day.Embeddings
+	}
+	return report, nil
+}
+
+// utcDay truncates t to midnight of its UTC date
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/egress"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// recordTimeout bounds logging a delivery attempt
+const recordTimeout = 5 * time.Second
+
+// webhookQueue is the task queue webhook deliveries are dispatched through
+const webhookQueue = "webhooks"
+
+// WebhookDispatcher POSTs signed events to session callback URLs and to the
+// webhook subscriptions of the event's namespace, retrying failed deliveries
+// with exponential backoff. Attempts to subscriptions are kept in their
+// delivery log. With a task queue, deliveries and their retries are queued, so
+// they survive restarts and any replica may make them; otherwise they are held
+// in memory
+type WebhookDispatcher struct {
+	subscriptionRepo ports.WebhookSubscriptionRepository
+	queue            ports.TaskQueue
+	client           *http.Client
+	secret           []byte
+	maxAttempts      int
+	initialBackoff   time.Duration
+	deliveryLogSize  int
+	concurrency      int
+	pollInterval     time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// webhookTarget is a receiver of an event: a callback URL signed with the
+// global secret, or a subscription signed with its own
+type webhookTarget struct {
+	url          string
+	secret       []byte
+	subscription *domain.WebhookSubscription
+}
+
+// webhookTask is a queued delivery of an event to a callback URL or to a
+// subscription. Subscriptions are looked up again when the task runs, so no
+// secret is queued and a deleted subscription is not delivered to
+type webhookTask struct {
+	URL            string          `json:"url,omitempty"`
+	SubscriptionID *uuid.UUID      `json:"subscription_id,omitempty"`
+	Namespace      string          `json:"namespace"`
+	Event          json.RawMessage `json:"event"`
+}
+
+// NewWebhookDispatcher creates a dispatcher that queues deliveries on queue, or
+// holds them in memory when it is nil
+func NewWebhookDispatcher(subscriptionRepo ports.WebhookSubscriptionRepository, queue ports.TaskQueue, guard *egress.Guard, cfg config.WebhookConfig, queueCfg config.QueueConfig) *WebhookDispatcher {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		subscriptionRepo: subscriptionRepo,
+		queue:            queue,
+		client:           guard.Client(cfg.Timeout, false),
+		secret:           []byte(cfg.Secret),
+		maxAttempts:      maxAttempts,
+		initialBackoff:   cfg.InitialBackoff,
+		deliveryLogSize:  cfg.DeliveryLogSize,
+		concurrency:      queueCfg.Concurrency,
+		pollInterval:     queueCfg.PollInterval,
+		ctx:              ctx,
+		cancel:           cancel,
+	}
+}
+
+// Notify queues delivery of event to every URL and to the subscriptions of its
+// namespace without blocking the caller
+func (d *WebhookDispatcher) Notify(urls []string, event *domain.WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal webhook event")
+		return
+	}
+
+	for _, url := range urls {
+		d.dispatch(webhookTarget{url: url, secret: d.secret}, event, body)
+	}
+
+	// Subscriptions are looked up off the caller's path too
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		subscriptions, err := d.subscriptionRepo.ListForEvent(d.ctx, event.Namespace, event.Type)
+		if err != nil {
+			if d.ctx.Err() == nil {
+				logrus.WithError(err).WithField("event", event.Type).Warn("Failed to load webhook subscriptions")
+			}
+			return
+		}
+		for _, subscription := range subscriptions {
+			d.dispatch(webhookTarget{url: subscription.URL, secret: []byte(subscription.Secret), subscription: subscription}, event, body)
+		}
+	}()
+}
+
+// Start launches the consumers of queued deliveries; without a queue there is nothing to start
+func (d *WebhookDispatcher) Start() {
+	if d.queue == nil {
+		return
+	}
+	for i := 0; i < d.concurrency; i++ {
+		d.wg.Add(1)
+		go d.consume()
+	}
+	logrus.WithField("concurrency", d.concurrency).Info("Webhook delivery consumers started")
+}
+
+// Stop abandons pending retries and waits for in-flight deliveries to return.
+// Queued deliveries stay queued for the next replica to take
+func (d *WebhookDispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+func (d *WebhookDispatcher) dispatch(target webhookTarget, event *domain.WebhookEvent, body []byte) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		// Delivered from memory if it can't be queued
+		if d.queue != nil && d.enqueue(target, event, body) == nil {
+			return
+		}
+		d.deliver(target, event, body)
+	}()
+}
+
+func (d *WebhookDispatcher) enqueue(target webhookTarget, event *domain.WebhookEvent, body []byte) error {
+	task := webhookTask{Namespace: event.Namespace, Event: body}
+	if target.subscription != nil {
+		task.SubscriptionID = &target.subscription.ID
+	} else {
+		task.URL = target.url
+	}
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	if err := d.queue.Enqueue(ctx, webhookQueue, payload, time.Now()); err != nil {
+		logrus.WithError(err).WithField("event_id", event.ID).Warn("Failed to queue webhook delivery; delivering from memory")
+		return err
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) consume() {
+	defer d.wg.Done()
+
+	for {
+		tasks, err := d.queue.Dequeue(d.ctx, webhookQueue, 1)
+		if err != nil && d.ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to dequeue webhook deliveries")
+		}
+
+		if len(tasks) == 0 {
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-time.After(d.pollInterval):
+			}
+			continue
+		}
+
+		for _, task := range tasks {
+			d.deliverQueued(task)
+		}
+	}
+}
+
+// deliverQueued makes one attempt at a queued delivery, queueing the next
+// attempt after a retryable failure
+func (d *WebhookDispatcher) deliverQueued(task *domain.QueuedTask) {
+	// Acknowledging outlives shutdown, so an attempt made is not made twice
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	log := logrus.WithFields(logrus.Fields{"task_id": task.ID, "attempt": task.Attempt})
+
+	var queued webhookTask
+	var event domain.WebhookEvent
+	if err := json.Unmarshal(task.Payload, &queued); err != nil {
+		log.WithError(err).Error("Dropping undecodable webhook delivery")
+		d.ack(ctx, task)
+		return
+	}
+	if err := json.Unmarshal(queued.Event, &event); err != nil {
+		log.WithError(err).Error("Dropping undecodable webhook delivery")
+		d.ack(ctx, task)
+		return
+	}
+
+	target, err := d.queuedTarget(ctx, &queued)
+	if err != nil {
+		// Left for the lease to expire and the delivery to be taken again
+		log.WithError(err).Warn("Failed to load webhook subscription")
+		return
+	}
+	if target == nil {
+		d.ack(ctx, task)
+		return
+	}
+	log = log.WithFields(logrus.Fields{"event_id": event.ID, "event": event.Type, "url": target.url})
+
+	started := time.Now()
+	statusCode, retryable, err := d.send(*target, &event, queued.Event)
+	d.record(*target, &event, task.Attempt, statusCode, time.Since(started), err)
+	if err == nil {
+		log.Debug("Webhook delivered")
+		d.ack(ctx, task)
+		return
+	}
+	if !retryable || task.Attempt >= d.maxAttempts {
+		log.WithError(err).Warn("Webhook delivery failed")
+		d.ack(ctx, task)
+		return
+	}
+
+	backoff := d.initialBackoff << (task.Attempt - 1)
+	if err := d.queue.Retry(ctx, task, time.Now().Add(backoff)); err != nil {
+		log.WithError(err).Error("Failed to queue webhook retry")
+	}
+}
+
+// queuedTarget resolves the receiver of a queued delivery, or nil when its
+// subscription has since been deleted or disabled
+func (d *WebhookDispatcher) queuedTarget(ctx context.Context, task *webhookTask) (*webhookTarget, error) {
+	if task.SubscriptionID == nil {
+		return &webhookTarget{url: task.URL, secret: d.secret}, nil
+	}
+
+	subscription, err := d.subscriptionRepo.Get(namespaceContext(ctx, task.Namespace), *task.SubscriptionID)
+	if err != nil || subscription == nil || !subscription.Enabled {
+		return nil, err
+	}
+	return &webhookTarget{url: subscription.URL, secret: []byte(subscription.Secret), subscription: subscription}, nil
+}
+
+func (d *WebhookDispatcher) ack(ctx context.Context, task *domain.QueuedTask) {
+	if err := d.queue.Ack(ctx, task); err != nil {
+		logrus.WithError(err).WithField("task_id", task.ID).Warn("Failed to acknowledge webhook delivery")
+	}
+}
+
+func (d *WebhookDispatcher) deliver(target webhookTarget, event *domain.WebhookEvent, body []byte) {
+	log := logrus.WithFields(logrus.Fields{
+		"event_id": event.ID,
+		"event":    event.Type,
+		"url":      target.url,
+	})
+
+	backoff := d.initialBackoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		started := time.Now()
+		statusCode, retryable, err := d.send(target, event, body)
+		d.record(target, event, attempt, statusCode, time.Since(started), err)
+		if err == nil {
+			log.WithField("attempt", attempt).Debug("Webhook delivered")
+			return
+		}
+		if !retryable || attempt == d.maxAttempts {
+			log.WithError(err).WithField("attempt", attempt).Warn("Webhook delivery failed")
+			return
+		}
+
+		select {
+		case <-d.ctx.Done():
+			log.Warn("Webhook delivery abandoned on shutdown")
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// record logs a delivery attempt to a subscription; callback URLs have no log
+func (d *WebhookDispatcher) record(target webhookTarget, event *domain.WebhookEvent, attempt, statusCode int, duration time.Duration, err error) {
+	if target.subscription == nil {
+		return
+	}
+
+	delivery := &domain.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: target.subscription.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Attempt:        attempt,
+		StatusCode:     statusCode,
+		Success:        err == nil,
+		DurationMs:     duration.Milliseconds(),
+		CreatedAt:      time.Now(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	// Attempts cut short by shutdown are still logged
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	if err := d.subscriptionRepo.RecordDelivery(ctx, delivery, d.deliveryLogSize); err != nil {
+		logrus.WithError(err).WithField("subscription_id", delivery.SubscriptionID).Warn("Failed to record webhook delivery")
+	}
+}
+
+// send makes one delivery attempt, returning the receiver's status code, if
+// any, and whether a failure is worth retrying
+func (d *WebhookDispatcher) send(target webhookTarget, event *domain.WebhookEvent, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, target.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Mentis-Event", string(event.Type))
+	req.Header.Set("X-Mentis-Delivery", event.ID.String())
+	req.Header.Set("X-Mentis-Timestamp", timestamp)
+	// A signature under an empty key is one anyone could forge, so none is sent
+	if len(target.secret) > 0 {
+		req.Header.Set("X-Mentis-Signature", "sha256="+signPayload(target.secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return resp.StatusCode, retryable, fmt.Errorf("receiver returned status %d", resp.StatusCode)
+}
+
+// signPayload is the hex HMAC-SHA256 of the timestamp and body, binding the two together
+func signPayload(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
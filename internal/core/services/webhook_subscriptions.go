@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/egress"
+	"github.com/google/uuid"
+)
+
+// WebhookSubscriptionService manages the subscriptions the webhook dispatcher
+// delivers namespace events to
+type WebhookSubscriptionService struct {
+	subscriptionRepo ports.WebhookSubscriptionRepository
+	guard            *egress.Guard
+}
+
+func NewWebhookSubscriptionService(subscriptionRepo ports.WebhookSubscriptionRepository, guard *egress.Guard) *WebhookSubscriptionService {
+	return &WebhookSubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		guard:            guard,
+	}
+}
+
+func (s *WebhookSubscriptionService) Create(ctx context.Context, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscription, error) {
+	now := time.Now()
+	subscription := &domain.WebhookSubscription{
+		ID:        uuid.New(),
+		Namespace: domain.NamespaceFromContext(ctx),
+		CreatedAt: now,
+	}
+
+	if err := applyWebhookSubscription(subscription, req, now, s.guard); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+
+	if err := s.subscriptionRepo.Store(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to store webhook subscription: %w", err)
+	}
+
+	// The secret is shown once, so receivers can verify signatures
+	return subscription, nil
+}
+
+func (s *WebhookSubscriptionService) Get(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	subscription, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (s *WebhookSubscriptionService) List(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	subscriptions, err := s.subscriptionRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	return subscriptions, nil
+}
+
+func (s *WebhookSubscriptionService) Update(ctx context.Context, id uuid.UUID, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscription, error) {
+	subscription, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyWebhookSubscription(subscription, req, time.Now(), s.guard); err != nil {
+		return nil, err
+	}
+
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (s *WebhookSubscriptionService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.subscriptionRepo.Delete(ctx, id)
+}
+
+// ListDeliveries returns the most recent delivery attempts of a subscription
+func (s *WebhookSubscriptionService) ListDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	// Deliveries are scoped through their subscription's namespace
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.subscriptionRepo.ListDeliveries(ctx, id, domain.NormalizePageSize(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *WebhookSubscriptionService) get(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	subscription, err := s.subscriptionRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if subscription == nil {
+		return nil, domain.NewNotFoundError("webhook subscription", id)
+	}
+	return subscription, nil
+}
+
+// applyWebhookSubscription validates a subscription request and copies it onto
+// subscription; an empty secret keeps the current one
+func applyWebhookSubscription(subscription *domain.WebhookSubscription, req *domain.WebhookSubscriptionRequest, now time.Time, guard *egress.Guard) error {
+	if err := validateCallbackURLs([]string{req.URL}, guard); err != nil {
+		return err
+	}
+	if len(req.EventTypes) == 0 {
+		return domain.NewValidationError("event_types must name at least one event type")
+	}
+	for _, eventType := range req.EventTypes {
+		if !eventType.Valid() {
+			return domain.NewValidationError("unknown event type").WithDetail("event_type", eventType)
+		}
+	}
+
+	subscription.URL = req.URL
+	subscription.EventTypes = req.EventTypes
+	if req.Secret != "" {
+		subscription.Secret = req.Secret
+	}
+	subscription.Enabled = req.Enabled == nil || *req.Enabled
+	subscription.UpdatedAt = now
+
+	return nil
+}
@@ -2,51 +2,142 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/anunay/mentis/internal/config"
 	"github.com/anunay/mentis/internal/core/domain"
 	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/egress"
+	"github.com/anunay/mentis/internal/logging"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 type WorkflowService struct {
 	workflowRepo    ports.WorkflowRepository
+	templateRepo    ports.TemplateRepository
+	eventRepo       ports.SessionEventRepository
+	jobRepo         ports.JobRepository
 	artifactRepo    ports.ArtifactRepository
 	vectorRepo      ports.VectorRepository
+	transactor      ports.Transactor
 	embeddingService ports.EmbeddingService
 	hashService     ports.HashService
+	quotaService    ports.QuotaService
+	schemaService   ports.StepSchemaService
+	contentScanner  ports.ContentScanner
+	retryPolicies   *RetryPolicies
+	cacheScopes     *CacheScopes
+	artifactTTLs    *ArtifactTTLs
+	negativeCache   *NegativeCache
+	cacheStats      *CacheStatsService
+	accessTracker   *AccessTracker
+	lookupCache     *LookupCache
+	executions      *ExecutionManager
+	webhooks        ports.WebhookNotifier
+	// processors execute steps by step type; other types are simulated
+	processors map[string]ports.StepProcessor
+	// guard screens callback URLs for private addresses
+	guard *egress.Guard
+	cfg   config.WorkflowConfig
+
+	// inflight runs concurrent identical inline steps once
+	inflight singleflight.Group
 }
 
 func NewWorkflowService(
 	workflowRepo ports.WorkflowRepository,
+	templateRepo ports.TemplateRepository,
+	eventRepo ports.SessionEventRepository,
+	jobRepo ports.JobRepository,
 	artifactRepo ports.ArtifactRepository,
 	vectorRepo ports.VectorRepository,
+	transactor ports.Transactor,
 	embeddingService ports.EmbeddingService,
 	hashService ports.HashService,
+	quotaService ports.QuotaService,
+	schemaService ports.StepSchemaService,
+	contentScanner ports.ContentScanner,
+	retryPolicies *RetryPolicies,
+	cacheScopes *CacheScopes,
+	artifactTTLs *ArtifactTTLs,
+	negativeCache *NegativeCache,
+	cacheStats *CacheStatsService,
+	accessTracker *AccessTracker,
+	lookupCache *LookupCache,
+	executions *ExecutionManager,
+	webhooks ports.WebhookNotifier,
+	processors map[string]ports.StepProcessor,
+	guard *egress.Guard,
+	cfg config.WorkflowConfig,
 ) *WorkflowService {
 	return &WorkflowService{
 		workflowRepo:    workflowRepo,
+		templateRepo:    templateRepo,
+		eventRepo:       eventRepo,
+		jobRepo:         jobRepo,
 		artifactRepo:    artifactRepo,
 		vectorRepo:      vectorRepo,
+		transactor:      transactor,
 		embeddingService: embeddingService,
 		hashService:     hashService,
+		quotaService:    quotaService,
+		schemaService:   schemaService,
+		contentScanner:  contentScanner,
+		retryPolicies:   retryPolicies,
+		cacheScopes:     cacheScopes,
+		artifactTTLs:    artifactTTLs,
+		negativeCache:   negativeCache,
+		cacheStats:      cacheStats,
+		accessTracker:   accessTracker,
+		lookupCache:     lookupCache,
+		executions:      executions,
+		webhooks:        webhooks,
+		processors:      processors,
+		guard:           guard,
+		cfg:             cfg,
 	}
 }
 
-func (s *WorkflowService) CreateSession(ctx context.Context, goal string, sessionContext map[string]interface{}) (*domain.WorkflowSession, error) {
+func (s *WorkflowService) CreateSession(ctx context.Context, req *domain.CreateSessionRequest) (*domain.WorkflowSession, error) {
+	if err := validateCallbackURLs(req.CallbackURLs, s.guard); err != nil {
+		return nil, err
+	}
+
 	session := &domain.WorkflowSession{
-		ID:        uuid.New(),
-		Goal:      goal,
-		Context:   sessionContext,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Status:    domain.SessionActive,
+		ID:           uuid.New(),
+		Namespace:    domain.NamespaceFromContext(ctx),
+		Goal:         req.Goal,
+		Context:      req.Context,
+		CallbackURLs: req.CallbackURLs,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		Status:       domain.SessionActive,
+	}
+
+	if req.Template != "" {
+		template, err := s.GetTemplate(ctx, req.Template, req.TemplateVersion)
+		if err != nil {
+			return nil, err
+		}
+		session.TemplateName = template.Name
+		session.TemplateVersion = template.Version
 	}
 
 	if err := s.workflowRepo.StoreSession(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to store session: %w", err)
 	}
+	s.recordEvent(ctx, session.ID, domain.SessionEventCreated, nil, map[string]interface{}{
+		"goal":     session.Goal,
+		"template": session.TemplateName,
+	})
+	s.indexSessionGoal(ctx, session)
 
 	return session, nil
 }
@@ -58,82 +149,718 @@ func (s *WorkflowService) GetSession(ctx context.Context, id uuid.UUID) (*domain
 	}
 
 	if session == nil {
-		return nil, fmt.Errorf("session not found")
+		return nil, domain.NewNotFoundError("session", id)
 	}
 
-	// Load steps
-	steps, err := s.workflowRepo.GetStepsBySession(ctx, id)
+	// Load steps, including those shared from the parent of a fork
+	steps, err := s.sessionSteps(ctx, session)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get steps: %w", err)
+		return nil, err
 	}
 
 	session.Steps = make([]domain.WorkflowStep, len(steps))
 	for i, step := range steps {
 		session.Steps[i] = *step
+		// Inherited steps were paid for by the parent session
+		if step.SessionID == session.ID {
+			session.Usage.Add(step.Usage)
+		}
 	}
 
 	return session, nil
 }
 
+// ForkSession creates a session that shares the parent's completed steps and
+// their artifacts by reference, so alternative strategies skip the shared prefix
+func (s *WorkflowService) ForkSession(ctx context.Context, parentID uuid.UUID, req *domain.ForkSessionRequest) (*domain.WorkflowSession, error) {
+	parent, err := s.workflowRepo.GetSession(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if parent == nil {
+		return nil, domain.NewNotFoundError("session", parentID)
+	}
+
+	now := time.Now()
+	forkedAt := now
+	if req.UntilStepID != nil {
+		step, err := s.workflowRepo.GetStep(ctx, *req.UntilStepID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get step: %w", err)
+		}
+		if step == nil || step.SessionID != parentID {
+			return nil, domain.NewValidationError("until_step_id must be a step of the parent session")
+		}
+		if step.Status != domain.StepCompleted || step.CompletedAt == nil {
+			return nil, domain.NewValidationError("until_step_id must be a completed step")
+		}
+		forkedAt = *step.CompletedAt
+	}
+
+	fork := &domain.WorkflowSession{
+		ID:              uuid.New(),
+		Namespace:       parent.Namespace,
+		Goal:            parent.Goal,
+		Context:         parent.Context,
+		CallbackURLs:    parent.CallbackURLs,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		Status:          domain.SessionActive,
+		TemplateName:    parent.TemplateName,
+		TemplateVersion: parent.TemplateVersion,
+		ParentID:        &parent.ID,
+		ForkedAt:        &forkedAt,
+	}
+	if req.Goal != "" {
+		fork.Goal = req.Goal
+	}
+	if req.Context != nil {
+		fork.Context = req.Context
+	}
+	if req.CallbackURLs != nil {
+		if err := validateCallbackURLs(req.CallbackURLs, s.guard); err != nil {
+			return nil, err
+		}
+		fork.CallbackURLs = req.CallbackURLs
+	}
+
+	if err := s.workflowRepo.StoreSession(ctx, fork); err != nil {
+		return nil, fmt.Errorf("failed to store session: %w", err)
+	}
+	s.recordEvent(ctx, fork.ID, domain.SessionEventForked, nil, map[string]interface{}{
+		"parent_id": parent.ID,
+		"forked_at": forkedAt,
+	})
+	s.indexSessionGoal(ctx, fork)
+
+	return s.GetSession(ctx, fork.ID)
+}
+
+// sessionSteps returns a session's own steps preceded by the completed steps
+// it shares with its ancestors up to each fork point
+func (s *WorkflowService) sessionSteps(ctx context.Context, session *domain.WorkflowSession) ([]*domain.WorkflowStep, error) {
+	steps, err := s.workflowRepo.GetStepsBySession(ctx, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get steps: %w", err)
+	}
+	if session.ParentID == nil || session.ForkedAt == nil {
+		return steps, nil
+	}
+
+	parent, err := s.workflowRepo.GetSession(ctx, *session.ParentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent session: %w", err)
+	}
+	if parent == nil {
+		return steps, nil
+	}
+
+	parentSteps, err := s.sessionSteps(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Steps are shared by when they completed, so one still running at the
+	// fork doesn't leak into the fork once it finishes
+	var shared []*domain.WorkflowStep
+	for _, step := range parentSteps {
+		if step.Status == domain.StepCompleted && step.CompletedAt != nil && !step.CompletedAt.After(*session.ForkedAt) {
+			shared = append(shared, step)
+		}
+	}
+
+	return append(shared, steps...), nil
+}
+
+func (s *WorkflowService) ListSessions(ctx context.Context, limit int, cursor string) (*domain.ListSessionsResponse, error) {
+	limit = domain.NormalizePageSize(limit)
+	after, err := domain.DecodeKeyCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to know whether another page exists
+	sessions, err := s.workflowRepo.ListSessions(ctx, limit+1, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	response := &domain.ListSessionsResponse{Sessions: sessions}
+	if len(sessions) > limit {
+		response.Sessions = sessions[:limit]
+		last := sessions[limit-1]
+		response.NextCursor = domain.EncodeKeyCursor(domain.PageKey{Time: last.CreatedAt, ID: last.ID})
+	}
+
+	return response, nil
+}
+
 func (s *WorkflowService) ExecuteStep(ctx context.Context, req *domain.WorkflowStepRequest) (*domain.WorkflowStepResponse, error) {
+	return s.executeStep(ctx, req, nil)
+}
+
+// executeStep runs a step, taking its cache status from hits when a batch
+// already looked it up
+func (s *WorkflowService) executeStep(ctx context.Context, req *domain.WorkflowStepRequest, hits stepCacheHits) (*domain.WorkflowStepResponse, error) {
+	ctx, span := tracer.Start(ctx, "WorkflowService.ExecuteStep", trace.WithAttributes(attribute.String("mentis.step_type", req.StepType)))
+	defer span.End()
+
+	if req.StepType == "" {
+		return nil, domain.NewValidationError("step_type is required")
+	}
+
+	timeout, err := parseStepTimeout(req.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject malformed input before it is hashed into the step cache
+	if err := s.schemaService.ValidateInput(ctx, req.StepType, req.Input); err != nil {
+		return nil, err
+	}
+
+	// The session must exist in the caller's namespace
+	session, err := s.workflowRepo.GetSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return nil, domain.NewNotFoundError("session", req.SessionID)
+	}
+
+	// Sessions pinned to a template only run its steps and reuse results of compatible versions
+	var compat *domain.TemplateCompatibility
+	if session.TemplateName != "" {
+		template, err := s.GetTemplate(ctx, session.TemplateName, session.TemplateVersion)
+		if err != nil {
+			return nil, err
+		}
+		if !template.AllowsStep(req.StepType) {
+			return nil, domain.NewValidationError(fmt.Sprintf("step type %q is not part of template %s v%d", req.StepType, template.Name, template.Version))
+		}
+		compat = &domain.TemplateCompatibility{
+			Name:       template.Name,
+			MinVersion: template.CompatibleFrom,
+			MaxVersion: template.Version,
+		}
+	}
+
 	// Compute input hash
 	inputHash := s.hashService.ComputeInputHash(req.Input)
 
 	// Check if we have a cached result for this step
-	cachedStep, err := s.workflowRepo.FindStepByInputHash(ctx, req.StepType, inputHash)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check cached step: %w", err)
+	var cachedStep *domain.WorkflowStep
+	if step, ok := hits[stepCacheKey(req.StepType, inputHash)]; ok && !req.Force {
+		cachedStep = step
+	} else if !req.Force {
+		filter, err := s.reuseFilter(ctx, session, req.StepType, compat)
+		if err != nil {
+			return nil, err
+		}
+		cachedStep, err = s.workflowRepo.FindStepByInputHash(ctx, req.StepType, inputHash, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check cached step: %w", err)
+		}
 	}
 
+	if cachedStep != nil && cachedStep.Namespace != session.Namespace {
+		// A step of another namespace, under the global scope, is only served as a copy
+		cachedStep, err = s.adoptStep(ctx, session, cachedStep)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if cachedStep != nil {
-		// Return cached result
 		artifact, err := s.artifactRepo.GetByID(ctx, cachedStep.ArtifactID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get cached artifact: %w", err)
 		}
+		return s.serveCachedStep(ctx, req.SessionID, cachedStep, artifact), nil
+	}
+
+	// Don't rerun an input that just failed unless the caller insists
+	if !req.Force {
+		if entry := s.negativeCache.Check(ctx, domain.NegativeStepFailure, stepFailureKey(req.StepType, inputHash)); entry != nil {
+			return &domain.WorkflowStepResponse{KnownFailure: entry}, nil
+		}
+	}
+
+	// Concurrent identical inline steps run once; the others wait and are served
+	// its result as a cache hit. Forced runs and queued steps always run
+	if req.Force || req.Async || req.Executor != "" {
+		return s.startStep(ctx, req, session, inputHash, timeout)
+	}
+	key := s.stepFlightKey(session, req.StepType, inputHash)
+	var led bool
+	result, err, _ := s.inflight.Do(key, func() (interface{}, error) {
+		led = true
+		return s.startStep(ctx, req, session, inputHash, timeout)
+	})
+	if led {
+		if err != nil {
+			return nil, err
+		}
+		return result.(*domain.WorkflowStepResponse), nil
+	}
+	// A failure may be the leader's own, such as its timeout or a dropped
+	// request, so the waiting caller starts over as if it had come after it
+	if err != nil {
+		return s.executeStep(ctx, req, nil)
+	}
+	shared := result.(*domain.WorkflowStepResponse)
+	return s.serveCachedStep(ctx, req.SessionID, shared.Step, shared.Artifact), nil
+}
+
+// stepFlightKey identifies steps that may share one run: the same step type and
+// input in sessions that could reuse each other's results
+func (s *WorkflowService) stepFlightKey(session *domain.WorkflowSession, stepType, inputHash string) string {
+	reuser := session.Namespace
+	if scope := s.cacheScopes.For(stepType); scope == domain.CacheScopeSession || scope == domain.CacheScopeGoal {
+		reuser = session.ID.String()
+	}
+	return strings.Join([]string{reuser, session.TemplateName, fmt.Sprint(session.TemplateVersion), stepType, inputHash}, "\x00")
+}
+
+// serveCachedStep answers a step with the result of an earlier run, which may
+// belong to another session; the hit is recorded on this one
+func (s *WorkflowService) serveCachedStep(ctx context.Context, sessionID uuid.UUID, cachedStep *domain.WorkflowStep, artifact *domain.Artifact) *domain.WorkflowStepResponse {
+	s.accessTracker.Record(cachedStep.ArtifactID)
+
+	s.recordEvent(ctx, sessionID, domain.SessionEventStepCacheHit, &cachedStep.ID, map[string]interface{}{
+		"step_type":         cachedStep.StepType,
+		"source_session_id": cachedStep.SessionID,
+	})
+	s.cacheStats.RecordStep(ctx, cachedStep.StepType, true)
+	s.recordStepReuse(ctx, sessionID, cachedStep)
+
+	return &domain.WorkflowStepResponse{
+		Step:     cachedStep,
+		Artifact: artifact,
+		Cached:   true,
+	}
+}
+
+// adoptStep copies a completed step of another namespace into the session.
+// Only the output content crosses over: the copy is a new step with an artifact
+// of the session's namespace, the one already holding that content or a new
+// one, and carries none of the source's IDs or metadata. Later steps of the
+// namespace reuse the copy. Sources whose content isn't stored inline are not
+// shared, and nil is returned
+func (s *WorkflowService) adoptStep(ctx context.Context, session *domain.WorkflowSession, source *domain.WorkflowStep) (*domain.WorkflowStep, error) {
+	sourceArtifact, err := s.artifactRepo.GetByID(namespaceContext(ctx, source.Namespace), source.ArtifactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached artifact: %w", err)
+	}
+	if sourceArtifact == nil || sourceArtifact.ContentRef != "" || len(sourceArtifact.Content) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	step := &domain.WorkflowStep{
+		ID:          uuid.New(),
+		Namespace:   session.Namespace,
+		SessionID:   session.ID,
+		StepType:    source.StepType,
+		InputHash:   source.InputHash,
+		OutputHash:  sourceArtifact.ContentHash,
+		CreatedAt:   now,
+		CompletedAt: &now,
+		Status:      domain.StepCompleted,
 
-		return &domain.WorkflowStepResponse{
-			Step:     cachedStep,
-			Artifact: artifact,
-			Cached:   true,
-		}, nil
+		TemplateName:    session.TemplateName,
+		TemplateVersion: session.TemplateVersion,
 	}
 
+	artifact, err := s.artifactRepo.GetByContentHash(ctx, sourceArtifact.ContentHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing artifact: %w", err)
+	}
+	reused := artifact != nil && !artifact.Stale && artifact.Type == sourceArtifact.Type
+	if !reused {
+		artifact = &domain.Artifact{
+			ID:          uuid.New(),
+			Namespace:   session.Namespace,
+			Type:        sourceArtifact.Type,
+			ContentHash: sourceArtifact.ContentHash,
+			Content:     sourceArtifact.Content,
+			Embedding:   sourceArtifact.Embedding,
+			Metadata: map[string]interface{}{
+				"step_type":  step.StepType,
+				"step_id":    step.ID.String(),
+				"session_id": step.SessionID.String(),
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+			Indexed:   len(sourceArtifact.Embedding) > 0,
+		}
+		if err := s.artifactTTLs.Apply(artifact, now); err != nil {
+			return nil, err
+		}
+	}
+	step.ArtifactID = artifact.ID
+
+	err = s.transactor.InTx(ctx, func(ctx context.Context) error {
+		if !reused {
+			if err := s.artifactRepo.Store(ctx, artifact); err != nil {
+				return fmt.Errorf("failed to store artifact: %w", err)
+			}
+		}
+		if err := s.workflowRepo.StoreStep(ctx, step); err != nil {
+			return fmt.Errorf("failed to store step: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !reused {
+		s.storeStepVector(ctx, step, artifact)
+	}
+	return step, nil
+}
+
+// startStep creates a step for a cache miss and runs it inline or queues it
+func (s *WorkflowService) startStep(ctx context.Context, req *domain.WorkflowStepRequest, session *domain.WorkflowSession, inputHash string, timeout time.Duration) (*domain.WorkflowStepResponse, error) {
+	s.cacheStats.RecordStep(ctx, req.StepType, false)
+
 	// Create new step
 	step := &domain.WorkflowStep{
 		ID:        uuid.New(),
+		Namespace: session.Namespace,
 		SessionID: req.SessionID,
 		StepType:  req.StepType,
 		InputHash: inputHash,
 		Metadata:  req.Metadata,
 		CreatedAt: time.Now(),
 		Status:    domain.StepRunning,
+
+		TemplateName:    session.TemplateName,
+		TemplateVersion: session.TemplateVersion,
+		Forced:          req.Force,
+	}
+
+	switch req.Executor {
+	case "":
+	case domain.ExecutorExternal:
+		return s.enqueueStep(ctx, step, req.Input, timeout, true)
+	default:
+		return nil, domain.NewValidationError(fmt.Sprintf("unknown executor %q", req.Executor))
+	}
+
+	if req.Async {
+		return s.enqueueStep(ctx, step, req.Input, timeout, false)
+	}
+
+	// Inline execution is refused once shutdown has begun
+	if s.executions.isStopping() {
+		return nil, domain.ErrShuttingDown
 	}
 
 	if err := s.workflowRepo.StoreStep(ctx, step); err != nil {
 		return nil, fmt.Errorf("failed to store step: %w", err)
 	}
 
-	// For now, we'll simulate step execution
-	// In production, this would call the actual step processor
-	artifact, err := s.simulateStepExecution(ctx, step, req.Input)
+	if timeout == 0 {
+		timeout = s.cfg.InlineStepTimeout
+	}
+	artifact, err := s.runStep(ctx, step, req.Input, timeout)
 	if err != nil {
-		step.Status = domain.StepFailed
-		s.workflowRepo.UpdateStep(ctx, step)
-		return nil, fmt.Errorf("failed to execute step: %w", err)
+		return nil, err
 	}
 
-	// Store the result artifact
-	if err := s.artifactRepo.Store(ctx, artifact); err != nil {
-		return nil, fmt.Errorf("failed to store artifact: %w", err)
+	return &domain.WorkflowStepResponse{
+		Step:     step,
+		Artifact: artifact,
+		Cached:   false,
+	}, nil
+}
+
+// recordStepReuse credits the session with what the cached step cost when it
+// ran; failures are only logged since the step result is already served
+func (s *WorkflowService) recordStepReuse(ctx context.Context, sessionID uuid.UUID, cachedStep *domain.WorkflowStep) {
+	var savedTime time.Duration
+	if cachedStep.CompletedAt != nil {
+		savedTime = cachedStep.CompletedAt.Sub(cachedStep.CreatedAt)
+	}
+	if err := s.workflowRepo.RecordStepReuse(context.WithoutCancel(ctx), sessionID, cachedStep.Usage, savedTime); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("session_id", sessionID).Warn("Failed to record step reuse savings")
 	}
+}
 
-	// Store vector if embedding is available
-	if len(artifact.Embedding) > 0 {
-		if err := s.vectorRepo.Store(ctx, artifact.ID, artifact.Embedding, artifact.Metadata); err != nil {
-			return nil, fmt.Errorf("failed to store vector: %w", err)
+// GetSavings totals cache savings over the caller's sessions created in the last days
+func (s *WorkflowService) GetSavings(ctx context.Context, days int) (*domain.SavingsReport, error) {
+	if days == 0 {
+		days = defaultStatsDays
+	}
+	if days < 1 || days > maxStatsDays {
+		return nil, domain.NewValidationError(fmt.Sprintf("days must be between 1 and %d", maxStatsDays))
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-days)
+
+	report, err := s.workflowRepo.Savings(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total cache savings: %w", err)
+	}
+	report.Finish()
+	return report, nil
+}
+
+// enqueueStep stores the step as pending and queues it for a background worker
+func (s *WorkflowService) enqueueStep(ctx context.Context, step *domain.WorkflowStep, input interface{}, timeout time.Duration, external bool) (*domain.WorkflowStepResponse, error) {
+	step.Status = domain.StepPending
+
+	// The step and its job commit together, so a failure between them can't
+	// leave a pending step that no worker will ever run
+	var job *domain.StepJob
+	err := s.transactor.InTx(ctx, func(ctx context.Context) error {
+		if err := s.workflowRepo.StoreStep(ctx, step); err != nil {
+			return fmt.Errorf("failed to store step: %w", err)
+		}
+		var err error
+		job, err = s.storeJob(ctx, step, input, timeout, external)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.recordStepEvent(ctx, domain.SessionEventStepQueued, step, map[string]interface{}{"external": job.External})
+
+	return &domain.WorkflowStepResponse{
+		Step:   step,
+		Queued: true,
+	}, nil
+}
+
+// enqueueJob queues a stored step for execution
+func (s *WorkflowService) enqueueJob(ctx context.Context, step *domain.WorkflowStep, input interface{}, timeout time.Duration, external bool) error {
+	if _, err := s.storeJob(ctx, step, input, timeout, external); err != nil {
+		return err
+	}
+	s.recordStepEvent(ctx, domain.SessionEventStepQueued, step, map[string]interface{}{"external": external})
+	return nil
+}
+
+// storeJob records the job that runs a step; it joins a transaction started
+// by the transactor
+func (s *WorkflowService) storeJob(ctx context.Context, step *domain.WorkflowStep, input interface{}, timeout time.Duration, external bool) (*domain.StepJob, error) {
+	now := time.Now()
+	job := &domain.StepJob{
+		ID:        uuid.New(),
+		StepID:    step.ID,
+		Namespace: step.Namespace,
+		Input:     input,
+		Timeout:   timeout,
+		External:  external,
+		Status:    domain.JobQueued,
+		RunAt:     now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.jobRepo.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue step: %w", err)
+	}
+	return job, nil
+}
+
+// RunJob executes a queued step on behalf of a background worker
+func (s *WorkflowService) RunJob(ctx context.Context, job *domain.StepJob) error {
+	ctx = domain.WithPrincipal(ctx, &domain.Principal{Namespace: job.Namespace})
+
+	step, err := s.workflowRepo.GetStep(ctx, job.StepID)
+	if err != nil {
+		return fmt.Errorf("failed to get step: %w", err)
+	}
+	if step == nil {
+		return domain.NewNotFoundError("step", job.StepID)
+	}
+	if step.Status == domain.StepCompleted {
+		// Already finished by an earlier attempt whose job update was lost
+		return nil
+	}
+
+	step.Status = domain.StepRunning
+	if err := s.workflowRepo.UpdateStep(ctx, step); err != nil {
+		return fmt.Errorf("failed to update step: %w", err)
+	}
+
+	// Each job run is a single attempt; retries are rescheduled through the queue
+	_, attempt, err := s.attemptStep(ctx, step, job.Input, job.Timeout)
+	if err != nil {
+		if errors.Is(err, domain.ErrStepInterrupted) {
+			// Requeue at once so this or another instance resumes the step
+			return &domain.RetryLaterError{Err: err}
+		}
+		policy := s.retryPolicies.For(step.StepType)
+		if policy.ShouldRetry(attempt, err) {
+			return &domain.RetryLaterError{Err: err, After: policy.Backoff(attempt)}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *WorkflowService) GetStep(ctx context.Context, id uuid.UUID) (*domain.WorkflowStepResponse, error) {
+	step, err := s.workflowRepo.GetStep(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step: %w", err)
+	}
+	if step == nil {
+		return nil, domain.NewNotFoundError("step", id)
+	}
+
+	response := &domain.WorkflowStepResponse{Step: step}
+	if step.ArtifactID != uuid.Nil {
+		artifact, err := s.artifactRepo.GetByID(ctx, step.ArtifactID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get artifact: %w", err)
+		}
+		response.Artifact = artifact
+	}
+
+	return response, nil
+}
+
+// runStep executes a stored step, retrying failed attempts per the step type's policy
+func (s *WorkflowService) runStep(ctx context.Context, step *domain.WorkflowStep, input interface{}, timeout time.Duration) (*domain.Artifact, error) {
+	policy := s.retryPolicies.For(step.StepType)
+
+	stopHeartbeat := s.heartbeat(ctx, step)
+	defer stopHeartbeat()
+
+	for {
+		artifact, attempt, err := s.attemptStep(ctx, step, input, timeout)
+		if err == nil {
+			return artifact, nil
+		}
+		if errors.Is(err, domain.ErrStepInterrupted) {
+			// The caller can't be answered, but the step resumes from the queue
+			if err := s.enqueueJob(context.WithoutCancel(ctx), step, input, timeout, false); err != nil {
+				logging.FromContext(ctx).WithError(err).WithField("step_id", step.ID).Error("Failed to queue interrupted step")
+			}
+			return nil, err
+		}
+		if !policy.ShouldRetry(attempt, err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			step.Status = domain.StepFailed
+			s.workflowRepo.UpdateStep(context.WithoutCancel(ctx), step)
+			return nil, ctx.Err()
+		case <-time.After(policy.Backoff(attempt)):
 		}
 	}
+}
+
+// heartbeat renews the step's heartbeat while it executes inline, so recovery
+// on another instance doesn't fail a step that is still running. The returned
+// function stops it
+func (s *WorkflowService) heartbeat(ctx context.Context, step *domain.WorkflowStep) func() {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.cfg.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.workflowRepo.HeartbeatStep(ctx, step.ID); err != nil && ctx.Err() == nil {
+					logging.FromContext(ctx).WithError(err).WithField("step_id", step.ID).Warn("Failed to renew step heartbeat")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// attemptStep runs one execution attempt, records it in the step's attempt
+// history, and persists the artifact and step status
+func (s *WorkflowService) attemptStep(ctx context.Context, step *domain.WorkflowStep, input interface{}, timeout time.Duration) (*domain.Artifact, int, error) {
+	attempt := domain.StepAttempt{
+		Attempt:   len(stepAttempts(step)) + 1,
+		StartedAt: time.Now(),
+	}
+
+	execCtx, done, err := s.executions.Track(ctx, step.ID)
+	if err != nil {
+		// Shutdown began after the step was admitted
+		s.finishAttempt(ctx, step, attempt, nil, domain.ErrStepInterrupted)
+		return nil, attempt.Attempt, domain.ErrStepInterrupted
+	}
+	defer done()
+
+	s.recordStepEvent(ctx, domain.SessionEventStepStarted, step, map[string]interface{}{"attempt": attempt.Attempt})
+
+	execCtx = domain.WithChunkAttempt(execCtx, step.ID, attempt.Attempt)
+	meterCtx, meter := domain.WithUsageMeter(execCtx)
+	artifact, err := s.produceArtifact(meterCtx, step, input, timeout)
+	step.Usage.Add(meter.Total())
+	if err := s.finishAttempt(ctx, step, attempt, artifact, err); err != nil {
+		return nil, attempt.Attempt, err
+	}
+	s.indexStepInput(ctx, step, input)
+
+	return artifact, attempt.Attempt, nil
+}
+
+// finishAttempt records an attempt's outcome on the step, moving it to completed,
+// retrying, timed_out or failed, and notifies webhooks of terminal outcomes
+func (s *WorkflowService) finishAttempt(ctx context.Context, step *domain.WorkflowStep, attempt domain.StepAttempt, artifact *domain.Artifact, err error) error {
+	attempt.FinishedAt = time.Now()
+
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.Code = domain.ErrorCodeOf(err)
+		recordAttempt(step, attempt)
+
+		switch {
+		case errors.Is(err, domain.ErrStepInterrupted):
+			step.Status = domain.StepInterrupted
+		case s.retryPolicies.For(step.StepType).ShouldRetry(attempt.Attempt, err):
+			step.Status = domain.StepRetrying
+		case attempt.Code == domain.CodeTimeout:
+			step.Status = domain.StepTimedOut
+		default:
+			step.Status = domain.StepFailed
+		}
+		// Record the outcome even if the caller has gone away
+		s.workflowRepo.UpdateStep(context.WithoutCancel(ctx), step)
+		eventType := domain.SessionEventStepFailed
+		switch step.Status {
+		case domain.StepRetrying:
+			eventType = domain.SessionEventStepRetrying
+		case domain.StepInterrupted:
+			eventType = domain.SessionEventStepInterrupted
+		}
+		s.recordStepEvent(ctx, eventType, step, map[string]interface{}{
+			"attempt": attempt.Attempt,
+			"status":  step.Status,
+			"code":    attempt.Code,
+			"error":   attempt.Error,
+		})
+		if step.Status == domain.StepFailed || step.Status == domain.StepTimedOut {
+			s.notifyStep(context.WithoutCancel(ctx), domain.EventStepFailed, step)
+			s.negativeCache.Record(ctx, domain.NegativeStepFailure, stepFailureKey(step.StepType, step.InputHash), attempt.Error, attempt.Code)
+		}
+		return err
+	}
+
+	recordAttempt(step, attempt)
 
 	// Update step
 	step.ArtifactID = artifact.ID
@@ -142,44 +869,195 @@ func (s *WorkflowService) ExecuteStep(ctx context.Context, req *domain.WorkflowS
 	now := time.Now()
 	step.CompletedAt = &now
 
-	if err := s.workflowRepo.UpdateStep(ctx, step); err != nil {
-		return nil, fmt.Errorf("failed to update step: %w", err)
+	// The artifact and the step's completion are written together, so a crash
+	// leaves neither an artifact without its step nor a step without its artifact
+	// A forced run replaces only the results its cache scope would have reused
+	var supersede domain.StepReuseFilter
+	if step.Forced {
+		if supersede, err = s.supersedeFilter(ctx, step); err != nil {
+			return err
+		}
 	}
 
-	return &domain.WorkflowStepResponse{
-		Step:     step,
-		Artifact: artifact,
-		Cached:   false,
-	}, nil
+	var superseded int64
+	reused := reusedArtifact(artifact, attempt)
+	err = s.transactor.InTx(ctx, func(ctx context.Context) error {
+		if !reused {
+			artifact.Indexed = len(artifact.Embedding) > 0
+			if err := s.artifactRepo.Store(ctx, artifact); err != nil {
+				return fmt.Errorf("failed to store artifact: %w", err)
+			}
+		}
+		if err := s.workflowRepo.UpdateStep(ctx, step); err != nil {
+			return fmt.Errorf("failed to update step: %w", err)
+		}
+		if step.Forced {
+			// Only a successful forced run replaces the cached result
+			var err error
+			if superseded, err = s.workflowRepo.SupersedeSteps(ctx, step, supersede); err != nil {
+				return fmt.Errorf("failed to supersede cached steps: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !reused {
+		s.storeStepVector(ctx, step, artifact)
+	}
+	if superseded > 0 {
+		s.recordStepEvent(ctx, domain.SessionEventStepSuperseded, step, map[string]interface{}{"superseded": superseded})
+	}
+	s.negativeCache.Forget(ctx, domain.NegativeStepFailure, stepFailureKey(step.StepType, step.InputHash))
+	s.recordStepEvent(ctx, domain.SessionEventStepCompleted, step, map[string]interface{}{
+		"attempt":     attempt.Attempt,
+		"artifact_id": artifact.ID,
+	})
+	s.notifyStep(ctx, domain.EventStepCompleted, step)
+
+	return nil
 }
 
-func (s *WorkflowService) LookupStep(ctx context.Context, req *domain.WorkflowLookupRequest) (*domain.WorkflowLookupResponse, error) {
-	// Generate embedding for the input
-	inputText := fmt.Sprintf("%v", req.Input)
-	embedding, err := s.embeddingService.GenerateEmbedding(ctx, inputText)
+// produceArtifact executes the step's processor under the attempt timeout and
+// returns the resulting artifact, validated, only if the processor finished in
+// time. finishAttempt stores it
+func (s *WorkflowService) produceArtifact(ctx context.Context, step *domain.WorkflowStep, input interface{}, timeout time.Duration) (*domain.Artifact, error) {
+	execCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	artifact, err := s.runProcessor(execCtx, step, input)
+
+	// A processor that ignores its context may still return after the deadline
+	if execCtx.Err() != nil {
+		if errors.Is(context.Cause(execCtx), domain.ErrStepInterrupted) {
+			return nil, domain.ErrStepInterrupted
+		}
+		if errors.Is(execCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, domain.NewTimeoutError("step", timeout)
+		}
+		return nil, fmt.Errorf("step execution cancelled: %w", execCtx.Err())
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate embedding: %w", err)
+		return nil, fmt.Errorf("failed to execute step: %w", err)
+	}
+
+	if err := s.schemaService.ValidateOutput(ctx, step.StepType, artifact.Content); err != nil {
+		return nil, err
 	}
 
-	// Search for similar steps
-	results, err := s.workflowRepo.FindSimilarSteps(ctx, req.StepType, embedding, req.TopK)
+	return artifact, nil
+}
+
+// runProcessor executes the step with the processor registered for its type,
+// simulating types without one. Output whose content is already stored as a
+// fresh artifact of the same type reuses that artifact instead of embedding
+// and storing a copy
+func (s *WorkflowService) runProcessor(ctx context.Context, step *domain.WorkflowStep, input interface{}) (*domain.Artifact, error) {
+	processor, ok := s.processors[step.StepType]
+	if !ok {
+		return s.simulateStepExecution(ctx, step, input)
+	}
+
+	output, err := processor.Process(ctx, step, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find similar steps: %w", err)
+		return nil, err
 	}
 
-	// Enrich with artifact data
-	for i, result := range results {
-		if result.Step.ArtifactID != uuid.Nil {
-			artifact, err := s.artifactRepo.GetByID(ctx, result.Step.ArtifactID)
-			if err == nil {
-				results[i].Artifact = artifact
-			}
+	existing, err := s.artifactRepo.GetByContentHash(ctx, s.hashService.ComputeContentHash([]byte(output.Content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing artifact: %w", err)
+	}
+	if existing != nil && !existing.Stale && existing.Type == output.Type {
+		// Offloaded content isn't loaded with the artifact
+		if len(existing.Content) == 0 {
+			existing.Content = []byte(output.Content)
 		}
+		return existing, nil
 	}
 
-	return &domain.WorkflowLookupResponse{
-		Results: results,
-	}, nil
+	return s.newStepArtifact(ctx, step, output.Type, []byte(output.Content), output.Metadata)
+}
+
+// reusedArtifact reports whether a step's artifact predates the attempt, i.e.
+// the output was deduplicated to stored content, which is then not stored again
+func reusedArtifact(artifact *domain.Artifact, attempt domain.StepAttempt) bool {
+	return artifact.CreatedAt.Before(attempt.StartedAt)
+}
+
+// storeStepVector stores the vector of a step's committed artifact. A vector
+// that can't be stored now stays queued for the outbox relay
+func (s *WorkflowService) storeStepVector(ctx context.Context, step *domain.WorkflowStep, artifact *domain.Artifact) {
+	if len(artifact.Embedding) > 0 {
+		if err := s.vectorRepo.Store(ctx, artifact.ID, artifact.Embedding, vectorPayload(artifact)); err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("artifact_id", artifact.ID).Warn("Failed to store vector of step artifact; it stays queued for retry")
+		}
+	}
+	s.lookupCache.Invalidate(step.Namespace)
+	s.recordStepEvent(ctx, domain.SessionEventArtifactPublished, step, map[string]interface{}{
+		"artifact_id":  artifact.ID,
+		"content_hash": artifact.ContentHash,
+	})
+	s.webhooks.Notify(nil, &domain.WebhookEvent{
+		ID:          uuid.New(),
+		Type:        domain.EventArtifactPublished,
+		Namespace:   step.Namespace,
+		SessionID:   &step.SessionID,
+		ArtifactIDs: []uuid.UUID{artifact.ID},
+		CreatedAt:   time.Now(),
+	})
+}
+
+// RecoverAbandonedSteps fails unfinished steps that no job will resume and whose
+// heartbeat is older than age, typically inline steps of an instance that
+// crashed mid-execution, so sessions don't wait on them forever
+func (s *WorkflowService) RecoverAbandonedSteps(ctx context.Context, age time.Duration) (int, error) {
+	steps, err := s.workflowRepo.FailAbandonedSteps(ctx, time.Now().Add(-age))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail abandoned steps: %w", err)
+	}
+
+	for _, step := range steps {
+		stepCtx := domain.WithPrincipal(ctx, &domain.Principal{Namespace: step.Namespace})
+		s.recordStepEvent(stepCtx, domain.SessionEventStepFailed, step, map[string]interface{}{
+			"status": step.Status,
+			"code":   domain.CodeInternal,
+			"error":  "step execution was abandoned",
+		})
+		s.notifyStep(stepCtx, domain.EventStepFailed, step)
+	}
+	return len(steps), nil
+}
+
+// parseStepTimeout parses a step request timeout; an empty timeout means none
+func parseStepTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, domain.NewValidationError("timeout must be a positive duration such as \"30s\"")
+	}
+	return timeout, nil
+}
+
+// stepAttempts returns the attempt history stored in the step's metadata
+func stepAttempts(step *domain.WorkflowStep) []interface{} {
+	attempts, _ := step.Metadata["attempts"].([]interface{})
+	return attempts
+}
+
+func recordAttempt(step *domain.WorkflowStep, attempt domain.StepAttempt) {
+	if step.Metadata == nil {
+		step.Metadata = make(map[string]interface{})
+	}
+	step.Metadata["attempts"] = append(stepAttempts(step), attempt)
 }
 
 func (s *WorkflowService) CompleteSession(ctx context.Context, sessionID uuid.UUID) error {
@@ -189,13 +1067,19 @@ func (s *WorkflowService) CompleteSession(ctx context.Context, sessionID uuid.UU
 	}
 
 	if session == nil {
-		return fmt.Errorf("session not found")
+		return domain.NewNotFoundError("session", sessionID)
 	}
 
 	session.Status = domain.SessionCompleted
 	session.UpdatedAt = time.Now()
 
-	return s.workflowRepo.UpdateSession(ctx, session)
+	if err := s.workflowRepo.UpdateSession(ctx, session); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, session.ID, domain.SessionEventCompleted, nil, nil)
+	s.notifySession(domain.EventSessionCompleted, session)
+
+	return nil
 }
 
 func (s *WorkflowService) FailSession(ctx context.Context, sessionID uuid.UUID, reason string) error {
@@ -205,7 +1089,7 @@ func (s *WorkflowService) FailSession(ctx context.Context, sessionID uuid.UUID,
 	}
 
 	if session == nil {
-		return fmt.Errorf("session not found")
+		return domain.NewNotFoundError("session", sessionID)
 	}
 
 	session.Status = domain.SessionFailed
@@ -215,7 +1099,60 @@ func (s *WorkflowService) FailSession(ctx context.Context, sessionID uuid.UUID,
 	}
 	session.Context["failure_reason"] = reason
 
-	return s.workflowRepo.UpdateSession(ctx, session)
+	if err := s.workflowRepo.UpdateSession(ctx, session); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, session.ID, domain.SessionEventFailed, nil, map[string]interface{}{"reason": reason})
+	s.notifySession(domain.EventSessionFailed, session)
+
+	return nil
+}
+
+// notifyStep sends a step event to the callback URLs of the step's session
+func (s *WorkflowService) notifyStep(ctx context.Context, eventType domain.WebhookEventType, step *domain.WorkflowStep) {
+	session, err := s.workflowRepo.GetSession(ctx, step.SessionID)
+	if err != nil || session == nil {
+		logging.FromContext(ctx).WithError(err).WithField("step_id", step.ID).Warn("Failed to load session for step webhook")
+		return
+	}
+
+	s.webhooks.Notify(session.CallbackURLs, &domain.WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		Namespace: step.Namespace,
+		SessionID: &step.SessionID,
+		Step:      step,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *WorkflowService) notifySession(eventType domain.WebhookEventType, session *domain.WorkflowSession) {
+	s.webhooks.Notify(session.CallbackURLs, &domain.WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		Namespace: session.Namespace,
+		SessionID: &session.ID,
+		Session:   session,
+		CreatedAt: time.Now(),
+	})
+}
+
+// validateCallbackURLs requires absolute http(s) URLs the guard doesn't refuse
+func validateCallbackURLs(urls []string, guard *egress.Guard) error {
+	if len(urls) > domain.MaxCallbackURLs {
+		return domain.NewValidationError(fmt.Sprintf("at most %d callback URLs are allowed", domain.MaxCallbackURLs))
+	}
+
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return domain.NewValidationError(fmt.Sprintf("invalid callback URL %q", raw))
+		}
+		if err := guard.CheckURL(u); err != nil {
+			return domain.NewValidationError(fmt.Sprintf("callback URL %q targets a private address", raw))
+		}
+	}
+	return nil
 }
 
 // simulateStepExecution simulates the execution of a workflow step
@@ -223,13 +1160,6 @@ func (s *WorkflowService) FailSession(ctx context.Context, sessionID uuid.UUID,
 func (s *WorkflowService) simulateStepExecution(ctx context.Context, step *domain.WorkflowStep, input interface{}) (*domain.Artifact, error) {
 	// Create a mock artifact based on the step type
 	content := fmt.Sprintf("Result of %s step with input: %v", step.StepType, input)
-	contentBytes := []byte(content)
-
-	// Generate embedding
-	embedding, err := s.embeddingService.GenerateEmbedding(ctx, content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate embedding: %w", err)
-	}
 
 	// Determine artifact type based on step type
 	var artifactType domain.ArtifactType
@@ -246,21 +1176,55 @@ func (s *WorkflowService) simulateStepExecution(ctx context.Context, step *domai
 		artifactType = domain.DERIVED
 	}
 
+	// Stream the output word by word, as an LLM-backed processor would stream tokens
+	for _, word := range strings.SplitAfter(content, " ") {
+		domain.EmitChunk(ctx, word)
+	}
+
+	return s.newStepArtifact(ctx, step, artifactType, []byte(content), nil)
+}
+
+// newStepArtifact wraps step output in an artifact linked to the step, applies
+// the PII policy to it, and embeds it
+func (s *WorkflowService) newStepArtifact(ctx context.Context, step *domain.WorkflowStep, artifactType domain.ArtifactType, content []byte, metadata map[string]interface{}) (*domain.Artifact, error) {
+	artifactMetadata := make(map[string]interface{}, len(metadata)+3)
+	for k, v := range metadata {
+		artifactMetadata[k] = v
+	}
+	artifactMetadata["step_type"] = step.StepType
+	artifactMetadata["step_id"] = step.ID.String()
+	artifactMetadata["session_id"] = step.SessionID.String()
+
 	artifact := &domain.Artifact{
-		ID:          uuid.New(),
-		Type:        artifactType,
-		ContentHash: s.hashService.ComputeContentHash(contentBytes),
-		Content:     contentBytes,
-		Embedding:   embedding,
-		Metadata: map[string]interface{}{
-			"step_type":  step.StepType,
-			"step_id":    step.ID.String(),
-			"session_id": step.SessionID.String(),
-		},
+		ID:        uuid.New(),
+		Namespace: step.Namespace,
+		Type:      artifactType,
+		Content:   content,
+		Metadata:  artifactMetadata,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Stale:     false,
 	}
+	if err := s.artifactTTLs.Apply(artifact, artifact.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	// Apply the PII policy first so the hash and embedding describe the redacted content
+	if err := enforcePIIPolicy(s.contentScanner, artifact); err != nil {
+		return nil, err
+	}
+	artifact.ContentHash = s.hashService.ComputeContentHash(artifact.Content)
+
+	if err := s.quotaService.ConsumeEmbeddings(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	// Generate embedding
+	embedding, err := s.embeddingService.GenerateEmbedding(ctx, string(artifact.Content))
+	if err != nil {
+		return nil, domain.NewUpstreamError("embedding provider", err)
+	}
+	artifact.Embedding = embedding
 
 	return artifact, nil
-}
\ No newline at end of file
+}
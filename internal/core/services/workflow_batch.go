@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// ExecuteSteps runs a batch of steps for one session, starting each step once
+// its dependencies have completed and bounding how many run at once
+func (s *WorkflowService) ExecuteSteps(ctx context.Context, req *domain.BatchStepRequest) (*domain.BatchStepResponse, error) {
+	if err := s.validateBatch(req); err != nil {
+		return nil, err
+	}
+
+	// The session must exist in the caller's namespace
+	session, err := s.workflowRepo.GetSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return nil, domain.NewNotFoundError("session", req.SessionID)
+	}
+
+	hits, err := s.prefetchCachedSteps(ctx, session, req.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	parallelism := s.cfg.BatchParallelism
+	if req.Parallelism > 0 && (parallelism <= 0 || req.Parallelism < parallelism) {
+		parallelism = req.Parallelism
+	}
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	results := make([]domain.BatchStepResult, len(req.Steps))
+	done := make(map[string]chan struct{}, len(req.Steps))
+	for _, step := range req.Steps {
+		if step.Key != "" {
+			done[step.Key] = make(chan struct{})
+		}
+	}
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i := range req.Steps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			step := req.Steps[i]
+			result := &results[i]
+			result.Key = step.Key
+			if step.Key != "" {
+				defer close(done[step.Key])
+			}
+
+			// Dependencies close their channel once their result is written
+			for _, dep := range step.DependsOn {
+				<-done[dep]
+				if results[indexOfKey(req.Steps, dep)].Error != nil {
+					result.Error = &domain.StepError{
+						Code:    domain.CodeValidation,
+						Message: fmt.Sprintf("dependency %q did not complete", dep),
+					}
+					return
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				result.Error = toStepError(ctx.Err())
+				return
+			}
+
+			stepReq := step.WorkflowStepRequest
+			stepReq.SessionID = req.SessionID
+			response, err := s.executeStep(ctx, &stepReq, hits)
+			if err != nil {
+				result.Error = toStepError(err)
+				return
+			}
+			result.Result = response
+			if response.KnownFailure != nil {
+				// Dependents must not run after a step that was skipped as a known failure
+				result.Error = &domain.StepError{
+					Code:    response.KnownFailure.Code,
+					Message: "input failed recently and was not retried",
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	return &domain.BatchStepResponse{Results: results}, nil
+}
+
+// stepCacheHits holds step cache lookups a batch made up front, keyed by
+// stepCacheKey. A key that is present was checked; its step is nil when
+// nothing could be reused
+type stepCacheHits map[string]*domain.WorkflowStep
+
+func stepCacheKey(stepType, inputHash string) string {
+	return stepType + ":" + inputHash
+}
+
+// prefetchCachedSteps checks the step cache for a whole batch with one query
+// per step type instead of one per step. Step types a template does not allow
+// are left for executeStep to reject
+func (s *WorkflowService) prefetchCachedSteps(ctx context.Context, session *domain.WorkflowSession, steps []domain.BatchStep) (stepCacheHits, error) {
+	var template *domain.WorkflowTemplate
+	if session.TemplateName != "" {
+		var err error
+		template, err = s.GetTemplate(ctx, session.TemplateName, session.TemplateVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hashesByType := make(map[string][]string)
+	for _, step := range steps {
+		if step.StepType == "" || step.Force {
+			continue
+		}
+		if template != nil && !template.AllowsStep(step.StepType) {
+			continue
+		}
+		hashesByType[step.StepType] = append(hashesByType[step.StepType], s.hashService.ComputeInputHash(step.Input))
+	}
+
+	hits := make(stepCacheHits)
+	for stepType, hashes := range hashesByType {
+		var compat *domain.TemplateCompatibility
+		if template != nil {
+			compat = &domain.TemplateCompatibility{
+				Name:       template.Name,
+				MinVersion: template.CompatibleFrom,
+				MaxVersion: template.Version,
+			}
+		}
+		filter, err := s.reuseFilter(ctx, session, stepType, compat)
+		if err != nil {
+			return nil, err
+		}
+		found, err := s.workflowRepo.FindStepsByInputHashes(ctx, stepType, hashes, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check cached steps: %w", err)
+		}
+		for _, hash := range hashes {
+			hits[stepCacheKey(stepType, hash)] = found[hash]
+		}
+	}
+
+	return hits, nil
+}
+
+// validateBatch checks batch size, step keys and that dependencies only point at earlier steps
+func (s *WorkflowService) validateBatch(req *domain.BatchStepRequest) error {
+	if len(req.Steps) == 0 {
+		return domain.NewValidationError("steps are required")
+	}
+	if s.cfg.MaxBatchSize > 0 && len(req.Steps) > s.cfg.MaxBatchSize {
+		return domain.NewValidationError(fmt.Sprintf("a batch may contain at most %d steps", s.cfg.MaxBatchSize))
+	}
+
+	seen := make(map[string]bool, len(req.Steps))
+	for i, step := range req.Steps {
+		if step.Async {
+			return domain.NewValidationError("async steps are not supported in a batch").WithDetail("index", i)
+		}
+		for _, dep := range step.DependsOn {
+			// Requiring earlier keys also rules out dependency cycles
+			if !seen[dep] {
+				return domain.NewValidationError(fmt.Sprintf("step depends on unknown or later step %q", dep)).WithDetail("index", i)
+			}
+		}
+		if step.Key != "" {
+			if seen[step.Key] {
+				return domain.NewValidationError(fmt.Sprintf("duplicate step key %q", step.Key)).WithDetail("index", i)
+			}
+			seen[step.Key] = true
+		}
+	}
+
+	return nil
+}
+
+func indexOfKey(steps []domain.BatchStep, key string) int {
+	for i, step := range steps {
+		if step.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// toStepError converts err to a client-safe per-step error, hiding internal error text
+func toStepError(err error) *domain.StepError {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return &domain.StepError{Code: domainErr.Code, Message: domainErr.Message}
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return &domain.StepError{Code: domain.CodeTimeout, Message: "step was cancelled"}
+	}
+	logrus.WithError(err).Error("Batch step failed")
+	return &domain.StepError{Code: domain.CodeInternal, Message: "internal server error"}
+}
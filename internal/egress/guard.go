@@ -0,0 +1,92 @@
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// ErrBlockedAddress is returned for connections to an address the guard refuses
+var ErrBlockedAddress = errors.New("address is not allowed")
+
+// Guard keeps requests to URLs callers supply, such as webhooks, refresh hooks,
+// feeds and scraped pages, from reaching the server's own network. Loopback,
+// private, link-local and unspecified addresses are refused as connections are
+// dialed, after DNS resolution, so a hostname can't be pointed at them either
+type Guard struct {
+	allowPrivate bool
+}
+
+func NewGuard(cfg config.EgressConfig) *Guard {
+	return &Guard{allowPrivate: cfg.AllowPrivateNetworks}
+}
+
+// Client returns an HTTP client whose connections the guard checks. Without
+// followRedirects a redirect is returned as the response rather than followed.
+// Proxies from the environment are not used, since the guard would only see
+// the proxy's address
+func (g *Guard) Client(timeout time.Duration, followRedirects bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !g.allowPrivate {
+		dialer.Control = g.control
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	client := &http.Client{Timeout: timeout, Transport: otelhttp.NewTransport(transport)}
+	if !followRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// CheckURL rejects a URL whose host is itself a refused address, so it fails
+// when it is registered rather than on every request. Hostnames are checked
+// when they are dialed
+func (g *Guard) CheckURL(u *url.URL) error {
+	if g.allowPrivate {
+		return nil
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("%s: %w", host, ErrBlockedAddress)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && blocked(ip) {
+		return fmt.Errorf("%s: %w", host, ErrBlockedAddress)
+	}
+	return nil
+}
+
+// control runs before each connection, with the resolved address
+func (g *Guard) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if blocked(ip) {
+		return fmt.Errorf("%s: %w", host, ErrBlockedAddress)
+	}
+	return nil
+}
+
+func blocked(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const timestampFormat = "2006-01-02T15:04:05.000Z"
+
+// Setup configures the standard logger's level, format, and output. An unknown
+// level falls back to info; an unknown format or output is an error
+func Setup(cfg config.LogConfig) error {
+	level, err := logrus.ParseLevel(cfg.Level)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+
+	var formatter logrus.Formatter
+	switch cfg.Format {
+	case "json":
+		formatter = &logrus.JSONFormatter{TimestampFormat: timestampFormat}
+	case "text":
+		formatter = &logrus.TextFormatter{TimestampFormat: timestampFormat, FullTimestamp: true}
+	default:
+		return fmt.Errorf("unsupported log format: %s", cfg.Format)
+	}
+	if len(cfg.ExcludeFields) > 0 {
+		formatter = newFieldFilter(formatter, cfg.ExcludeFields)
+	}
+
+	var output io.Writer
+	switch cfg.Output {
+	case "stdout":
+		output = os.Stdout
+	case "file", "both":
+		file, err := rotatingFile(cfg.File)
+		if err != nil {
+			return err
+		}
+		output = file
+		if cfg.Output == "both" {
+			output = io.MultiWriter(os.Stdout, file)
+		}
+	default:
+		return fmt.Errorf("unsupported log output: %s", cfg.Output)
+	}
+
+	logrus.SetLevel(level)
+	logrus.SetFormatter(formatter)
+	logrus.SetOutput(output)
+	return nil
+}
+
+// rotatingFile writes to cfg.Path, rotating it by size and pruning old files by
+// age and count. The file is opened once up front so a bad path fails at startup
+// rather than on the first log entry
+func rotatingFile(cfg config.LogFileConfig) (io.Writer, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("log file path is required for file output")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	file.Close()
+
+	return &lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxAge:     cfg.MaxAgeDays,
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+		LocalTime:  true,
+	}, nil
+}
+
+// fieldFilter drops excluded fields before formatting
+type fieldFilter struct {
+	logrus.Formatter
+	exclude map[string]struct{}
+}
+
+func newFieldFilter(formatter logrus.Formatter, fields []string) *fieldFilter {
+	exclude := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		exclude[field] = struct{}{}
+	}
+	return &fieldFilter{Formatter: formatter, exclude: exclude}
+}
+
+func (f *fieldFilter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		if _, ok := f.exclude[key]; !ok {
+			data[key] = value
+		}
+	}
+	filtered := *entry
+	filtered.Data = data
+	return f.Formatter.Format(&filtered)
+}
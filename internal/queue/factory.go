@@ -0,0 +1,34 @@
+package queue
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/queue/redis"
+	"github.com/anunay/mentis/internal/storage/postgres"
+)
+
+// Backend represents the durable queue tasks are dispatched through
+type Backend string
+
+const (
+	BackendNone     Backend = ""
+	BackendPostgres Backend = "postgres"
+	BackendRedis    Backend = "redis"
+)
+
+// NewTaskQueue creates a queue on the configured backend, or nil when no durable queue is configured
+func NewTaskQueue(cfg *config.QueueConfig, db *sql.DB) (ports.TaskQueue, error) {
+	switch Backend(cfg.Backend) {
+	case BackendNone:
+		return nil, nil
+	case BackendPostgres:
+		return postgres.NewTaskQueue(db, cfg.LeaseTimeout), nil
+	case BackendRedis:
+		return redis.NewQueue(*cfg)
+	default:
+		return nil, fmt.Errorf("unsupported queue backend: %s", cfg.Backend)
+	}
+}
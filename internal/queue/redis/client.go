@@ -0,0 +1,292 @@
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout  = 5 * time.Second
+	defaultPoolSize = 10
+)
+
+var errClientClosed = errors.New("redis: client is closed")
+
+// replyError is an error reply from the server, such as a failed command. The
+// connection stays usable after one
+type replyError string
+
+func (e replyError) Error() string {
+	return "redis: " + string(e)
+}
+
+// client sends commands to a Redis server over RESP from a pool of
+// connections, one command at a time on each. Connections are dialled as
+// commands need them, up to the pool size; a command waits for one to be
+// returned beyond that. A connection that fails is closed rather than reused
+type client struct {
+	address  string
+	useTLS   bool
+	username string
+	password string
+	database int
+	// timeout bounds connecting and commands not bounded by a context deadline
+	timeout time.Duration
+
+	// slots holds a token for every connection in use, bounding them to the
+	// pool size; idle ones wait in idle until the next command
+	slots  chan struct{}
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// conn is one connection to the server
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// newClient parses a redis:// or rediss:// (TLS) URL with optional credentials
+// and database number, such as redis://:password@host:6379/0
+func newClient(rawURL string, timeout time.Duration, poolSize int) (*client, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "redis" && parsed.Scheme != "rediss") {
+		return nil, fmt.Errorf("invalid Redis URL: %s", rawURL)
+	}
+
+	address := parsed.Host
+	if parsed.Port() == "" {
+		address = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+	database := 0
+	if path := strings.Trim(parsed.Path, "/"); path != "" {
+		if database, err = strconv.Atoi(path); err != nil {
+			return nil, fmt.Errorf("invalid Redis database: %s", path)
+		}
+	}
+	password, _ := parsed.User.Password()
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if poolSize <= 0 {
+		poolSize = defaultPoolSize
+	}
+
+	return &client{
+		address:  address,
+		useTLS:   parsed.Scheme == "rediss",
+		username: parsed.User.Username(),
+		password: password,
+		database: database,
+		timeout:  timeout,
+		slots:    make(chan struct{}, poolSize),
+	}, nil
+}
+
+// do sends a command and returns its reply: a string for simple and bulk
+// strings, an int64, a []interface{} for arrays, or nil
+func (c *client) do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.roundTrip(ctx, args, c.timeout)
+	var replyErr replyError
+	if err != nil && !errors.As(err, &replyErr) {
+		c.discard(cn)
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close closes the idle connections; those in use are closed as they return
+func (c *client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	var firstErr error
+	for _, cn := range c.idle {
+		if err := cn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.idle = nil
+	return firstErr
+}
+
+// get takes an idle connection, or dials one when none is idle
+func (c *client) get(ctx context.Context) (*conn, error) {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to get a Redis connection: %w", ctx.Err())
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		<-c.slots
+		return nil, errClientClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	cn, err := c.connect(ctx)
+	if err != nil {
+		<-c.slots
+		return nil, err
+	}
+	return cn, nil
+}
+
+// put returns a healthy connection to the pool
+func (c *client) put(cn *conn) {
+	c.mu.Lock()
+	if c.closed {
+		cn.Close()
+	} else {
+		c.idle = append(c.idle, cn)
+	}
+	c.mu.Unlock()
+	<-c.slots
+}
+
+// discard closes a connection whose state is unknown after a failure
+func (c *client) discard(cn *conn) {
+	cn.Close()
+	<-c.slots
+}
+
+// connect dials the server, then authenticates and selects the database
+func (c *client) connect(ctx context.Context) (*conn, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	var netConn net.Conn
+	var err error
+	if c.useTLS {
+		host, _, _ := net.SplitHostPort(c.address)
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", c.address)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", c.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	cn := &conn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	switch {
+	case c.username != "" && c.password != "":
+		setup = append(setup, []string{"AUTH", c.username, c.password})
+	case c.password != "":
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.database != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.database)})
+	}
+	for _, args := range setup {
+		if _, err := cn.roundTrip(ctx, args, c.timeout); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("failed to set up Redis connection: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+func (cn *conn) roundTrip(ctx context.Context, args []string, timeout time.Duration) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+	cn.SetDeadline(deadline)
+	defer cn.SetDeadline(time.Time{})
+
+	if _, err := cn.Write(encodeCommand(args)); err != nil {
+		return nil, fmt.Errorf("failed to send Redis command: %w", err)
+	}
+
+	return readReply(cn.reader)
+}
+
+// encodeCommand writes a command as a RESP array of bulk strings
+func encodeCommand(args []string) []byte {
+	var command bytes.Buffer
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return command.Bytes()
+}
+
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty Redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, replyError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis bulk length: %s", line)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, fmt.Errorf("failed to read Redis reply: %w", err)
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis array length: %s", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readReply(reader); err != nil {
+				var replyErr replyError
+				if !errors.As(err, &replyErr) {
+					return nil, err
+				}
+				items[i] = err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected Redis reply: %s", line)
+	}
+}
@@ -0,0 +1,321 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer answers RESP commands with the replies handle returns; an empty
+// reply closes the connection instead
+type fakeServer struct {
+	listener net.Listener
+	handle   func(args []string) string
+
+	mu       sync.Mutex
+	commands [][]string
+	accepted int
+}
+
+func newFakeServer(t *testing.T, handle func(args []string) string) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeServer{listener: listener, handle: handle}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeServer) url(userinfo, path string) string {
+	return "redis://" + userinfo + s.listener.Addr().String() + path
+}
+
+func (s *fakeServer) serve() {
+	for {
+		cn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.accepted++
+		s.mu.Unlock()
+		go s.serveConn(cn)
+	}
+}
+
+func (s *fakeServer) serveConn(cn net.Conn) {
+	defer cn.Close()
+	reader := bufio.NewReader(cn)
+	for {
+		request, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		items, _ := request.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		s.mu.Unlock()
+
+		reply := s.handle(args)
+		if reply == "" {
+			return
+		}
+		if _, err := cn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) stats() ([][]string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.commands...), s.accepted
+}
+
+func replyOK(args []string) string {
+	return "+OK\r\n"
+}
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{"simple string", "+OK\r\n", "OK"},
+		{"integer", ":42\r\n", int64(42)},
+		{"negative integer", ":-1\r\n", int64(-1)},
+		{"bulk string", "$5\r\nhello\r\n", "hello"},
+		{"empty bulk string", "$0\r\n\r\n", ""},
+		{"bulk string with line breaks", "$4\r\na\r\nb\r\n", "a\r\nb"},
+		{"null bulk string", "$-1\r\n", nil},
+		{"null array", "*-1\r\n", nil},
+		{"empty array", "*0\r\n", []interface{}{}},
+		{"array", "*2\r\n$1\r\na\r\n:1\r\n", []interface{}{"a", int64(1)}},
+		{"nested array", "*2\r\n*1\r\n+x\r\n$-1\r\n", []interface{}{[]interface{}{"x"}, nil}},
+		{"array with error", "*2\r\n-ERR no\r\n+OK\r\n", []interface{}{replyError("ERR no"), "OK"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readReply(bufio.NewReader(strings.NewReader(tt.input)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReadReplyErrors(t *testing.T) {
+	reply, err := readReply(bufio.NewReader(strings.NewReader("-WRONGTYPE bad key\r\n")))
+	var replyErr replyError
+	if reply != nil || !errors.As(err, &replyErr) || string(replyErr) != "WRONGTYPE bad key" {
+		t.Fatalf("expected a reply error, got %v, %v", reply, err)
+	}
+
+	for _, input := range []string{"", "\r\n", "?x\r\n", ":x\r\n", "$x\r\n", "$5\r\nab", "*x\r\n", "*2\r\n+a\r\n"} {
+		_, err := readReply(bufio.NewReader(strings.NewReader(input)))
+		if err == nil || errors.As(err, &replyErr) {
+			t.Errorf("expected a protocol error for %q, got %v", input, err)
+		}
+	}
+}
+
+func TestEncodeCommand(t *testing.T) {
+	got := string(encodeCommand([]string{"SET", "k", "a\r\nb", ""}))
+	want := "*4\r\n$3\r\nSET\r\n$1\r\nk\r\n$4\r\na\r\nb\r\n$0\r\n\r\n"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestClientAuthenticatesAndSelectsDatabase(t *testing.T) {
+	server := newFakeServer(t, replyOK)
+	c, err := newClient(server.url("user:secret@", "/2"), time.Second, 2)
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.do(context.Background(), "PING"); err != nil {
+			t.Fatalf("do: %v", err)
+		}
+	}
+
+	commands, accepted := server.stats()
+	want := [][]string{{"AUTH", "user", "secret"}, {"SELECT", "2"}, {"PING"}, {"PING"}}
+	if !reflect.DeepEqual(commands, want) {
+		t.Fatalf("expected %v, got %v", want, commands)
+	}
+	if accepted != 1 {
+		t.Fatalf("expected the connection to be reused, got %d connections", accepted)
+	}
+}
+
+func TestClientKeepsConnectionAfterReplyError(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string {
+		if args[0] == "BAD" {
+			return "-ERR unknown command\r\n"
+		}
+		return "+OK\r\n"
+	})
+	c, err := newClient(server.url("", ""), time.Second, 1)
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	defer c.Close()
+
+	var replyErr replyError
+	if _, err := c.do(context.Background(), "BAD"); !errors.As(err, &replyErr) {
+		t.Fatalf("expected a reply error, got %v", err)
+	}
+	if reply, err := c.do(context.Background(), "PING"); err != nil || reply != "OK" {
+		t.Fatalf("expected OK, got %v, %v", reply, err)
+	}
+	if _, accepted := server.stats(); accepted != 1 {
+		t.Fatalf("expected one connection, got %d", accepted)
+	}
+}
+
+func TestClientReconnectsAfterConnectionFailure(t *testing.T) {
+	var mu sync.Mutex
+	dropped := false
+	server := newFakeServer(t, func(args []string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if !dropped {
+			dropped = true
+			return ""
+		}
+		return "+OK\r\n"
+	})
+	c, err := newClient(server.url("", ""), time.Second, 1)
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.do(context.Background(), "PING"); err == nil {
+		t.Fatal("expected the dropped connection to fail the command")
+	}
+	if reply, err := c.do(context.Background(), "PING"); err != nil || reply != "OK" {
+		t.Fatalf("expected OK after reconnecting, got %v, %v", reply, err)
+	}
+	if _, accepted := server.stats(); accepted != 2 {
+		t.Fatalf("expected a second connection, got %d", accepted)
+	}
+}
+
+func TestClientPoolBoundsConnections(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+	server := newFakeServer(t, func(args []string) string {
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return ":1\r\n"
+	})
+	c, err := newClient(server.url("", ""), time.Second, 2)
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.do(context.Background(), "INCR", "k"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("do: %v", err)
+	}
+
+	_, accepted := server.stats()
+	if accepted > 2 || peak > 2 {
+		t.Fatalf("expected at most 2 connections, got %d opened and %d in use at once", accepted, peak)
+	}
+	if peak < 2 {
+		t.Fatalf("expected commands to run on both connections at once, peak was %d", peak)
+	}
+}
+
+func TestClientWaitsForConnectionUntilContextEnds(t *testing.T) {
+	release := make(chan struct{})
+	server := newFakeServer(t, func(args []string) string {
+		<-release
+		return "+OK\r\n"
+	})
+	defer close(release)
+	c, err := newClient(server.url("", ""), time.Second, 1)
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	defer c.Close()
+
+	go c.do(context.Background(), "SLOW")
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.do(ctx, "PING"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait for a connection to time out, got %v", err)
+	}
+}
+
+func TestClientRejectsCommandsOnceClosed(t *testing.T) {
+	server := newFakeServer(t, replyOK)
+	c, err := newClient(server.url("", ""), time.Second, 1)
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	if _, err := c.do(context.Background(), "PING"); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := c.do(context.Background(), "PING"); !errors.Is(err, errClientClosed) {
+		t.Fatalf("expected errClientClosed, got %v", err)
+	}
+}
+
+func TestNewClientRejectsInvalidURLs(t *testing.T) {
+	for _, rawURL := range []string{"", "localhost:6379", "http://localhost", "redis://localhost/x"} {
+		if _, err := newClient(rawURL, 0, 0); err == nil {
+			t.Errorf("expected %q to be rejected", rawURL)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// consumerGroup is the stream consumer group every replica reads in, so each
+// task goes to one of them
+const consumerGroup = "mentis"
+
+// promoteScript moves delayed tasks that are due onto their stream
+const promoteScript = `
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, task in ipairs(due) do
+	redis.call('XADD', KEYS[2], '*', 'task', task)
+	redis.call('ZREM', KEYS[1], task)
+end
+return #due
+`
+
+// envelope is a task as stored in Redis
+type envelope struct {
+	ID      string `json:"id"`
+	Payload []byte `json:"payload"`
+	Attempt int    `json:"attempt"`
+}
+
+// Queue is a durable task queue on Redis Streams. Each queue is a stream read
+// through a consumer group; entries a consumer leaves unacknowledged past the
+// lease are claimed by the next Dequeue. Tasks due later wait in a sorted set
+// until they are moved onto the stream
+type Queue struct {
+	client   *client
+	prefix   string
+	consumer string
+	lease    time.Duration
+
+	mu     sync.Mutex
+	groups map[string]bool
+}
+
+func NewQueue(cfg config.QueueConfig) (*Queue, error) {
+	client, err := newClient(cfg.URL, cfg.Timeout, cfg.PoolSize)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	return &Queue{
+		client:   client,
+		prefix:   cfg.Prefix,
+		consumer: hostname + "-" + uuid.NewString()[:8],
+		lease:    cfg.LeaseTimeout,
+		groups:   make(map[string]bool),
+	}, nil
+}
+
+func (q *Queue) Enqueue(ctx context.Context, queue string, payload []byte, runAt time.Time) error {
+	return q.add(ctx, queue, envelope{ID: uuid.NewString(), Payload: payload, Attempt: 1}, runAt)
+}
+
+func (q *Queue) Dequeue(ctx context.Context, queue string, limit int) ([]*domain.QueuedTask, error) {
+	stream := q.streamKey(queue)
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	if _, err := q.client.do(ctx, "EVAL", promoteScript, "2", q.delayedKey(queue), stream, now, strconv.Itoa(limit)); err != nil {
+		return nil, fmt.Errorf("failed to promote delayed tasks: %w", err)
+	}
+	if err := q.ensureGroup(ctx, queue); err != nil {
+		return nil, err
+	}
+
+	// Entries another consumer leased and abandoned come first
+	reply, err := q.client.do(ctx, "XAUTOCLAIM", stream, consumerGroup, q.consumer,
+		strconv.FormatInt(q.lease.Milliseconds(), 10), "0-0", "COUNT", strconv.Itoa(limit))
+	if err != nil {
+		return nil, q.groupError(queue, fmt.Errorf("failed to claim expired tasks: %w", err))
+	}
+	var entries []interface{}
+	if claimed, ok := reply.([]interface{}); ok && len(claimed) > 1 {
+		entries, _ = claimed[1].([]interface{})
+	}
+
+	if len(entries) < limit {
+		reply, err := q.client.do(ctx, "XREADGROUP", "GROUP", consumerGroup, q.consumer,
+			"COUNT", strconv.Itoa(limit-len(entries)), "STREAMS", stream, ">")
+		if err != nil {
+			return nil, q.groupError(queue, fmt.Errorf("failed to read tasks: %w", err))
+		}
+		if streams, ok := reply.([]interface{}); ok && len(streams) > 0 {
+			if read, ok := streams[0].([]interface{}); ok && len(read) > 1 {
+				fresh, _ := read[1].([]interface{})
+				entries = append(entries, fresh...)
+			}
+		}
+	}
+
+	var tasks []*domain.QueuedTask
+	for _, entry := range entries {
+		task, err := parseEntry(queue, entry)
+		if err == nil && task.Payload != nil {
+			tasks = append(tasks, task)
+			continue
+		}
+
+		// Entries that can't be read or were deleted would otherwise be claimed forever
+		if err != nil {
+			logrus.WithError(err).WithField("queue", queue).Warn("Dropping undecodable queued task")
+		}
+		if task.Receipt != "" {
+			if err := q.Ack(ctx, task); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func (q *Queue) Ack(ctx context.Context, task *domain.QueuedTask) error {
+	stream := q.streamKey(task.Queue)
+	if _, err := q.client.do(ctx, "XACK", stream, consumerGroup, task.Receipt); err != nil {
+		return fmt.Errorf("failed to acknowledge task: %w", err)
+	}
+	if _, err := q.client.do(ctx, "XDEL", stream, task.Receipt); err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return nil
+}
+
+// Retry queues the next attempt before acknowledging this one, so a failure in
+// between delivers the task twice rather than not at all
+func (q *Queue) Retry(ctx context.Context, task *domain.QueuedTask, runAt time.Time) error {
+	next := envelope{ID: task.ID, Payload: task.Payload, Attempt: task.Attempt + 1}
+	if err := q.add(ctx, task.Queue, next, runAt); err != nil {
+		return err
+	}
+	return q.Ack(ctx, task)
+}
+
+func (q *Queue) Close() error {
+	return q.client.Close()
+}
+
+// add puts a task on its stream, or in the delayed set while it isn't due
+func (q *Queue) add(ctx context.Context, queue string, task envelope, runAt time.Time) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	if runAt.After(time.Now()) {
+		_, err = q.client.do(ctx, "ZADD", q.delayedKey(queue), strconv.FormatInt(runAt.UnixMilli(), 10), string(data))
+	} else {
+		_, err = q.client.do(ctx, "XADD", q.streamKey(queue), "*", "task", string(data))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	return nil
+}
+
+// ensureGroup creates the queue's stream and consumer group on first use
+func (q *Queue) ensureGroup(ctx context.Context, queue string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.groups[queue] {
+		return nil
+	}
+	_, err := q.client.do(ctx, "XGROUP", "CREATE", q.streamKey(queue), consumerGroup, "0", "MKSTREAM")
+	var replyErr replyError
+	if err != nil && !(errors.As(err, &replyErr) && strings.HasPrefix(string(replyErr), "BUSYGROUP")) {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	q.groups[queue] = true
+	return nil
+}
+
+// groupError forgets a consumer group Redis no longer has, such as after the
+// stream was deleted, so the next Dequeue creates it again
+func (q *Queue) groupError(queue string, err error) error {
+	var replyErr replyError
+	if errors.As(err, &replyErr) && strings.HasPrefix(string(replyErr), "NOGROUP") {
+		q.mu.Lock()
+		delete(q.groups, queue)
+		q.mu.Unlock()
+	}
+	return err
+}
+
+// The keys of a queue share a hash tag, keeping them in one cluster slot for
+// the promote script
+func (q *Queue) streamKey(queue string) string {
+	return "{" + q.prefix + ":queue:" + queue + "}"
+}
+
+func (q *Queue) delayedKey(queue string) string {
+	return q.streamKey(queue) + ":delayed"
+}
+
+// parseEntry reads a stream entry, [id, [field, value, ...]]. The task of an
+// entry deleted while pending has no payload. The task is returned with its
+// receipt even when it can't be decoded, so it can be acknowledged
+func parseEntry(queue string, entry interface{}) (*domain.QueuedTask, error) {
+	task := &domain.QueuedTask{Queue: queue}
+	parts, _ := entry.([]interface{})
+	if len(parts) < 2 {
+		return task, nil
+	}
+	task.Receipt, _ = parts[0].(string)
+	fields, _ := parts[1].([]interface{})
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		if name, _ := fields[i].(string); name != "task" {
+			continue
+		}
+		data, _ := fields[i+1].(string)
+		var stored envelope
+		if err := json.Unmarshal([]byte(data), &stored); err != nil {
+			return task, fmt.Errorf("failed to decode task %s: %w", task.Receipt, err)
+		}
+		task.ID, task.Payload, task.Attempt = stored.ID, stored.Payload, stored.Attempt
+		return task, nil
+	}
+	return task, nil
+}
@@ -0,0 +1,218 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+func bulk(value string) string {
+	return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+}
+
+// array encodes a RESP array of already encoded items
+func array(items ...string) string {
+	return "*" + strconv.Itoa(len(items)) + "\r\n" + strings.Join(items, "")
+}
+
+// streamEntry encodes a stream entry holding one task field
+func streamEntry(id, task string) string {
+	return array(bulk(id), array(bulk("task"), bulk(task)))
+}
+
+func encodeTask(t *testing.T, task envelope) string {
+	t.Helper()
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("failed to marshal task: %v", err)
+	}
+	return string(data)
+}
+
+func newTestQueue(t *testing.T, server *fakeServer) *Queue {
+	t.Helper()
+	q, err := NewQueue(config.QueueConfig{
+		URL:          server.url("", ""),
+		Prefix:       "mentis",
+		Timeout:      time.Second,
+		LeaseTimeout: 30 * time.Second,
+		PoolSize:     1,
+	})
+	if err != nil {
+		t.Fatalf("NewQueue: %v", err)
+	}
+	t.Cleanup(func() { q.Close() })
+	return q
+}
+
+// commandNames returns the name of each command the server received
+func commandNames(commands [][]string) []string {
+	names := make([]string, len(commands))
+	for i, command := range commands {
+		names[i] = command[0]
+	}
+	return names
+}
+
+func TestQueueEnqueue(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string { return bulk("1-0") })
+	q := newTestQueue(t, server)
+
+	runAt := time.Now().Add(time.Hour)
+	if err := q.Enqueue(context.Background(), "webhooks", []byte("now"), time.Time{}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := q.Enqueue(context.Background(), "webhooks", []byte("later"), runAt); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	commands, _ := server.stats()
+	if len(commands) != 2 {
+		t.Fatalf("expected two commands, got %v", commands)
+	}
+
+	due := commands[0]
+	if !reflect.DeepEqual(due[:4], []string{"XADD", "{mentis:queue:webhooks}", "*", "task"}) {
+		t.Fatalf("expected a due task to be added to the stream, got %v", due)
+	}
+	var task envelope
+	if err := json.Unmarshal([]byte(due[4]), &task); err != nil || string(task.Payload) != "now" || task.Attempt != 1 || task.ID == "" {
+		t.Fatalf("expected the first attempt of the task, got %+v, %v", task, err)
+	}
+
+	delayed := commands[1]
+	if !reflect.DeepEqual(delayed[:3], []string{"ZADD", "{mentis:queue:webhooks}:delayed", strconv.FormatInt(runAt.UnixMilli(), 10)}) {
+		t.Fatalf("expected a later task to wait in the delayed set, got %v", delayed)
+	}
+}
+
+func TestQueueDequeue(t *testing.T) {
+	claimed := encodeTask(t, envelope{ID: "a", Payload: []byte("claimed"), Attempt: 2})
+	fresh := encodeTask(t, envelope{ID: "b", Payload: []byte("fresh"), Attempt: 1})
+	server := newFakeServer(t, func(args []string) string {
+		switch args[0] {
+		case "EVAL":
+			return ":0\r\n"
+		case "XGROUP":
+			return "-BUSYGROUP Consumer Group name already exists\r\n"
+		case "XAUTOCLAIM":
+			return array(bulk("0-0"), array(streamEntry("1-0", claimed)), array())
+		case "XREADGROUP":
+			return array(array(bulk("{mentis:queue:webhooks}"), array(
+				streamEntry("2-0", fresh),
+				streamEntry("3-0", "not json"),
+				array(bulk("4-0"), "*-1\r\n"),
+			)))
+		default:
+			return ":1\r\n"
+		}
+	})
+	q := newTestQueue(t, server)
+
+	tasks, err := q.Dequeue(context.Background(), "webhooks", 5)
+	if err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+
+	want := []*domain.QueuedTask{
+		{ID: "a", Queue: "webhooks", Payload: []byte("claimed"), Attempt: 2, Receipt: "1-0"},
+		{ID: "b", Queue: "webhooks", Payload: []byte("fresh"), Attempt: 1, Receipt: "2-0"},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Fatalf("expected %+v, got %+v", want, tasks)
+	}
+
+	commands, _ := server.stats()
+	wantNames := []string{"EVAL", "XGROUP", "XAUTOCLAIM", "XREADGROUP", "XACK", "XDEL", "XACK", "XDEL"}
+	if names := commandNames(commands); !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("expected %v, got %v", wantNames, names)
+	}
+	if promote := commands[0]; promote[3] != "{mentis:queue:webhooks}:delayed" || promote[4] != "{mentis:queue:webhooks}" {
+		t.Fatalf("expected due tasks to move from the delayed set to the stream, got %v", promote)
+	}
+	if claim := commands[2]; claim[4] != "30000" || claim[7] != "5" {
+		t.Fatalf("expected entries idle past the lease to be claimed, got %v", claim)
+	}
+	if read := commands[3]; read[5] != "4" {
+		t.Fatalf("expected only the remaining capacity to be read, got %v", read)
+	}
+	// Undecodable and deleted entries are acknowledged so they aren't claimed forever
+	if commands[4][3] != "3-0" || commands[6][3] != "4-0" {
+		t.Fatalf("expected the unreadable entries to be acknowledged, got %v", commands[4:])
+	}
+}
+
+func TestQueueDequeueRecreatesMissingGroup(t *testing.T) {
+	var mu sync.Mutex
+	missing := true
+	server := newFakeServer(t, func(args []string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch args[0] {
+		case "EVAL":
+			return ":0\r\n"
+		case "XGROUP":
+			return "+OK\r\n"
+		case "XAUTOCLAIM":
+			if missing {
+				missing = false
+				return "-NOGROUP No such key or consumer group\r\n"
+			}
+			return array(bulk("0-0"), array(), array())
+		default:
+			return "*-1\r\n"
+		}
+	})
+	q := newTestQueue(t, server)
+
+	if _, err := q.Dequeue(context.Background(), "webhooks", 1); err == nil {
+		t.Fatal("expected the missing group to fail the dequeue")
+	}
+	if _, err := q.Dequeue(context.Background(), "webhooks", 1); err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+	if _, err := q.Dequeue(context.Background(), "webhooks", 1); err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+
+	commands, _ := server.stats()
+	groups := 0
+	for _, name := range commandNames(commands) {
+		if name == "XGROUP" {
+			groups++
+		}
+	}
+	if groups != 2 {
+		t.Fatalf("expected the group to be created again once after NOGROUP, got %d creations", groups)
+	}
+}
+
+func TestQueueRetryAddsNextAttemptBeforeAcknowledging(t *testing.T) {
+	server := newFakeServer(t, func(args []string) string { return ":1\r\n" })
+	q := newTestQueue(t, server)
+
+	task := &domain.QueuedTask{ID: "a", Queue: "webhooks", Payload: []byte("p"), Attempt: 1, Receipt: "1-0"}
+	if err := q.Retry(context.Background(), task, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+
+	commands, _ := server.stats()
+	if names := commandNames(commands); !reflect.DeepEqual(names, []string{"ZADD", "XACK", "XDEL"}) {
+		t.Fatalf("expected the next attempt to be queued before the ack, got %v", names)
+	}
+	var next envelope
+	if err := json.Unmarshal([]byte(commands[0][3]), &next); err != nil || next.ID != "a" || next.Attempt != 2 {
+		t.Fatalf("expected the second attempt of the same task, got %+v, %v", next, err)
+	}
+	if !reflect.DeepEqual(commands[1], []string{"XACK", "{mentis:queue:webhooks}", consumerGroup, "1-0"}) {
+		t.Fatalf("expected the delivery to be acknowledged, got %v", commands[1])
+	}
+}
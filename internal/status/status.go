@@ -0,0 +1,121 @@
+package status
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// MaxWindow is the longest span of history kept for each component
+const MaxWindow = time.Hour
+
+// Dependencies tracked from the spans of calls made to them
+const (
+	Postgres  = "postgres"
+	Vector    = "vector"
+	Embedding = "embedding"
+)
+
+const bucketCount = int(MaxWindow / time.Minute)
+
+// bucket counts outcomes within one minute
+type bucket struct {
+	minute     int64
+	operations int64
+	errors     int64
+}
+
+type component struct {
+	buckets       [bucketCount]bucket
+	lastError     string
+	lastErrorAt   time.Time
+	lastSuccessAt time.Time
+}
+
+var (
+	mu         sync.Mutex
+	components = map[string]*component{}
+)
+
+// Track lists a component before its first outcome, so a job that has not run
+// yet still shows up
+func Track(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	lookup(name)
+}
+
+// Record counts one call or job pass of a component; a nil err is a success
+func Record(name string, err error) {
+	now := time.Now().UTC()
+	minute := now.Unix() / 60
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	c := lookup(name)
+	b := &c.buckets[minute%int64(bucketCount)]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.operations++
+	if err != nil {
+		b.errors++
+		c.lastError = err.Error()
+		c.lastErrorAt = now
+	} else {
+		c.lastSuccessAt = now
+	}
+}
+
+// RecordRun records a background job pass, ignoring passes cut short by shutdown
+func RecordRun(ctx context.Context, name string, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	Record(name, err)
+}
+
+// Snapshot reports every component's outcomes within window, sorted by name.
+// State is left for the caller to decide
+func Snapshot(window time.Duration) []domain.ComponentStatus {
+	now := time.Now().UTC().Unix() / 60
+	minutes := int64(min(window, MaxWindow) / time.Minute)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	statuses := make([]domain.ComponentStatus, 0, len(components))
+	for name, c := range components {
+		status := domain.ComponentStatus{Name: name, LastError: c.lastError}
+		for _, b := range c.buckets {
+			if now-b.minute < minutes {
+				status.Operations += b.operations
+				status.Errors += b.errors
+			}
+		}
+		if !c.lastErrorAt.IsZero() {
+			lastErrorAt := c.lastErrorAt
+			status.LastErrorAt = &lastErrorAt
+		}
+		if !c.lastSuccessAt.IsZero() {
+			lastSuccessAt := c.lastSuccessAt
+			status.LastSuccessAt = &lastSuccessAt
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func lookup(name string) *component {
+	c, ok := components[name]
+	if !ok {
+		c = &component{}
+		components[name] = c
+	}
+	return c
+}
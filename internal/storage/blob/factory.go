@@ -0,0 +1,33 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/ports"
+	"github.com/anunay/mentis/internal/storage/blob/filesystem"
+	"github.com/anunay/mentis/internal/storage/blob/s3"
+)
+
+// Provider represents the object storage provider
+type Provider string
+
+const (
+	ProviderNone       Provider = ""
+	ProviderS3         Provider = "s3"
+	ProviderFilesystem Provider = "filesystem"
+)
+
+// NewBlobStore creates a blob store for the configured provider, or nil when offloading is disabled
+func NewBlobStore(cfg *config.BlobConfig) (ports.BlobStore, error) {
+	switch Provider(cfg.Provider) {
+	case ProviderNone:
+		return nil, nil
+	case ProviderS3:
+		return s3.NewStore(cfg.S3)
+	case ProviderFilesystem:
+		return filesystem.NewStore(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unsupported blob provider: %s", cfg.Provider)
+	}
+}
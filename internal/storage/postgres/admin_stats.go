@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type AdminStatsRepository struct {
+	db *sql.DB
+}
+
+func NewAdminStatsRepository(db *sql.DB) *AdminStatsRepository {
+	return &AdminStatsRepository{db: db}
+}
+
+// ArtifactStats counts artifacts per type, including soft-deleted ones, and sums
+// their content bytes by where the content is stored
+func (r *AdminStatsRepository) ArtifactStats(ctx context.Context, namespace string) ([]domain.ArtifactTypeStats, domain.StorageStats, error) {
+	query := `
+		SELECT type,
+			COUNT(*) FILTER (WHERE deleted_at IS NULL AND NOT COALESCE(stale, false)),
+			COUNT(*) FILTER (WHERE deleted_at IS NULL AND COALESCE(stale, false)),
+			COUNT(*) FILTER (WHERE deleted_at IS NOT NULL),
+			COALESCE(SUM(content_size), 0),
+			COALESCE(SUM(content_size) FILTER (WHERE content_ref IS NOT NULL), 0)
+		FROM artifacts
+		WHERE $1 = '' OR namespace = $1
+		GROUP BY type
+		ORDER BY type
+	`
+
+	var storage domain.StorageStats
+	rows, err := r.db.QueryContext(ctx, query, namespace)
+	if err != nil {
+		return nil, storage, err
+	}
+	defer rows.Close()
+
+	stats := []domain.ArtifactTypeStats{}
+	for rows.Next() {
+		var typeStats domain.ArtifactTypeStats
+		var blobBytes int64
+		if err := rows.Scan(&typeStats.Type, &typeStats.Fresh, &typeStats.Stale, &typeStats.Deleted, &typeStats.Bytes, &blobBytes); err != nil {
+			return nil, storage, err
+		}
+		stats = append(stats, typeStats)
+		storage.TotalBytes += typeStats.Bytes
+		storage.BlobBytes += blobBytes
+	}
+	storage.InlineBytes = storage.TotalBytes - storage.BlobBytes
+
+	return stats, storage, rows.Err()
+}
+
+func (r *AdminStatsRepository) SessionCounts(ctx context.Context, namespace string) (map[domain.SessionStatus]int64, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM workflow_sessions
+		WHERE $1 = '' OR namespace = $1
+		GROUP BY status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.SessionStatus]int64)
+	for rows.Next() {
+		var status domain.SessionStatus
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// StepCounts counts steps per type and status, ordered by step type
+func (r *AdminStatsRepository) StepCounts(ctx context.Context, namespace string) ([]domain.StepTypeStats, error) {
+	query := `
+		SELECT step_type, status, COUNT(*)
+		FROM workflow_steps
+		WHERE $1 = '' OR namespace = $1
+		GROUP BY step_type, status
+		ORDER BY step_type, status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []domain.StepTypeStats{}
+	for rows.Next() {
+		var stepType string
+		var status domain.StepStatus
+		var count int64
+		if err := rows.Scan(&stepType, &status, &count); err != nil {
+			return nil, err
+		}
+		if len(stats) == 0 || stats[len(stats)-1].StepType != stepType {
+			stats = append(stats, domain.StepTypeStats{StepType: stepType, ByStatus: make(map[domain.StepStatus]int64)})
+		}
+		typeStats := &stats[len(stats)-1]
+		typeStats.ByStatus[status] = count
+		typeStats.Total += count
+	}
+
+	return stats, rows.Err()
+}
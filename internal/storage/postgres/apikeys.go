@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Store(ctx context.Context, key *domain.APIKey) error {
+	artifactTypes := make([]string, len(key.ArtifactTypes))
+	for i, t := range key.ArtifactTypes {
+		artifactTypes[i] = string(t)
+	}
+	operations := make([]string, len(key.Operations))
+	for i, op := range key.Operations {
+		operations[i] = string(op)
+	}
+
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, namespaces, artifact_types, operations, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Namespaces,
+		artifactTypes,
+		operations,
+		key.CreatedAt,
+		key.ExpiresAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, key_hash, namespaces, artifact_types, operations, created_at, expires_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1
+	`
+
+	row := r.db.QueryRowContext(ctx, query, keyHash)
+	return r.scanAPIKey(row)
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, key_hash, namespaces, artifact_types, operations, created_at, expires_at, revoked_at
+		FROM api_keys
+		WHERE id = $1
+	`
+
+	row := r.db.QueryRowContext(ctx, query, id)
+	return r.scanAPIKey(row)
+}
+
+func (r *APIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, key_hash, namespaces, artifact_types, operations, created_at, expires_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := r.scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewNotFoundError("api key", id)
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) scanAPIKey(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var artifactTypes, operations []string
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		textArray(&key.Namespaces),
+		textArray(&artifactTypes),
+		textArray(&operations),
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, t := range artifactTypes {
+		key.ArtifactTypes = append(key.ArtifactTypes, domain.ArtifactType(t))
+	}
+	for _, op := range operations {
+		key.Operations = append(key.Operations, domain.Operation(op))
+	}
+
+	return &key, nil
+}
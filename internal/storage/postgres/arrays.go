@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// textArray scans a text[] column into a string slice; NULL scans as nil.
+// Slices passed as query parameters need no wrapper
+func textArray(dest *[]string) sql.Scanner {
+	return arrayScanner[string]{oid: pgtype.TextArrayOID, dest: dest}
+}
+
+// float4Array scans a real[] column into a float32 slice; NULL scans as nil
+func float4Array(dest *[]float32) sql.Scanner {
+	return arrayScanner[float32]{oid: pgtype.Float4ArrayOID, dest: dest}
+}
+
+// arrayTypes decodes array columns. A Map caches scan plans and is not safe
+// for concurrent use, so scans share it under a lock
+var (
+	arrayTypes   = pgtype.NewMap()
+	arrayTypesMu sync.Mutex
+)
+
+// arrayScanner decodes the text form in which array columns reach database/sql
+type arrayScanner[T any] struct {
+	oid  uint32
+	dest *[]T
+}
+
+func (s arrayScanner[T]) Scan(src any) error {
+	var text []byte
+	switch src := src.(type) {
+	case nil:
+		*s.dest = nil
+		return nil
+	case string:
+		text = []byte(src)
+	case []byte:
+		text = src
+	default:
+		return fmt.Errorf("cannot scan %T into an array", src)
+	}
+	arrayTypesMu.Lock()
+	defer arrayTypesMu.Unlock()
+	return arrayTypes.Scan(s.oid, pgtype.TextFormatCode, text, s.dest)
+}
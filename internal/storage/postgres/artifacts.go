@@ -11,33 +11,56 @@ import (
 )
 
 type ArtifactRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts *statements
 }
 
 func NewArtifactRepository(db *sql.DB) *ArtifactRepository {
-	return &ArtifactRepository{db: db}
+	return &ArtifactRepository{db: db, stmts: newStatements(db)}
 }
 
+// Store writes an artifact and, when it has an embedding, queues its vector in
+// the outbox in the same statement, so the vector is stored eventually even if
+// the upsert that follows fails
 func (r *ArtifactRepository) Store(ctx context.Context, artifact *domain.Artifact) error {
 	metadataJSON, err := json.Marshal(artifact.Metadata)
 	if err != nil {
 		return err
 	}
 
+	// A newer embedding replaces a queued one and is attempted again right away
 	query := `
-		INSERT INTO artifacts (id, type, content_hash, content, metadata, created_at, updated_at, stale)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		ON CONFLICT (id) DO UPDATE SET
-			type = EXCLUDED.type,
-			content_hash = EXCLUDED.content_hash,
-			content = EXCLUDED.content,
-			metadata = EXCLUDED.metadata,
-			updated_at = EXCLUDED.updated_at,
-			stale = EXCLUDED.stale
+		WITH stored AS (
+			INSERT INTO artifacts (id, namespace, type, content_hash, content, metadata, created_at, updated_at, stale, expires_at, pinned, tags, content_ref, content_size, indexed, api_key_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12::text[], '{}'), NULLIF($13, ''), $14, $15, $17)
+			ON CONFLICT (id) DO UPDATE SET
+				type = EXCLUDED.type,
+				content_hash = EXCLUDED.content_hash,
+				content = EXCLUDED.content,
+				content_ref = EXCLUDED.content_ref,
+				content_size = EXCLUDED.content_size,
+				metadata = EXCLUDED.metadata,
+				updated_at = EXCLUDED.updated_at,
+				stale = EXCLUDED.stale,
+				expires_at = EXCLUDED.expires_at,
+				pinned = EXCLUDED.pinned,
+				tags = EXCLUDED.tags,
+				indexed = EXCLUDED.indexed
+			WHERE artifacts.namespace = EXCLUDED.namespace
+			RETURNING id
+		), queued AS (
+			INSERT INTO vector_outbox (artifact_id, embedding)
+			SELECT id, $16::real[] FROM stored WHERE $16::real[] IS NOT NULL
+			ON CONFLICT (artifact_id) DO UPDATE SET
+				embedding = EXCLUDED.embedding, queued_at = NOW(), attempts = 0, next_attempt_at = NOW(), last_error = NULL
+		)
+		SELECT COUNT(*) FROM stored
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	var stored int
+	err = conn(ctx, r.db).QueryRowContext(ctx, query,
 		artifact.ID,
+		artifact.Namespace,
 		artifact.Type,
 		artifact.ContentHash,
 		artifact.Content,
@@ -45,41 +68,173 @@ func (r *ArtifactRepository) Store(ctx context.Context, artifact *domain.Artifac
 		artifact.CreatedAt,
 		artifact.UpdatedAt,
 		artifact.Stale,
-	)
-	return err
+		artifact.ExpiresAt,
+		artifact.Pinned,
+		artifact.Tags,
+		artifact.ContentRef,
+		contentSize(artifact),
+		artifact.Indexed,
+		outboxEmbedding(artifact),
+		storingKey(ctx),
+	).Scan(&stored)
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	// The ID is taken by an artifact of another namespace, which is left alone
+	if stored == 0 {
+		return domain.NewConflictError("artifact ID already exists", nil).WithDetail("id", artifact.ID.String())
+	}
+	return nil
 }
 
-func (r *ArtifactRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
-	query := `
-		SELECT id, type, content_hash, content, metadata, created_at, updated_at, stale
+// storingKey is the issued API key an artifact's quota is charged to; an
+// update keeps the key that first stored it
+func storingKey(ctx context.Context) *uuid.UUID {
+	if id, ok := domain.APIKeyIDFromContext(ctx); ok {
+		return &id
+	}
+	return nil
+}
+
+// outboxEmbedding is the embedding queued with an artifact; nil queues nothing
+func outboxEmbedding(artifact *domain.Artifact) []float32 {
+	if len(artifact.Embedding) == 0 {
+		return nil
+	}
+	return artifact.Embedding
+}
+
+// Lookups enrich every result by ID and every publish checks its content hash,
+// so both run as prepared statements
+const (
+	getArtifactByIDQuery = `
+		SELECT id, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
+		FROM artifacts
+		WHERE id = $1 AND namespace = $2 AND deleted_at IS NULL
+	`
+	getArtifactMetadataByIDQuery = `
+		SELECT id, namespace, type, content_hash, NULL::bytea, COALESCE(content_ref, ''),
+			CASE WHEN content_ref IS NULL THEN COALESCE(octet_length(content), 0) ELSE content_size END,
+			metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
 		FROM artifacts
-		WHERE id = $1
+		WHERE id = $1 AND namespace = $2 AND deleted_at IS NULL
 	`
+	getArtifactByContentHashQuery = `
+		SELECT id, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
+		FROM artifacts
+		WHERE content_hash = $1 AND namespace = $2 AND deleted_at IS NULL
+	`
+)
 
-	row := r.db.QueryRowContext(ctx, query, id)
+func (r *ArtifactRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	row := r.stmts.queryRow(ctx, getArtifactByIDQuery, id, domain.NamespaceFromContext(ctx))
+	return r.scanArtifact(row)
+}
+
+// GetMetadataByID returns an artifact without its content. Inline content is
+// sized from the stored value, so callers can stream it with OpenContent
+func (r *ArtifactRepository) GetMetadataByID(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	row := r.stmts.queryRow(ctx, getArtifactMetadataByIDQuery, id, domain.NamespaceFromContext(ctx))
 	return r.scanArtifact(row)
 }
 
 func (r *ArtifactRepository) GetByContentHash(ctx context.Context, hash string) (*domain.Artifact, error) {
+	row := r.stmts.queryRow(ctx, getArtifactByContentHashQuery, hash, domain.NamespaceFromContext(ctx))
+	return r.scanArtifact(row)
+}
+
+// ExistingContentHashes returns which of the hashes belong to an undeleted artifact
+func (r *ArtifactRepository) ExistingContentHashes(ctx context.Context, hashes []string) ([]string, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
 	query := `
-		SELECT id, type, content_hash, content, metadata, created_at, updated_at, stale
+		SELECT DISTINCT content_hash
 		FROM artifacts
-		WHERE content_hash = $1
+		WHERE content_hash = ANY($1) AND namespace = $2 AND deleted_at IS NULL
 	`
 
-	row := r.db.QueryRowContext(ctx, query, hash)
-	return r.scanArtifact(row)
+	rows, err := r.db.QueryContext(ctx, query, hashes, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		existing = append(existing, hash)
+	}
+
+	return existing, rows.Err()
+}
+
+// SearchText ranks artifacts whose content matches a web-search style query,
+// best first. Chunk matches count toward their parent, so artifacts whose
+// content was offloaded are found through their chunks
+func (r *ArtifactRepository) SearchText(ctx context.Context, query string, limit int, filter domain.TextSearchFilter) ([]domain.TextMatch, error) {
+	tags := filter.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	// Chunks are filtered by their parent's metadata
+	args := []interface{}{query, filter.Namespaces, string(filter.ArtifactType), tags, filter.IncludeStale, limit}
+	conditions, args, err := metadataConditions(filter.Conditions, "COALESCE(p.metadata, a.metadata)", args)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `
+		SELECT COALESCE((a.metadata->>'chunk_of')::uuid, a.id), a.namespace, MAX(ts_rank_cd(a.search_vector, q)) AS rank
+		FROM artifacts a
+		CROSS JOIN websearch_to_tsquery('english', $1) q
+		LEFT JOIN artifacts p ON p.id = (a.metadata->>'chunk_of')::uuid
+		WHERE a.search_vector @@ q AND a.namespace = ANY($2) AND a.deleted_at IS NULL
+			AND ($3 = '' OR a.type = $3)
+			AND (cardinality($4::text[]) = 0 OR a.tags && $4::text[])
+			AND ($5 OR NOT a.stale)
+			AND ` + conditions + `
+		GROUP BY 1, 2
+		ORDER BY rank DESC
+		LIMIT $6
+	`
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []domain.TextMatch
+	for rows.Next() {
+		var match domain.TextMatch
+		if err := rows.Scan(&match.ID, &match.Namespace, &match.Rank); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
 }
 
-func (r *ArtifactRepository) List(ctx context.Context, limit, offset int) ([]*domain.Artifact, error) {
+// List returns artifacts newest first, starting after the given position
+func (r *ArtifactRepository) List(ctx context.Context, limit int, after *domain.PageKey) ([]*domain.Artifact, error) {
 	query := `
-		SELECT id, type, content_hash, content, metadata, created_at, updated_at, stale
+		SELECT id, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
 		FROM artifacts
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		WHERE namespace = $2 AND deleted_at IS NULL
+			AND ($3::timestamptz IS NULL OR (created_at, id) < ($3::timestamptz, $4::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	afterTime, afterID := pageKeyArgs(after)
+	rows, err := r.db.QueryContext(ctx, query, limit, domain.NamespaceFromContext(ctx), afterTime, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +261,7 @@ func (r *ArtifactRepository) Update(ctx context.Context, artifact *domain.Artifa
 	query := `
 		UPDATE artifacts
 		SET type = $2, content_hash = $3, content = $4, metadata = $5, updated_at = $6, stale = $7
-		WHERE id = $1
+		WHERE id = $1 AND namespace = $8
 	`
 
 	_, err = r.db.ExecContext(ctx, query,
@@ -117,90 +272,712 @@ func (r *ArtifactRepository) Update(ctx context.Context, artifact *domain.Artifa
 		metadataJSON,
 		time.Now(),
 		artifact.Stale,
+		domain.NamespaceFromContext(ctx),
 	)
-	return err
+	return mapError(ctx, err)
+}
+
+// GetBySourceURL returns the most recently updated artifact fetched from sourceURL
+func (r *ArtifactRepository) GetBySourceURL(ctx context.Context, sourceURL string) (*domain.Artifact, error) {
+	query := `
+		SELECT id, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
+		FROM artifacts
+		WHERE metadata->>'source_url' = $1 AND namespace = $2 AND deleted_at IS NULL
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, sourceURL, domain.NamespaceFromContext(ctx))
+	return r.scanArtifact(row)
 }
 
+// ListSourceVersions returns, for fresh artifacts fetched from any of the
+// source URLs, the page validators their metadata recorded
+func (r *ArtifactRepository) ListSourceVersions(ctx context.Context, sourceURLs []string) ([]domain.SourceVersion, error) {
+	if len(sourceURLs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT metadata->>'source_url', COALESCE(metadata->>'etag', ''), COALESCE(metadata->>'last_modified', ''),
+			COALESCE(metadata->>'fetched_at', ''), updated_at
+		FROM artifacts
+		WHERE metadata->>'source_url' = ANY($1) AND namespace = $2 AND stale = false AND deleted_at IS NULL
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sourceURLs, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, mapError(ctx, err)
+	}
+	defer rows.Close()
+
+	var versions []domain.SourceVersion
+	for rows.Next() {
+		var version domain.SourceVersion
+		if err := rows.Scan(&version.SourceURL, &version.ETag, &version.LastModified, &version.FetchedAt, &version.UpdatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// GetByUpsertKey returns the newest undeleted artifact whose metadata field key equals value
+func (r *ArtifactRepository) GetByUpsertKey(ctx context.Context, key, value string) (*domain.Artifact, error) {
+	query := `
+		SELECT id, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
+		FROM artifacts
+		WHERE metadata->>$1 = $2 AND namespace = $3 AND deleted_at IS NULL
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`
+
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, key, value, domain.NamespaceFromContext(ctx))
+	return r.scanArtifact(row)
+}
+
+// LockIdentity serialises publishes of the same identity in the caller's
+// namespace until the surrounding transaction ends. Upsert keys name arbitrary
+// metadata fields, so no unique index can cover them
+func (r *ArtifactRepository) LockIdentity(ctx context.Context, identity string) error {
+	query := `SELECT pg_advisory_xact_lock(hashtext('publish:' || $1 || ':' || $2))`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, domain.NamespaceFromContext(ctx), identity)
+	return mapError(ctx, err)
+}
+
+// Republish archives the artifact's current content as a version and replaces it,
+// setting artifact.Version to the new version number. Like Store, it queues the
+// new embedding in the vector outbox
+func (r *ArtifactRepository) Republish(ctx context.Context, artifact *domain.Artifact) error {
+	metadataJSON, err := json.Marshal(artifact.Metadata)
+	if err != nil {
+		return err
+	}
+
+	// The vector of the archived content is dropped from the outbox along with
+	// it; a new embedding is queued in its place
+	query := `
+		WITH archived AS (
+			INSERT INTO artifact_versions (artifact_id, version, namespace, type, content_hash, content, content_ref, content_size, metadata, published_at)
+			SELECT id, version, namespace, type, content_hash, content, content_ref, content_size, metadata, updated_at
+			FROM artifacts
+			WHERE id = $1 AND namespace = $2
+		), dequeued AS (
+			DELETE FROM vector_outbox
+			WHERE artifact_id = $1 AND $13::real[] IS NULL
+				AND EXISTS (SELECT 1 FROM artifacts WHERE id = $1 AND namespace = $2)
+		), queued AS (
+			INSERT INTO vector_outbox (artifact_id, embedding)
+			SELECT id, $13::real[] FROM artifacts WHERE id = $1 AND namespace = $2 AND $13::real[] IS NOT NULL
+			ON CONFLICT (artifact_id) DO UPDATE SET
+				embedding = EXCLUDED.embedding, queued_at = NOW(), attempts = 0, next_attempt_at = NOW(), last_error = NULL
+		)
+		UPDATE artifacts
+		SET type = $3, content_hash = $4, content = $5, metadata = $6, updated_at = $7,
+			stale = false, refresh_attempted_at = NULL, expires_at = $8, tags = COALESCE($9::text[], '{}'), version = version + 1,
+			content_ref = NULLIF($10, ''), content_size = $11, indexed = $12
+		WHERE id = $1 AND namespace = $2
+		RETURNING version
+	`
+
+	err = conn(ctx, r.db).QueryRowContext(ctx, query,
+		artifact.ID,
+		domain.NamespaceFromContext(ctx),
+		artifact.Type,
+		artifact.ContentHash,
+		artifact.Content,
+		metadataJSON,
+		artifact.UpdatedAt,
+		artifact.ExpiresAt,
+		artifact.Tags,
+		artifact.ContentRef,
+		contentSize(artifact),
+		artifact.Indexed,
+		outboxEmbedding(artifact),
+	).Scan(&artifact.Version)
+	if err == sql.ErrNoRows {
+		return domain.NewNotFoundError("artifact", artifact.ID)
+	}
+	return mapError(ctx, err)
+}
+
+// ListVersions returns an artifact's archived versions, newest first, without
+// content, starting below the given version
+func (r *ArtifactRepository) ListVersions(ctx context.Context, artifactID uuid.UUID, limit int, before *int64) ([]*domain.ArtifactVersion, error) {
+	query := `
+		SELECT artifact_id, version, namespace, type, content_hash, NULL::bytea, COALESCE(content_ref, ''), content_size, metadata, published_at, superseded_at
+		FROM artifact_versions
+		WHERE artifact_id = $1 AND namespace = $2
+			AND ($4::bigint IS NULL OR version < $4::bigint)
+		ORDER BY version DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, artifactID, domain.NamespaceFromContext(ctx), limit, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []*domain.ArtifactVersion{}
+	for rows.Next() {
+		version, err := r.scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+func (r *ArtifactRepository) GetVersion(ctx context.Context, artifactID uuid.UUID, version int) (*domain.ArtifactVersion, error) {
+	query := `
+		SELECT artifact_id, version, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, published_at, superseded_at
+		FROM artifact_versions
+		WHERE artifact_id = $1 AND version = $2 AND namespace = $3
+	`
+
+	row := r.db.QueryRowContext(ctx, query, artifactID, version, domain.NamespaceFromContext(ctx))
+	return r.scanVersion(row)
+}
+
+// Delete soft-deletes an artifact; it stays restorable until purged
 func (r *ArtifactRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM artifacts WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	query := `UPDATE artifacts SET deleted_at = NOW() WHERE id = $1 AND namespace = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "artifact", id)
+}
+
+func (r *ArtifactRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE artifacts SET deleted_at = NULL WHERE id = $1 AND namespace = $2 AND deleted_at IS NOT NULL`
+	result, err := r.db.ExecContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "deleted artifact", id)
 }
 
+func (r *ArtifactRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*domain.Artifact, error) {
+	query := `
+		SELECT id, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
+		FROM artifacts
+		WHERE id = $1 AND namespace = $2 AND deleted_at IS NOT NULL
+	`
+
+	row := r.db.QueryRowContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	return r.scanArtifact(row)
+}
+
+// ListDeleted returns restorable artifacts, most recently deleted first
+func (r *ArtifactRepository) ListDeleted(ctx context.Context, limit int, after *domain.PageKey) ([]*domain.Artifact, error) {
+	query := `
+		SELECT id, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
+		FROM artifacts
+		WHERE namespace = $2 AND deleted_at IS NOT NULL
+			AND ($3::timestamptz IS NULL OR (deleted_at, id) < ($3::timestamptz, $4::uuid))
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1
+	`
+
+	afterTime, afterID := pageKeyArgs(after)
+	rows, err := r.db.QueryContext(ctx, query, limit, domain.NamespaceFromContext(ctx), afterTime, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []*domain.Artifact
+	for rows.Next() {
+		artifact, err := r.scanArtifact(rows)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, artifact)
+	}
+
+	return artifacts, rows.Err()
+}
+
+// PurgeDeleted permanently removes up to limit artifacts in any namespace
+// deleted before the given time
+func (r *ArtifactRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) ([]domain.RemovedArtifact, error) {
+	query := `
+		DELETE FROM artifacts
+		WHERE id IN (
+			SELECT id FROM artifacts
+			WHERE deleted_at <= $1
+			ORDER BY deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + removedColumns
+
+	return r.queryRemoved(ctx, query, before, limit)
+}
+
+// StoreDependency links two artifacts of the caller's namespace; IDs outside it are not linked
 func (r *ArtifactRepository) StoreDependency(ctx context.Context, parentID, childID uuid.UUID) error {
 	query := `
 		INSERT INTO artifact_dependencies (parent_id, child_id)
-		VALUES ($1, $2)
+		SELECT p.id, c.id
+		FROM artifacts p, artifacts c
+		WHERE p.id = $1 AND c.id = $2 AND p.namespace = $3 AND c.namespace = $3
 		ON CONFLICT (parent_id, child_id) DO NOTHING
 	`
-	_, err := r.db.ExecContext(ctx, query, parentID, childID)
-	return err
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, parentID, childID, domain.NamespaceFromContext(ctx))
+	return mapError(ctx, err)
 }
 
 func (r *ArtifactRepository) GetDependencies(ctx context.Context, artifactID uuid.UUID) ([]uuid.UUID, error) {
 	query := `
-		SELECT child_id
-		FROM artifact_dependencies
-		WHERE parent_id = $1
+		SELECT d.child_id
+		FROM artifact_dependencies d
+		JOIN artifacts p ON p.id = d.parent_id
+		JOIN artifacts c ON c.id = d.child_id
+		WHERE d.parent_id = $1 AND p.namespace = $2 AND c.namespace = $2
+	`
+
+	return r.queryIDs(ctx, query, artifactID, domain.NamespaceFromContext(ctx))
+}
+
+func (r *ArtifactRepository) GetDependents(ctx context.Context, artifactID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT d.parent_id
+		FROM artifact_dependencies d
+		JOIN artifacts p ON p.id = d.parent_id
+		JOIN artifacts c ON c.id = d.child_id
+		WHERE d.child_id = $1 AND p.namespace = $2 AND c.namespace = $2
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, artifactID)
+	return r.queryIDs(ctx, query, artifactID, domain.NamespaceFromContext(ctx))
+}
+
+func (r *ArtifactRepository) queryIDs(ctx context.Context, query string, args ...interface{}) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var dependencies []uuid.UUID
+	var ids []uuid.UUID
 	for rows.Next() {
-		var depID uuid.UUID
-		if err := rows.Scan(&depID); err != nil {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		dependencies = append(dependencies, depID)
+		ids = append(ids, id)
 	}
 
-	return dependencies, rows.Err()
+	return ids, rows.Err()
 }
 
-func (r *ArtifactRepository) GetDependents(ctx context.Context, artifactID uuid.UUID) ([]uuid.UUID, error) {
+// ListLineageEdges returns the dependency edges leading from ids to their
+// sources when upstream is set, or to their dependents otherwise. Chunk
+// artifacts are not part of an artifact's lineage
+func (r *ArtifactRepository) ListLineageEdges(ctx context.Context, ids []uuid.UUID, upstream bool) ([]domain.LineageEdge, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// The traversed-to artifact is the parent going upstream and the child going downstream
+	known, next := "child_id", "parent_id"
+	if !upstream {
+		known, next = next, known
+	}
 	query := `
-		SELECT parent_id
-		FROM artifact_dependencies
-		WHERE child_id = $1
+		SELECT d.parent_id, d.child_id
+		FROM artifact_dependencies d
+		JOIN artifacts a ON a.id = d.` + next + `
+		WHERE d.` + known + ` = ANY($1::uuid[]) AND a.namespace = $2 AND a.metadata->>'chunk_of' IS NULL
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, artifactID)
+	rows, err := r.db.QueryContext(ctx, query, uuidStrings(ids), domain.NamespaceFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var dependents []uuid.UUID
+	var edges []domain.LineageEdge
 	for rows.Next() {
-		var depID uuid.UUID
-		if err := rows.Scan(&depID); err != nil {
+		var edge domain.LineageEdge
+		if err := rows.Scan(&edge.From, &edge.To); err != nil {
 			return nil, err
 		}
-		dependents = append(dependents, depID)
+		edges = append(edges, edge)
 	}
 
-	return dependents, rows.Err()
+	return edges, rows.Err()
+}
+
+// GetLineageNodes returns lineage nodes for the given artifacts, including deleted ones
+func (r *ArtifactRepository) GetLineageNodes(ctx context.Context, ids []uuid.UUID) ([]*domain.LineageNode, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, type,
+			CASE
+				WHEN deleted_at IS NOT NULL THEN 'deleted'
+				WHEN expires_at <= NOW() THEN 'expired'
+				ELSE 'active'
+			END,
+			stale, version, COALESCE(metadata->>'source_url', ''), created_at, updated_at
+		FROM artifacts
+		WHERE id = ANY($1::uuid[]) AND namespace = $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, uuidStrings(ids), domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []*domain.LineageNode
+	for rows.Next() {
+		var node domain.LineageNode
+		if err := rows.Scan(&node.ID, &node.Type, &node.Status, &node.Stale, &node.Version, &node.SourceURL, &node.CreatedAt, &node.UpdatedAt); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &node)
+	}
+
+	return nodes, rows.Err()
 }
 
 func (r *ArtifactRepository) MarkStale(ctx context.Context, artifactID uuid.UUID) error {
-	query := `UPDATE artifacts SET stale = true, updated_at = NOW() WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, artifactID)
-	return err
+	query := `UPDATE artifacts SET stale = true, refresh_attempted_at = NULL, updated_at = NOW() WHERE id = $1 AND namespace = $2`
+	_, err := r.db.ExecContext(ctx, query, artifactID, domain.NamespaceFromContext(ctx))
+	return mapError(ctx, err)
 }
 
-func (r *ArtifactRepository) MarkStaleBySourceURL(ctx context.Context, sourceURL string) error {
+func (r *ArtifactRepository) MarkStaleBySourceURL(ctx context.Context, sourceURL string) ([]uuid.UUID, error) {
 	query := `
 		UPDATE artifacts
-		SET stale = true, updated_at = NOW()
-		WHERE metadata->>'source_url' = $1
+		SET stale = true, refresh_attempted_at = NULL, updated_at = NOW()
+		WHERE metadata->>'source_url' = $1 AND namespace = $2
+		RETURNING id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sourceURL, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, mapError(ctx, err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// MarkStaleOlderThan marks the namespace's fresh artifacts of a type (any type when
+// empty) last published before the given time stale and returns their IDs
+func (r *ArtifactRepository) MarkStaleOlderThan(ctx context.Context, artifactType domain.ArtifactType, before time.Time) ([]uuid.UUID, error) {
+	query := `
+		UPDATE artifacts
+		SET stale = true, refresh_attempted_at = NULL, updated_at = NOW()
+		WHERE namespace = $1 AND stale = false AND deleted_at IS NULL
+			AND ($2 = '' OR type = $2) AND updated_at < $3
+		RETURNING id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.NamespaceFromContext(ctx), artifactType, before)
+	if err != nil {
+		return nil, mapError(ctx, err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// MarkFresh clears an artifact's stale flag and sets its new expiry, keeping its
+// content; indexed records a vector stored for it since
+func (r *ArtifactRepository) MarkFresh(ctx context.Context, artifactID uuid.UUID, expiresAt *time.Time, indexed bool) error {
+	query := `
+		UPDATE artifacts
+		SET stale = false, refresh_attempted_at = NULL, expires_at = $3, indexed = indexed OR $4, updated_at = NOW()
+		WHERE id = $1 AND namespace = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, artifactID, domain.NamespaceFromContext(ctx), expiresAt, indexed)
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "artifact", artifactID)
+}
+
+// ClaimStale locks up to limit stale artifacts in any namespace that an enabled
+// refresh hook matches and that were not tried since retryBefore, recording the
+// attempt so other instances and later sweeps skip them. Chunks are refreshed
+// with their parent and never claimed on their own.
+func (r *ArtifactRepository) ClaimStale(ctx context.Context, retryBefore time.Time, limit int) ([]*domain.Artifact, error) {
+	query := `
+		UPDATE artifacts
+		SET refresh_attempted_at = NOW()
+		WHERE id IN (
+			SELECT a.id FROM artifacts a
+			WHERE a.stale AND a.deleted_at IS NULL AND a.metadata->>'chunk_of' IS NULL
+				AND (a.refresh_attempted_at IS NULL OR a.refresh_attempted_at < $1)
+				AND EXISTS (
+					SELECT 1 FROM refresh_hooks h
+					WHERE h.namespace = a.namespace AND h.enabled
+						AND (h.artifact_type = '' OR h.artifact_type = a.type)
+						AND (h.source_prefix = '' OR starts_with(COALESCE(a.metadata->>'source_url', ''), h.source_prefix))
+				)
+			ORDER BY a.updated_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, namespace, type, content_hash, content, COALESCE(content_ref, ''), content_size, metadata, created_at, updated_at, stale, expires_at, pinned, last_accessed_at, version, tags, deleted_at, indexed, hit_count
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, retryBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []*domain.Artifact
+	for rows.Next() {
+		artifact, err := r.scanArtifact(rows)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, artifact)
+	}
+
+	return artifacts, rows.Err()
+}
+
+// ExpireDue marks up to limit artifacts in any namespace that expired before now
+// stale, returning their IDs so their vectors can be removed
+func (r *ArtifactRepository) ExpireDue(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		UPDATE artifacts
+		SET stale = true, refresh_attempted_at = NULL, indexed = false, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM artifacts
+			WHERE expires_at <= $1 AND stale = false AND deleted_at IS NULL
+			ORDER BY expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// RecordAccess adds hit counts to artifacts of any namespace and marks them
+// accessed now, keeping them from eviction
+func (r *ArtifactRepository) RecordAccess(ctx context.Context, hits map[uuid.UUID]int64) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(hits))
+	counts := make([]int64, 0, len(hits))
+	for id, count := range hits {
+		ids = append(ids, id.String())
+		counts = append(counts, count)
+	}
+
+	query := `
+		UPDATE artifacts a
+		SET hit_count = a.hit_count + h.hits, last_accessed_at = NOW()
+		FROM unnest($1::uuid[], $2::bigint[]) AS h(id, hits)
+		WHERE a.id = h.id
+	`
+	_, err := r.db.ExecContext(ctx, query, ids, counts)
+	return mapError(ctx, err)
+}
+
+// ListMostAccessed returns up to limit undeleted artifacts with the most hits, most first
+func (r *ArtifactRepository) ListMostAccessed(ctx context.Context, limit int) ([]*domain.ArtifactUsage, error) {
+	query := `
+		SELECT id, type, COALESCE(metadata->>'source_url', ''), hit_count, last_accessed_at, created_at
+		FROM artifacts
+		WHERE namespace = $1 AND hit_count > 0 AND deleted_at IS NULL
+		ORDER BY hit_count DESC, last_accessed_at DESC
+		LIMIT $2
 	`
-	_, err := r.db.ExecContext(ctx, query, sourceURL)
-	return err
+
+	rows, err := r.db.QueryContext(ctx, query, domain.NamespaceFromContext(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []*domain.ArtifactUsage
+	for rows.Next() {
+		var usage domain.ArtifactUsage
+		if err := rows.Scan(&usage.ID, &usage.Type, &usage.SourceURL, &usage.HitCount, &usage.LastAccessedAt, &usage.CreatedAt); err != nil {
+			return nil, err
+		}
+		usages = append(usages, &usage)
+	}
+
+	return usages, rows.Err()
+}
+
+func (r *ArtifactRepository) SetPinned(ctx context.Context, id uuid.UUID, pinned bool) error {
+	query := `UPDATE artifacts SET pinned = $2 WHERE id = $1 AND namespace = $3`
+	result, err := r.db.ExecContext(ctx, query, id, pinned, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "artifact", id)
+}
+
+// StorageUsage returns the artifact count and content bytes across all namespaces
+func (r *ArtifactRepository) StorageUsage(ctx context.Context) (int64, int64, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(content_size), 0) FROM artifacts`
+
+	var count, bytes int64
+	if err := r.db.QueryRowContext(ctx, query).Scan(&count, &bytes); err != nil {
+		return 0, 0, err
+	}
+	return count, bytes, nil
+}
+
+// ListIndexed returns up to limit indexed, undeleted artifacts of any namespace
+// with IDs after the given one, in ID order
+func (r *ArtifactRepository) ListIndexed(ctx context.Context, after uuid.UUID, limit int) ([]domain.ArtifactRef, error) {
+	query := `
+		SELECT id, namespace
+		FROM artifacts
+		WHERE indexed AND deleted_at IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []domain.ArtifactRef
+	for rows.Next() {
+		var ref domain.ArtifactRef
+		if err := rows.Scan(&ref.ID, &ref.Namespace); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, rows.Err()
+}
+
+// ExistingIDs returns the subset of ids that are artifacts of any namespace,
+// including deleted ones whose vectors are kept until they are purged
+func (r *ArtifactRepository) ExistingIDs(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM artifacts WHERE id = ANY($1::uuid[])`, uuidStrings(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var existing []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		existing = append(existing, id)
+	}
+
+	return existing, rows.Err()
+}
+
+// EvictLRU deletes up to limit unpinned artifacts in any namespace, least recently accessed first
+func (r *ArtifactRepository) EvictLRU(ctx context.Context, limit int) ([]domain.RemovedArtifact, error) {
+	query := `
+		DELETE FROM artifacts
+		WHERE id IN (
+			SELECT id FROM artifacts
+			WHERE pinned = false
+			ORDER BY deleted_at IS NULL, last_accessed_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + removedColumns
+
+	return r.queryRemoved(ctx, query, limit)
+}
+
+// removedColumns returns what a DELETE removed, including offloaded blobs of
+// archived versions that the cascade removes with the artifact
+const removedColumns = `id, content_size,
+		array_remove(ARRAY[content_ref] || ARRAY(
+			SELECT v.content_ref FROM artifact_versions v WHERE v.artifact_id = artifacts.id AND v.content_ref IS NOT NULL
+		), NULL)`
+
+func (r *ArtifactRepository) queryRemoved(ctx context.Context, query string, args ...interface{}) ([]domain.RemovedArtifact, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var removed []domain.RemovedArtifact
+	for rows.Next() {
+		var artifact domain.RemovedArtifact
+		if err := rows.Scan(&artifact.ID, &artifact.Bytes, textArray(&artifact.BlobKeys)); err != nil {
+			return nil, err
+		}
+		removed = append(removed, artifact)
+	}
+
+	return removed, rows.Err()
+}
+
+// contentSize is the size of the artifact's content wherever it is stored
+func contentSize(artifact *domain.Artifact) int64 {
+	if artifact.ContentRef != "" {
+		return artifact.ContentSize
+	}
+	return int64(len(artifact.Content))
 }
 
 func (r *ArtifactRepository) scanArtifact(row interface {
@@ -211,13 +988,24 @@ func (r *ArtifactRepository) scanArtifact(row interface {
 
 	err := row.Scan(
 		&artifact.ID,
+		&artifact.Namespace,
 		&artifact.Type,
 		&artifact.ContentHash,
 		&artifact.Content,
+		&artifact.ContentRef,
+		&artifact.ContentSize,
 		&metadataJSON,
 		&artifact.CreatedAt,
 		&artifact.UpdatedAt,
 		&artifact.Stale,
+		&artifact.ExpiresAt,
+		&artifact.Pinned,
+		&artifact.LastAccessedAt,
+		&artifact.Version,
+		textArray(&artifact.Tags),
+		&artifact.DeletedAt,
+		&artifact.Indexed,
+		&artifact.HitCount,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -231,4 +1019,48 @@ func (r *ArtifactRepository) scanArtifact(row interface {
 	}
 
 	return &artifact, nil
-}
\ No newline at end of file
+}
+
+func (r *ArtifactRepository) scanVersion(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.ArtifactVersion, error) {
+	var version domain.ArtifactVersion
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&version.ArtifactID,
+		&version.Version,
+		&version.Namespace,
+		&version.Type,
+		&version.ContentHash,
+		&version.Content,
+		&version.ContentRef,
+		&version.ContentSize,
+		&metadataJSON,
+		&version.PublishedAt,
+		&version.SupersededAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &version.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return &version, nil
+}
+
+// uuidStrings converts ids for binding as a uuid[] parameter
+func uuidStrings(ids []uuid.UUID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return values
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type CacheStatsRepository struct {
+	db *sql.DB
+}
+
+func NewCacheStatsRepository(db *sql.DB) *CacheStatsRepository {
+	return &CacheStatsRepository{db: db}
+}
+
+func (r *CacheStatsRepository) Increment(ctx context.Context, kind domain.CacheStatKind, stepType string, hits, misses int64) error {
+	query := `
+		INSERT INTO cache_stats_daily (namespace, day, kind, step_type, hits, misses)
+		VALUES ($1, CURRENT_DATE, $2, $3, $4, $5)
+		ON CONFLICT (namespace, day, kind, step_type) DO UPDATE SET
+			hits = cache_stats_daily.hits + EXCLUDED.hits,
+			misses = cache_stats_daily.misses + EXCLUDED.misses
+	`
+
+	_, err := r.db.ExecContext(ctx, query, domain.NamespaceFromContext(ctx), kind, stepType, hits, misses)
+	return err
+}
+
+func (r *CacheStatsRepository) Counts(ctx context.Context, since time.Time, allNamespaces bool) ([]domain.CacheStatCount, error) {
+	query := `
+		SELECT namespace, kind, step_type, SUM(hits), SUM(misses)
+		FROM cache_stats_daily
+		WHERE day >= $1::date AND ($2 OR namespace = $3)
+		GROUP BY namespace, kind, step_type
+		ORDER BY namespace, kind, step_type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, allNamespaces, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []domain.CacheStatCount
+	for rows.Next() {
+		var count domain.CacheStatCount
+		if err := rows.Scan(&count.Namespace, &count.Kind, &count.StepType, &count.Hits, &count.Misses); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
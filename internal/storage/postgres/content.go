@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"io"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// contentChunkSize bounds how much inline content one read loads into memory
+const contentChunkSize = 1 << 20
+
+// OpenContent streams the inline content of an artifact in chunks, so large
+// artifacts are never held in memory whole. Content offloaded to object
+// storage is not stored here and reads as empty
+func (r *ArtifactRepository) OpenContent(ctx context.Context, id uuid.UUID) (io.ReadCloser, error) {
+	return &contentReader{
+		ctx:       ctx,
+		stmts:     r.stmts,
+		id:        id,
+		namespace: domain.NamespaceFromContext(ctx),
+	}, nil
+}
+
+const readContentChunkQuery = `
+		SELECT substring(content FROM $3 FOR $4)
+		FROM artifacts
+		WHERE id = $1 AND namespace = $2 AND deleted_at IS NULL
+	`
+
+// contentReader fetches the next chunk of content once the previous one is read
+type contentReader struct {
+	ctx       context.Context
+	stmts     *statements
+	id        uuid.UUID
+	namespace string
+	offset    int64
+	chunk     []byte
+	done      bool
+}
+
+func (c *contentReader) Read(p []byte) (int, error) {
+	for len(c.chunk) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.fetch(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, c.chunk)
+	c.chunk = c.chunk[n:]
+	return n, nil
+}
+
+func (c *contentReader) fetch() error {
+	var chunk []byte
+	// substring positions start at 1
+	err := c.stmts.queryRow(c.ctx, readContentChunkQuery, c.id, c.namespace, c.offset+1, contentChunkSize).Scan(&chunk)
+	if err == sql.ErrNoRows {
+		return domain.NewNotFoundError("artifact", c.id)
+	}
+	if err != nil {
+		return mapError(c.ctx, err)
+	}
+
+	c.chunk = chunk
+	c.offset += int64(len(chunk))
+	c.done = len(chunk) < contentChunkSize
+	return nil
+}
+
+func (c *contentReader) Close() error {
+	c.chunk = nil
+	c.done = true
+	return nil
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/XSAM/otelsql"
+	"github.com/anunay/mentis/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Open connects to Postgres through a pgx connection pool sized by cfg and
+// returns it as a *sql.DB for the repositories. Queries are traced as child
+// spans of the request. The returned function closes the database and its pool
+func Open(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, func(), error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	if cfg.MaxConns <= 0 || cfg.MinConns <= 0 {
+		return nil, nil, fmt.Errorf("database pool sizes must be positive, got max %d and min %d", cfg.MaxConns, cfg.MinConns)
+	}
+	if cfg.MinConns > cfg.MaxConns {
+		return nil, nil, fmt.Errorf("database min connections %d exceed max connections %d", cfg.MinConns, cfg.MaxConns)
+	}
+	poolConfig.MaxConns = int32(min(cfg.MaxConns, math.MaxInt32))
+	poolConfig.MinConns = int32(min(cfg.MinConns, math.MaxInt32))
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+
+	db := otelsql.OpenDB(stdlib.GetPoolConnector(pool), otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+	// Connections go straight back to the pgx pool, which does the pooling
+	db.SetMaxIdleConns(0)
+
+	return db, func() {
+		db.Close()
+		pool.Close()
+	}, nil
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+)
+
+// mapError translates constraint violations into typed domain errors. Postgres
+// errors are logged with the request's correlation fields, since the code and
+// detail are lost once the error reaches the client
+func mapError(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	log := logging.FromContext(ctx).WithFields(logrus.Fields{
+		"pg_code":    pgErr.Code,
+		"constraint": pgErr.ConstraintName,
+		"table":      pgErr.TableName,
+	})
+	if strings.HasPrefix(pgErr.Code, "23") {
+		log.Debug("Database constraint violation")
+	} else {
+		log.WithError(err).Error("Database error")
+	}
+
+	// unique_violation, foreign_key_violation and check_violation
+	switch pgErr.Code {
+	case "23505":
+		return domain.NewConflictError("resource already exists", err).WithDetail("constraint", pgErr.ConstraintName)
+	case "23503":
+		return domain.NewValidationError("referenced resource does not exist").WithDetail("constraint", pgErr.ConstraintName)
+	case "23514":
+		return domain.NewValidationError("value violates constraint").WithDetail("constraint", pgErr.ConstraintName)
+	default:
+		return err
+	}
+}
+
+// requireRow turns an update or delete that matched nothing into a not-found error
+func requireRow(result sql.Result, resource string, id interface{}) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.NewNotFoundError(resource, id)
+	}
+	return nil
+}
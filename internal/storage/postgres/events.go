@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type SessionEventRepository struct {
+	db *sql.DB
+}
+
+func NewSessionEventRepository(db *sql.DB) *SessionEventRepository {
+	return &SessionEventRepository{db: db}
+}
+
+func (r *SessionEventRepository) Append(ctx context.Context, event *domain.SessionEvent) error {
+	dataJSON, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO session_events (id, namespace, session_id, type, step_id, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING seq
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		event.ID,
+		event.Namespace,
+		event.SessionID,
+		event.Type,
+		event.StepID,
+		dataJSON,
+		event.CreatedAt,
+	).Scan(&event.Sequence)
+	return mapError(ctx, err)
+}
+
+func (r *SessionEventRepository) AppendForSourceURL(ctx context.Context, sourceURL string, eventType domain.SessionEventType, data map[string]interface{}) (int64, error) {
+	query := `
+		INSERT INTO session_events (namespace, session_id, type, data)
+		SELECT DISTINCT s.namespace, s.session_id, $1, $2::jsonb
+		FROM workflow_steps s
+		JOIN artifacts a ON a.id = s.artifact_id
+		WHERE a.metadata->>'source_url' = $3 AND s.namespace = $4
+	`
+	return r.appendMany(ctx, query, eventType, data, sourceURL, domain.NamespaceFromContext(ctx))
+}
+
+func (r *SessionEventRepository) AppendForTemplate(ctx context.Context, templateName string, beforeVersion int, eventType domain.SessionEventType, data map[string]interface{}) (int64, error) {
+	query := `
+		INSERT INTO session_events (namespace, session_id, type, data)
+		SELECT DISTINCT namespace, session_id, $1, $2::jsonb
+		FROM workflow_steps
+		WHERE template_name = $3 AND template_version < $4 AND namespace = $5 AND artifact_id IS NOT NULL
+	`
+	return r.appendMany(ctx, query, eventType, data, templateName, beforeVersion, domain.NamespaceFromContext(ctx))
+}
+
+// appendMany runs an INSERT ... SELECT fanning one event out to many sessions
+func (r *SessionEventRepository) appendMany(ctx context.Context, query string, eventType domain.SessionEventType, data map[string]interface{}, args ...interface{}) (int64, error) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.ExecContext(ctx, query, append([]interface{}{eventType, string(dataJSON)}, args...)...)
+	if err != nil {
+		return 0, mapError(ctx, err)
+	}
+	return result.RowsAffected()
+}
+
+// List returns a session's events in recorded order, starting after the given sequence number
+func (r *SessionEventRepository) List(ctx context.Context, sessionID uuid.UUID, limit int, after *int64) ([]*domain.SessionEvent, error) {
+	query := `
+		SELECT id, seq, namespace, session_id, type, step_id, data, created_at
+		FROM session_events
+		WHERE session_id = $1 AND namespace = $2
+			AND ($4::bigint IS NULL OR seq > $4::bigint)
+		ORDER BY seq ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID, domain.NamespaceFromContext(ctx), limit, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*domain.SessionEvent{}
+	for rows.Next() {
+		var event domain.SessionEvent
+		var dataJSON []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.Sequence,
+			&event.Namespace,
+			&event.SessionID,
+			&event.Type,
+			&event.StepID,
+			&dataJSON,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if len(dataJSON) > 0 {
+			if err := json.Unmarshal(dataJSON, &event.Data); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
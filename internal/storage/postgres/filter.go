@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+// metadataConditions translates filter conditions on the metadata column into
+// WHERE clauses joined by AND, appending their parameters to args
+func metadataConditions(filter domain.Filter, column string, args []interface{}) (string, []interface{}, error) {
+	var clauses []string
+	for _, condition := range filter {
+		args = append(args, strings.Split(condition.Field, "."))
+		value := fmt.Sprintf("%s #> $%d", column, len(args))
+		text := fmt.Sprintf("%s #>> $%d", column, len(args))
+
+		switch condition.Op {
+		case domain.FilterEq, domain.FilterNe:
+			encoded, err := json.Marshal(condition.Value)
+			if err != nil {
+				return "", nil, err
+			}
+			args = append(args, string(encoded))
+			if condition.Op == domain.FilterEq {
+				clauses = append(clauses, fmt.Sprintf("%s = $%d::jsonb", value, len(args)))
+			} else {
+				clauses = append(clauses, fmt.Sprintf("%s IS DISTINCT FROM $%d::jsonb", value, len(args)))
+			}
+		case domain.FilterGt, domain.FilterLt:
+			number, _ := domain.FilterNumber(condition.Value)
+			args = append(args, number)
+			operator := ">"
+			if condition.Op == domain.FilterLt {
+				operator = "<"
+			}
+			clauses = append(clauses, fmt.Sprintf("(CASE WHEN jsonb_typeof(%s) = 'number' THEN (%s)::numeric %s $%d END)", value, text, operator, len(args)))
+		case domain.FilterIn:
+			values, _ := condition.Value.([]interface{})
+			encoded := make([]string, len(values))
+			for i, v := range values {
+				b, err := json.Marshal(v)
+				if err != nil {
+					return "", nil, err
+				}
+				encoded[i] = string(b)
+			}
+			args = append(args, encoded)
+			clauses = append(clauses, fmt.Sprintf("%s = ANY($%d::jsonb[])", value, len(args)))
+		case domain.FilterExists:
+			// Matches Qdrant, which treats null and empty lists as missing
+			clauses = append(clauses, fmt.Sprintf("COALESCE(%s, 'null') NOT IN ('null'::jsonb, '[]'::jsonb)", value))
+		case domain.FilterPrefix:
+			args = append(args, condition.Value)
+			clauses = append(clauses, fmt.Sprintf("starts_with(%s, $%d)", text, len(args)))
+		default:
+			return "", nil, fmt.Errorf("unsupported filter op %q", condition.Op)
+		}
+	}
+
+	if len(clauses) == 0 {
+		return "TRUE", args, nil
+	}
+	return strings.Join(clauses, " AND "), args, nil
+}
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+const freshnessSourceColumns = `id, namespace, name, url, interval_seconds, enabled, etag, last_modified,
+		last_checked_at, next_check_at, last_error, last_invalidated, created_at, updated_at`
+
+type FreshnessSourceRepository struct {
+	db *sql.DB
+}
+
+func NewFreshnessSourceRepository(db *sql.DB) *FreshnessSourceRepository {
+	return &FreshnessSourceRepository{db: db}
+}
+
+func (r *FreshnessSourceRepository) Store(ctx context.Context, source *domain.FreshnessSource) error {
+	interval, err := intervalSeconds(source)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO freshness_sources (id, namespace, name, url, interval_seconds, enabled, next_check_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		source.ID,
+		source.Namespace,
+		source.Name,
+		source.URL,
+		interval,
+		source.Enabled,
+		source.NextCheckAt,
+		source.CreatedAt,
+		source.UpdatedAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *FreshnessSourceRepository) Get(ctx context.Context, id uuid.UUID) (*domain.FreshnessSource, error) {
+	query := `SELECT ` + freshnessSourceColumns + ` FROM freshness_sources WHERE id = $1 AND namespace = $2`
+
+	row := r.db.QueryRowContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	return r.scanSource(row)
+}
+
+func (r *FreshnessSourceRepository) List(ctx context.Context) ([]*domain.FreshnessSource, error) {
+	query := `SELECT ` + freshnessSourceColumns + ` FROM freshness_sources WHERE namespace = $1 ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return r.scanSources(rows)
+}
+
+// Update changes a source's definition; a changed URL is checked afresh
+func (r *FreshnessSourceRepository) Update(ctx context.Context, source *domain.FreshnessSource) error {
+	interval, err := intervalSeconds(source)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE freshness_sources
+		SET name = $2, url = $3, interval_seconds = $4, enabled = $5, next_check_at = $6, updated_at = $7,
+			etag = CASE WHEN url = $3 THEN etag ELSE '' END,
+			last_modified = CASE WHEN url = $3 THEN last_modified ELSE '' END
+		WHERE id = $1 AND namespace = $8
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		source.ID,
+		source.Name,
+		source.URL,
+		interval,
+		source.Enabled,
+		source.NextCheckAt,
+		source.UpdatedAt,
+		domain.NamespaceFromContext(ctx),
+	)
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "freshness source", source.ID)
+}
+
+func (r *FreshnessSourceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM freshness_sources WHERE id = $1 AND namespace = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	return requireRow(result, "freshness source", id)
+}
+
+func (r *FreshnessSourceRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.FreshnessSource, error) {
+	query := `
+		SELECT ` + freshnessSourceColumns + `
+		FROM freshness_sources
+		WHERE enabled AND next_check_at <= $1
+		ORDER BY next_check_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanSources(rows)
+}
+
+func (r *FreshnessSourceRepository) RecordCheck(ctx context.Context, source *domain.FreshnessSource) error {
+	query := `
+		UPDATE freshness_sources
+		SET etag = $2, last_modified = $3, last_checked_at = $4, next_check_at = $5, last_error = $6, last_invalidated = $7
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		source.ID,
+		source.ETag,
+		source.LastModified,
+		source.LastCheckedAt,
+		source.NextCheckAt,
+		source.LastError,
+		source.LastInvalidated,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *FreshnessSourceRepository) scanSources(rows *sql.Rows) ([]*domain.FreshnessSource, error) {
+	defer rows.Close()
+
+	var sources []*domain.FreshnessSource
+	for rows.Next() {
+		source, err := r.scanSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, rows.Err()
+}
+
+func (r *FreshnessSourceRepository) scanSource(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.FreshnessSource, error) {
+	var source domain.FreshnessSource
+	var interval int64
+
+	err := row.Scan(
+		&source.ID,
+		&source.Namespace,
+		&source.Name,
+		&source.URL,
+		&interval,
+		&source.Enabled,
+		&source.ETag,
+		&source.LastModified,
+		&source.LastCheckedAt,
+		&source.NextCheckAt,
+		&source.LastError,
+		&source.LastInvalidated,
+		&source.CreatedAt,
+		&source.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	source.Interval = (time.Duration(interval) * time.Second).String()
+	return &source, nil
+}
+
+// intervalSeconds converts a source's check interval to whole seconds for storage
+func intervalSeconds(source *domain.FreshnessSource) (int64, error) {
+	interval, err := time.ParseDuration(source.Interval)
+	if err != nil {
+		return 0, domain.NewValidationError("invalid interval")
+	}
+	return int64(interval / time.Second), nil
+}
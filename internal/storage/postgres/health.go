@@ -0,0 +1,19 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+)
+
+// HealthRepository checks database reachability for the readiness probe
+type HealthRepository struct {
+	db *sql.DB
+}
+
+func NewHealthRepository(db *sql.DB) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type InvalidationPolicyRepository struct {
+	db *sql.DB
+}
+
+func NewInvalidationPolicyRepository(db *sql.DB) *InvalidationPolicyRepository {
+	return &InvalidationPolicyRepository{db: db}
+}
+
+func (r *InvalidationPolicyRepository) Store(ctx context.Context, policy *domain.InvalidationPolicy) error {
+	maxAge, err := maxAgeSeconds(policy)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO invalidation_policies (id, namespace, name, artifact_type, max_age_seconds, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		policy.ID,
+		policy.Namespace,
+		policy.Name,
+		policy.ArtifactType,
+		maxAge,
+		policy.Enabled,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *InvalidationPolicyRepository) Get(ctx context.Context, id uuid.UUID) (*domain.InvalidationPolicy, error) {
+	query := `
+		SELECT id, namespace, name, artifact_type, max_age_seconds, enabled, created_at, updated_at
+		FROM invalidation_policies
+		WHERE id = $1 AND namespace = $2
+	`
+
+	row := r.db.QueryRowContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	return r.scanPolicy(row)
+}
+
+func (r *InvalidationPolicyRepository) List(ctx context.Context) ([]*domain.InvalidationPolicy, error) {
+	query := `
+		SELECT id, namespace, name, artifact_type, max_age_seconds, enabled, created_at, updated_at
+		FROM invalidation_policies
+		WHERE namespace = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*domain.InvalidationPolicy
+	for rows.Next() {
+		policy, err := r.scanPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+func (r *InvalidationPolicyRepository) Update(ctx context.Context, policy *domain.InvalidationPolicy) error {
+	maxAge, err := maxAgeSeconds(policy)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE invalidation_policies
+		SET name = $2, artifact_type = $3, max_age_seconds = $4, enabled = $5, updated_at = $6
+		WHERE id = $1 AND namespace = $7
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		policy.ID,
+		policy.Name,
+		policy.ArtifactType,
+		maxAge,
+		policy.Enabled,
+		policy.UpdatedAt,
+		domain.NamespaceFromContext(ctx),
+	)
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "invalidation policy", policy.ID)
+}
+
+func (r *InvalidationPolicyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM invalidation_policies WHERE id = $1 AND namespace = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	return requireRow(result, "invalidation policy", id)
+}
+
+// Enforce marks up to limit fresh artifacts of every namespace stale whose last
+// publish is older than the max age of an enabled policy covering their type
+func (r *InvalidationPolicyRepository) Enforce(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	query := `
+		UPDATE artifacts
+		SET stale = true, refresh_attempted_at = NULL, updated_at = NOW()
+		WHERE id IN (
+			SELECT a.id FROM artifacts a
+			WHERE a.stale = false AND a.deleted_at IS NULL
+				AND EXISTS (
+					SELECT 1 FROM invalidation_policies p
+					WHERE p.namespace = a.namespace AND p.enabled
+						AND (p.artifact_type = '' OR p.artifact_type = a.type)
+						AND a.updated_at < NOW() - p.max_age_seconds * INTERVAL '1 second'
+				)
+			ORDER BY a.updated_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *InvalidationPolicyRepository) scanPolicy(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.InvalidationPolicy, error) {
+	var policy domain.InvalidationPolicy
+	var maxAge int64
+
+	err := row.Scan(
+		&policy.ID,
+		&policy.Namespace,
+		&policy.Name,
+		&policy.ArtifactType,
+		&maxAge,
+		&policy.Enabled,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	policy.MaxAge = (time.Duration(maxAge) * time.Second).String()
+	return &policy, nil
+}
+
+// maxAgeSeconds converts a policy's max age to whole seconds for storage
+func maxAgeSeconds(policy *domain.InvalidationPolicy) (int64, error) {
+	maxAge, err := time.ParseDuration(policy.MaxAge)
+	if err != nil {
+		return 0, domain.NewValidationError("invalid max_age")
+	}
+	return int64(maxAge / time.Second), nil
+}
@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func (r *JobRepository) Enqueue(ctx context.Context, job *domain.StepJob) error {
+	inputJSON, err := json.Marshal(job.Input)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO step_jobs (id, step_id, namespace, input, timeout_ms, external, status, run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		job.ID,
+		job.StepID,
+		job.Namespace,
+		inputJSON,
+		job.Timeout.Milliseconds(),
+		job.External,
+		job.Status,
+		job.RunAt,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	return mapError(ctx, err)
+}
+
+// Claim locks up to limit due jobs for this worker, skipping jobs other workers hold
+func (r *JobRepository) Claim(ctx context.Context, limit int) ([]*domain.StepJob, error) {
+	query := `
+		UPDATE step_jobs
+		SET status = 'running', locked_at = NOW(), attempts = attempts + 1, lease_token = gen_random_uuid()
+		WHERE id IN (
+			SELECT id FROM step_jobs
+			WHERE status = 'queued' AND run_at <= NOW() AND NOT external
+			ORDER BY run_at, created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, step_id, namespace, input, timeout_ms, external, status, attempts, last_error, run_at, locked_at, lease_token, created_at, updated_at
+	`
+
+	return r.queryJobs(ctx, query, limit)
+}
+
+// ClaimByID locks the given jobs that are still queued and due; the rest are
+// left alone, having been claimed already or not yet due
+func (r *JobRepository) ClaimByID(ctx context.Context, ids []uuid.UUID) ([]*domain.StepJob, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		UPDATE step_jobs
+		SET status = 'running', locked_at = NOW(), attempts = attempts + 1, lease_token = gen_random_uuid()
+		WHERE id IN (
+			SELECT id FROM step_jobs
+			WHERE id = ANY($1::uuid[]) AND status = 'queued' AND run_at <= NOW() AND NOT external
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, step_id, namespace, input, timeout_ms, external, status, attempts, last_error, run_at, locked_at, lease_token, created_at, updated_at
+	`
+
+	return r.queryJobs(ctx, query, uuidStrings(ids))
+}
+
+func (r *JobRepository) ClaimExternal(ctx context.Context, limit int, stepTypes []string) ([]*domain.StepJob, error) {
+	// A nil slice binds as NULL, whose cardinality is NULL rather than 0
+	if stepTypes == nil {
+		stepTypes = []string{}
+	}
+
+	query := `
+		UPDATE step_jobs
+		SET status = 'running', locked_at = NOW(), attempts = attempts + 1, lease_token = gen_random_uuid()
+		WHERE id IN (
+			SELECT j.id FROM step_jobs j
+			JOIN workflow_steps s ON s.id = j.step_id
+			WHERE j.status = 'queued' AND j.run_at <= NOW() AND j.external AND j.namespace = $2
+				AND (cardinality($3::text[]) = 0 OR s.step_type = ANY($3))
+			ORDER BY j.run_at, j.created_at
+			LIMIT $1
+			FOR UPDATE OF j SKIP LOCKED
+		)
+		RETURNING id, step_id, namespace, input, timeout_ms, external, status, attempts, last_error, run_at, locked_at, lease_token, created_at, updated_at
+	`
+
+	return r.queryJobs(ctx, query, limit, domain.NamespaceFromContext(ctx), stepTypes)
+}
+
+func (r *JobRepository) GetRunningByStep(ctx context.Context, stepID uuid.UUID) (*domain.StepJob, error) {
+	query := `
+		SELECT id, step_id, namespace, input, timeout_ms, external, status, attempts, last_error, run_at, locked_at, lease_token, created_at, updated_at
+		FROM step_jobs
+		WHERE step_id = $1 AND namespace = $2 AND status = 'running'
+		LIMIT 1
+	`
+
+	row := r.db.QueryRowContext(ctx, query, stepID, domain.NamespaceFromContext(ctx))
+	return r.scanJob(row)
+}
+
+func (r *JobRepository) queryJobs(ctx context.Context, query string, args ...interface{}) ([]*domain.StepJob, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*domain.StepJob
+	for rows.Next() {
+		job, err := r.scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Complete, Fail, Retry, and Heartbeat only update a job still held under the
+// given lease; a job whose lease expired and was claimed again is a conflict
+
+func (r *JobRepository) Complete(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID) error {
+	query := `
+		UPDATE step_jobs SET status = 'succeeded', locked_at = NULL
+		WHERE id = $1 AND status = 'running' AND lease_token IS NOT DISTINCT FROM $2::uuid
+	`
+	return r.updateLeased(ctx, query, id, leaseToken)
+}
+
+func (r *JobRepository) Fail(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID, reason string) error {
+	query := `
+		UPDATE step_jobs SET status = 'failed', locked_at = NULL, last_error = $3
+		WHERE id = $1 AND status = 'running' AND lease_token IS NOT DISTINCT FROM $2::uuid
+	`
+	return r.updateLeased(ctx, query, id, leaseToken, reason)
+}
+
+// Retry returns a failed job to the queue, to be picked up again at runAt
+func (r *JobRepository) Retry(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID, runAt time.Time, reason string) error {
+	query := `
+		UPDATE step_jobs SET status = 'queued', locked_at = NULL, run_at = $3, last_error = $4
+		WHERE id = $1 AND status = 'running' AND lease_token IS NOT DISTINCT FROM $2::uuid
+	`
+	return r.updateLeased(ctx, query, id, leaseToken, runAt, reason)
+}
+
+// Heartbeat renews the lease of a running job
+func (r *JobRepository) Heartbeat(ctx context.Context, id uuid.UUID, leaseToken *uuid.UUID) error {
+	query := `
+		UPDATE step_jobs SET locked_at = NOW()
+		WHERE id = $1 AND status = 'running' AND lease_token IS NOT DISTINCT FROM $2::uuid
+	`
+	return r.updateLeased(ctx, query, id, leaseToken)
+}
+
+func (r *JobRepository) updateLeased(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.NewConflictError("step job lease has expired or is held by another worker", nil)
+	}
+	return nil
+}
+
+// RequeueExpired returns running jobs whose lease lapsed, e.g. after a worker crash, to the queue
+func (r *JobRepository) RequeueExpired(ctx context.Context, lease time.Duration) (int64, error) {
+	query := `
+		UPDATE step_jobs
+		SET status = 'queued', locked_at = NULL
+		WHERE status = 'running' AND locked_at < NOW() - make_interval(secs => $1)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, lease.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *JobRepository) scanJob(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.StepJob, error) {
+	var job domain.StepJob
+	var inputJSON []byte
+	var lastError sql.NullString
+	var timeoutMs int64
+
+	err := row.Scan(
+		&job.ID,
+		&job.StepID,
+		&job.Namespace,
+		&inputJSON,
+		&timeoutMs,
+		&job.External,
+		&job.Status,
+		&job.Attempts,
+		&lastError,
+		&job.RunAt,
+		&job.LockedAt,
+		&job.LeaseToken,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	job.LastError = lastError.String
+	job.Timeout = time.Duration(timeoutMs) * time.Millisecond
+	if len(inputJSON) > 0 {
+		if err := json.Unmarshal(inputJSON, &job.Input); err != nil {
+			return nil, err
+		}
+	}
+
+	return &job, nil
+}
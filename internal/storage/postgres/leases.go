@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// LeaseRepository keeps background job leases in worker_leases. Expiry is
+// compared against the database clock, so replicas need not agree on the time
+type LeaseRepository struct {
+	db *sql.DB
+}
+
+func NewLeaseRepository(db *sql.DB) *LeaseRepository {
+	return &LeaseRepository{db: db}
+}
+
+func (r *LeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO worker_leases (name, holder, expires_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 millisecond')
+		ON CONFLICT (name) DO UPDATE
+		SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at,
+			acquired_at = CASE WHEN worker_leases.holder = EXCLUDED.holder THEN worker_leases.acquired_at ELSE NOW() END
+		WHERE worker_leases.holder = EXCLUDED.holder OR worker_leases.expires_at <= NOW()
+		RETURNING name
+	`
+
+	var acquired string
+	err := r.db.QueryRowContext(ctx, query, name, holder, ttl.Milliseconds()).Scan(&acquired)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, mapError(ctx, err)
+	}
+	return true, nil
+}
+
+func (r *LeaseRepository) Renew(ctx context.Context, names []string, holder string, ttl time.Duration) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		UPDATE worker_leases
+		SET expires_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE name = ANY($1) AND holder = $2 AND expires_at > NOW()
+		RETURNING name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, names, holder, ttl.Milliseconds())
+	if err != nil {
+		return nil, mapError(ctx, err)
+	}
+	defer rows.Close()
+
+	var renewed []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		renewed = append(renewed, name)
+	}
+
+	return renewed, rows.Err()
+}
+
+func (r *LeaseRepository) Release(ctx context.Context, names []string, holder string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	query := `DELETE FROM worker_leases WHERE name = ANY($1) AND holder = $2`
+	_, err := r.db.ExecContext(ctx, query, names, holder)
+	return mapError(ctx, err)
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// openLeaseTestDB connects to the database in MENTIS_TEST_DATABASE_URL and
+// creates worker_leases from its migration as a temporary table, so tests
+// neither need a migrated database nor touch real leases. The pool holds one
+// connection, which keeps the table visible to every query
+func openLeaseTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("MENTIS_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("MENTIS_TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	migration, err := os.ReadFile("../../../migrations/035_worker_leases.sql")
+	if err != nil {
+		t.Fatalf("failed to read migration: %v", err)
+	}
+	schema := strings.Replace(string(migration), "CREATE TABLE", "CREATE TEMP TABLE", 1)
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create worker_leases: %v", err)
+	}
+	return db
+}
+
+func TestLeaseAcquire(t *testing.T) {
+	repo := NewLeaseRepository(openLeaseTestDB(t))
+	ctx := context.Background()
+
+	acquire := func(holder string, ttl time.Duration, want bool) {
+		t.Helper()
+		acquired, err := repo.Acquire(ctx, "sweeper", holder, ttl)
+		if err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+		if acquired != want {
+			t.Fatalf("expected %s to acquire=%v, got %v", holder, want, acquired)
+		}
+	}
+
+	acquire("a", 200*time.Millisecond, true)
+	acquire("b", time.Minute, false)
+	// The holder may acquire again, which extends its lease
+	acquire("a", 200*time.Millisecond, true)
+
+	time.Sleep(300 * time.Millisecond)
+	acquire("b", time.Minute, true)
+	acquire("a", time.Minute, false)
+}
+
+func TestLeaseReacquireKeepsAcquiredAt(t *testing.T) {
+	db := openLeaseTestDB(t)
+	repo := NewLeaseRepository(db)
+	ctx := context.Background()
+
+	acquiredAt := func() time.Time {
+		t.Helper()
+		var at time.Time
+		if err := db.QueryRow(`SELECT acquired_at FROM worker_leases WHERE name = 'sweeper'`).Scan(&at); err != nil {
+			t.Fatalf("failed to read lease: %v", err)
+		}
+		return at
+	}
+
+	if _, err := repo.Acquire(ctx, "sweeper", "a", time.Minute); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	first := acquiredAt()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := repo.Acquire(ctx, "sweeper", "a", time.Minute); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if again := acquiredAt(); !again.Equal(first) {
+		t.Fatalf("expected the holder's acquired_at to stay %s, got %s", first, again)
+	}
+}
+
+func TestLeaseRenew(t *testing.T) {
+	repo := NewLeaseRepository(openLeaseTestDB(t))
+	ctx := context.Background()
+
+	for name, holder := range map[string]string{"sweeper": "a", "scheduler": "a", "evictor": "b"} {
+		if _, err := repo.Acquire(ctx, name, holder, 200*time.Millisecond); err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+	}
+
+	renewed, err := repo.Renew(ctx, []string{"sweeper", "scheduler", "evictor", "missing"}, "a", 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Renew: %v", err)
+	}
+	sort.Strings(renewed)
+	if !reflect.DeepEqual(renewed, []string{"scheduler", "sweeper"}) {
+		t.Fatalf("expected only the holder's leases to be renewed, got %v", renewed)
+	}
+
+	// A lease that lapsed can't be renewed, even before anyone else takes it
+	time.Sleep(300 * time.Millisecond)
+	renewed, err = repo.Renew(ctx, []string{"sweeper"}, "a", time.Minute)
+	if err != nil {
+		t.Fatalf("Renew: %v", err)
+	}
+	if len(renewed) != 0 {
+		t.Fatalf("expected an expired lease not to be renewed, got %v", renewed)
+	}
+
+	if renewed, err := repo.Renew(ctx, nil, "a", time.Minute); err != nil || renewed != nil {
+		t.Fatalf("expected renewing nothing to do nothing, got %v, %v", renewed, err)
+	}
+}
+
+func TestLeaseRelease(t *testing.T) {
+	repo := NewLeaseRepository(openLeaseTestDB(t))
+	ctx := context.Background()
+
+	if _, err := repo.Acquire(ctx, "sweeper", "a", time.Minute); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	// Only the holder can release a lease
+	if err := repo.Release(ctx, []string{"sweeper"}, "b"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if acquired, err := repo.Acquire(ctx, "sweeper", "b", time.Minute); err != nil || acquired {
+		t.Fatalf("expected the lease to survive another holder's release, got %v, %v", acquired, err)
+	}
+
+	if err := repo.Release(ctx, []string{"sweeper"}, "a"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if acquired, err := repo.Acquire(ctx, "sweeper", "b", time.Minute); err != nil || !acquired {
+		t.Fatalf("expected a released lease to be free, got %v, %v", acquired, err)
+	}
+
+	if err := repo.Release(ctx, nil, "b"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type NegativeCacheRepository struct {
+	db *sql.DB
+}
+
+func NewNegativeCacheRepository(db *sql.DB) *NegativeCacheRepository {
+	return &NegativeCacheRepository{db: db}
+}
+
+func (r *NegativeCacheRepository) Put(ctx context.Context, entry *domain.NegativeEntry) error {
+	query := `
+		INSERT INTO negative_cache (namespace, kind, key, reason, code, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (namespace, kind, key) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			code = EXCLUDED.code,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.Namespace,
+		entry.Kind,
+		entry.Key,
+		entry.Reason,
+		entry.Code,
+		entry.CreatedAt,
+		entry.ExpiresAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *NegativeCacheRepository) Get(ctx context.Context, kind domain.NegativeKind, key string) (*domain.NegativeEntry, error) {
+	query := `
+		SELECT namespace, kind, key, COALESCE(reason, ''), COALESCE(code, ''), created_at, expires_at
+		FROM negative_cache
+		WHERE namespace = $1 AND kind = $2 AND key = $3 AND expires_at > NOW()
+	`
+
+	var entry domain.NegativeEntry
+	err := r.db.QueryRowContext(ctx, query, domain.NamespaceFromContext(ctx), kind, key).Scan(
+		&entry.Namespace,
+		&entry.Kind,
+		&entry.Key,
+		&entry.Reason,
+		&entry.Code,
+		&entry.CreatedAt,
+		&entry.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *NegativeCacheRepository) Delete(ctx context.Context, kind domain.NegativeKind, key string) error {
+	query := `DELETE FROM negative_cache WHERE namespace = $1 AND kind = $2 AND key = $3`
+	_, err := r.db.ExecContext(ctx, query, domain.NamespaceFromContext(ctx), kind, key)
+	return mapError(ctx, err)
+}
+
+func (r *NegativeCacheRepository) DeleteKind(ctx context.Context, kind domain.NegativeKind) error {
+	query := `DELETE FROM negative_cache WHERE namespace = $1 AND kind = $2`
+	_, err := r.db.ExecContext(ctx, query, domain.NamespaceFromContext(ctx), kind)
+	return mapError(ctx, err)
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// VectorOutboxRepository reads the vector upserts that ArtifactRepository queues
+// alongside artifacts
+type VectorOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewVectorOutboxRepository(db *sql.DB) *VectorOutboxRepository {
+	return &VectorOutboxRepository{db: db}
+}
+
+func (r *VectorOutboxRepository) Claim(ctx context.Context, lease time.Duration, limit int) ([]domain.OutboxEntry, error) {
+	query := `
+		UPDATE vector_outbox o
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+		FROM artifacts a
+		WHERE a.id = o.artifact_id AND o.artifact_id IN (
+			SELECT artifact_id
+			FROM vector_outbox
+			WHERE next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING o.artifact_id, a.namespace, o.embedding, o.queued_at, o.attempts
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, mapError(ctx, err)
+	}
+	defer rows.Close()
+
+	var entries []domain.OutboxEntry
+	for rows.Next() {
+		var entry domain.OutboxEntry
+		if err := rows.Scan(&entry.ArtifactID, &entry.Namespace, float4Array(&entry.Embedding), &entry.QueuedAt, &entry.Attempts); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Complete leaves entries queued after before, such as a republish racing the
+// upsert, for the next relay pass
+func (r *VectorOutboxRepository) Complete(ctx context.Context, artifactIDs []uuid.UUID, before time.Time) error {
+	if len(artifactIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		DELETE FROM vector_outbox
+		WHERE artifact_id = ANY($1::uuid[]) AND queued_at <= $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, uuidStrings(artifactIDs), before)
+	return mapError(ctx, err)
+}
+
+func (r *VectorOutboxRepository) Retry(ctx context.Context, artifactID uuid.UUID, retryAt time.Time, reason string) error {
+	query := `
+		UPDATE vector_outbox
+		SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+		WHERE artifact_id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, artifactID, retryAt, reason)
+	return mapError(ctx, err)
+}
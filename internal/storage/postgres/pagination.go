@@ -0,0 +1,12 @@
+package postgres
+
+import "github.com/anunay/mentis/internal/core/domain"
+
+// pageKeyArgs returns the time and ID query parameters of a keyset position;
+// both are NULL on the first page
+func pageKeyArgs(after *domain.PageKey) (interface{}, interface{}) {
+	if after == nil {
+		return nil, nil
+	}
+	return after.Time, after.ID
+}
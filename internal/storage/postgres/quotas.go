@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type QuotaRepository struct {
+	db *sql.DB
+}
+
+func NewQuotaRepository(db *sql.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+func (r *QuotaRepository) GetLimits(ctx context.Context, namespace string) (*domain.QuotaLimits, error) {
+	query := `
+		SELECT max_artifacts, max_bytes, max_embeddings_per_day
+		FROM namespace_quotas
+		WHERE namespace = $1
+	`
+
+	var limits domain.QuotaLimits
+	err := r.db.QueryRowContext(ctx, query, namespace).Scan(
+		&limits.MaxArtifacts,
+		&limits.MaxBytes,
+		&limits.MaxEmbeddingsPerDay,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &limits, nil
+}
+
+func (r *QuotaRepository) SetLimits(ctx context.Context, namespace string, limits *domain.QuotaLimits) error {
+	query := `
+		INSERT INTO namespace_quotas (namespace, max_artifacts, max_bytes, max_embeddings_per_day, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (namespace) DO UPDATE SET
+			max_artifacts = EXCLUDED.max_artifacts,
+			max_bytes = EXCLUDED.max_bytes,
+			max_embeddings_per_day = EXCLUDED.max_embeddings_per_day,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		namespace,
+		limits.MaxArtifacts,
+		limits.MaxBytes,
+		limits.MaxEmbeddingsPerDay,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *QuotaRepository) DeleteLimits(ctx context.Context, namespace string) error {
+	query := `DELETE FROM namespace_quotas WHERE namespace = $1`
+	_, err := r.db.ExecContext(ctx, query, namespace)
+	return err
+}
+
+func (r *QuotaRepository) GetUsage(ctx context.Context, namespace string) (*domain.QuotaUsage, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM artifacts WHERE namespace = $1 AND deleted_at IS NULL),
+			(SELECT COALESCE(SUM(content_size), 0) FROM artifacts WHERE namespace = $1 AND deleted_at IS NULL),
+			(SELECT COALESCE(SUM(embeddings), 0) FROM usage_daily WHERE namespace = $1 AND day = CURRENT_DATE)
+	`
+
+	var usage domain.QuotaUsage
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, namespace).Scan(
+		&usage.Artifacts,
+		&usage.Bytes,
+		&usage.EmbeddingsToday,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// ConsumeEmbeddings adds count to today's embeddings in one statement, unless
+// that would take them past limit. A limit of zero or less is unlimited. It
+// reports whether the embeddings were recorded
+func (r *QuotaRepository) ConsumeEmbeddings(ctx context.Context, namespace string, count, limit int64) (bool, error) {
+	query := `
+		INSERT INTO usage_daily (namespace, day, embeddings)
+		SELECT $1, CURRENT_DATE, $2::bigint
+		WHERE $3::bigint <= 0 OR $2::bigint <= $3::bigint
+		ON CONFLICT (namespace, day) DO UPDATE SET
+			embeddings = usage_daily.embeddings + EXCLUDED.embeddings
+		WHERE $3::bigint <= 0 OR usage_daily.embeddings + EXCLUDED.embeddings <= $3::bigint
+		RETURNING embeddings
+	`
+
+	var used int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, namespace, count, limit).Scan(&used)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LockUsage serialises quota checks on namespace, and on keyID when given,
+// until the surrounding transaction ends. Keys are locked after namespaces,
+// always in the same order
+func (r *QuotaRepository) LockUsage(ctx context.Context, namespace string, keyID *uuid.UUID) error {
+	query := `SELECT pg_advisory_xact_lock(hashtext('quota:' || $1))`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, namespace); err != nil {
+		return mapError(ctx, err)
+	}
+	if keyID == nil {
+		return nil
+	}
+
+	query = `SELECT pg_advisory_xact_lock(hashtext('quota-key:' || $1::text))`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, *keyID)
+	return mapError(ctx, err)
+}
+
+func (r *QuotaRepository) GetKeyLimits(ctx context.Context, keyID uuid.UUID) (*domain.QuotaLimits, error) {
+	query := `
+		SELECT max_artifacts, max_bytes, max_embeddings_per_day
+		FROM api_key_quotas
+		WHERE key_id = $1
+	`
+
+	var limits domain.QuotaLimits
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, keyID).Scan(
+		&limits.MaxArtifacts,
+		&limits.MaxBytes,
+		&limits.MaxEmbeddingsPerDay,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &limits, nil
+}
+
+func (r *QuotaRepository) SetKeyLimits(ctx context.Context, keyID uuid.UUID, limits *domain.QuotaLimits) error {
+	query := `
+		INSERT INTO api_key_quotas (key_id, max_artifacts, max_bytes, max_embeddings_per_day, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (key_id) DO UPDATE SET
+			max_artifacts = EXCLUDED.max_artifacts,
+			max_bytes = EXCLUDED.max_bytes,
+			max_embeddings_per_day = EXCLUDED.max_embeddings_per_day,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		keyID,
+		limits.MaxArtifacts,
+		limits.MaxBytes,
+		limits.MaxEmbeddingsPerDay,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *QuotaRepository) DeleteKeyLimits(ctx context.Context, keyID uuid.UUID) error {
+	query := `DELETE FROM api_key_quotas WHERE key_id = $1`
+	_, err := r.db.ExecContext(ctx, query, keyID)
+	return err
+}
+
+// GetKeyUsage counts the live artifacts keyID stored, across all namespaces
+func (r *QuotaRepository) GetKeyUsage(ctx context.Context, keyID uuid.UUID) (*domain.QuotaUsage, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM artifacts WHERE api_key_id = $1 AND deleted_at IS NULL),
+			(SELECT COALESCE(SUM(content_size), 0) FROM artifacts WHERE api_key_id = $1 AND deleted_at IS NULL),
+			(SELECT COALESCE(SUM(embeddings), 0) FROM usage_daily_keys WHERE key_id = $1 AND day = CURRENT_DATE)
+	`
+
+	var usage domain.QuotaUsage
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, keyID).Scan(
+		&usage.Artifacts,
+		&usage.Bytes,
+		&usage.EmbeddingsToday,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// ConsumeKeyEmbeddings is ConsumeEmbeddings for an API key's daily counter
+func (r *QuotaRepository) ConsumeKeyEmbeddings(ctx context.Context, keyID uuid.UUID, count, limit int64) (bool, error) {
+	query := `
+		INSERT INTO usage_daily_keys (key_id, day, embeddings)
+		SELECT $1, CURRENT_DATE, $2::bigint
+		WHERE $3::bigint <= 0 OR $2::bigint <= $3::bigint
+		ON CONFLICT (key_id, day) DO UPDATE SET
+			embeddings = usage_daily_keys.embeddings + EXCLUDED.embeddings
+		WHERE $3::bigint <= 0 OR usage_daily_keys.embeddings + EXCLUDED.embeddings <= $3::bigint
+		RETURNING embeddings
+	`
+
+	var used int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, keyID, count, limit).Scan(&used)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
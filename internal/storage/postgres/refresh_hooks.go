@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type RefreshHookRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshHookRepository(db *sql.DB) *RefreshHookRepository {
+	return &RefreshHookRepository{db: db}
+}
+
+func (r *RefreshHookRepository) Store(ctx context.Context, hook *domain.RefreshHook) error {
+	query := `
+		INSERT INTO refresh_hooks (id, namespace, name, source_prefix, artifact_type, kind, url, step_type, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		hook.ID,
+		hook.Namespace,
+		hook.Name,
+		hook.SourcePrefix,
+		hook.ArtifactType,
+		hook.Kind,
+		hook.URL,
+		hook.StepType,
+		hook.Enabled,
+		hook.CreatedAt,
+		hook.UpdatedAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *RefreshHookRepository) Get(ctx context.Context, id uuid.UUID) (*domain.RefreshHook, error) {
+	query := `
+		SELECT id, namespace, name, source_prefix, artifact_type, kind, url, step_type, enabled, created_at, updated_at
+		FROM refresh_hooks
+		WHERE id = $1 AND namespace = $2
+	`
+
+	row := r.db.QueryRowContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	return r.scanHook(row)
+}
+
+func (r *RefreshHookRepository) List(ctx context.Context) ([]*domain.RefreshHook, error) {
+	query := `
+		SELECT id, namespace, name, source_prefix, artifact_type, kind, url, step_type, enabled, created_at, updated_at
+		FROM refresh_hooks
+		WHERE namespace = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []*domain.RefreshHook
+	for rows.Next() {
+		hook, err := r.scanHook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+func (r *RefreshHookRepository) Update(ctx context.Context, hook *domain.RefreshHook) error {
+	query := `
+		UPDATE refresh_hooks
+		SET name = $2, source_prefix = $3, artifact_type = $4, kind = $5, url = $6, step_type = $7, enabled = $8, updated_at = $9
+		WHERE id = $1 AND namespace = $10
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		hook.ID,
+		hook.Name,
+		hook.SourcePrefix,
+		hook.ArtifactType,
+		hook.Kind,
+		hook.URL,
+		hook.StepType,
+		hook.Enabled,
+		hook.UpdatedAt,
+		domain.NamespaceFromContext(ctx),
+	)
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "refresh hook", hook.ID)
+}
+
+func (r *RefreshHookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM refresh_hooks WHERE id = $1 AND namespace = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	return requireRow(result, "refresh hook", id)
+}
+
+func (r *RefreshHookRepository) scanHook(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.RefreshHook, error) {
+	var hook domain.RefreshHook
+	err := row.Scan(
+		&hook.ID,
+		&hook.Namespace,
+		&hook.Name,
+		&hook.SourcePrefix,
+		&hook.ArtifactType,
+		&hook.Kind,
+		&hook.URL,
+		&hook.StepType,
+		&hook.Enabled,
+		&hook.CreatedAt,
+		&hook.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &hook, nil
+}
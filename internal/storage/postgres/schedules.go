@@ -0,0 +1,287 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+type ScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+func (r *ScheduleRepository) Store(ctx context.Context, schedule *domain.WorkflowSchedule) error {
+	contextJSON, stepsJSON, err := marshalSchedule(schedule)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO workflow_schedules (id, namespace, name, cron, template_name, template_version, goal, context, steps, enabled, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		schedule.ID,
+		schedule.Namespace,
+		schedule.Name,
+		schedule.Cron,
+		schedule.Template,
+		schedule.TemplateVersion,
+		schedule.Goal,
+		contextJSON,
+		stepsJSON,
+		schedule.Enabled,
+		schedule.NextRunAt,
+		schedule.CreatedAt,
+		schedule.UpdatedAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *ScheduleRepository) Get(ctx context.Context, id uuid.UUID) (*domain.WorkflowSchedule, error) {
+	query := `
+		SELECT id, namespace, name, cron, template_name, template_version, goal, context, steps, enabled, next_run_at, last_run_at, created_at, updated_at
+		FROM workflow_schedules
+		WHERE id = $1 AND namespace = $2
+	`
+
+	row := r.db.QueryRowContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	return r.scanSchedule(row)
+}
+
+func (r *ScheduleRepository) List(ctx context.Context) ([]*domain.WorkflowSchedule, error) {
+	query := `
+		SELECT id, namespace, name, cron, template_name, template_version, goal, context, steps, enabled, next_run_at, last_run_at, created_at, updated_at
+		FROM workflow_schedules
+		WHERE namespace = $1
+		ORDER BY name
+	`
+
+	return r.querySchedules(ctx, query, domain.NamespaceFromContext(ctx))
+}
+
+func (r *ScheduleRepository) Update(ctx context.Context, schedule *domain.WorkflowSchedule) error {
+	contextJSON, stepsJSON, err := marshalSchedule(schedule)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE workflow_schedules
+		SET name = $2, cron = $3, template_name = $4, template_version = $5, goal = $6, context = $7,
+			steps = $8, enabled = $9, next_run_at = $10, updated_at = $11
+		WHERE id = $1 AND namespace = $12
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		schedule.ID,
+		schedule.Name,
+		schedule.Cron,
+		schedule.Template,
+		schedule.TemplateVersion,
+		schedule.Goal,
+		contextJSON,
+		stepsJSON,
+		schedule.Enabled,
+		schedule.NextRunAt,
+		schedule.UpdatedAt,
+		domain.NamespaceFromContext(ctx),
+	)
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "schedule", schedule.ID)
+}
+
+func (r *ScheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM workflow_schedules WHERE id = $1 AND namespace = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	return requireRow(result, "schedule", id)
+}
+
+// ListDue returns enabled schedules of every namespace whose next run is at or before now
+func (r *ScheduleRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.WorkflowSchedule, error) {
+	query := `
+		SELECT id, namespace, name, cron, template_name, template_version, goal, context, steps, enabled, next_run_at, last_run_at, created_at, updated_at
+		FROM workflow_schedules
+		WHERE enabled AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2
+	`
+
+	return r.querySchedules(ctx, query, now, limit)
+}
+
+// Advance moves a schedule's next run from `from` to next, reporting false if
+// another scheduler instance already advanced it. A zero next disables the
+// schedule instead, leaving its next run where it was
+func (r *ScheduleRepository) Advance(ctx context.Context, id uuid.UUID, from, next time.Time) (bool, error) {
+	query := `
+		UPDATE workflow_schedules
+		SET next_run_at = COALESCE($3, next_run_at), enabled = enabled AND $3::timestamptz IS NOT NULL, last_run_at = NOW()
+		WHERE id = $1 AND next_run_at = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, from, sql.NullTime{Time: next, Valid: !next.IsZero()})
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (r *ScheduleRepository) StoreRun(ctx context.Context, run *domain.ScheduleRun) error {
+	query := `
+		INSERT INTO schedule_runs (id, schedule_id, namespace, session_id, status, error, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		run.ID,
+		run.ScheduleID,
+		run.Namespace,
+		run.SessionID,
+		run.Status,
+		run.Error,
+		run.StartedAt,
+		run.FinishedAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *ScheduleRepository) UpdateRun(ctx context.Context, run *domain.ScheduleRun) error {
+	query := `
+		UPDATE schedule_runs
+		SET session_id = $2, status = $3, error = $4, finished_at = $5
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, run.ID, run.SessionID, run.Status, run.Error, run.FinishedAt)
+	return mapError(ctx, err)
+}
+
+func (r *ScheduleRepository) ListRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]*domain.ScheduleRun, error) {
+	query := `
+		SELECT id, schedule_id, namespace, session_id, status, error, started_at, finished_at
+		FROM schedule_runs
+		WHERE schedule_id = $1 AND namespace = $2
+		ORDER BY started_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, scheduleID, domain.NamespaceFromContext(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*domain.ScheduleRun
+	for rows.Next() {
+		var run domain.ScheduleRun
+		var runError sql.NullString
+		if err := rows.Scan(
+			&run.ID,
+			&run.ScheduleID,
+			&run.Namespace,
+			&run.SessionID,
+			&run.Status,
+			&runError,
+			&run.StartedAt,
+			&run.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		run.Error = runError.String
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *ScheduleRepository) querySchedules(ctx context.Context, query string, args ...interface{}) ([]*domain.WorkflowSchedule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*domain.WorkflowSchedule
+	for rows.Next() {
+		schedule, err := r.scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *ScheduleRepository) scanSchedule(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.WorkflowSchedule, error) {
+	var schedule domain.WorkflowSchedule
+	var contextJSON, stepsJSON []byte
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Namespace,
+		&schedule.Name,
+		&schedule.Cron,
+		&schedule.Template,
+		&schedule.TemplateVersion,
+		&schedule.Goal,
+		&contextJSON,
+		&stepsJSON,
+		&schedule.Enabled,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if len(contextJSON) > 0 {
+		if err := json.Unmarshal(contextJSON, &schedule.Context); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(stepsJSON, &schedule.Steps); err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func marshalSchedule(schedule *domain.WorkflowSchedule) ([]byte, []byte, error) {
+	contextJSON, err := json.Marshal(schedule.Context)
+	if err != nil {
+		return nil, nil, err
+	}
+	stepsJSON, err := json.Marshal(schedule.Steps)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contextJSON, stepsJSON, nil
+}
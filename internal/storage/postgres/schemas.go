@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type StepSchemaRepository struct {
+	db *sql.DB
+}
+
+func NewStepSchemaRepository(db *sql.DB) *StepSchemaRepository {
+	return &StepSchemaRepository{db: db}
+}
+
+func (r *StepSchemaRepository) Store(ctx context.Context, schema *domain.StepSchema) error {
+	inputJSON, err := marshalSchema(schema.InputSchema)
+	if err != nil {
+		return err
+	}
+	outputJSON, err := marshalSchema(schema.OutputSchema)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO step_schemas (namespace, step_type, input_schema, output_schema, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (namespace, step_type) DO UPDATE SET
+			input_schema = EXCLUDED.input_schema,
+			output_schema = EXCLUDED.output_schema,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		schema.Namespace,
+		schema.StepType,
+		inputJSON,
+		outputJSON,
+		schema.UpdatedAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *StepSchemaRepository) Get(ctx context.Context, stepType string) (*domain.StepSchema, error) {
+	query := `
+		SELECT namespace, step_type, input_schema, output_schema, updated_at
+		FROM step_schemas
+		WHERE namespace = $1 AND step_type = $2
+	`
+
+	row := r.db.QueryRowContext(ctx, query, domain.NamespaceFromContext(ctx), stepType)
+	return r.scanSchema(row)
+}
+
+func (r *StepSchemaRepository) List(ctx context.Context) ([]*domain.StepSchema, error) {
+	query := `
+		SELECT namespace, step_type, input_schema, output_schema, updated_at
+		FROM step_schemas
+		WHERE namespace = $1
+		ORDER BY step_type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemas []*domain.StepSchema
+	for rows.Next() {
+		schema, err := r.scanSchema(rows)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+func (r *StepSchemaRepository) Delete(ctx context.Context, stepType string) error {
+	query := `DELETE FROM step_schemas WHERE namespace = $1 AND step_type = $2`
+
+	result, err := r.db.ExecContext(ctx, query, domain.NamespaceFromContext(ctx), stepType)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.NewNotFoundError("step schema", stepType)
+	}
+	return nil
+}
+
+func (r *StepSchemaRepository) scanSchema(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.StepSchema, error) {
+	var schema domain.StepSchema
+	var inputJSON, outputJSON []byte
+
+	err := row.Scan(
+		&schema.Namespace,
+		&schema.StepType,
+		&inputJSON,
+		&outputJSON,
+		&schema.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if len(inputJSON) > 0 {
+		if err := json.Unmarshal(inputJSON, &schema.InputSchema); err != nil {
+			return nil, err
+		}
+	}
+	if len(outputJSON) > 0 {
+		if err := json.Unmarshal(outputJSON, &schema.OutputSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	return &schema, nil
+}
+
+// marshalSchema encodes a schema for storage, keeping a missing schema as NULL
+func marshalSchema(schema map[string]interface{}) ([]byte, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	return json.Marshal(schema)
+}
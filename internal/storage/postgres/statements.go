@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// statements prepares hot queries on first use and reuses them, so lookups
+// skip parsing and planning on every call. database/sql transparently prepares
+// a statement again on pool connections that have not seen it yet
+type statements struct {
+	db    *sql.DB
+	mu    sync.Mutex
+	cache map[string]*sql.Stmt
+}
+
+func newStatements(db *sql.DB) *statements {
+	return &statements{db: db, cache: make(map[string]*sql.Stmt)}
+}
+
+// prepared returns the prepared statement for query, preparing it if needed.
+// A failed prepare is not cached, so it is retried on the next call
+func (s *statements) prepared(ctx context.Context, query string) (*sql.Stmt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stmt, ok := s.cache[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s.cache[query] = stmt
+	return stmt, nil
+}
+
+// queryRow runs a prepared single-row query. A prepare error is returned as a
+// row whose Scan fails with it, so callers scan as they would a plain query
+func (s *statements) queryRow(ctx context.Context, query string, args ...interface{}) rowScanner {
+	stmt, err := s.prepared(ctx, query)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error {
+	return r.err
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// TaskQueue is a durable task queue in the queued_tasks table. Consumers lock
+// due tasks with SKIP LOCKED, so replicas never take the same task at once
+type TaskQueue struct {
+	db    *sql.DB
+	lease time.Duration
+}
+
+func NewTaskQueue(db *sql.DB, lease time.Duration) *TaskQueue {
+	return &TaskQueue{db: db, lease: lease}
+}
+
+func (q *TaskQueue) Enqueue(ctx context.Context, queue string, payload []byte, runAt time.Time) error {
+	query := `
+		INSERT INTO queued_tasks (id, queue, payload, run_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := q.db.ExecContext(ctx, query, uuid.New(), queue, payload, runAt)
+	return mapError(ctx, err)
+}
+
+func (q *TaskQueue) Dequeue(ctx context.Context, queue string, limit int) ([]*domain.QueuedTask, error) {
+	query := `
+		UPDATE queued_tasks
+		SET run_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM queued_tasks
+			WHERE queue = $1 AND run_at <= NOW()
+			ORDER BY run_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, queue, payload, attempt
+	`
+
+	rows, err := q.db.QueryContext(ctx, query, queue, limit, q.lease.Milliseconds())
+	if err != nil {
+		return nil, mapError(ctx, err)
+	}
+	defer rows.Close()
+
+	var tasks []*domain.QueuedTask
+	for rows.Next() {
+		var task domain.QueuedTask
+		if err := rows.Scan(&task.ID, &task.Queue, &task.Payload, &task.Attempt); err != nil {
+			return nil, err
+		}
+		task.Receipt = task.ID
+		tasks = append(tasks, &task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (q *TaskQueue) Ack(ctx context.Context, task *domain.QueuedTask) error {
+	_, err := q.db.ExecContext(ctx, `DELETE FROM queued_tasks WHERE id = $1`, task.Receipt)
+	return mapError(ctx, err)
+}
+
+func (q *TaskQueue) Retry(ctx context.Context, task *domain.QueuedTask, runAt time.Time) error {
+	query := `UPDATE queued_tasks SET attempt = attempt + 1, run_at = $2 WHERE id = $1`
+	_, err := q.db.ExecContext(ctx, query, task.Receipt, runAt)
+	return mapError(ctx, err)
+}
+
+// Close is a no-op; the database connection is shared
+func (q *TaskQueue) Close() error {
+	return nil
+}
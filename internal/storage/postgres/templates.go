@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+type TemplateRepository struct {
+	db *sql.DB
+}
+
+func NewTemplateRepository(db *sql.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+func (r *TemplateRepository) Store(ctx context.Context, template *domain.WorkflowTemplate) error {
+	query := `
+		INSERT INTO workflow_templates (id, namespace, name, version, description, steps, compatible_from, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		template.ID,
+		template.Namespace,
+		template.Name,
+		template.Version,
+		template.Description,
+		template.Steps,
+		template.CompatibleFrom,
+		template.CreatedAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *TemplateRepository) Get(ctx context.Context, name string, version int) (*domain.WorkflowTemplate, error) {
+	query := `
+		SELECT id, namespace, name, version, description, steps, compatible_from, created_at
+		FROM workflow_templates
+		WHERE namespace = $1 AND name = $2 AND ($3 = 0 OR version = $3)
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	row := r.db.QueryRowContext(ctx, query, domain.NamespaceFromContext(ctx), name, version)
+	return r.scanTemplate(row)
+}
+
+func (r *TemplateRepository) ListLatest(ctx context.Context) ([]*domain.WorkflowTemplate, error) {
+	query := `
+		SELECT DISTINCT ON (name) id, namespace, name, version, description, steps, compatible_from, created_at
+		FROM workflow_templates
+		WHERE namespace = $1
+		ORDER BY name, version DESC
+	`
+
+	return r.queryTemplates(ctx, query, domain.NamespaceFromContext(ctx))
+}
+
+func (r *TemplateRepository) ListVersions(ctx context.Context, name string) ([]*domain.WorkflowTemplate, error) {
+	query := `
+		SELECT id, namespace, name, version, description, steps, compatible_from, created_at
+		FROM workflow_templates
+		WHERE namespace = $1 AND name = $2
+		ORDER BY version DESC
+	`
+
+	return r.queryTemplates(ctx, query, domain.NamespaceFromContext(ctx), name)
+}
+
+func (r *TemplateRepository) queryTemplates(ctx context.Context, query string, args ...interface{}) ([]*domain.WorkflowTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*domain.WorkflowTemplate
+	for rows.Next() {
+		template, err := r.scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+func (r *TemplateRepository) scanTemplate(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.WorkflowTemplate, error) {
+	var template domain.WorkflowTemplate
+	var description sql.NullString
+
+	err := row.Scan(
+		&template.ID,
+		&template.Namespace,
+		&template.Name,
+		&template.Version,
+		&description,
+		textArray(&template.Steps),
+		&template.CompatibleFrom,
+		&template.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	template.Description = description.String
+	return &template, nil
+}
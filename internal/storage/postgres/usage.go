@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/anunay/mentis/internal/core/domain"
+)
+
+const dateLayout = "2006-01-02"
+
+type UsageRepository struct {
+	db *sql.DB
+}
+
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// Aggregate rolls the source tables up into usage_summary_daily. Publishes count
+// artifacts created and versions superseded by a republish; bytes are only
+// measured for today, so earlier snapshots are kept. Days are UTC.
+// Counts never decrease, so re-aggregating a day after its artifacts were
+// purged or evicted keeps the history already recorded
+func (r *UsageRepository) Aggregate(ctx context.Context, from, to, today time.Time) (int64, error) {
+	query := `
+		INSERT INTO usage_summary_daily (namespace, day, publishes, lookups, cache_hits, embeddings, bytes_stored, aggregated_at)
+		SELECT namespace, day,
+			COALESCE(SUM(publishes), 0),
+			COALESCE(SUM(lookups), 0),
+			COALESCE(SUM(cache_hits), 0),
+			COALESCE(SUM(embeddings), 0),
+			SUM(bytes_stored),
+			NOW()
+		FROM (
+			SELECT namespace, (created_at AT TIME ZONE 'UTC')::date AS day, COUNT(*) AS publishes, 0 AS lookups, 0 AS cache_hits, 0 AS embeddings, NULL::bigint AS bytes_stored
+			FROM artifacts
+			WHERE created_at >= $1::timestamp AT TIME ZONE 'UTC' AND created_at < ($2::date + 1)::timestamp AT TIME ZONE 'UTC'
+				AND metadata->>'chunk_of' IS NULL
+			GROUP BY namespace, (created_at AT TIME ZONE 'UTC')::date
+			UNION ALL
+			SELECT namespace, (superseded_at AT TIME ZONE 'UTC')::date, COUNT(*), 0, 0, 0, NULL
+			FROM artifact_versions
+			WHERE superseded_at >= $1::timestamp AT TIME ZONE 'UTC' AND superseded_at < ($2::date + 1)::timestamp AT TIME ZONE 'UTC'
+			GROUP BY namespace, (superseded_at AT TIME ZONE 'UTC')::date
+			UNION ALL
+			SELECT namespace, day, 0, SUM(hits + misses) FILTER (WHERE kind = $3), SUM(hits), 0, NULL
+			FROM cache_stats_daily
+			WHERE day BETWEEN $1::date AND $2::date
+			GROUP BY namespace, day
+			UNION ALL
+			SELECT namespace, day, 0, 0, 0, embeddings, NULL
+			FROM usage_daily
+			WHERE day BETWEEN $1::date AND $2::date
+			UNION ALL
+			SELECT namespace, $4::date, 0, 0, 0, 0, SUM(content_size)
+			FROM artifacts
+			WHERE $4::date BETWEEN $1::date AND $2::date
+			GROUP BY namespace
+		) counts
+		GROUP BY namespace, day
+		ON CONFLICT (namespace, day) DO UPDATE SET
+			publishes = GREATEST(usage_summary_daily.publishes, EXCLUDED.publishes),
+			lookups = GREATEST(usage_summary_daily.lookups, EXCLUDED.lookups),
+			cache_hits = GREATEST(usage_summary_daily.cache_hits, EXCLUDED.cache_hits),
+			embeddings = GREATEST(usage_summary_daily.embeddings, EXCLUDED.embeddings),
+			bytes_stored = COALESCE(EXCLUDED.bytes_stored, usage_summary_daily.bytes_stored),
+			aggregated_at = EXCLUDED.aggregated_at
+	`
+
+	result, err := r.db.ExecContext(ctx, query, from.UTC().Format(dateLayout), to.UTC().Format(dateLayout), domain.CacheStatLookup, today.UTC().Format(dateLayout))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *UsageRepository) Summaries(ctx context.Context, from, to time.Time, namespace string) ([]domain.DailyUsage, error) {
+	query := `
+		SELECT namespace, day, publishes, lookups, cache_hits, embeddings, bytes_stored
+		FROM usage_summary_daily
+		WHERE day BETWEEN $1::date AND $2::date AND ($3 = '' OR namespace = $3)
+		ORDER BY day, namespace
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from.Format(dateLayout), to.Format(dateLayout), namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []domain.DailyUsage{}
+	for rows.Next() {
+		var day domain.DailyUsage
+		var bytesStored sql.NullInt64
+		if err := rows.Scan(&day.Namespace, &day.Day, &day.Publishes, &day.Lookups, &day.CacheHits, &day.Embeddings, &bytesStored); err != nil {
+			return nil, err
+		}
+		if bytesStored.Valid {
+			day.BytesStored = &bytesStored.Int64
+		}
+		usage = append(usage, day)
+	}
+
+	return usage, rows.Err()
+}
@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+const webhookSubscriptionColumns = `id, namespace, url, event_types, secret, enabled, created_at, updated_at`
+
+type WebhookSubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookSubscriptionRepository(db *sql.DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: db}
+}
+
+func (r *WebhookSubscriptionRepository) Store(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (id, namespace, url, event_types, secret, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		subscription.ID,
+		subscription.Namespace,
+		subscription.URL,
+		eventTypeStrings(subscription.EventTypes),
+		subscription.Secret,
+		subscription.Enabled,
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+	)
+	return mapError(ctx, err)
+}
+
+func (r *WebhookSubscriptionRepository) Get(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE id = $1 AND namespace = $2
+	`
+
+	row := r.db.QueryRowContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	return r.scanSubscription(row)
+}
+
+func (r *WebhookSubscriptionRepository) List(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE namespace = $1
+		ORDER BY created_at
+	`
+
+	return r.querySubscriptions(ctx, query, domain.NamespaceFromContext(ctx))
+}
+
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2, event_types = $3, secret = $4, enabled = $5, updated_at = $6
+		WHERE id = $1 AND namespace = $7
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		subscription.ID,
+		subscription.URL,
+		eventTypeStrings(subscription.EventTypes),
+		subscription.Secret,
+		subscription.Enabled,
+		subscription.UpdatedAt,
+		domain.NamespaceFromContext(ctx),
+	)
+	if err != nil {
+		return mapError(ctx, err)
+	}
+	return requireRow(result, "webhook subscription", subscription.ID)
+}
+
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1 AND namespace = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	return requireRow(result, "webhook subscription", id)
+}
+
+func (r *WebhookSubscriptionRepository) ListForEvent(ctx context.Context, namespace string, eventType domain.WebhookEventType) ([]*domain.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE namespace = $1 AND enabled AND $2 = ANY(event_types)
+	`
+
+	return r.querySubscriptions(ctx, query, namespace, string(eventType))
+}
+
+func (r *WebhookSubscriptionRepository) RecordDelivery(ctx context.Context, delivery *domain.WebhookDelivery, keep int) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, attempt, status_code, success, error, duration_ms, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		delivery.ID,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Attempt,
+		delivery.StatusCode,
+		delivery.Success,
+		delivery.Error,
+		delivery.DurationMs,
+		delivery.CreatedAt,
+	)
+	if err != nil || keep <= 0 {
+		return mapError(ctx, err)
+	}
+
+	// Trim the log so a busy subscription doesn't grow it without bound
+	query = `
+		DELETE FROM webhook_deliveries
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE subscription_id = $1
+			ORDER BY created_at DESC
+			OFFSET $2
+		)
+	`
+
+	_, err = r.db.ExecContext(ctx, query, delivery.SubscriptionID, keep)
+	return mapError(ctx, err)
+}
+
+func (r *WebhookSubscriptionRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT id, subscription_id, event_id, event_type, attempt, status_code, success, error, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*domain.WebhookDelivery{}
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Attempt,
+			&delivery.StatusCode,
+			&delivery.Success,
+			&delivery.Error,
+			&delivery.DurationMs,
+			&delivery.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookSubscriptionRepository) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]*domain.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*domain.WebhookSubscription
+	for rows.Next() {
+		subscription, err := r.scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (r *WebhookSubscriptionRepository) scanSubscription(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	var eventTypes []string
+
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.Namespace,
+		&subscription.URL,
+		textArray(&eventTypes),
+		&subscription.Secret,
+		&subscription.Enabled,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, t := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, domain.WebhookEventType(t))
+	}
+
+	return &subscription, nil
+}
+
+func eventTypeStrings(eventTypes []domain.WebhookEventType) []string {
+	strs := make([]string, len(eventTypes))
+	for i, t := range eventTypes {
+		strs[i] = string(t)
+	}
+	return strs
+}
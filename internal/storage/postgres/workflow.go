@@ -11,11 +11,12 @@ import (
 )
 
 type WorkflowRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts *statements
 }
 
 func NewWorkflowRepository(db *sql.DB) *WorkflowRepository {
-	return &WorkflowRepository{db: db}
+	return &WorkflowRepository{db: db, stmts: newStatements(db)}
 }
 
 func (r *WorkflowRepository) StoreSession(ctx context.Context, session *domain.WorkflowSession) error {
@@ -25,34 +26,43 @@ func (r *WorkflowRepository) StoreSession(ctx context.Context, session *domain.W
 	}
 
 	query := `
-		INSERT INTO workflow_sessions (id, goal, context, created_at, updated_at, status)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO workflow_sessions (id, namespace, goal, context, callback_urls, created_at, updated_at, status, template_name, template_version, parent_session_id, forked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (id) DO UPDATE SET
 			goal = EXCLUDED.goal,
 			context = EXCLUDED.context,
 			updated_at = EXCLUDED.updated_at,
 			status = EXCLUDED.status
+		WHERE workflow_sessions.namespace = EXCLUDED.namespace
 	`
 
 	_, err = r.db.ExecContext(ctx, query,
 		session.ID,
+		session.Namespace,
 		session.Goal,
 		contextJSON,
+		session.CallbackURLs,
 		session.CreatedAt,
 		session.UpdatedAt,
 		session.Status,
+		session.TemplateName,
+		session.TemplateVersion,
+		session.ParentID,
+		session.ForkedAt,
 	)
-	return err
+	return mapError(ctx, err)
 }
 
 func (r *WorkflowRepository) GetSession(ctx context.Context, id uuid.UUID) (*domain.WorkflowSession, error) {
 	query := `
-		SELECT id, goal, context, created_at, updated_at, status
+		SELECT id, namespace, goal, context, callback_urls, created_at, updated_at, status, template_name, template_version, parent_session_id, forked_at,
+			cache_hits, saved_prompt_tokens, saved_completion_tokens, saved_embedding_tokens, saved_cost_usd, saved_seconds,
+			(SELECT COUNT(*) FROM workflow_steps WHERE session_id = workflow_sessions.id AND status <> 'pending')
 		FROM workflow_sessions
-		WHERE id = $1
+		WHERE id = $1 AND namespace = $2
 	`
 
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := r.db.QueryRowContext(ctx, query, id, domain.NamespaceFromContext(ctx))
 	return r.scanSession(row)
 }
 
@@ -65,7 +75,7 @@ func (r *WorkflowRepository) UpdateSession(ctx context.Context, session *domain.
 	query := `
 		UPDATE workflow_sessions
 		SET goal = $2, context = $3, updated_at = $4, status = $5
-		WHERE id = $1
+		WHERE id = $1 AND namespace = $6
 	`
 
 	_, err = r.db.ExecContext(ctx, query,
@@ -74,8 +84,41 @@ func (r *WorkflowRepository) UpdateSession(ctx context.Context, session *domain.
 		contextJSON,
 		time.Now(),
 		session.Status,
+		domain.NamespaceFromContext(ctx),
 	)
-	return err
+	return mapError(ctx, err)
+}
+
+// ListSessions returns sessions newest first, starting after the given position
+func (r *WorkflowRepository) ListSessions(ctx context.Context, limit int, after *domain.PageKey) ([]*domain.WorkflowSession, error) {
+	query := `
+		SELECT id, namespace, goal, context, callback_urls, created_at, updated_at, status, template_name, template_version, parent_session_id, forked_at,
+			cache_hits, saved_prompt_tokens, saved_completion_tokens, saved_embedding_tokens, saved_cost_usd, saved_seconds,
+			(SELECT COUNT(*) FROM workflow_steps WHERE session_id = workflow_sessions.id AND status <> 'pending')
+		FROM workflow_sessions
+		WHERE namespace = $2
+			AND ($3::timestamptz IS NULL OR (created_at, id) < ($3::timestamptz, $4::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`
+
+	afterTime, afterID := pageKeyArgs(after)
+	rows, err := r.db.QueryContext(ctx, query, limit, domain.NamespaceFromContext(ctx), afterTime, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.WorkflowSession
+	for rows.Next() {
+		session, err := r.scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
 }
 
 func (r *WorkflowRepository) StoreStep(ctx context.Context, step *domain.WorkflowStep) error {
@@ -85,18 +128,24 @@ func (r *WorkflowRepository) StoreStep(ctx context.Context, step *domain.Workflo
 	}
 
 	query := `
-		INSERT INTO workflow_steps (id, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO workflow_steps (id, namespace, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status, template_name, template_version, prompt_tokens, completion_tokens, embedding_tokens, cost_usd, forced)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		ON CONFLICT (id) DO UPDATE SET
 			artifact_id = EXCLUDED.artifact_id,
 			output_hash = EXCLUDED.output_hash,
 			metadata = EXCLUDED.metadata,
 			completed_at = EXCLUDED.completed_at,
-			status = EXCLUDED.status
+			status = EXCLUDED.status,
+			prompt_tokens = EXCLUDED.prompt_tokens,
+			completion_tokens = EXCLUDED.completion_tokens,
+			embedding_tokens = EXCLUDED.embedding_tokens,
+			cost_usd = EXCLUDED.cost_usd
+		WHERE workflow_steps.namespace = EXCLUDED.namespace
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		step.ID,
+		step.Namespace,
 		step.SessionID,
 		step.StepType,
 		step.ArtifactID,
@@ -106,18 +155,25 @@ func (r *WorkflowRepository) StoreStep(ctx context.Context, step *domain.Workflo
 		step.CreatedAt,
 		step.CompletedAt,
 		step.Status,
+		step.TemplateName,
+		step.TemplateVersion,
+		step.Usage.PromptTokens,
+		step.Usage.CompletionTokens,
+		step.Usage.EmbeddingTokens,
+		step.Usage.CostUSD,
+		step.Forced,
 	)
-	return err
+	return mapError(ctx, err)
 }
 
 func (r *WorkflowRepository) GetStep(ctx context.Context, id uuid.UUID) (*domain.WorkflowStep, error) {
 	query := `
-		SELECT id, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status
+		SELECT id, namespace, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status, template_name, template_version, prompt_tokens, completion_tokens, embedding_tokens, cost_usd, forced, superseded_by
 		FROM workflow_steps
-		WHERE id = $1
+		WHERE id = $1 AND namespace = $2
 	`
 
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := r.db.QueryRowContext(ctx, query, id, domain.NamespaceFromContext(ctx))
 	return r.scanStep(row)
 }
 
@@ -129,32 +185,52 @@ func (r *WorkflowRepository) UpdateStep(ctx context.Context, step *domain.Workfl
 
 	query := `
 		UPDATE workflow_steps
-		SET artifact_id = $2, output_hash = $3, metadata = $4, completed_at = $5, status = $6
-		WHERE id = $1
+		SET artifact_id = $2, output_hash = $3, metadata = $4, completed_at = $5, status = $6,
+			prompt_tokens = $8, completion_tokens = $9, embedding_tokens = $10, cost_usd = $11
+		WHERE id = $1 AND namespace = $7
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		step.ID,
 		step.ArtifactID,
 		step.OutputHash,
 		metadataJSON,
 		step.CompletedAt,
 		step.Status,
+		domain.NamespaceFromContext(ctx),
+		step.Usage.PromptTokens,
+		step.Usage.CompletionTokens,
+		step.Usage.EmbeddingTokens,
+		step.Usage.CostUSD,
 	)
-	return err
+	return mapError(ctx, err)
 }
 
-func (r *WorkflowRepository) GetStepsBySession(ctx context.Context, sessionID uuid.UUID) ([]*domain.WorkflowStep, error) {
+// HeartbeatStep records that the step's inline execution is still running
+func (r *WorkflowRepository) HeartbeatStep(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE workflow_steps SET heartbeat_at = NOW() WHERE id = $1`, id)
+	return mapError(ctx, err)
+}
+
+// FailAbandonedSteps fails unfinished steps in any namespace whose last
+// heartbeat, or creation if they never had one, came before the given time and
+// that no queued or running job will pick up, and returns them
+func (r *WorkflowRepository) FailAbandonedSteps(ctx context.Context, before time.Time) ([]*domain.WorkflowStep, error) {
 	query := `
-		SELECT id, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status
-		FROM workflow_steps
-		WHERE session_id = $1
-		ORDER BY created_at ASC
+		UPDATE workflow_steps s
+		SET status = 'failed'
+		WHERE s.status IN ('pending', 'running', 'retrying', 'interrupted')
+			AND COALESCE(s.heartbeat_at, s.created_at) < $1
+			AND NOT EXISTS (
+				SELECT 1 FROM step_jobs j
+				WHERE j.step_id = s.id AND j.status IN ('queued', 'running')
+			)
+		RETURNING id, namespace, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status, template_name, template_version, prompt_tokens, completion_tokens, embedding_tokens, cost_usd, forced, superseded_by
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	rows, err := r.db.QueryContext(ctx, query, before)
 	if err != nil {
-		return nil, err
+		return nil, mapError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -170,49 +246,212 @@ func (r *WorkflowRepository) GetStepsBySession(ctx context.Context, sessionID uu
 	return steps, rows.Err()
 }
 
-func (r *WorkflowRepository) FindStepByInputHash(ctx context.Context, stepType, inputHash string) (*domain.WorkflowStep, error) {
+func (r *WorkflowRepository) GetStepsBySession(ctx context.Context, sessionID uuid.UUID) ([]*domain.WorkflowStep, error) {
 	query := `
-		SELECT id, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status
+		SELECT id, namespace, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status, template_name, template_version, prompt_tokens, completion_tokens, embedding_tokens, cost_usd, forced, superseded_by
 		FROM workflow_steps
-		WHERE step_type = $1 AND input_hash = $2 AND status = 'completed'
-		ORDER BY created_at DESC
-		LIMIT 1
+		WHERE session_id = $1 AND namespace = $2
+		ORDER BY created_at ASC
 	`
 
-	row := r.db.QueryRowContext(ctx, query, stepType, inputHash)
+	rows, err := r.db.QueryContext(ctx, query, sessionID, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []*domain.WorkflowStep
+	for rows.Next() {
+		step, err := r.scanStep(rows)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, rows.Err()
+}
+
+func (r *WorkflowRepository) FindStepByInputHash(ctx context.Context, stepType, inputHash string, filter domain.StepReuseFilter) (*domain.WorkflowStep, error) {
+	templateName, minVersion, maxVersion, sessionIDs := reuseFilterArgs(filter)
+	row := r.stmts.queryRow(ctx, findStepByInputHashQuery, stepType, inputHash, domain.NamespaceFromContext(ctx), templateName, minVersion, maxVersion, filter.AllNamespaces, sessionIDs)
 	return r.scanStep(row)
 }
 
-func (r *WorkflowRepository) FindSimilarSteps(ctx context.Context, stepType string, embedding []float32, topK int) ([]domain.WorkflowStepResult, error) {
-	// This is a simplified implementation - in production, you'd want to use pgvector
-	// or integrate with the vector database for similarity search
+// FindStepsByInputHashes finds the latest reusable completed step for each of
+// the input hashes in one query, keyed by input hash. Hashes without a
+// reusable step are left out
+func (r *WorkflowRepository) FindStepsByInputHashes(ctx context.Context, stepType string, inputHashes []string, filter domain.StepReuseFilter) (map[string]*domain.WorkflowStep, error) {
+	steps := make(map[string]*domain.WorkflowStep)
+	if len(inputHashes) == 0 {
+		return steps, nil
+	}
+
+	templateName, minVersion, maxVersion, sessionIDs := reuseFilterArgs(filter)
 	query := `
-		SELECT id, session_id, step_type, artifact_id, input_hash, output_hash, metadata, created_at, completed_at, status
-		FROM workflow_steps
-		WHERE step_type = $1 AND status = 'completed'
-		ORDER BY created_at DESC
-		LIMIT $2
+		SELECT DISTINCT ON (s.input_hash) s.id, s.namespace, s.session_id, s.step_type, s.artifact_id, s.input_hash, s.output_hash, s.metadata, s.created_at, s.completed_at, s.status, s.template_name, s.template_version, s.prompt_tokens, s.completion_tokens, s.embedding_tokens, s.cost_usd, s.forced, s.superseded_by
+		FROM workflow_steps s
+		JOIN artifacts a ON a.id = s.artifact_id
+		WHERE s.step_type = $1 AND s.input_hash = ANY($2) AND s.status = 'completed'
+			AND ($7 OR s.namespace = $3)
+			AND ($8::uuid[] IS NULL OR s.session_id = ANY($8::uuid[]))
+			AND a.stale IS NOT TRUE
+			AND a.deleted_at IS NULL
+			AND (a.expires_at IS NULL OR a.expires_at > NOW())
+			AND s.superseded_by IS NULL
+			AND ($4 = '' OR (s.template_name = $4 AND s.template_version BETWEEN $5 AND $6))
+		ORDER BY s.input_hash, s.created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, stepType, topK)
+	rows, err := r.db.QueryContext(ctx, query, stepType, inputHashes, domain.NamespaceFromContext(ctx), templateName, minVersion, maxVersion, filter.AllNamespaces, sessionIDs)
 	if err != nil {
-		return nil, err
+		return nil, mapError(ctx, err)
 	}
 	defer rows.Close()
 
-	var results []domain.WorkflowStepResult
 	for rows.Next() {
 		step, err := r.scanStep(rows)
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, domain.WorkflowStepResult{
-			Step:  step,
-			Score: 1.0, // Placeholder - actual similarity scoring would be done by vector DB
-		})
+		steps[step.InputHash] = step
+	}
+
+	return steps, rows.Err()
+}
+
+// reuseFilterArgs flattens a reuse filter into query arguments. A nil session
+// array matches any session; an empty one matches none
+func reuseFilterArgs(filter domain.StepReuseFilter) (templateName string, minVersion, maxVersion int, sessionIDs []string) {
+	if compat := filter.Compat; compat != nil {
+		templateName, minVersion, maxVersion = compat.Name, compat.MinVersion, compat.MaxVersion
+	}
+	if filter.SessionIDs != nil {
+		sessionIDs = make([]string, len(filter.SessionIDs))
+		for i, id := range filter.SessionIDs {
+			sessionIDs[i] = id.String()
+		}
+	}
+	return templateName, minVersion, maxVersion, sessionIDs
+}
+
+// findStepByInputHashQuery runs before every step execution, so it is prepared.
+// Steps whose artifact was marked stale are never reused
+const findStepByInputHashQuery = `
+		SELECT s.id, s.namespace, s.session_id, s.step_type, s.artifact_id, s.input_hash, s.output_hash, s.metadata, s.created_at, s.completed_at, s.status, s.template_name, s.template_version, s.prompt_tokens, s.completion_tokens, s.embedding_tokens, s.cost_usd, s.forced, s.superseded_by
+		FROM workflow_steps s
+		JOIN artifacts a ON a.id = s.artifact_id
+		WHERE s.step_type = $1 AND s.input_hash = $2 AND s.status = 'completed'
+			AND ($7 OR s.namespace = $3)
+			AND ($8::uuid[] IS NULL OR s.session_id = ANY($8::uuid[]))
+			AND a.stale IS NOT TRUE
+			AND a.deleted_at IS NULL
+			AND (a.expires_at IS NULL OR a.expires_at > NOW())
+			AND s.superseded_by IS NULL
+			AND ($4 = '' OR (s.template_name = $4 AND s.template_version BETWEEN $5 AND $6))
+		ORDER BY s.created_at DESC
+		LIMIT 1
+	`
+
+// SupersedeSteps only touches steps of the caller's namespace, whatever the
+// filter's AllNamespaces
+func (r *WorkflowRepository) SupersedeSteps(ctx context.Context, step *domain.WorkflowStep, filter domain.StepReuseFilter) (int64, error) {
+	templateName, minVersion, maxVersion, sessionIDs := reuseFilterArgs(filter)
+	query := `
+		UPDATE workflow_steps
+		SET superseded_by = $1
+		WHERE step_type = $2 AND input_hash = $3 AND namespace = $4
+			AND id <> $1 AND status = 'completed' AND superseded_by IS NULL AND created_at <= $5
+			AND ($9::uuid[] IS NULL OR session_id = ANY($9::uuid[]))
+			AND ($6 = '' OR (template_name = $6 AND template_version BETWEEN $7 AND $8))
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, step.ID, step.StepType, step.InputHash, domain.NamespaceFromContext(ctx), step.CreatedAt, templateName, minVersion, maxVersion, sessionIDs)
+	if err != nil {
+		return 0, mapError(ctx, err)
 	}
+	return result.RowsAffected()
+}
 
-	return results, rows.Err()
+func (r *WorkflowRepository) RecordStepReuse(ctx context.Context, sessionID uuid.UUID, saved domain.Usage, savedTime time.Duration) error {
+	query := `
+		UPDATE workflow_sessions
+		SET cache_hits = cache_hits + 1,
+			saved_prompt_tokens = saved_prompt_tokens + $2,
+			saved_completion_tokens = saved_completion_tokens + $3,
+			saved_embedding_tokens = saved_embedding_tokens + $4,
+			saved_cost_usd = saved_cost_usd + $5,
+			saved_seconds = saved_seconds + $6
+		WHERE id = $1 AND namespace = $7
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		sessionID,
+		saved.PromptTokens,
+		saved.CompletionTokens,
+		saved.EmbeddingTokens,
+		saved.CostUSD,
+		savedTime.Seconds(),
+		domain.NamespaceFromContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	return requireRow(result, "session", sessionID)
+}
+
+// Savings counts executed steps as the non-pending steps the sessions ran themselves
+func (r *WorkflowRepository) Savings(ctx context.Context, since time.Time) (*domain.SavingsReport, error) {
+	query := `
+		SELECT COUNT(*),
+			COALESCE(SUM(cache_hits), 0),
+			COALESCE(SUM(saved_prompt_tokens), 0),
+			COALESCE(SUM(saved_completion_tokens), 0),
+			COALESCE(SUM(saved_embedding_tokens), 0),
+			COALESCE(SUM(saved_cost_usd), 0),
+			COALESCE(SUM(saved_seconds), 0),
+			(
+				SELECT COUNT(*)
+				FROM workflow_steps st
+				JOIN workflow_sessions ws ON ws.id = st.session_id
+				WHERE ws.namespace = $1 AND ws.created_at >= $2 AND st.status <> 'pending'
+			)
+		FROM workflow_sessions
+		WHERE namespace = $1 AND created_at >= $2
+	`
+
+	report := &domain.SavingsReport{Since: since}
+	err := r.db.QueryRowContext(ctx, query, domain.NamespaceFromContext(ctx), since).Scan(
+		&report.Sessions,
+		&report.CacheHits,
+		&report.Saved.PromptTokens,
+		&report.Saved.CompletionTokens,
+		&report.Saved.EmbeddingTokens,
+		&report.Saved.CostUSD,
+		&report.SavedSeconds,
+		&report.ExecutedSteps,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *WorkflowRepository) MarkTemplateArtifactsStale(ctx context.Context, templateName string, beforeVersion int) (int64, error) {
+	query := `
+		UPDATE artifacts
+		SET stale = true, refresh_attempted_at = NULL, updated_at = NOW()
+		WHERE namespace = $3 AND stale = false AND id IN (
+			SELECT artifact_id FROM workflow_steps
+			WHERE template_name = $1 AND template_version < $2 AND namespace = $3 AND artifact_id IS NOT NULL
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, templateName, beforeVersion, domain.NamespaceFromContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 func (r *WorkflowRepository) scanSession(row interface {
@@ -223,11 +462,24 @@ func (r *WorkflowRepository) scanSession(row interface {
 
 	err := row.Scan(
 		&session.ID,
+		&session.Namespace,
 		&session.Goal,
 		&contextJSON,
+		textArray(&session.CallbackURLs),
 		&session.CreatedAt,
 		&session.UpdatedAt,
 		&session.Status,
+		&session.TemplateName,
+		&session.TemplateVersion,
+		&session.ParentID,
+		&session.ForkedAt,
+		&session.Savings.CacheHits,
+		&session.Savings.Saved.PromptTokens,
+		&session.Savings.Saved.CompletionTokens,
+		&session.Savings.Saved.EmbeddingTokens,
+		&session.Savings.Saved.CostUSD,
+		&session.Savings.SavedSeconds,
+		&session.Savings.ExecutedSteps,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -239,6 +491,7 @@ func (r *WorkflowRepository) scanSession(row interface {
 	if err := json.Unmarshal(contextJSON, &session.Context); err != nil {
 		return nil, err
 	}
+	session.Savings.Finish()
 
 	return &session, nil
 }
@@ -252,6 +505,7 @@ func (r *WorkflowRepository) scanStep(row interface {
 
 	err := row.Scan(
 		&step.ID,
+		&step.Namespace,
 		&step.SessionID,
 		&step.StepType,
 		&artifactID,
@@ -261,6 +515,14 @@ func (r *WorkflowRepository) scanStep(row interface {
 		&step.CreatedAt,
 		&step.CompletedAt,
 		&step.Status,
+		&step.TemplateName,
+		&step.TemplateVersion,
+		&step.Usage.PromptTokens,
+		&step.Usage.CompletionTokens,
+		&step.Usage.EmbeddingTokens,
+		&step.Usage.CostUSD,
+		&step.Forced,
+		&step.SupersededBy,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -7,6 +7,9 @@ import (
 	"github.com/anunay/mentis/internal/core/ports"
 	"github.com/anunay/mentis/internal/storage/vector/qdrant"
 	qdrant_client "github.com/qdrant/go-client/qdrant"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 // Provider represents the vector database provider
@@ -45,13 +48,22 @@ func newQdrantRepository(cfg config.QdrantConfig) (ports.VectorRepository, error
 		Port:   cfg.Port,
 		APIKey: cfg.APIKey,
 		UseTLS: cfg.UseTLS,
+		GrpcOptions: []grpc.DialOption{
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+			// Keepalive pings find dead connections before a request does
+			grpc.WithKeepaliveParams(keepalive.ClientParameters{
+				Time:                cfg.KeepaliveTime,
+				Timeout:             cfg.KeepaliveTimeout,
+				PermitWithoutStream: true,
+			}),
+		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create qdrant client: %w", err)
 	}
 	
 	// Create repository
-	repo := qdrant.NewRepository(client, cfg.Collection)
+	repo := qdrant.NewRepository(client, cfg)
 	return repo, nil
 }
 
@@ -3,28 +3,63 @@ package qdrant
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/anunay/mentis/internal/config"
 	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/ports"
 	"github.com/google/uuid"
 	"github.com/qdrant/go-client/qdrant"
 )
 
 // Repository uses the official Qdrant Go client (gRPC)
 type Repository struct {
-	client     *qdrant.Client
-	collection string
+	client       *qdrant.Client
+	collection   string
+	batchSize    int
+	timeout      time.Duration
+	writeTimeout time.Duration
+	retry        domain.RetryPolicy
+
+	// collectionReady remembers that the collection exists, so writes skip the
+	// check; a failed upsert clears it in case the collection was dropped
+	collectionReady atomic.Bool
 }
 
-func NewRepository(client *qdrant.Client, collection string) *Repository {
+func NewRepository(client *qdrant.Client, cfg config.QdrantConfig) *Repository {
+	batchSize := cfg.UpsertBatchSize
+	if batchSize <= 0 {
+		batchSize = 256
+	}
 	return &Repository{
-		client:     client,
-		collection: collection,
+		client:       client,
+		collection:   cfg.Collection,
+		batchSize:    batchSize,
+		timeout:      cfg.Timeout,
+		writeTimeout: cfg.WriteTimeout,
+		retry: domain.RetryPolicy{
+			MaxAttempts:    cfg.RetryMaxAttempts,
+			InitialBackoff: cfg.RetryInitialBackoff,
+			MaxBackoff:     cfg.RetryMaxBackoff,
+			Multiplier:     2,
+		},
 	}
 }
 
 func (r *Repository) ensureCollection(ctx context.Context) error {
+	if r.collectionReady.Load() {
+		return nil
+	}
+
 	// Check if collection exists
-	collections, err := r.client.ListCollections(ctx)
+	var collections []string
+	err := r.call(ctx, "ListCollections", r.timeout, func(ctx context.Context) error {
+		var err error
+		collections, err = r.client.ListCollections(ctx)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list collections: %w", err)
 	}
@@ -32,51 +67,130 @@ func (r *Repository) ensureCollection(ctx context.Context) error {
 	// Check if our collection exists - collections is a slice of strings
 	for _, collectionName := range collections {
 		if collectionName == r.collection {
+			r.collectionReady.Store(true)
 			return nil // Collection already exists
 		}
 	}
 
 	// Create collection with configurable dimensions (defaulting to 1536 for OpenAI)
 	// TODO: This should be configurable based on embedding provider
-	err = r.client.CreateCollection(ctx, &qdrant.CreateCollection{
-		CollectionName: r.collection,
-		VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
-			Size:     1536,
-			Distance: qdrant.Distance_Cosine,
-		}),
+	err = r.call(ctx, "CreateCollection", r.timeout, func(ctx context.Context) error {
+		return r.client.CreateCollection(ctx, &qdrant.CreateCollection{
+			CollectionName: r.collection,
+			VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
+				Size:     1536,
+				Distance: qdrant.Distance_Cosine,
+			}),
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create collection: %w", err)
 	}
 
+	r.collectionReady.Store(true)
 	return nil
 }
 
 func (r *Repository) Store(ctx context.Context, id uuid.UUID, embedding []float32, metadata map[string]interface{}) error {
+	return r.StoreBatch(ctx, []domain.VectorPoint{{ID: id, Embedding: embedding, Payload: metadata}})
+}
+
+func (r *Repository) StoreBatch(ctx context.Context, points []domain.VectorPoint) error {
+	for start := 0; start < len(points); start += r.batchSize {
+		end := min(start+r.batchSize, len(points))
+		if err := r.upsert(ctx, points[start:end], true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsert sends one upsert request; without wait, Qdrant acknowledges the points
+// once queued rather than once applied
+func (r *Repository) upsert(ctx context.Context, points []domain.VectorPoint, wait bool) error {
+	if len(points) == 0 {
+		return nil
+	}
 	if err := r.ensureCollection(ctx); err != nil {
 		return err
 	}
 
-	// Create point with UUID, vector, and metadata
-	point := &qdrant.PointStruct{
-		Id:      qdrant.NewID(id.String()),
-		Vectors: qdrant.NewVectors(embedding...),
-		Payload: qdrant.NewValueMap(metadata),
+	// Create points with UUID, vector, and metadata
+	structs := make([]*qdrant.PointStruct, len(points))
+	for i, point := range points {
+		structs[i] = &qdrant.PointStruct{
+			Id:      qdrant.NewID(point.ID.String()),
+			Vectors: qdrant.NewVectors(point.Embedding...),
+			Payload: qdrant.NewValueMap(point.Payload),
+		}
 	}
 
-	// Upsert the point
-	_, err := r.client.Upsert(ctx, &qdrant.UpsertPoints{
-		CollectionName: r.collection,
-		Points:         []*qdrant.PointStruct{point},
+	// Upsert the points
+	err := r.call(ctx, "Upsert", r.writeTimeout, func(ctx context.Context) error {
+		_, err := r.client.Upsert(ctx, &qdrant.UpsertPoints{
+			CollectionName: r.collection,
+			Wait:           qdrant.PtrOf(wait),
+			Points:         structs,
+		})
+		return err
 	})
 	if err != nil {
-		return fmt.Errorf("failed to store vector: %w", err)
+		r.collectionReady.Store(false)
+		return fmt.Errorf("failed to store vectors: %w", err)
 	}
 
 	return nil
 }
 
-func (r *Repository) Search(ctx context.Context, query []float32, topK int, minScore float32, filter map[string]interface{}) ([]domain.LookupResult, error) {
+func (r *Repository) NewBuffer() ports.VectorBuffer {
+	return &upsertBuffer{repo: r}
+}
+
+// upsertBuffer sends full batches without waiting. Qdrant applies updates to a
+// collection in order, so a waited upsert on flush also covers the batches
+// sent before it; when nothing is left to send, the last batch is sent again
+type upsertBuffer struct {
+	repo *Repository
+
+	mu      sync.Mutex
+	pending []domain.VectorPoint
+	last    []domain.VectorPoint
+}
+
+func (b *upsertBuffer) Add(ctx context.Context, point domain.VectorPoint) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, point)
+	if len(b.pending) < b.repo.batchSize {
+		return nil
+	}
+
+	batch := b.pending
+	b.pending = nil
+	if err := b.repo.upsert(ctx, batch, false); err != nil {
+		return err
+	}
+	b.last = batch
+	return nil
+}
+
+func (b *upsertBuffer) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch := b.pending
+	if len(batch) == 0 {
+		batch = b.last
+	}
+	if err := b.repo.upsert(ctx, batch, true); err != nil {
+		return err
+	}
+	b.pending, b.last = nil, nil
+	return nil
+}
+
+func (r *Repository) Search(ctx context.Context, query []float32, topK int, minScore float32, filter domain.Filter) ([]domain.LookupResult, error) {
 	// Build the query request
 	request := &qdrant.QueryPoints{
 		CollectionName: r.collection,
@@ -90,25 +204,15 @@ func (r *Repository) Search(ctx context.Context, query []float32, topK int, minS
 		request.ScoreThreshold = qdrant.PtrOf(minScore)
 	}
 
-	// Add filter if provided
-	if len(filter) > 0 {
-		// Convert filter to Qdrant filter format
-		conditions := make([]*qdrant.Condition, 0, len(filter))
-		for key, value := range filter {
-			// Type assert value to string for match condition
-			if strValue, ok := value.(string); ok {
-				conditions = append(conditions, qdrant.NewMatch(key, strValue))
-			}
-		}
-		if len(conditions) > 0 {
-			request.Filter = &qdrant.Filter{
-				Must: conditions,
-			}
-		}
-	}
+	request.Filter = buildFilter(filter)
 
 	// Execute the query
-	response, err := r.client.Query(ctx, request)
+	var response []*qdrant.ScoredPoint
+	err := r.call(ctx, "Query", r.timeout, func(ctx context.Context) error {
+		var err error
+		response, err = r.client.Query(ctx, request)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search vectors: %w", err)
 	}
@@ -139,6 +243,9 @@ func (r *Repository) Search(ctx context.Context, query []float32, topK int, minS
 				metadata[key] = extractValue(value)
 			}
 		}
+		if !matchesPrefixes(filter, metadata) {
+			continue
+		}
 
 		lookupResult := domain.LookupResult{
 			Score: result.Score,
@@ -155,9 +262,12 @@ func (r *Repository) Search(ctx context.Context, query []float32, topK int, minS
 
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Delete the point by ID
-	_, err := r.client.Delete(ctx, &qdrant.DeletePoints{
-		CollectionName: r.collection,
-		Points:         qdrant.NewPointsSelector(qdrant.NewID(id.String())),
+	err := r.call(ctx, "Delete", r.timeout, func(ctx context.Context) error {
+		_, err := r.client.Delete(ctx, &qdrant.DeletePoints{
+			CollectionName: r.collection,
+			Points:         qdrant.NewPointsSelector(qdrant.NewID(id.String())),
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete vector: %w", err)
@@ -171,6 +281,126 @@ func (r *Repository) Update(ctx context.Context, id uuid.UUID, embedding []float
 	return r.Store(ctx, id, embedding, metadata)
 }
 
+func (r *Repository) SetPayload(ctx context.Context, ids []uuid.UUID, payload map[string]interface{}) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pointIDs := make([]*qdrant.PointId, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = qdrant.NewID(id.String())
+	}
+
+	err := r.call(ctx, "SetPayload", r.timeout, func(ctx context.Context) error {
+		_, err := r.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+			CollectionName: r.collection,
+			Payload:        qdrant.NewValueMap(payload),
+			PointsSelector: qdrant.NewPointsSelector(pointIDs...),
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set vector payload: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) Scroll(ctx context.Context, offset uuid.UUID, limit int) ([]domain.VectorRecord, uuid.UUID, error) {
+	request := &qdrant.ScrollPoints{
+		CollectionName: r.collection,
+		Limit:          qdrant.PtrOf(uint32(limit)),
+		WithPayload:    qdrant.NewWithPayload(true),
+	}
+	if offset != uuid.Nil {
+		request.Offset = qdrant.NewID(offset.String())
+	}
+
+	var points []*qdrant.RetrievedPoint
+	var next *qdrant.PointId
+	err := r.call(ctx, "Scroll", r.timeout, func(ctx context.Context) error {
+		var err error
+		points, next, err = r.client.ScrollAndOffset(ctx, request)
+		return err
+	})
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to scroll vectors: %w", err)
+	}
+
+	records := make([]domain.VectorRecord, 0, len(points))
+	for _, point := range points {
+		id, ok := pointUUID(point.Id)
+		if !ok {
+			continue
+		}
+
+		payload := make(map[string]interface{}, len(point.Payload))
+		for key, value := range point.Payload {
+			payload[key] = extractValue(value)
+		}
+		records = append(records, domain.VectorRecord{ID: id, Payload: payload})
+	}
+
+	// A nil next offset means the scroll reached the end of the collection
+	nextID := uuid.Nil
+	if next != nil {
+		if id, ok := pointUUID(next); ok {
+			nextID = id
+		}
+	}
+
+	return records, nextID, nil
+}
+
+func (r *Repository) Existing(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	pointIDs := make([]*qdrant.PointId, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = qdrant.NewID(id.String())
+	}
+
+	var points []*qdrant.RetrievedPoint
+	err := r.call(ctx, "Get", r.timeout, func(ctx context.Context) error {
+		var err error
+		points, err = r.client.Get(ctx, &qdrant.GetPoints{
+			CollectionName: r.collection,
+			Ids:            pointIDs,
+			WithPayload:    qdrant.NewWithPayload(false),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vectors: %w", err)
+	}
+
+	existing := make([]uuid.UUID, 0, len(points))
+	for _, point := range points {
+		if id, ok := pointUUID(point.Id); ok {
+			existing = append(existing, id)
+		}
+	}
+
+	return existing, nil
+}
+
+// pointUUID parses a point ID stored as a UUID
+func pointUUID(pointID *qdrant.PointId) (uuid.UUID, bool) {
+	value, ok := pointID.GetPointIdOptions().(*qdrant.PointId_Uuid)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(value.Uuid)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
 // extractValue converts Qdrant Value to Go interface{}
 func extractValue(value *qdrant.Value) interface{} {
 	if value == nil {
@@ -203,4 +433,37 @@ func extractValue(value *qdrant.Value) interface{} {
 	default:
 		return nil
 	}
-}
\ No newline at end of file
+}
+// Ping checks that Qdrant answers and the collection exists. It is not retried,
+// so health checks report a hiccup as it happens
+func (r *Repository) Ping(ctx context.Context) error {
+	exists, err := r.client.CollectionExists(ctx, r.collection)
+	if err != nil {
+		return fmt.Errorf("failed to reach qdrant: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("collection %s does not exist", r.collection)
+	}
+	return nil
+}
+
+func (r *Repository) Count(ctx context.Context, namespace string) (int64, error) {
+	request := &qdrant.CountPoints{
+		CollectionName: r.collection,
+		Exact:          qdrant.PtrOf(true),
+	}
+	if namespace != "" {
+		request.Filter = buildFilter(domain.Filter{domain.Eq("namespace", namespace)})
+	}
+
+	var count uint64
+	err := r.call(ctx, "Count", r.timeout, func(ctx context.Context) error {
+		var err error
+		count, err = r.client.Count(ctx, request)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count vectors: %w", err)
+	}
+	return int64(count), nil
+}
@@ -0,0 +1,105 @@
+package qdrant
+
+import (
+	"strings"
+
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// buildFilter translates filter conditions into a Qdrant filter. Prefix
+// conditions narrow the query with a text match and are checked exactly by
+// matchesPrefixes on the results
+func buildFilter(filter domain.Filter) *qdrant.Filter {
+	if len(filter) == 0 {
+		return nil
+	}
+
+	result := &qdrant.Filter{}
+	for _, condition := range filter {
+		switch condition.Op {
+		case domain.FilterEq:
+			result.Must = append(result.Must, matchCondition(condition.Field, condition.Value))
+		case domain.FilterNe:
+			result.MustNot = append(result.MustNot, matchCondition(condition.Field, condition.Value))
+		case domain.FilterGt:
+			value, _ := domain.FilterNumber(condition.Value)
+			result.Must = append(result.Must, qdrant.NewRange(condition.Field, &qdrant.Range{Gt: &value}))
+		case domain.FilterLt:
+			value, _ := domain.FilterNumber(condition.Value)
+			result.Must = append(result.Must, qdrant.NewRange(condition.Field, &qdrant.Range{Lt: &value}))
+		case domain.FilterIn:
+			result.Must = append(result.Must, anyCondition(condition.Field, condition.Value))
+		case domain.FilterExists:
+			result.MustNot = append(result.MustNot, qdrant.NewIsEmpty(condition.Field))
+		case domain.FilterPrefix:
+			prefix, _ := condition.Value.(string)
+			result.Must = append(result.Must, qdrant.NewMatchText(condition.Field, prefix))
+		}
+	}
+	return result
+}
+
+// matchCondition matches a field equal to a scalar value. Numbers are stored
+// as doubles, so they match as a closed range rather than an integer
+func matchCondition(field string, value interface{}) *qdrant.Condition {
+	switch v := value.(type) {
+	case string:
+		return qdrant.NewMatch(field, v)
+	case bool:
+		return qdrant.NewMatchBool(field, v)
+	}
+	number, _ := domain.FilterNumber(value)
+	return qdrant.NewRange(field, &qdrant.Range{Gte: &number, Lte: &number})
+}
+
+// anyCondition matches a field equal to any of a list of values
+func anyCondition(field string, value interface{}) *qdrant.Condition {
+	values, _ := value.([]interface{})
+
+	keywords := make([]string, 0, len(values))
+	for _, v := range values {
+		keyword, ok := v.(string)
+		if !ok {
+			break
+		}
+		keywords = append(keywords, keyword)
+	}
+	if len(keywords) == len(values) {
+		return qdrant.NewMatchKeywords(field, keywords...)
+	}
+
+	should := make([]*qdrant.Condition, len(values))
+	for i, v := range values {
+		should[i] = matchCondition(field, v)
+	}
+	return qdrant.NewFilterAsCondition(&qdrant.Filter{Should: should})
+}
+
+// matchesPrefixes reports whether a result's payload satisfies every prefix condition
+func matchesPrefixes(filter domain.Filter, payload map[string]interface{}) bool {
+	for _, condition := range filter {
+		if condition.Op != domain.FilterPrefix {
+			continue
+		}
+		prefix, _ := condition.Value.(string)
+		value, ok := payloadField(payload, condition.Field).(string)
+		if !ok || !strings.HasPrefix(value, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// payloadField reads a dotted path from a payload
+func payloadField(payload map[string]interface{}, field string) interface{} {
+	var value interface{} = payload
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anunay/mentis/internal/logging"
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// call runs op with each attempt bounded by timeout, retrying transient
+// failures with backoff until the attempts run out or ctx ends, so a brief
+// Qdrant restart or overload doesn't fail the request
+func (r *Repository) call(ctx context.Context, operation string, timeout time.Duration, op func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := attemptCall(ctx, timeout, op)
+		if err == nil || attempt >= r.retry.MaxAttempts || !transient(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := r.retry.Backoff(attempt)
+		logging.FromContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"operation": operation,
+			"attempt":   attempt,
+			"retry_in":  delay,
+		}).Warn("Qdrant call failed, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func attemptCall(ctx context.Context, timeout time.Duration, op func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return op(ctx)
+}
+
+// transient reports whether a failed call may succeed when sent again: Qdrant
+// was unreachable, overloaded, or didn't answer within the attempt timeout
+func transient(err error) bool {
+	var exhausted *qdrant.QdrantResourceExhaustedError
+	if errors.As(err, &exhausted) {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
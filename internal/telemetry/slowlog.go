@@ -0,0 +1,116 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/anunay/mentis/internal/config"
+	"github.com/anunay/mentis/internal/core/domain"
+	"github.com/anunay/mentis/internal/core/services/embedding"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	requestIDAttribute = attribute.Key("mentis.request_id")
+	// maxLoggedStatement truncates SQL in slow query warnings
+	maxLoggedStatement = 500
+)
+
+// slowSpanProcessor logs embedding calls, vector searches, and database queries
+// whose spans outlast their configured threshold
+type slowSpanProcessor struct {
+	thresholds config.SlowLogConfig
+}
+
+func newSlowSpanProcessor(thresholds config.SlowLogConfig) *slowSpanProcessor {
+	return &slowSpanProcessor{thresholds: thresholds}
+}
+
+// OnStart tags spans with the request they serve so slow child spans can be traced back
+func (p *slowSpanProcessor) OnStart(parent context.Context, span sdktrace.ReadWriteSpan) {
+	if requestID := domain.RequestIDFromContext(parent); requestID != "" {
+		span.SetAttributes(requestIDAttribute.String(requestID))
+	}
+}
+
+func (p *slowSpanProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	attributes := make(map[attribute.Key]attribute.Value, len(span.Attributes()))
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+
+	operation, threshold := p.classify(span.Name(), attributes)
+	duration := span.EndTime().Sub(span.StartTime())
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	fields := logrus.Fields{
+		"operation":    operation,
+		"span":         span.Name(),
+		"duration_ms":  duration.Milliseconds(),
+		"threshold_ms": threshold.Milliseconds(),
+		"trace_id":     span.SpanContext().TraceID().String(),
+	}
+	if requestID, ok := attributes[requestIDAttribute]; ok {
+		fields["request_id"] = requestID.AsString()
+	}
+	if statement, ok := attributes["db.statement"]; ok {
+		fields["statement"] = truncate(strings.Join(strings.Fields(statement.AsString()), " "), maxLoggedStatement)
+	}
+	if model, ok := attributes["mentis.embedding.model"]; ok {
+		fields["model"] = model.AsString()
+	}
+	if span.Status().Code == codes.Error {
+		fields["error"] = span.Status().Description
+	}
+	logrus.WithFields(fields).Warn("Slow operation")
+}
+
+// classify names the kind of operation a span records and its threshold; spans
+// that are not watched get a zero threshold
+func (p *slowSpanProcessor) classify(name string, attributes map[attribute.Key]attribute.Value) (string, time.Duration) {
+	switch {
+	case name == embedding.SpanName:
+		return "embedding", p.thresholds.Embedding
+	case attributes["rpc.system"].AsString() == "grpc" &&
+		(strings.HasPrefix(name, "qdrant.Points/Query") || strings.HasPrefix(name, "qdrant.Points/Search")):
+		return "vector_search", p.thresholds.VectorSearch
+	case attributes["db.statement"].AsString() != "":
+		return "db_query", p.thresholds.Query
+	}
+	return "", 0
+}
+
+func (p *slowSpanProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+func (p *slowSpanProcessor) ForceFlush(context.Context) error {
+	return nil
+}
+
+// recordingSampler keeps spans the wrapped sampler drops as record-only, so the
+// status and slow span processors see every span while only sampled ones are exported
+type recordingSampler struct {
+	sdktrace.Sampler
+}
+
+func (s recordingSampler) ShouldSample(parameters sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.Sampler.ShouldSample(parameters)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/XSAM/otelsql"
+	"github.com/anunay/mentis/internal/core/services/embedding"
+	"github.com/anunay/mentis/internal/status"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// statusSpanProcessor counts calls to Postgres, the vector store, and the
+// embedding provider, and their failures, for the component status report
+type statusSpanProcessor struct{}
+
+func (statusSpanProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (statusSpanProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	component := dependencyOf(span)
+	if component == "" {
+		return
+	}
+
+	var err error
+	if span.Status().Code == codes.Error {
+		// A caller giving up is not a failure of the dependency
+		if span.Status().Description == context.Canceled.Error() {
+			return
+		}
+		err = errors.New(span.Status().Description)
+	}
+	status.Record(component, err)
+}
+
+// dependencyOf names the dependency a span calls. Row iteration and session
+// reset spans are skipped so each query counts once
+func dependencyOf(span sdktrace.ReadOnlySpan) string {
+	switch span.Name() {
+	case embedding.SpanName:
+		return status.Embedding
+	case string(otelsql.MethodRows), string(otelsql.MethodConnResetSession):
+		return ""
+	}
+	for _, kv := range span.Attributes() {
+		switch {
+		case kv.Key == semconv.DBSystemKey && kv.Value.AsString() == semconv.DBSystemPostgreSQL.Value.AsString():
+			return status.Postgres
+		case kv.Key == semconv.RPCSystemKey && kv.Value.AsString() == "grpc" && strings.HasPrefix(span.Name(), "qdrant."):
+			return status.Vector
+		}
+	}
+	return ""
+}
+
+func (statusSpanProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+func (statusSpanProcessor) ForceFlush(context.Context) error {
+	return nil
+}
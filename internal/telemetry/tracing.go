@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anunay/mentis/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Setup installs the global tracer provider. Every span is recorded so calls to
+// dependencies feed the component status report and, with slow-operation logging
+// enabled, their durations can be checked. With tracing enabled sampled spans
+// are also exported over OTLP gRPC. The returned function flushes pending spans
+func Setup(ctx context.Context, tracing config.TracingConfig, slowLog config.SlowLogConfig) (func(context.Context) error, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(tracing.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	sampler := sdktrace.NeverSample()
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSpanProcessor(statusSpanProcessor{}),
+	}
+	if tracing.Enabled {
+		exporterOptions := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(tracing.Endpoint)}
+		if tracing.Insecure {
+			exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, exporterOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+		sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(tracing.SampleRatio))
+
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		))
+	}
+	if slowLog.Enabled() {
+		options = append(options, sdktrace.WithSpanProcessor(newSlowSpanProcessor(slowLog)))
+	}
+	options = append(options, sdktrace.WithSampler(recordingSampler{sampler}))
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
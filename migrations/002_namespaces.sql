@@ -0,0 +1,14 @@
+-- Add namespace scoping to all tenant-owned tables
+ALTER TABLE artifacts ADD COLUMN namespace VARCHAR(100) NOT NULL DEFAULT 'default';
+ALTER TABLE workflow_sessions ADD COLUMN namespace VARCHAR(100) NOT NULL DEFAULT 'default';
+ALTER TABLE workflow_steps ADD COLUMN namespace VARCHAR(100) NOT NULL DEFAULT 'default';
+
+-- Create indexes for namespace-scoped lookups
+CREATE INDEX idx_artifacts_namespace_content_hash ON artifacts(namespace, content_hash);
+CREATE INDEX idx_artifacts_namespace_created_at ON artifacts(namespace, created_at);
+CREATE INDEX idx_workflow_sessions_namespace_created_at ON workflow_sessions(namespace, created_at);
+CREATE INDEX idx_workflow_steps_namespace_input_hash ON workflow_steps(namespace, step_type, input_hash);
+
+-- Step deduplication is per namespace
+DROP INDEX idx_workflow_steps_dedup;
+CREATE UNIQUE INDEX idx_workflow_steps_dedup ON workflow_steps(namespace, step_type, input_hash) WHERE status = 'completed';
@@ -0,0 +1,15 @@
+-- Create api_keys table for scoped, revocable API keys
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(200) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    namespaces TEXT[] NOT NULL DEFAULT '{}',
+    artifact_types TEXT[] NOT NULL DEFAULT '{}',
+    operations TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_api_keys_created_at ON api_keys(created_at);
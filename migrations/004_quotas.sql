@@ -0,0 +1,16 @@
+-- Per-namespace quota overrides; namespaces without a row use the configured defaults
+CREATE TABLE namespace_quotas (
+    namespace VARCHAR(100) PRIMARY KEY,
+    max_artifacts BIGINT NOT NULL DEFAULT 0,
+    max_bytes BIGINT NOT NULL DEFAULT 0,
+    max_embeddings_per_day BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Daily usage counters per namespace
+CREATE TABLE usage_daily (
+    namespace VARCHAR(100) NOT NULL,
+    day DATE NOT NULL,
+    embeddings BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (namespace, day)
+);
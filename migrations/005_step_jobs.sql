@@ -0,0 +1,21 @@
+-- Create step_jobs table backing asynchronous step execution
+CREATE TABLE step_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    step_id UUID NOT NULL REFERENCES workflow_steps(id) ON DELETE CASCADE,
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    input JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes for job claiming and lease recovery
+CREATE INDEX idx_step_jobs_claim ON step_jobs(run_at, created_at) WHERE status = 'queued';
+CREATE INDEX idx_step_jobs_running ON step_jobs(locked_at) WHERE status = 'running';
+CREATE INDEX idx_step_jobs_step_id ON step_jobs(step_id);
+
+CREATE TRIGGER update_step_jobs_updated_at BEFORE UPDATE ON step_jobs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+-- Allow steps to wait between retry attempts
+ALTER TABLE workflow_steps DROP CONSTRAINT workflow_steps_status_check;
+ALTER TABLE workflow_steps ADD CONSTRAINT workflow_steps_status_check
+    CHECK (status IN ('pending', 'running', 'retrying', 'completed', 'failed'));
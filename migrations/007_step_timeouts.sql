@@ -0,0 +1,7 @@
+-- Allow steps to be marked as timed out
+ALTER TABLE workflow_steps DROP CONSTRAINT workflow_steps_status_check;
+ALTER TABLE workflow_steps ADD CONSTRAINT workflow_steps_status_check
+    CHECK (status IN ('pending', 'running', 'retrying', 'completed', 'failed', 'timed_out'));
+
+-- Carry the per-attempt timeout of asynchronous steps
+ALTER TABLE step_jobs ADD COLUMN timeout_ms BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Webhook receivers notified of session and step events
+ALTER TABLE workflow_sessions ADD COLUMN callback_urls TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,9 @@
+-- Create step_schemas table holding per-step-type JSON Schemas
+CREATE TABLE step_schemas (
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    step_type VARCHAR(100) NOT NULL,
+    input_schema JSONB,
+    output_schema JSONB,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (namespace, step_type)
+);
@@ -0,0 +1,20 @@
+-- Create workflow_templates table holding immutable template versions
+CREATE TABLE workflow_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    name VARCHAR(100) NOT NULL,
+    version INTEGER NOT NULL CHECK (version > 0),
+    description TEXT,
+    steps TEXT[] NOT NULL DEFAULT '{}',
+    compatible_from INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (namespace, name, version)
+);
+
+-- Record the template version sessions and steps run under
+ALTER TABLE workflow_sessions ADD COLUMN template_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE workflow_sessions ADD COLUMN template_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workflow_steps ADD COLUMN template_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE workflow_steps ADD COLUMN template_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_workflow_steps_template ON workflow_steps(namespace, template_name, template_version);
@@ -0,0 +1,4 @@
+-- Mark step jobs that are claimed by external executors instead of the worker pool
+ALTER TABLE step_jobs ADD COLUMN external BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX idx_step_jobs_external_claim ON step_jobs(namespace, run_at, created_at) WHERE status = 'queued' AND external;
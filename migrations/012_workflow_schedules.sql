@@ -0,0 +1,33 @@
+-- Create workflow_schedules table for cron-triggered workflows
+CREATE TABLE workflow_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    name VARCHAR(255) NOT NULL,
+    cron VARCHAR(100) NOT NULL,
+    template_name VARCHAR(100) NOT NULL DEFAULT '',
+    template_version INTEGER NOT NULL DEFAULT 0,
+    goal TEXT NOT NULL,
+    context JSONB DEFAULT '{}',
+    steps JSONB NOT NULL DEFAULT '[]',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (namespace, name)
+);
+
+-- Create schedule_runs table recording each execution and its session
+CREATE TABLE schedule_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES workflow_schedules(id) ON DELETE CASCADE,
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    session_id UUID REFERENCES workflow_sessions(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_workflow_schedules_due ON workflow_schedules(next_run_at) WHERE enabled;
+CREATE INDEX idx_schedule_runs_schedule ON schedule_runs(schedule_id, started_at DESC);
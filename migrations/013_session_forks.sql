@@ -0,0 +1,5 @@
+-- Let sessions fork from a parent, sharing its steps completed before the fork point
+ALTER TABLE workflow_sessions ADD COLUMN parent_session_id UUID REFERENCES workflow_sessions(id) ON DELETE SET NULL;
+ALTER TABLE workflow_sessions ADD COLUMN forked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_workflow_sessions_parent ON workflow_sessions(parent_session_id);
@@ -0,0 +1,5 @@
+-- Track token usage and cost per step so spend can be attributed to sessions
+ALTER TABLE workflow_steps ADD COLUMN prompt_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_steps ADD COLUMN completion_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_steps ADD COLUMN embedding_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_steps ADD COLUMN cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0;
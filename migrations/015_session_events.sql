@@ -0,0 +1,13 @@
+-- Ordered per-session event timeline for debugging agent behaviour
+CREATE TABLE session_events (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    session_id UUID NOT NULL REFERENCES workflow_sessions(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    step_id UUID,
+    data JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_session_events_session ON session_events(namespace, session_id, seq);
@@ -0,0 +1,3 @@
+-- Let forced re-executions replace earlier cached results for the same input
+ALTER TABLE workflow_steps ADD COLUMN forced BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE workflow_steps ADD COLUMN superseded_by UUID REFERENCES workflow_steps(id) ON DELETE SET NULL;
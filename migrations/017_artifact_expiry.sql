@@ -0,0 +1,4 @@
+-- Optional per-artifact expiry, enforced by a background sweeper
+ALTER TABLE artifacts ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_artifacts_expiry ON artifacts(expires_at) WHERE expires_at IS NOT NULL AND stale = false;
@@ -0,0 +1,5 @@
+-- Track artifact access for least-recently-used eviction; pinned artifacts are never evicted
+ALTER TABLE artifacts ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE artifacts ADD COLUMN last_accessed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_artifacts_lru ON artifacts(last_accessed_at) WHERE pinned = false;
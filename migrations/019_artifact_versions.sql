@@ -0,0 +1,15 @@
+-- Republishing an artifact under the same identity archives the replaced content
+ALTER TABLE artifacts ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE artifact_versions (
+    artifact_id UUID NOT NULL REFERENCES artifacts(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    content BYTEA,
+    metadata JSONB,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    superseded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (artifact_id, version)
+);
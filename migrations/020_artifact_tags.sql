@@ -0,0 +1,4 @@
+-- Free-form labels for filtering artifacts
+ALTER TABLE artifacts ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_artifacts_tags ON artifacts USING GIN (tags);
@@ -0,0 +1,13 @@
+-- Short-lived records of lookups that found nothing and step inputs that failed
+CREATE TABLE negative_cache (
+    namespace VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    key TEXT NOT NULL,
+    reason TEXT,
+    code VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (namespace, kind, key)
+);
+
+CREATE INDEX idx_negative_cache_expires_at ON negative_cache(expires_at);
@@ -0,0 +1,10 @@
+-- Daily cache hit and miss counters per namespace; step_type is empty for artifact lookups
+CREATE TABLE cache_stats_daily (
+    namespace VARCHAR(100) NOT NULL,
+    day DATE NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    step_type VARCHAR(255) NOT NULL DEFAULT '',
+    hits BIGINT NOT NULL DEFAULT 0,
+    misses BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (namespace, day, kind, step_type)
+);
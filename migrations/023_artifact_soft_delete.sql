@@ -0,0 +1,4 @@
+-- Deleted artifacts stay restorable until the sweeper purges them
+ALTER TABLE artifacts ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_artifacts_deleted_at ON artifacts(deleted_at) WHERE deleted_at IS NOT NULL;
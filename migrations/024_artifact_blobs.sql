@@ -0,0 +1,8 @@
+-- Content above the offload threshold lives in object storage; content_ref is its key
+ALTER TABLE artifacts ADD COLUMN content_ref TEXT;
+ALTER TABLE artifacts ADD COLUMN content_size BIGINT NOT NULL DEFAULT 0;
+UPDATE artifacts SET content_size = COALESCE(octet_length(content), 0);
+
+ALTER TABLE artifact_versions ADD COLUMN content_ref TEXT;
+ALTER TABLE artifact_versions ADD COLUMN content_size BIGINT NOT NULL DEFAULT 0;
+UPDATE artifact_versions SET content_size = COALESCE(octet_length(content), 0);
@@ -0,0 +1,21 @@
+-- Create refresh_hooks table for callbacks that re-fetch or re-derive stale artifacts
+CREATE TABLE refresh_hooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    name VARCHAR(255) NOT NULL,
+    source_prefix TEXT NOT NULL DEFAULT '',
+    artifact_type VARCHAR(50) NOT NULL DEFAULT '',
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('webhook', 'step')),
+    url TEXT NOT NULL DEFAULT '',
+    step_type VARCHAR(100) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (namespace, name)
+);
+
+-- When the refresher last tried a stale artifact, so failed refreshes back off
+ALTER TABLE artifacts ADD COLUMN refresh_attempted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_refresh_hooks_namespace ON refresh_hooks(namespace) WHERE enabled;
+CREATE INDEX idx_artifacts_stale_since ON artifacts(updated_at) WHERE stale AND deleted_at IS NULL;
@@ -0,0 +1,15 @@
+-- Create invalidation_policies table; the sweeper marks artifacts of a policy's
+-- type stale once they are older than its max age
+CREATE TABLE invalidation_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    name VARCHAR(255) NOT NULL,
+    artifact_type VARCHAR(50) NOT NULL DEFAULT '',
+    max_age_seconds BIGINT NOT NULL CHECK (max_age_seconds > 0),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (namespace, name)
+);
+
+CREATE INDEX idx_artifacts_fresh_age ON artifacts(namespace, type, updated_at) WHERE stale = false AND deleted_at IS NULL;
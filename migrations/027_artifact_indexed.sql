@@ -0,0 +1,5 @@
+-- Track which artifacts should have a vector so reconciliation can find the
+-- ones that lost it; existing rows are unknown and left unindexed
+ALTER TABLE artifacts ADD COLUMN indexed BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX idx_artifacts_indexed ON artifacts(id) WHERE indexed = true AND deleted_at IS NULL;
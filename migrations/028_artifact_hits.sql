@@ -0,0 +1,4 @@
+-- Count artifact reuse; hits are buffered in memory and flushed in batches
+ALTER TABLE artifacts ADD COLUMN hit_count BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_artifacts_hits ON artifacts(namespace, hit_count DESC) WHERE hit_count > 0 AND deleted_at IS NULL;
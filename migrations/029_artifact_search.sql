@@ -0,0 +1,16 @@
+-- Full-text index over artifact content for hybrid lookups. Content that is not
+-- valid UTF-8 text, or too long for a tsvector, is left unindexed rather than
+-- failing the write; content offloaded to blob storage is not indexed
+CREATE FUNCTION artifact_search_vector(content BYTEA) RETURNS tsvector
+LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+    RETURN to_tsvector('english'::regconfig, convert_from(content, 'UTF8'));
+EXCEPTION WHEN others THEN
+    RETURN ''::tsvector;
+END
+$$;
+
+ALTER TABLE artifacts ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (artifact_search_vector(content)) STORED;
+
+CREATE INDEX idx_artifacts_search ON artifacts USING GIN (search_vector);
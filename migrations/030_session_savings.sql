@@ -0,0 +1,8 @@
+-- Estimate what step reuse saved each session: the usage and run time the
+-- reused steps originally cost
+ALTER TABLE workflow_sessions ADD COLUMN cache_hits BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_sessions ADD COLUMN saved_prompt_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_sessions ADD COLUMN saved_completion_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_sessions ADD COLUMN saved_embedding_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_sessions ADD COLUMN saved_cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE workflow_sessions ADD COLUMN saved_seconds DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Daily usage per namespace, rolled up by the usage aggregator from the artifact,
+-- cache statistics, and embedding usage tables. bytes_stored is a snapshot taken
+-- while the day was current and stays NULL for days it was never measured
+CREATE TABLE usage_summary_daily (
+    namespace VARCHAR(100) NOT NULL,
+    day DATE NOT NULL,
+    publishes BIGINT NOT NULL DEFAULT 0,
+    lookups BIGINT NOT NULL DEFAULT 0,
+    cache_hits BIGINT NOT NULL DEFAULT 0,
+    embeddings BIGINT NOT NULL DEFAULT 0,
+    bytes_stored BIGINT,
+    aggregated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (namespace, day)
+);
+
+CREATE INDEX idx_usage_summary_daily_day ON usage_summary_daily(day);
@@ -0,0 +1,13 @@
+-- Vector upserts owed to the vector store, written in the same statement as the
+-- artifact they belong to and removed once the vector is stored. The relay
+-- retries rows whose next attempt is due, backing off after each failure
+CREATE TABLE vector_outbox (
+    artifact_id UUID PRIMARY KEY REFERENCES artifacts(id) ON DELETE CASCADE,
+    embedding REAL[] NOT NULL,
+    queued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT
+);
+
+CREATE INDEX idx_vector_outbox_next_attempt_at ON vector_outbox(next_attempt_at);
@@ -0,0 +1,5 @@
+-- Listings page by (time, id) instead of offset; these indexes serve each page
+-- as a range scan that starts at the cursor
+CREATE INDEX idx_artifacts_list ON artifacts(namespace, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_artifacts_list_deleted ON artifacts(namespace, deleted_at DESC, id DESC) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_workflow_sessions_list ON workflow_sessions(namespace, created_at DESC, id DESC);
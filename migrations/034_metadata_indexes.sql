@@ -0,0 +1,14 @@
+-- Invalidating by source URL matches the exact URL within a namespace; the
+-- trigram index from the initial schema only serves substring searches
+CREATE INDEX idx_artifacts_source_url ON artifacts(namespace, (metadata->>'source_url'));
+
+CREATE INDEX idx_artifacts_namespace_type ON artifacts(namespace, type) WHERE deleted_at IS NULL;
+
+-- A plain index on a boolean is rarely used; stale artifacts are a small
+-- fraction of a namespace, so index just those
+DROP INDEX IF EXISTS idx_artifacts_stale;
+CREATE INDEX idx_artifacts_namespace_stale ON artifacts(namespace) WHERE stale AND deleted_at IS NULL;
+
+-- Step cache checks match a step type and input hash, newest reusable run first,
+-- including across namespaces when a lookup shares steps
+CREATE INDEX idx_workflow_steps_reuse ON workflow_steps(step_type, input_hash, status, created_at DESC) WHERE superseded_by IS NULL;
@@ -0,0 +1,8 @@
+-- Background jobs that must run on one replica at a time hold a lease here.
+-- The holder renews it while running; another replica takes over once it expires
+CREATE TABLE worker_leases (
+    name VARCHAR(100) PRIMARY KEY,
+    holder VARCHAR(255) NOT NULL,
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
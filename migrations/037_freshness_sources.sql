@@ -0,0 +1,21 @@
+-- Create freshness_sources table; the freshness watcher polls each sitemap or
+-- feed and invalidates artifacts of the URLs it reports modified
+CREATE TABLE freshness_sources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    namespace VARCHAR(100) NOT NULL DEFAULT 'default',
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    interval_seconds BIGINT NOT NULL CHECK (interval_seconds > 0),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    next_check_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    last_invalidated INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (namespace, name)
+);
+
+CREATE INDEX idx_freshness_sources_due ON freshness_sources(next_check_at) WHERE enabled;
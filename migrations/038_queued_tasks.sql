@@ -0,0 +1,13 @@
+-- Durable tasks any replica may take, such as webhook deliveries. Dequeuing a
+-- task pushes its run_at out by the lease, so a task its consumer neither
+-- acknowledges nor retries in time is delivered again
+CREATE TABLE queued_tasks (
+    id UUID PRIMARY KEY,
+    queue VARCHAR(100) NOT NULL,
+    payload BYTEA NOT NULL,
+    attempt INTEGER NOT NULL DEFAULT 1,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_queued_tasks_due ON queued_tasks(queue, run_at);
@@ -0,0 +1,3 @@
+-- Identify each lease an external executor takes, so only the executor holding
+-- a step's current lease can complete it
+ALTER TABLE step_jobs ADD COLUMN lease_token UUID;
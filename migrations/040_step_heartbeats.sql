@@ -0,0 +1,3 @@
+-- Inline executions renew a heartbeat on their step, so recovery only fails
+-- steps whose executing instance stopped renewing it
+ALTER TABLE workflow_steps ADD COLUMN heartbeat_at TIMESTAMP WITH TIME ZONE;
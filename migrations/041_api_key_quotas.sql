@@ -0,0 +1,20 @@
+-- Per-API-key quota overrides; keys without a row are only bound by their namespace's quota
+CREATE TABLE api_key_quotas (
+    key_id UUID PRIMARY KEY REFERENCES api_keys(id) ON DELETE CASCADE,
+    max_artifacts BIGINT NOT NULL DEFAULT 0,
+    max_bytes BIGINT NOT NULL DEFAULT 0,
+    max_embeddings_per_day BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Daily usage counters per API key
+CREATE TABLE usage_daily_keys (
+    key_id UUID NOT NULL,
+    day DATE NOT NULL,
+    embeddings BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
+
+-- The key that first stored each artifact, so its artifacts count against it
+ALTER TABLE artifacts ADD COLUMN api_key_id UUID;
+CREATE INDEX idx_artifacts_api_key ON artifacts (api_key_id) WHERE api_key_id IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,14 @@
+-- skip_cache was folded into force; scheduled steps that skipped the cache are
+-- now forced, so they keep being recomputed on every run
+UPDATE workflow_schedules
+SET steps = (
+    SELECT jsonb_agg(
+        CASE WHEN (step->>'skip_cache')::boolean
+            THEN (step - 'skip_cache') || '{"force": true}'
+            ELSE step - 'skip_cache'
+        END
+        ORDER BY position
+    )
+    FROM jsonb_array_elements(steps) WITH ORDINALITY AS elements(step, position)
+)
+WHERE jsonb_path_exists(steps, '$[*].skip_cache');